@@ -34,9 +34,11 @@ type AbiMgr struct {
 }
 
 func NewAbiMgr() *AbiMgr {
-	return &AbiMgr{
+	mgr := &AbiMgr{
 		nativeAbis: make(map[string]*NativeContractAbi),
 	}
+	registerNativeEvents(mgr)
+	return mgr
 }
 
 func (this *AbiMgr) GetNativeAbi(address string) *NativeContractAbi {
@@ -47,6 +49,22 @@ func (this *AbiMgr) GetNativeAbi(address string) *NativeContractAbi {
 	return nil
 }
 
+//RegisterNativeEvent add event abi of native contract at address, replace the event of same name
+func (this *AbiMgr) RegisterNativeEvent(address string, evtAbi *NativeContractEventAbi) {
+	nativeAbi, ok := this.nativeAbis[address]
+	if !ok {
+		nativeAbi = &NativeContractAbi{Address: address}
+		this.nativeAbis[address] = nativeAbi
+	}
+	for i, evt := range nativeAbi.Events {
+		if strings.ToLower(evt.Name) == strings.ToLower(evtAbi.Name) {
+			nativeAbi.Events[i] = evtAbi
+			return
+		}
+	}
+	nativeAbi.Events = append(nativeAbi.Events, evtAbi)
+}
+
 func (this *AbiMgr) Init(path string) {
 	this.Path = path
 	this.loadNativeAbi()
@@ -77,6 +95,14 @@ func (this *AbiMgr) loadNativeAbi() {
 			log.Errorf("AbiMgr loadNativeAbi name:%s error:%s", fileName, err)
 			continue
 		}
+		//events registered are kept unless defined by the file
+		if registered, ok := this.nativeAbis[nativeAbi.Address]; ok {
+			for _, evtAbi := range registered.Events {
+				if nativeAbi.GetEvent(evtAbi.Name) == nil {
+					nativeAbi.Events = append(nativeAbi.Events, evtAbi)
+				}
+			}
+		}
 		this.nativeAbis[nativeAbi.Address] = nativeAbi
 		log.Infof("Native contract name:%s address:%s abi load success", fileName, nativeAbi.Address)
 	}
@@ -0,0 +1,212 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+//NativeEvent is notify states of native contract decoded with event abi
+type NativeEvent struct {
+	Name   string
+	Fields map[string]interface{}
+}
+
+//newNativeEventAbi return event abi of fields in format of name:type
+func newNativeEventAbi(name string, fields ...string) *NativeContractEventAbi {
+	evtAbi := &NativeContractEventAbi{Name: name, Parameters: make([]*NativeContractParamAbi, 0, len(fields))}
+	for _, field := range fields {
+		items := strings.SplitN(field, ":", 2)
+		param := &NativeContractParamAbi{Name: items[0], Type: items[1]}
+		if strings.HasPrefix(param.Type, NATIVE_PARAM_TYPE_ARRAY+":") {
+			param.SubType = []*NativeContractParamAbi{{Type: strings.TrimPrefix(param.Type, NATIVE_PARAM_TYPE_ARRAY+":")}}
+			param.Type = NATIVE_PARAM_TYPE_ARRAY
+		}
+		evtAbi.Parameters = append(evtAbi.Parameters, param)
+	}
+	return evtAbi
+}
+
+//registerNativeEvents register events notified by poly native contracts, the first state of notify is the event name
+func registerNativeEvents(mgr *AbiMgr) {
+	register := func(address string, events ...*NativeContractEventAbi) {
+		for _, evtAbi := range events {
+			mgr.RegisterNativeEvent(address, evtAbi)
+		}
+	}
+	register(utils.HeaderSyncContractAddress.ToHexString(),
+		newNativeEventAbi("syncHeader", "ChainID:int", "Height:int", "BlockHash:string", "PolyHeight:int"),
+		newNativeEventAbi("syncCrossChainMsg", "ChainID:int", "Height:int", "PolyHeight:int"),
+		newNativeEventAbi("setBtcCheckpoints", "ChainID:int", "Height:int", "BlockHash:string"),
+		newNativeEventAbi("setBtcMaxReorgDepth", "ChainID:int", "MaxReorgDepth:int"),
+	)
+	register(utils.CrossChainManagerContractAddress.ToHexString(),
+		newNativeEventAbi("makeProof", "FromChainID:int", "ToChainID:int", "TxHash:string", "PolyHeight:int", "Key:string"),
+		newNativeEventAbi("setVoteConfig", "ChainID:int", "QuorumNumerator:int", "QuorumDenominator:int", "ExpiryBlocks:int"),
+		newNativeEventAbi("vote", "ChainID:int", "CrossChainID:bytearray", "ID:bytearray", "Address:string",
+			"Votes:int", "Quorum:int"),
+		newNativeEventAbi("voteExpired", "ChainID:int", "CrossChainID:bytearray", "ID:bytearray", "Votes:int"),
+		newNativeEventAbi("voteFinalized", "ChainID:int", "CrossChainID:bytearray", "ID:bytearray", "Votes:int",
+			"Sum:int"),
+		newNativeEventAbi("setAttesterCommittee", "ChainID:int", "Epoch:int", "Threshold:int", "Size:int"),
+		newNativeEventAbi("blackAttester", "ChainID:int", "PubKey:bytearray"),
+		newNativeEventAbi("whiteAttester", "ChainID:int", "PubKey:bytearray"),
+		newNativeEventAbi("attesterEquivocation", "ChainID:int", "PubKey:bytearray", "TxHash:bytearray",
+			"Address:string"),
+		newNativeEventAbi("btcTxMultiSign", "TxHash:bytearray", "MultiSignInfo:struct"),
+		newNativeEventAbi("btcTxToRelay", "FromChainID:int", "ChainID:int", "BtcTx:bytearray", "FromTxHash:bytearray",
+			"RedeemKey:string"),
+		newNativeEventAbi("btcUtxosConsolidated", "ChainID:int", "RedeemKey:string", "TxHash:bytearray", "Inputs:int",
+			"Amount:int", "Fee:int"),
+		newNativeEventAbi("btcTxFeeBumped", "ChainID:int", "RedeemKey:string", "TxHash:bytearray", "NewTxHash:bytearray",
+			"Fee:int", "NewFee:int"),
+		newNativeEventAbi("makeBtcTx", "RedeemKey:string", "BtcTx:bytearray", "Amounts:array:int"),
+	)
+	register(utils.SideChainManagerContractAddress.ToHexString(),
+		newNativeEventAbi("RegisterSideChain", "ChainId:int", "Router:int", "Name:string", "BlocksToWait:int"),
+		newNativeEventAbi("ApproveRegisterSideChain", "ChainId:int"),
+		newNativeEventAbi("UpdateSideChain", "ChainId:int", "Router:int", "Name:string", "BlocksToWait:int"),
+		newNativeEventAbi("ApproveUpdateSideChain", "ChainId:int"),
+		newNativeEventAbi("QuitSideChain", "ChainId:int"),
+		newNativeEventAbi("ApproveQuitSideChain", "ChainId:int"),
+		newNativeEventAbi("RegisterRedeem", "RedeemKey:bytearray", "ContractAddress:bytearray"),
+		newNativeEventAbi("SetBtcTxParam", "RedeemKey:bytearray", "RedeemChainId:int", "FeeRate:int", "MinChange:int"),
+	)
+	register(utils.NodeManagerContractAddress.ToHexString(),
+		newNativeEventAbi("registerCandidate", "PeerPubkey:string"),
+		newNativeEventAbi("unRegisterCandidate", "PeerPubkey:string"),
+		newNativeEventAbi("approveCandidate", "PeerPubkey:string"),
+		newNativeEventAbi("blackNode", "PeerPubkeyList:array:string"),
+		newNativeEventAbi("whiteNode", "PeerPubkey:string"),
+		newNativeEventAbi("quitNode", "PeerPubkey:string"),
+		newNativeEventAbi("registerBlsKey", "PeerPubkey:string", "BlsPubkey:bytearray"),
+		newNativeEventAbi("reportEquivocation", "PeerPubkey:string", "Height:int"),
+		newNativeEventAbi("commitDpos"),
+		newNativeEventAbi("updateConfig", "Configuration:struct"),
+		newNativeEventAbi("CheckConsensusSigns", "SignsCount:int"),
+	)
+	register(utils.RelayerManagerContractAddress.ToHexString(),
+		newNativeEventAbi("putRelayerApply", "ApplyID:int"),
+		newNativeEventAbi("putRelayerRemove", "RemoveID:int"),
+		newNativeEventAbi("ApproveRegisterRelayer", "ApplyID:int"),
+		newNativeEventAbi("ApproveRemoveRelayer", "RemoveID:int"),
+	)
+	register(utils.Neo3StateManagerContractAddress.ToHexString(),
+		newNativeEventAbi("putStateValidatorApply", "ApplyID:int"),
+		newNativeEventAbi("putStateValidatorRemove", "RemoveID:int"),
+		newNativeEventAbi("ApproveRegisterStateValidator", "ApplyID:int"),
+		newNativeEventAbi("ApproveRemoveStateValidator", "RemoveID:int"),
+	)
+}
+
+//DecodeNativeEvent decode notify states of native contract at address, whose first state is the event name
+func (this *AbiMgr) DecodeNativeEvent(address string, states interface{}) (*NativeEvent, error) {
+	items, ok := states.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("states is not array of event name and fields")
+	}
+	name, ok := items[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("event name is not string")
+	}
+	nativeAbi := this.GetNativeAbi(address)
+	if nativeAbi == nil {
+		return nil, fmt.Errorf("abi of contract %s not found", address)
+	}
+	evtAbi := nativeAbi.GetEvent(name)
+	if evtAbi == nil {
+		return nil, fmt.Errorf("abi of event %s not found", name)
+	}
+	if len(items)-1 != len(evtAbi.Parameters) {
+		return nil, fmt.Errorf("event %s has %d fields, expected %d", name, len(items)-1, len(evtAbi.Parameters))
+	}
+	evt := &NativeEvent{Name: evtAbi.Name, Fields: make(map[string]interface{}, len(evtAbi.Parameters))}
+	for i, param := range evtAbi.Parameters {
+		value, err := decodeNativeParam(param, items[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("event %s field %s error:%s", name, param.Name, err)
+		}
+		evt.Fields[param.Name] = value
+	}
+	return evt, nil
+}
+
+//decodeNativeParam check value of notify states against the param type. Values of notify read from event store
+//are json decoded, so numbers are float64 and byte slices are base64 string
+func decodeNativeParam(param *NativeContractParamAbi, value interface{}) (interface{}, error) {
+	v := reflect.ValueOf(value)
+	switch strings.ToLower(param.Type) {
+	case NATIVE_PARAM_TYPE_BOOL:
+		if v.Kind() != reflect.Bool {
+			return nil, fmt.Errorf("%v is not bool", value)
+		}
+		return v.Bool(), nil
+	case NATIVE_PARAM_TYPE_BYTE, NATIVE_PARAM_TYPE_INTEGER:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return v.Int(), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return v.Uint(), nil
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f != float64(int64(f)) {
+				return nil, fmt.Errorf("%v is not integer", value)
+			}
+			return int64(f), nil
+		}
+		return nil, fmt.Errorf("%v is not integer", value)
+	case NATIVE_PARAM_TYPE_STRING, NATIVE_PARAM_TYPE_ADDRESS, NATIVE_PARAM_TYPE_UINT256:
+		if v.Kind() != reflect.String {
+			return nil, fmt.Errorf("%v is not string", value)
+		}
+		return v.String(), nil
+	case NATIVE_PARAM_TYPE_BYTEARRAY:
+		if data, ok := value.([]byte); ok {
+			return hex.EncodeToString(data), nil
+		}
+		if v.Kind() != reflect.String {
+			return nil, fmt.Errorf("%v is not bytearray", value)
+		}
+		return v.String(), nil
+	case NATIVE_PARAM_TYPE_ARRAY:
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			return nil, fmt.Errorf("%v is not array", value)
+		}
+		if len(param.SubType) != 1 {
+			return value, nil
+		}
+		values := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := decodeNativeParam(param.SubType[0], v.Index(i).Interface())
+			if err != nil {
+				return nil, fmt.Errorf("item %d error:%s", i, err)
+			}
+			values = append(values, item)
+		}
+		return values, nil
+	case NATIVE_PARAM_TYPE_STRUCT:
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", param.Type)
+}
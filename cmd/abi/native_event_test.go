@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package abi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+//jsonStates return states as read from event store
+func jsonStates(t *testing.T, states ...interface{}) interface{} {
+	data, err := json.Marshal(&event.NotifyEventInfo{States: states})
+	assert.Nil(t, err)
+	notify := &event.NotifyEventInfo{}
+	assert.Nil(t, json.Unmarshal(data, notify))
+	return notify.States
+}
+
+func TestDecodeNativeEvent(t *testing.T) {
+	mgr := NewAbiMgr()
+	address := utils.CrossChainManagerContractAddress.ToHexString()
+	evt, err := mgr.DecodeNativeEvent(address, jsonStates(t, "makeProof", uint64(2), uint64(3), "txhash", uint32(100), "key"))
+	assert.Nil(t, err)
+	assert.Equal(t, "makeProof", evt.Name)
+	assert.Equal(t, map[string]interface{}{"FromChainID": int64(2), "ToChainID": int64(3), "TxHash": "txhash",
+		"PolyHeight": int64(100), "Key": "key"}, evt.Fields)
+
+	//notifies of executing are decoded as well
+	evt, err = mgr.DecodeNativeEvent(utils.NodeManagerContractAddress.ToHexString(),
+		[]interface{}{"blackNode", []string{"peer1", "peer2"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"peer1", "peer2"}, evt.Fields["PeerPubkeyList"])
+
+	_, err = mgr.DecodeNativeEvent(address, jsonStates(t, "makeProof", uint64(2)))
+	assert.NotNil(t, err)
+	_, err = mgr.DecodeNativeEvent(address, jsonStates(t, "makeProof", "2", uint64(3), "txhash", uint32(100), "key"))
+	assert.NotNil(t, err)
+	_, err = mgr.DecodeNativeEvent(address, jsonStates(t, "unknown"))
+	assert.NotNil(t, err)
+	_, err = mgr.DecodeNativeEvent(utils.HeaderSyncContractAddress.ToHexString(), jsonStates(t, uint64(2), "hash"))
+	assert.NotNil(t, err)
+
+	//events registered are replaced by the same name
+	mgr.RegisterNativeEvent(address, newNativeEventAbi("MAKEPROOF", "Height:int"))
+	evt, err = mgr.DecodeNativeEvent(address, jsonStates(t, "makeProof", uint64(2)))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), evt.Fields["Height"])
+}
@@ -35,6 +35,7 @@ func SetOntologyConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 	}
 	setCommonConfig(ctx, cfg.Common)
 	setConsensusConfig(ctx, cfg.Consensus)
+	setTxPoolConfig(ctx, cfg.TxPool)
 	setP2PNodeConfig(ctx, cfg.P2PNode)
 	setRpcConfig(ctx, cfg.Rpc)
 	setRestfulConfig(ctx, cfg.Restful)
@@ -48,6 +49,23 @@ func SetOntologyConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
 		cfg.P2PNode.NetworkMagic = config.GetNetworkMagic(cfg.P2PNode.NetworkId)
 		cfg.Common.GasPrice = 0
 	}
+	switch cfg.Common.DBBackend {
+	case config.DB_BACKEND_LEVELDB, config.DB_BACKEND_BADGER:
+	default:
+		return nil, fmt.Errorf("unknown db backend %s", cfg.Common.DBBackend)
+	}
+	if cfg.TxPool.Capacity == 0 {
+		return nil, fmt.Errorf("tx pool capacity should be greater than 0")
+	}
+	if cfg.Common.PruneBlocks != 0 && cfg.Common.PruneBlocks < config.MIN_PRUNE_BLOCKS {
+		return nil, fmt.Errorf("prune blocks should not be less than %d", config.MIN_PRUNE_BLOCKS)
+	}
+	if cfg.Common.LightNode {
+		if cfg.Genesis.ConsensusType == config.CONSENSUS_TYPE_SOLO {
+			return nil, fmt.Errorf("light node is not supported in test mode")
+		}
+		cfg.Consensus.EnableConsensus = false
+	}
 	if cfg.P2PNode.NetworkId == config.NETWORK_ID_MAIN_NET ||
 		cfg.P2PNode.NetworkId == config.NETWORK_ID_TEST_NET {
 		defNetworkId, err := cfg.GetDefaultNetworkId()
@@ -119,13 +137,26 @@ func setGenesis(ctx *cli.Context, cfg *config.OntologyConfig) error {
 
 func setCommonConfig(ctx *cli.Context, cfg *config.CommonConfig) {
 	cfg.LogLevel = ctx.Uint(utils.GetFlagName(utils.LogLevelFlag))
+	cfg.LogFormat = ctx.String(utils.GetFlagName(utils.LogFormatFlag))
+	cfg.LogModules = ctx.String(utils.GetFlagName(utils.LogModulesFlag))
+	cfg.LogMaxSize = ctx.Uint(utils.GetFlagName(utils.LogMaxSizeFlag))
+	cfg.LogMaxAge = ctx.Uint(utils.GetFlagName(utils.LogMaxAgeFlag))
 	cfg.EnableEventLog = !ctx.Bool(utils.GetFlagName(utils.DisableEventLogFlag))
 	cfg.DataDir = ctx.String(utils.GetFlagName(utils.DataDirFlag))
+	cfg.LightNode = ctx.Bool(utils.GetFlagName(utils.LightNodeFlag))
+	cfg.DBBackend = ctx.String(utils.GetFlagName(utils.DBBackendFlag))
+	cfg.PruneBlocks = uint32(ctx.Uint(utils.GetFlagName(utils.PruneBlocksFlag)))
+	cfg.RollbackBlocks = uint32(ctx.Uint(utils.GetFlagName(utils.RollbackBlocksFlag)))
 }
 
 func setConsensusConfig(ctx *cli.Context, cfg *config.ConsensusConfig) {
 	cfg.EnableConsensus = ctx.Bool(utils.GetFlagName(utils.EnableConsensusFlag))
 	cfg.MaxTxInBlock = ctx.Uint(utils.GetFlagName(utils.MaxTxInBlockFlag))
+	cfg.BlsKeyPath = ctx.String(utils.GetFlagName(utils.BlsKeyFlag))
+}
+
+func setTxPoolConfig(ctx *cli.Context, cfg *config.TxPoolConfig) {
+	cfg.Capacity = ctx.Uint(utils.GetFlagName(utils.TxpoolCapacityFlag))
 }
 
 func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
@@ -137,9 +168,15 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 	cfg.DualPortSupport = ctx.Bool(utils.GetFlagName(utils.DualPortSupportFlag))
 	cfg.HttpInfoPort = ctx.Uint(utils.GetFlagName(utils.HttpInfoPortFlag))
 	cfg.ReservedPeersOnly = ctx.Bool(utils.GetFlagName(utils.ReservedPeersOnlyFlag))
+	cfg.IsNoise = ctx.Bool(utils.GetFlagName(utils.NoiseFlag))
 	cfg.MaxConnInBound = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundFlag))
 	cfg.MaxConnOutBound = ctx.Uint(utils.GetFlagName(utils.MaxConnOutBoundFlag))
 	cfg.MaxConnInBoundForSingleIP = ctx.Uint(utils.GetFlagName(utils.MaxConnInBoundForSingleIPFlag))
+	cfg.Compression = ctx.String(utils.GetFlagName(utils.P2PCompressionFlag))
+	cfg.MaxIngressRate = ctx.Uint(utils.GetFlagName(utils.MaxIngressRateFlag))
+	cfg.MaxEgressRate = ctx.Uint(utils.GetFlagName(utils.MaxEgressRateFlag))
+	cfg.MaxPeerIngressRate = ctx.Uint(utils.GetFlagName(utils.MaxPeerIngressRateFlag))
+	cfg.MaxPeerEgressRate = ctx.Uint(utils.GetFlagName(utils.MaxPeerEgressRateFlag))
 
 	rsvfile := ctx.String(utils.GetFlagName(utils.ReservedPeersFileFlag))
 	if cfg.ReservedPeersOnly {
@@ -158,6 +195,9 @@ func setP2PNodeConfig(ctx *cli.Context, cfg *config.P2PNodeConfig) {
 		for i := 0; i < len(cfg.ReservedCfg.MaskPeers); i++ {
 			log.Info("mask addr: " + cfg.ReservedCfg.MaskPeers[i])
 		}
+		for i := 0; i < len(cfg.ReservedCfg.ReservedKeys); i++ {
+			log.Info("reserved key: " + cfg.ReservedCfg.ReservedKeys[i])
+		}
 	}
 
 }
@@ -166,6 +206,7 @@ func setRpcConfig(ctx *cli.Context, cfg *config.RpcConfig) {
 	cfg.EnableHttpJsonRpc = !ctx.Bool(utils.GetFlagName(utils.RPCDisabledFlag))
 	cfg.HttpJsonPort = ctx.Uint(utils.GetFlagName(utils.RPCPortFlag))
 	cfg.HttpLocalPort = ctx.Uint(utils.GetFlagName(utils.RPCLocalProtFlag))
+	cfg.MaxRequests = ctx.Uint(utils.GetFlagName(utils.RPCMaxRequestsFlag))
 }
 
 func setRestfulConfig(ctx *cli.Context, cfg *config.RestfulConfig) {
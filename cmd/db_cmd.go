@@ -0,0 +1,239 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/gosuri/uiprogress"
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/store/ledgerstore"
+	"github.com/urfave/cli"
+)
+
+var DBCommand = cli.Command{
+	Name:  "db",
+	Usage: "Maintain the local block data storage",
+	Subcommands: []cli.Command{
+		{
+			Action:    migrateDB,
+			Name:      "migrate",
+			Usage:     "Copy all stores to another kv backend",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.DataDirFlag,
+				utils.NetworkIdFlag,
+				utils.TargetDirFlag,
+				utils.DBBackendFlag,
+			},
+			Description: `Copy block, state, event and light header stores under --data-dir to --target-dir,
+kept by the kv engine of --db-backend. The state merkle root at the tip is verified after copied.
+Node should be stopped while migrating.`,
+		},
+		{
+			Action:    verifyDB,
+			Name:      "verify",
+			Usage:     "Check the integrity of local ledger",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.ConfigFlag,
+				utils.DataDirFlag,
+				utils.NetworkIdFlag,
+				utils.RepairFlag,
+			},
+			Description: `Walk block store from genesis, check the header chain and bookkeeper signatures, transaction roots,
+block merkle tree, cross state roots and event index. Report the first divergent height.
+With --repair, state and event stores lagging behind block store are replayed, and the ledger diverged
+is rolled back to the height before the divergent one.
+Node should be stopped while verifying.`,
+		},
+		{
+			Action:    rollbackDB,
+			Name:      "rollback",
+			Usage:     "Roll the local ledger back to a block height",
+			ArgsUsage: "",
+			Flags: []cli.Flag{
+				utils.ConfigFlag,
+				utils.DataDirFlag,
+				utils.NetworkIdFlag,
+				utils.RollbackHeightFlag,
+			},
+			Description: `Revert block, state, event stores and header index to block at --height. The state is reverted by the
+undo data kept for the latest --rollback-blocks blocks, so only blocks within them can be rolled back.
+Blocks above are synced and executed again after node started.
+Node should be stopped while rolling back.`,
+		},
+	},
+}
+
+func migrateDB(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+	targetDir := ctx.String(utils.GetFlagName(utils.TargetDirFlag))
+	if targetDir == "" {
+		PrintErrorMsg("Missing %s argument.", utils.TargetDirFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	backend := ctx.String(utils.GetFlagName(utils.DBBackendFlag))
+	switch backend {
+	case config.DB_BACKEND_LEVELDB, config.DB_BACKEND_BADGER:
+	default:
+		return fmt.Errorf("unknown db backend %s", backend)
+	}
+	networkName := config.GetNetworkName(uint32(ctx.Uint(utils.GetFlagName(utils.NetworkIdFlag))))
+	dataDir := utils.GetStoreDirPath(ctx.String(utils.GetFlagName(utils.DataDirFlag)), networkName)
+	targetDir = utils.GetStoreDirPath(targetDir, networkName)
+
+	PrintInfoMsg("Start migrate %s to %s with %s.", dataDir, targetDir, backend)
+	err := ledgerstore.MigrateStore(dataDir, targetDir, backend)
+	if err != nil {
+		return fmt.Errorf("migrate error:%s", err)
+	}
+	PrintInfoMsg("Migrate stores successfully, state merkle root verified.")
+	PrintInfoMsg("Start node with --data-dir %s --db-backend %s", ctx.String(utils.GetFlagName(utils.TargetDirFlag)), backend)
+	return nil
+}
+
+func verifyDB(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+	dbDir, err := setDBConfig(ctx)
+	if err != nil {
+		return err
+	}
+	ledgerStore, err := ledgerstore.NewLedgerStore(dbDir)
+	if err != nil {
+		return fmt.Errorf("NewLedgerStore error:%s", err)
+	}
+
+	PrintInfoMsg("Start verify %s.", dbDir)
+	uiprogress.Start()
+	var bar *uiprogress.Bar
+	report, err := ledgerStore.VerifyLedger(func(height, blockHeight uint32) {
+		if bar == nil {
+			bar = uiprogress.AddBar(int(blockHeight + 1)).AppendCompleted().AppendElapsed()
+		}
+		bar.Incr()
+	})
+	uiprogress.Stop()
+	ledgerStore.Close()
+	if err != nil {
+		return fmt.Errorf("verify error:%s", err)
+	}
+	PrintInfoMsg("BlockHeight:%d StateHeight:%d EventHeight:%d PrunedHeight:%d", report.BlockHeight,
+		report.StateHeight, report.EventHeight, report.PrunedHeight)
+	repair := ctx.Bool(utils.GetFlagName(utils.RepairFlag))
+	if report.Divergent {
+		if !repair || report.DivergentHeight == 0 {
+			return fmt.Errorf("ledger diverged at height:%d, %s", report.DivergentHeight, report.Reason)
+		}
+		PrintWarnMsg("Ledger diverged at height:%d, %s", report.DivergentHeight, report.Reason)
+		err = rollbackLedger(dbDir, report.DivergentHeight-1)
+		if err != nil {
+			return fmt.Errorf("repair error:%s", err)
+		}
+		PrintInfoMsg("Repair ledger successfully, rolled back to height:%d.", report.DivergentHeight-1)
+		return nil
+	}
+	if !report.Recoverable() {
+		PrintInfoMsg("Verify ledger successfully.")
+		return nil
+	}
+	if !repair {
+		PrintWarnMsg("State or event store lags behind block store, repair with --repair.")
+		return nil
+	}
+	//stores lagging behind are replayed when ledger inits
+	err = initLedger(dbDir)
+	if err != nil {
+		return fmt.Errorf("repair error:%s", err)
+	}
+	ledger.DefLedger.Close()
+	PrintInfoMsg("Repair ledger successfully.")
+	return nil
+}
+
+func rollbackDB(ctx *cli.Context) error {
+	log.InitLog(log.InfoLog)
+	if !ctx.IsSet(utils.GetFlagName(utils.RollbackHeightFlag)) {
+		PrintErrorMsg("Missing %s argument.", utils.RollbackHeightFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	height := uint32(ctx.Uint(utils.GetFlagName(utils.RollbackHeightFlag)))
+	dbDir, err := setDBConfig(ctx)
+	if err != nil {
+		return err
+	}
+	PrintInfoMsg("Start rollback %s to height:%d.", dbDir, height)
+	err = rollbackLedger(dbDir, height)
+	if err != nil {
+		return fmt.Errorf("rollback error:%s", err)
+	}
+	PrintInfoMsg("Rollback ledger successfully.")
+	return nil
+}
+
+func rollbackLedger(dbDir string, height uint32) error {
+	ledgerStore, err := ledgerstore.NewLedgerStore(dbDir)
+	if err != nil {
+		return fmt.Errorf("NewLedgerStore error:%s", err)
+	}
+	err = ledgerStore.Rollback(height)
+	ledgerStore.Close()
+	return err
+}
+
+//setDBConfig load genesis config of the network, return the store dir
+func setDBConfig(ctx *cli.Context) (string, error) {
+	err := setGenesis(ctx, config.DefConfig)
+	if err != nil {
+		return "", fmt.Errorf("setGenesis error:%s", err)
+	}
+	networkName := config.GetNetworkName(uint32(ctx.Uint(utils.GetFlagName(utils.NetworkIdFlag))))
+	return utils.GetStoreDirPath(ctx.String(utils.GetFlagName(utils.DataDirFlag)), networkName), nil
+}
+
+func initLedger(dbDir string) error {
+	var err error
+	if config.DefConfig.Common.LightNode {
+		ledger.DefLedger, err = ledger.NewLightLedger(dbDir)
+	} else {
+		ledger.DefLedger, err = ledger.NewLedger(dbDir)
+	}
+	if err != nil {
+		return fmt.Errorf("NewLedger error:%s", err)
+	}
+	bookKeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, config.DefConfig.Genesis)
+	if err != nil {
+		return fmt.Errorf("BuildGenesisBlock error %s", err)
+	}
+	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
+	if err != nil {
+		return fmt.Errorf("init ledger error:%s", err)
+	}
+	return nil
+}
@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"github.com/gosuri/uiprogress"
 	"github.com/polynetwork/poly/cmd/utils"
-	"github.com/polynetwork/poly/common/serialization"
 	"github.com/urfave/cli"
 	"os"
 	"time"
@@ -40,8 +39,11 @@ var ExportCommand = cli.Command{
 		utils.ExportStartHeightFlag,
 		utils.ExportEndHeightFlag,
 		utils.ExportSpeedFlag,
+		utils.ExportCompressTypeFlag,
+		utils.ExportHeaderOnlyFlag,
 	},
-	Description: "",
+	Description: `Blocks are written in chunks, each chunk is compressed and checksummed, so import can resume
+from the last good chunk. With --header-only, only block headers are exported.`,
 }
 
 func exportBlocks(ctx *cli.Context) error {
@@ -71,6 +73,26 @@ func exportBlocks(ctx *cli.Context) error {
 		endHeight = currentBlockHeight
 	}
 
+	metadata := utils.NewExportBlockMetadata()
+	metadata.StartBlockHeight = uint32(startHeight)
+	metadata.EndBlockHeight = uint32(endHeight)
+	switch compressType := ctx.String(utils.GetFlagName(utils.ExportCompressTypeFlag)); compressType {
+	case "zstd":
+		if !utils.ZstdSupported {
+			return fmt.Errorf("zstd is not supported by the build without cgo, export with --%s zlib",
+				utils.ExportCompressTypeFlag.Name)
+		}
+		metadata.CompressType = utils.COMPRESS_TYPE_ZSTD
+	case "zlib":
+		metadata.CompressType = utils.COMPRESS_TYPE_ZLIB
+	default:
+		return fmt.Errorf("unknown compress type %s", compressType)
+	}
+	headerOnly := ctx.Bool(utils.GetFlagName(utils.ExportHeaderOnlyFlag))
+	if headerOnly {
+		metadata.DataType = utils.EXPORT_DATA_HEADER
+	}
+
 	speed := ctx.String(utils.GetFlagName(utils.ExportSpeedFlag))
 	var sleepTime time.Duration
 	switch speed {
@@ -83,16 +105,13 @@ func exportBlocks(ctx *cli.Context) error {
 	}
 
 	exportFile = utils.GenExportBlocksFileName(exportFile, uint32(startHeight), uint32(endHeight))
-	ef, err := os.OpenFile(exportFile, os.O_RDWR|os.O_CREATE, 0664)
+	ef, err := os.OpenFile(exportFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0664)
 	if err != nil {
 		return fmt.Errorf("open file:%s error:%s", exportFile, err)
 	}
 	defer ef.Close()
 	fWriter := bufio.NewWriter(ef)
 
-	metadata := utils.NewExportBlockMetadata()
-	metadata.StartBlockHeight = uint32(startHeight)
-	metadata.EndBlockHeight = uint32(endHeight)
 	err = metadata.Serialize(fWriter)
 	if err != nil {
 		return fmt.Errorf("write export metadata error:%s", err)
@@ -108,22 +127,23 @@ func exportBlocks(ctx *cli.Context) error {
 		})
 
 	PrintInfoMsg("Start export.")
+	chunkWriter := utils.NewExportChunkWriter(fWriter, metadata)
 	for i := uint32(startHeight); i <= uint32(endHeight); i++ {
-		blockData, err := utils.GetBlockData(i)
-		if err != nil {
-			return fmt.Errorf("GetBlockData:%d error:%s", i, err)
-		}
-		data, err := utils.CompressBlockData(blockData, metadata.CompressType)
-		if err != nil {
-			return fmt.Errorf("CompressBlockData height:%d error:%s", i, err)
+		var data []byte
+		if headerOnly {
+			data, err = utils.GetHeaderData(i)
+			if err != nil {
+				return fmt.Errorf("GetHeaderData:%d error:%s", i, err)
+			}
+		} else {
+			data, err = utils.GetBlockData(i)
+			if err != nil {
+				return fmt.Errorf("GetBlockData:%d error:%s", i, err)
+			}
 		}
-		err = serialization.WriteUint32(fWriter, uint32(len(data)))
+		err = chunkWriter.Write(data)
 		if err != nil {
-			return fmt.Errorf("write block data height:%d len:%d error:%s", i, uint32(len(data)), err)
-		}
-		_, err = fWriter.Write(data)
-		if err != nil {
-			return fmt.Errorf("write block data height:%d error:%s", i, err)
+			return fmt.Errorf("write chunk height:%d error:%s", i, err)
 		}
 		if sleepTime > 0 {
 			time.Sleep(sleepTime)
@@ -132,6 +152,10 @@ func exportBlocks(ctx *cli.Context) error {
 	}
 	uiprogress.Stop()
 
+	err = chunkWriter.Flush()
+	if err != nil {
+		return fmt.Errorf("write chunk height:%d error:%s", endHeight, err)
+	}
 	err = fWriter.Flush()
 	if err != nil {
 		return fmt.Errorf("export flush file error:%s", err)
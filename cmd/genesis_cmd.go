@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
+	"github.com/urfave/cli"
+)
+
+const (
+	GENESIS_CONFIG_FILE = "config.json"
+	GENESIS_BLOCK_FILE  = "genesis.block"
+)
+
+var GenesisCommand = cli.Command{
+	Name:      "genesis",
+	Action:    buildGenesis,
+	Usage:     "Build genesis block and config of a new relay network",
+	ArgsUsage: "",
+	Flags: []cli.Flag{
+		utils.GenesisSpecFlag,
+		utils.GenesisOutputDirFlag,
+	},
+	Description: `Build genesis config and block from the declarative --spec file, which contains network_id, seed_list,
+vbft config with peers, and initial side_chains, relayers, btc_redeems and state_validators registered by genesis
+block instead of governance transactions. The output is deterministic, config.json is used by node with
+--config config.json --networkid <network_id>, and genesis.block is the raw genesis block for checking.`,
+}
+
+func buildGenesis(ctx *cli.Context) error {
+	specFile := ctx.String(utils.GetFlagName(utils.GenesisSpecFlag))
+	if specFile == "" {
+		PrintErrorMsg("Missing %s argument.", utils.GenesisSpecFlag.Name)
+		cli.ShowSubcommandHelp(ctx)
+		return nil
+	}
+	spec := &utils.GenesisSpec{}
+	err := utils.GetJsonObjectFromFile(specFile, spec)
+	if err != nil {
+		return fmt.Errorf("read spec file:%s error:%s", specFile, err)
+	}
+	genesisConfig, block, err := utils.BuildGenesis(spec)
+	if err != nil {
+		return fmt.Errorf("build genesis error:%s", err)
+	}
+
+	outputDir := ctx.String(utils.GetFlagName(utils.GenesisOutputDirFlag))
+	err = os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return fmt.Errorf("create output dir:%s error:%s", outputDir, err)
+	}
+	data, err := json.MarshalIndent(genesisConfig, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal genesis config error:%s", err)
+	}
+	configFile := filepath.Join(outputDir, GENESIS_CONFIG_FILE)
+	err = ioutil.WriteFile(configFile, data, 0644)
+	if err != nil {
+		return fmt.Errorf("write file:%s error:%s", configFile, err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	err = block.Serialization(sink)
+	if err != nil {
+		return fmt.Errorf("serialize genesis block error:%s", err)
+	}
+	blockFile := filepath.Join(outputDir, GENESIS_BLOCK_FILE)
+	err = ioutil.WriteFile(blockFile, sink.Bytes(), 0644)
+	if err != nil {
+		return fmt.Errorf("write file:%s error:%s", blockFile, err)
+	}
+
+	PrintInfoMsg("Build genesis successfully.")
+	hash := block.Hash()
+	PrintInfoMsg("Genesis block hash:%s", hash.ToHexString())
+	PrintInfoMsg("Genesis config:%s", configFile)
+	PrintInfoMsg("Genesis block:%s", blockFile)
+	PrintInfoMsg("Start node with --config %s --networkid %d", configFile, spec.NetworkId)
+	return nil
+}
@@ -26,11 +26,12 @@ import (
 
 	"github.com/gosuri/uiprogress"
 	"github.com/polynetwork/poly/cmd/utils"
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/common/serialization"
-	"github.com/polynetwork/poly/core/genesis"
 	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/store/ledgerstore"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/urfave/cli"
 )
@@ -43,38 +44,21 @@ var ImportCommand = cli.Command{
 	Flags: []cli.Flag{
 		utils.ImportFileFlag,
 		utils.ImportEndHeightFlag,
+		utils.ImportVerifyOnlyFlag,
 		utils.DataDirFlag,
 		utils.ConfigFlag,
 		utils.NetworkIdFlag,
 		utils.DisableEventLogFlag,
+		utils.LightNodeFlag,
 	},
-	Description: "Note that import cmd doesn't support testmode",
+	Description: `Note that import cmd doesn't support testmode.
+Import starts from the next block of local ledger, so an interrupted import resumes from the last good chunk
+when run again. Header only files are imported by light node, or verified with --verify-only.`,
 }
 
 func importBlocks(ctx *cli.Context) error {
-	var err error
 	log.InitLog(log.InfoLog)
 
-	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
-
-	ledger.DefLedger, err = ledger.NewLedger(dbDir)
-	if err != nil {
-		return fmt.Errorf("NewLedger error:%s", err)
-	}
-	bookKeepers, err := config.DefConfig.GetBookkeepers()
-	if err != nil {
-		return fmt.Errorf("GetBookkeepers error:%s", err)
-	}
-	genesisConfig := config.DefConfig.Genesis
-	genesisBlock, err := genesis.BuildGenesisBlock(bookKeepers, genesisConfig)
-	if err != nil {
-		return fmt.Errorf("BuildGenesisBlock error %s", err)
-	}
-	err = ledger.DefLedger.Init(bookKeepers, genesisBlock)
-	if err != nil {
-		return fmt.Errorf("init ledger error:%s", err)
-	}
-
 	dataDir := ctx.String(utils.GetFlagName(utils.DataDirFlag))
 	if dataDir == "" {
 		PrintErrorMsg("Missing %s argument.", utils.DataDirFlag.Name)
@@ -87,6 +71,17 @@ func importBlocks(ctx *cli.Context) error {
 		cli.ShowSubcommandHelp(ctx)
 		return nil
 	}
+	dbDir, err := setDBConfig(ctx)
+	if err != nil {
+		return err
+	}
+	config.DefConfig.Common.LightNode = ctx.Bool(utils.GetFlagName(utils.LightNodeFlag))
+	err = initLedger(dbDir)
+	if err != nil {
+		return err
+	}
+	defer ledger.DefLedger.Close()
+
 	endBlockHeight := uint32(ctx.Uint(utils.GetFlagName(utils.ImportEndHeightFlag)))
 	currBlockHeight := ledger.DefLedger.GetCurrentBlockHeight()
 
@@ -108,7 +103,7 @@ func importBlocks(ctx *cli.Context) error {
 		return fmt.Errorf("block data file metadata deserialize error:%s", err)
 	}
 	if metadata.EndBlockHeight <= currBlockHeight {
-		PrintWarnMsg("CurrentBlockHeight:%d larger than or equal to EndBlockHeight:%d, No blocks to import.", currBlockHeight, endBlockHeight)
+		PrintWarnMsg("CurrentBlockHeight:%d larger than or equal to EndBlockHeight:%d, No blocks to import.", currBlockHeight, metadata.EndBlockHeight)
 		return nil
 	}
 	if endBlockHeight == 0 || endBlockHeight > metadata.EndBlockHeight {
@@ -119,9 +114,13 @@ func importBlocks(ctx *cli.Context) error {
 	if startBlockHeight > (currBlockHeight + 1) {
 		return fmt.Errorf("import block error: StartBlockHeight:%d larger than NextBlockHeight:%d", startBlockHeight, currBlockHeight+1)
 	}
+	importer, err := newBlockImporter(metadata, ctx.Bool(utils.GetFlagName(utils.ImportVerifyOnlyFlag)))
+	if err != nil {
+		return err
+	}
 	//progress bar
 	uiprogress.Start()
-	bar := uiprogress.AddBar(int(endBlockHeight - startBlockHeight + 1)).
+	bar := uiprogress.AddBar(int(endBlockHeight - currBlockHeight)).
 		AppendCompleted().
 		AppendElapsed().
 		PrependFunc(func(b *uiprogress.Bar) string {
@@ -129,8 +128,27 @@ func importBlocks(ctx *cli.Context) error {
 		})
 
 	PrintInfoMsg("Start import blocks.")
+	if metadata.Version == utils.EXPORT_BLOCK_METADATA_VERSION {
+		err = importBlockStream(fReader, metadata, currBlockHeight, endBlockHeight, importer, bar)
+	} else {
+		err = importBlockChunks(fReader, metadata, currBlockHeight, endBlockHeight, importer, bar)
+	}
+	uiprogress.Stop()
+	if err != nil {
+		return err
+	}
+	if importer.verifier != nil {
+		PrintInfoMsg("Verify blocks completed, verified to height:%d.", endBlockHeight)
+		return nil
+	}
+	PrintInfoMsg("Import block completed, current block height:%d.", ledger.DefLedger.GetCurrentBlockHeight())
+	return nil
+}
 
-	for i := uint32(startBlockHeight); i <= endBlockHeight; i++ {
+//importBlockStream import the file of version 1, blocks are compressed one by one
+func importBlockStream(fReader *bufio.Reader, metadata *utils.ExportBlockMetadata, currBlockHeight, endBlockHeight uint32,
+	importer *blockImporter, bar *uiprogress.Bar) error {
+	for i := metadata.StartBlockHeight; i <= endBlockHeight; i++ {
 		size, err := serialization.ReadUint32(fReader)
 		if err != nil {
 			return fmt.Errorf("read block height:%d error:%s", i, err)
@@ -149,21 +167,135 @@ func importBlocks(ctx *cli.Context) error {
 		if err != nil {
 			return fmt.Errorf("block height:%d decompress error:%s", i, err)
 		}
-		block, err := types.BlockFromRawBytes(blockData)
+		err = importer.importData(blockData, i)
+		if err != nil {
+			return err
+		}
+		bar.Incr()
+	}
+	return nil
+}
+
+//importBlockChunks import the chunked file, chunks imported already are skipped without decompressed
+func importBlockChunks(fReader *bufio.Reader, metadata *utils.ExportBlockMetadata, currBlockHeight, endBlockHeight uint32,
+	importer *blockImporter, bar *uiprogress.Bar) error {
+	chunkReader := utils.NewExportChunkReader(fReader, metadata)
+	nextHeight := currBlockHeight + 1
+	for nextHeight <= endBlockHeight {
+		chunk, err := chunkReader.Next()
+		if err == io.EOF {
+			return fmt.Errorf("file ends before height:%d", nextHeight)
+		}
+		if err != nil {
+			return fmt.Errorf("read chunk at height:%d error:%s", nextHeight, err)
+		}
+		if chunk.StartHeight > nextHeight {
+			return fmt.Errorf("chunk from height:%d missing blocks from height:%d", chunk.StartHeight, nextHeight)
+		}
+		if chunk.StartHeight+chunk.Count <= nextHeight {
+			continue
+		}
+		items, err := chunkReader.Read()
 		if err != nil {
-			return fmt.Errorf("block height:%d deserialize error:%s", i, err)
+			return fmt.Errorf("chunk from height:%d error:%s, blocks below are imported, run import again to resume",
+				chunk.StartHeight, err)
+		}
+		for i, data := range items {
+			height := chunk.StartHeight + uint32(i)
+			if height < nextHeight {
+				continue
+			}
+			if height > endBlockHeight {
+				break
+			}
+			err = importer.importData(data, height)
+			if err != nil {
+				return err
+			}
+			nextHeight = height + 1
+			bar.Incr()
 		}
-		execResult, err := ledger.DefLedger.ExecuteBlock(block)
+	}
+	return nil
+}
+
+//blockImporter execute and save imported blocks, add headers to light node, or only verify them
+type blockImporter struct {
+	headerOnly bool
+	light      bool
+	verifier   *ledgerstore.HeaderVerifier
+}
+
+func newBlockImporter(metadata *utils.ExportBlockMetadata, verifyOnly bool) (*blockImporter, error) {
+	_, light := ledger.DefLedger.GetStore().(*ledgerstore.LightStoreImp)
+	importer := &blockImporter{
+		headerOnly: metadata.Version >= utils.EXPORT_BLOCK_METADATA_VERSION_CHUNKED && metadata.DataType == utils.EXPORT_DATA_HEADER,
+		light:      light,
+	}
+	if verifyOnly {
+		header, err := ledger.DefLedger.GetHeaderByHeight(ledger.DefLedger.GetCurrentBlockHeight())
 		if err != nil {
-			return fmt.Errorf("block height:%d ExecuteBlock error:%s", i, err)
+			return nil, fmt.Errorf("GetHeaderByHeight error:%s", err)
 		}
-		err = ledger.DefLedger.SubmitBlock(block, execResult)
+		importer.verifier, err = ledgerstore.NewHeaderVerifier(header, ledger.DefLedger.GetHeaderByHeight)
 		if err != nil {
-			return fmt.Errorf("SubmitBlock block height:%d error:%s", i, err)
+			return nil, fmt.Errorf("NewHeaderVerifier error:%s", err)
 		}
-		bar.Incr()
+	} else if importer.headerOnly && !light {
+		return nil, fmt.Errorf("header only file can only be imported by light node, or verified with --%s",
+			utils.ImportVerifyOnlyFlag.Name)
+	}
+	return importer, nil
+}
+
+func (this *blockImporter) importData(data []byte, height uint32) error {
+	var block *types.Block
+	if this.headerOnly {
+		header, err := types.HeaderFromRawBytes(data)
+		if err != nil {
+			return fmt.Errorf("header height:%d deserialize error:%s", height, err)
+		}
+		block = &types.Block{Header: header}
+	} else {
+		var err error
+		block, err = types.BlockFromRawBytes(data)
+		if err != nil {
+			return fmt.Errorf("block height:%d deserialize error:%s", height, err)
+		}
+	}
+	if block.Header.Height != height {
+		return fmt.Errorf("block height:%d mismatch height:%d", block.Header.Height, height)
+	}
+	if this.verifier != nil {
+		if !this.headerOnly {
+			txHashes := make([]common.Uint256, 0, len(block.Transactions))
+			for _, tx := range block.Transactions {
+				txHashes = append(txHashes, tx.Hash())
+			}
+			if common.ComputeMerkleRoot(txHashes) != block.Header.TransactionsRoot {
+				return fmt.Errorf("block height:%d transactions root mismatch", height)
+			}
+		}
+		err := this.verifier.Verify(block.Header)
+		if err != nil {
+			return fmt.Errorf("block height:%d verify error:%s", height, err)
+		}
+		return nil
+	}
+	if this.light {
+		err := ledger.DefLedger.AddHeaders([]*types.Header{block.Header})
+		if err != nil {
+			return fmt.Errorf("AddHeaders height:%d error:%s", height, err)
+		}
+		return nil
+	}
+	execResult, err := ledger.DefLedger.ExecuteBlock(block)
+	if err != nil {
+		return fmt.Errorf("block height:%d ExecuteBlock error:%s", height, err)
+	}
+	err = ledger.DefLedger.SubmitBlock(block, execResult)
+	if err != nil {
+		return fmt.Errorf("SubmitBlock block height:%d error:%s", height, err)
 	}
-	uiprogress.Stop()
-	PrintInfoMsg("Import block completed, current block height:%d.", ledger.DefLedger.GetCurrentBlockHeight())
 	return nil
 }
@@ -99,8 +99,16 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.ConfigFlag,
 			utils.LogLevelFlag,
+			utils.LogFormatFlag,
+			utils.LogModulesFlag,
+			utils.LogMaxSizeFlag,
+			utils.LogMaxAgeFlag,
 			utils.DisableEventLogFlag,
 			utils.DataDirFlag,
+			utils.LightNodeFlag,
+			utils.DBBackendFlag,
+			utils.PruneBlocksFlag,
+			utils.RollbackBlocksFlag,
 		},
 	},
 	{
@@ -131,11 +139,13 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.EnableConsensusFlag,
 			utils.MaxTxInBlockFlag,
+			utils.BlsKeyFlag,
 		},
 	},
 	{
 		Name: "TXPOOL",
 		Flags: []cli.Flag{
+			utils.TxpoolCapacityFlag,
 			utils.TxpoolPreExecDisableFlag,
 			utils.DisableSyncVerifyTxFlag,
 			utils.DisableBroadcastNetTxFlag,
@@ -146,6 +156,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.ReservedPeersOnlyFlag,
 			utils.ReservedPeersFileFlag,
+			utils.NoiseFlag,
 			utils.NetworkIdFlag,
 			utils.NodePortFlag,
 			utils.DualPortSupportFlag,
@@ -154,6 +165,11 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MaxConnInBoundFlag,
 			utils.MaxConnOutBoundFlag,
 			utils.MaxConnInBoundForSingleIPFlag,
+			utils.P2PCompressionFlag,
+			utils.MaxIngressRateFlag,
+			utils.MaxEgressRateFlag,
+			utils.MaxPeerIngressRateFlag,
+			utils.MaxPeerEgressRateFlag,
 		},
 	},
 	{
@@ -163,6 +179,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCPortFlag,
 			utils.RPCLocalEnableFlag,
 			utils.RPCLocalProtFlag,
+			utils.RPCMaxRequestsFlag,
 		},
 	},
 	{
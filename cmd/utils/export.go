@@ -21,7 +21,9 @@ package utils
 import (
 	"bytes"
 	"compress/zlib"
+	"crypto/sha256"
 	"fmt"
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/serialization"
 	"io"
 	"io/ioutil"
@@ -29,12 +31,22 @@ import (
 
 const (
 	COMPRESS_TYPE_ZLIB = iota
+	COMPRESS_TYPE_ZSTD
 )
 
 const (
-	DEFAULT_COMPRESS_TYPE         = COMPRESS_TYPE_ZLIB
-	EXPORT_BLOCK_METADATA_LEN     = 256
-	EXPORT_BLOCK_METADATA_VERSION = 1
+	EXPORT_DATA_BLOCK  = iota //Full blocks
+	EXPORT_DATA_HEADER        //Headers only, for light node and relayer bootstrap
+)
+
+const (
+	DEFAULT_COMPRESS_TYPE                 = COMPRESS_TYPE_ZSTD
+	EXPORT_BLOCK_METADATA_LEN             = 256
+	EXPORT_BLOCK_METADATA_VERSION         = 1 //Blocks compressed one by one
+	EXPORT_BLOCK_METADATA_VERSION_CHUNKED = 2 //Blocks compressed in chunks with checksum
+	DEFAULT_EXPORT_CHUNK_SIZE             = 1000
+	EXPORT_CHUNK_HEADER_LEN               = 12 + sha256.Size
+	MAX_EXPORT_CHUNK_DATA_SIZE            = 1024 * 1024 * 1024
 )
 
 type ExportBlockMetadata struct {
@@ -42,12 +54,16 @@ type ExportBlockMetadata struct {
 	CompressType     byte
 	StartBlockHeight uint32
 	EndBlockHeight   uint32
+	DataType         byte   //Since version 2, blocks or headers
+	ChunkSize        uint32 //Since version 2, blocks per chunk
 }
 
 func NewExportBlockMetadata() *ExportBlockMetadata {
 	return &ExportBlockMetadata{
-		Version:      EXPORT_BLOCK_METADATA_VERSION,
+		Version:      EXPORT_BLOCK_METADATA_VERSION_CHUNKED,
 		CompressType: DEFAULT_COMPRESS_TYPE,
+		DataType:     EXPORT_DATA_BLOCK,
+		ChunkSize:    DEFAULT_EXPORT_CHUNK_SIZE,
 	}
 }
 
@@ -70,6 +86,16 @@ func (this *ExportBlockMetadata) Serialize(w io.Writer) error {
 	if err != nil {
 		return err
 	}
+	if this.Version >= EXPORT_BLOCK_METADATA_VERSION_CHUNKED {
+		err = serialization.WriteByte(buf, this.DataType)
+		if err != nil {
+			return err
+		}
+		err = serialization.WriteUint32(buf, this.ChunkSize)
+		if err != nil {
+			return err
+		}
+	}
 	data := buf.Bytes()
 	if len(data) > EXPORT_BLOCK_METADATA_LEN {
 		return fmt.Errorf("metata len size larger than %d", EXPORT_BLOCK_METADATA_LEN)
@@ -85,7 +111,7 @@ func (this *ExportBlockMetadata) Deserialize(r io.Reader) error {
 	if err != nil {
 		return err
 	}
-	if metadata[0] != EXPORT_BLOCK_METADATA_VERSION {
+	if metadata[0] != EXPORT_BLOCK_METADATA_VERSION && metadata[0] != EXPORT_BLOCK_METADATA_VERSION_CHUNKED {
 		return fmt.Errorf("version unmatch")
 	}
 	reader := bytes.NewBuffer(metadata)
@@ -109,13 +135,197 @@ func (this *ExportBlockMetadata) Deserialize(r io.Reader) error {
 		return err
 	}
 	this.EndBlockHeight = height
+	if this.Version < EXPORT_BLOCK_METADATA_VERSION_CHUNKED {
+		return nil
+	}
+	dataType, err := serialization.ReadByte(reader)
+	if err != nil {
+		return err
+	}
+	this.DataType = dataType
+	chunkSize, err := serialization.ReadUint32(reader)
+	if err != nil {
+		return err
+	}
+	if chunkSize == 0 {
+		return fmt.Errorf("invalid chunk size")
+	}
+	this.ChunkSize = chunkSize
 	return nil
 }
 
+//ExportChunkHeader is written before the compressed data of each chunk
+type ExportChunkHeader struct {
+	StartHeight uint32
+	Count       uint32
+	Size        uint32            //Size of compressed chunk data
+	Checksum    [sha256.Size]byte //Sha256 of compressed chunk data
+}
+
+func (this *ExportChunkHeader) Serialize(w io.Writer) error {
+	buf := bytes.NewBuffer(make([]byte, 0, EXPORT_CHUNK_HEADER_LEN))
+	serialization.WriteUint32(buf, this.StartHeight)
+	serialization.WriteUint32(buf, this.Count)
+	serialization.WriteUint32(buf, this.Size)
+	buf.Write(this.Checksum[:])
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+//Deserialize read chunk header, io.EOF is returned if no more chunk
+func (this *ExportChunkHeader) Deserialize(r io.Reader) error {
+	data := make([]byte, EXPORT_CHUNK_HEADER_LEN)
+	_, err := io.ReadFull(r, data)
+	if err != nil {
+		return err
+	}
+	reader := bytes.NewReader(data)
+	this.StartHeight, _ = serialization.ReadUint32(reader)
+	this.Count, _ = serialization.ReadUint32(reader)
+	this.Size, _ = serialization.ReadUint32(reader)
+	copy(this.Checksum[:], data[12:])
+	return nil
+}
+
+//ExportChunkWriter write exported data of consecutive heights in chunks of metadata ChunkSize,
+//each chunk is compressed and led by its header with checksum
+type ExportChunkWriter struct {
+	w           io.Writer
+	metadata    *ExportBlockMetadata
+	startHeight uint32
+	count       uint32
+	sink        *common.ZeroCopySink
+}
+
+func NewExportChunkWriter(w io.Writer, metadata *ExportBlockMetadata) *ExportChunkWriter {
+	return &ExportChunkWriter{
+		w:           w,
+		metadata:    metadata,
+		startHeight: metadata.StartBlockHeight,
+		sink:        common.NewZeroCopySink(nil),
+	}
+}
+
+//Write append data of the next height, chunk is written out once full
+func (this *ExportChunkWriter) Write(data []byte) error {
+	this.sink.WriteVarBytes(data)
+	this.count++
+	if this.count >= this.metadata.ChunkSize {
+		return this.Flush()
+	}
+	return nil
+}
+
+//Flush write out data appended as a chunk
+func (this *ExportChunkWriter) Flush() error {
+	if this.count == 0 {
+		return nil
+	}
+	data, err := CompressBlockData(this.sink.Bytes(), this.metadata.CompressType)
+	if err != nil {
+		return fmt.Errorf("CompressBlockData error %s", err)
+	}
+	header := &ExportChunkHeader{
+		StartHeight: this.startHeight,
+		Count:       this.count,
+		Size:        uint32(len(data)),
+		Checksum:    sha256.Sum256(data),
+	}
+	err = header.Serialize(this.w)
+	if err != nil {
+		return err
+	}
+	_, err = this.w.Write(data)
+	if err != nil {
+		return err
+	}
+	this.startHeight += this.count
+	this.count = 0
+	this.sink.Reset()
+	return nil
+}
+
+//ExportChunkReader read chunks written by ExportChunkWriter
+type ExportChunkReader struct {
+	r        io.Reader
+	metadata *ExportBlockMetadata
+	header   *ExportChunkHeader
+}
+
+func NewExportChunkReader(r io.Reader, metadata *ExportBlockMetadata) *ExportChunkReader {
+	return &ExportChunkReader{
+		r:        r,
+		metadata: metadata,
+	}
+}
+
+//Next read header of the next chunk, io.EOF is returned after the last chunk
+func (this *ExportChunkReader) Next() (*ExportChunkHeader, error) {
+	if this.header != nil {
+		err := this.Skip()
+		if err != nil {
+			return nil, err
+		}
+	}
+	header := &ExportChunkHeader{}
+	err := header.Deserialize(this.r)
+	if err != nil {
+		return nil, err
+	}
+	if header.Count == 0 || header.Count > this.metadata.ChunkSize || header.Size > MAX_EXPORT_CHUNK_DATA_SIZE {
+		return nil, fmt.Errorf("invalid chunk header from height %d", header.StartHeight)
+	}
+	this.header = header
+	return header, nil
+}
+
+//Skip discard data of the current chunk without checked
+func (this *ExportChunkReader) Skip() error {
+	if this.header == nil {
+		return nil
+	}
+	_, err := io.CopyN(ioutil.Discard, this.r, int64(this.header.Size))
+	this.header = nil
+	return err
+}
+
+//Read return data of the current chunk, error if checksum mismatch
+func (this *ExportChunkReader) Read() ([][]byte, error) {
+	if this.header == nil {
+		return nil, fmt.Errorf("no chunk to read")
+	}
+	header := this.header
+	this.header = nil
+	data := make([]byte, header.Size)
+	_, err := io.ReadFull(this.r, data)
+	if err != nil {
+		return nil, err
+	}
+	if sha256.Sum256(data) != header.Checksum {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+	data, err = DecompressBlockData(data, this.metadata.CompressType)
+	if err != nil {
+		return nil, fmt.Errorf("DecompressBlockData error %s", err)
+	}
+	source := common.NewZeroCopySource(data)
+	items := make([][]byte, 0, header.Count)
+	for i := uint32(0); i < header.Count; i++ {
+		item, eof := source.NextVarBytes()
+		if eof {
+			return nil, io.ErrUnexpectedEOF
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
 func CompressBlockData(data []byte, compressType byte) ([]byte, error) {
 	switch compressType {
 	case COMPRESS_TYPE_ZLIB:
 		return ZLibCompress(data)
+	case COMPRESS_TYPE_ZSTD:
+		return ZstdCompress(data)
 	default:
 		return nil, fmt.Errorf("unknown compress type")
 	}
@@ -125,6 +335,8 @@ func DecompressBlockData(data []byte, compressType byte) ([]byte, error) {
 	switch compressType {
 	case COMPRESS_TYPE_ZLIB:
 		return ZLibDecompress(data)
+	case COMPRESS_TYPE_ZSTD:
+		return ZstdDecompress(data)
 	default:
 		return nil, fmt.Errorf("unknown compress type")
 	}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeExportChunks(t *testing.T, metadata *ExportBlockMetadata, count uint32) []byte {
+	buf := bytes.NewBuffer(nil)
+	err := metadata.Serialize(buf)
+	assert.Nil(t, err)
+	writer := NewExportChunkWriter(buf, metadata)
+	for i := uint32(0); i < count; i++ {
+		err = writer.Write([]byte(fmt.Sprintf("block %d", metadata.StartBlockHeight+i)))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, writer.Flush())
+	return buf.Bytes()
+}
+
+func TestExportChunks(t *testing.T) {
+	compressTypes := []byte{COMPRESS_TYPE_ZLIB}
+	if ZstdSupported {
+		compressTypes = append(compressTypes, COMPRESS_TYPE_ZSTD)
+	}
+	for _, compressType := range compressTypes {
+		metadata := NewExportBlockMetadata()
+		metadata.CompressType = compressType
+		metadata.ChunkSize = 3
+		metadata.StartBlockHeight = 1
+		metadata.EndBlockHeight = 10
+		data := writeExportChunks(t, metadata, 10)
+
+		r := bytes.NewReader(data)
+		metadata2 := NewExportBlockMetadata()
+		assert.Nil(t, metadata2.Deserialize(r))
+		assert.Equal(t, metadata, metadata2)
+
+		reader := NewExportChunkReader(r, metadata2)
+		height := uint32(1)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, height, header.StartHeight)
+			items, err := reader.Read()
+			assert.Nil(t, err)
+			assert.Equal(t, int(header.Count), len(items))
+			for _, item := range items {
+				assert.Equal(t, fmt.Sprintf("block %d", height), string(item))
+				height++
+			}
+		}
+		assert.Equal(t, uint32(11), height)
+	}
+}
+
+func TestExportChunksResume(t *testing.T) {
+	metadata := NewExportBlockMetadata()
+	metadata.CompressType = COMPRESS_TYPE_ZLIB
+	metadata.ChunkSize = 4
+	metadata.EndBlockHeight = 11
+	data := writeExportChunks(t, metadata, 12)
+
+	r := bytes.NewReader(data)
+	assert.Nil(t, NewExportBlockMetadata().Deserialize(r))
+	reader := NewExportChunkReader(r, metadata)
+	header, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(0), header.StartHeight)
+	// chunk not read is skipped by Next
+	header, err = reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(4), header.StartHeight)
+	assert.Nil(t, reader.Skip())
+	header, err = reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(8), header.StartHeight)
+	items, err := reader.Read()
+	assert.Nil(t, err)
+	assert.Equal(t, "block 8", string(items[0]))
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestExportChunksChecksum(t *testing.T) {
+	metadata := NewExportBlockMetadata()
+	metadata.CompressType = COMPRESS_TYPE_ZLIB
+	metadata.ChunkSize = 5
+	metadata.EndBlockHeight = 9
+	data := writeExportChunks(t, metadata, 10)
+	// corrupt the last byte of the second chunk
+	data[len(data)-1] ^= 0xff
+
+	r := bytes.NewReader(data)
+	assert.Nil(t, NewExportBlockMetadata().Deserialize(r))
+	reader := NewExportChunkReader(r, metadata)
+	_, err := reader.Next()
+	assert.Nil(t, err)
+	_, err = reader.Read()
+	assert.Nil(t, err)
+	header, err := reader.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(5), header.StartHeight)
+	_, err = reader.Read()
+	assert.NotNil(t, err)
+}
+
+func TestExportBlockMetadataV1(t *testing.T) {
+	metadata := NewExportBlockMetadata()
+	metadata.Version = EXPORT_BLOCK_METADATA_VERSION
+	metadata.StartBlockHeight = 1
+	metadata.EndBlockHeight = 100
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, metadata.Serialize(buf))
+
+	metadata2 := NewExportBlockMetadata()
+	assert.Nil(t, metadata2.Deserialize(buf))
+	assert.Equal(t, byte(EXPORT_BLOCK_METADATA_VERSION), metadata2.Version)
+	assert.Equal(t, metadata.StartBlockHeight, metadata2.StartBlockHeight)
+	assert.Equal(t, metadata.EndBlockHeight, metadata2.EndBlockHeight)
+	assert.Equal(t, metadata.CompressType, metadata2.CompressType)
+}
@@ -43,6 +43,24 @@ var (
 		Usage: "Set the log level to `<level>` (0~6). 0:Trace 1:Debug 2:Info 3:Warn 4:Error 5:Fatal 6:MaxLevel",
 		Value: config.DEFAULT_LOG_LEVEL,
 	}
+	LogFormatFlag = cli.StringFlag{
+		Name:  "log-format",
+		Usage: "Log output `<format>`, text or json. Json lines have fields such as module, chainID, height and txHash",
+		Value: config.DEFAULT_LOG_FORMAT,
+	}
+	LogModulesFlag = cli.StringFlag{
+		Name:  "log-modules",
+		Usage: "Override log level of modules by `<spec>`, such as consensus=debug,p2p=warn",
+	}
+	LogMaxSizeFlag = cli.UintFlag{
+		Name:  "log-max-size",
+		Usage: "Open a new log file when current one exceeds `<size>` MB",
+		Value: config.DEFAULT_MAX_LOG_SIZE,
+	}
+	LogMaxAgeFlag = cli.UintFlag{
+		Name:  "log-max-age",
+		Usage: "Remove log files older than `<days>`. 0 keeps all log files",
+	}
 	DisableEventLogFlag = cli.BoolFlag{
 		Name:  "disable-event-log",
 		Usage: "Discard event log output by smart contract execution",
@@ -62,11 +80,55 @@ var (
 		Usage: "Stop import block `<height>` of the import.",
 		Value: DEFAULT_EXPORT_HEIGHT,
 	}
+	ImportVerifyOnlyFlag = cli.BoolFlag{
+		Name:  "verify-only",
+		Usage: "Only verify the header chain and signatures of the import file against local ledger, blocks are not executed or saved",
+	}
 	DataDirFlag = cli.StringFlag{
 		Name:  "data-dir",
 		Usage: "Block data storage `<path>`",
 		Value: config.DEFAULT_DATA_DIR,
 	}
+	LightNodeFlag = cli.BoolFlag{
+		Name:  "light",
+		Usage: "Run as light node, only sync headers and serve header and proof rpc",
+	}
+	DBBackendFlag = cli.StringFlag{
+		Name:  "db-backend",
+		Usage: "Kv `<engine>` of new stores, leveldb or badger. Existing stores keep their engine until migrated by `db migrate`",
+		Value: config.DEFAULT_DB_BACKEND,
+	}
+	PruneBlocksFlag = cli.UintFlag{
+		Name:  "prune-blocks",
+		Usage: "Keep transactions and events of the latest `<number>` blocks only, headers and merkle data of all blocks are kept. 0 keeps all",
+		Value: config.DEFAULT_PRUNE_BLOCKS,
+	}
+	RollbackBlocksFlag = cli.UintFlag{
+		Name:  "rollback-blocks",
+		Usage: "Keep undo data of the latest `<number>` blocks, the ledger can be rolled back by `db rollback` within them. 0 disables",
+		Value: config.DEFAULT_ROLLBACK_BLOCKS,
+	}
+	RollbackHeightFlag = cli.UintFlag{
+		Name:  "height",
+		Usage: "Target block `<height>` of rollback",
+	}
+	RepairFlag = cli.BoolFlag{
+		Name:  "repair",
+		Usage: "Repair the inconsistency found by verify",
+	}
+	TargetDirFlag = cli.StringFlag{
+		Name:  "target-dir",
+		Usage: "Target storage `<path>` of migrated stores",
+	}
+	GenesisSpecFlag = cli.StringFlag{
+		Name:  "spec",
+		Usage: "Declarative genesis spec `<file>` of new network",
+	}
+	GenesisOutputDirFlag = cli.StringFlag{
+		Name:  "output-dir",
+		Usage: "Output `<path>` of genesis config and block",
+		Value: "./genesis",
+	}
 
 	//Consensus setting
 	EnableConsensusFlag = cli.BoolFlag{
@@ -78,6 +140,10 @@ var (
 		Usage: "Max transaction `<number>` in block",
 		Value: config.DEFAULT_MAX_TX_IN_BLOCK,
 	}
+	BlsKeyFlag = cli.StringFlag{
+		Name:  "bls-key",
+		Usage: "Hex encoded bls private key `<file>` used to sign blocks when the chain seals blocks with bls signatures",
+	}
 
 	//Test Mode setting
 	EnableTestModeFlag = cli.BoolFlag{
@@ -100,6 +166,10 @@ var (
 		Usage: "Reserved peers `<file>`",
 		Value: config.DEFAULT_RESERVED_FILE,
 	}
+	NoiseFlag = cli.BoolFlag{
+		Name:  "noise",
+		Usage: "Encrypt and authenticate p2p links with noise handshake. Consensus nodes authenticate with the wallet account, reserved peers may be pinned by key with \"reservedKeys\" in --reserved-file.",
+	}
 	NetworkIdFlag = cli.UintFlag{
 		Name:  "networkid",
 		Usage: "Network id `<number>`. 1=ontology main net, 2=polaris test net, 3=testmode, and other for custom network",
@@ -139,6 +209,27 @@ var (
 		Usage: "Max connection `<number>` in bound for single ip",
 		Value: config.DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
 	}
+	P2PCompressionFlag = cli.StringFlag{
+		Name:  "p2p-compression",
+		Usage: "Compression `<algorithm>` of block, transaction and consensus messages negotiated with peers, snappy, zstd or none. zstd requires cgo and falls back to snappy",
+		Value: config.DEFAULT_P2P_COMPRESSION,
+	}
+	MaxIngressRateFlag = cli.UintFlag{
+		Name:  "max-ingress-rate",
+		Usage: "Max `<bytes>` per second received from all peers, 0 for unlimited",
+	}
+	MaxEgressRateFlag = cli.UintFlag{
+		Name:  "max-egress-rate",
+		Usage: "Max `<bytes>` per second sent to all peers, 0 for unlimited",
+	}
+	MaxPeerIngressRateFlag = cli.UintFlag{
+		Name:  "max-peer-ingress-rate",
+		Usage: "Max `<bytes>` per second received from a single peer link, 0 for unlimited",
+	}
+	MaxPeerEgressRateFlag = cli.UintFlag{
+		Name:  "max-peer-egress-rate",
+		Usage: "Max `<bytes>` per second sent to a single peer link, 0 for unlimited",
+	}
 	// RPC settings
 	RPCDisabledFlag = cli.BoolFlag{
 		Name:  "disable-rpc",
@@ -158,6 +249,11 @@ var (
 		Usage: "Json rpc local server listening port `<number>`",
 		Value: config.DEFAULT_RPC_LOCAL_PORT,
 	}
+	RPCMaxRequestsFlag = cli.UintFlag{
+		Name:  "rpc-max-requests",
+		Usage: "Json rpc requests `<number>` served per second, 0 for unlimited",
+		Value: config.DEFAULT_RPC_MAX_REQUESTS,
+	}
 
 	//Websocket setting
 	WsEnabledFlag = cli.BoolFlag{
@@ -433,8 +529,22 @@ var (
 		Usage: "Export block speed `<level>` (h|m|l), h for high speed, m for middle speed and l for low speed",
 		Value: "m",
 	}
+	ExportCompressTypeFlag = cli.StringFlag{
+		Name:  "compress-type",
+		Usage: "Compress `<type>` (zstd|zlib) of export chunks, zstd requires cgo",
+		Value: "zstd",
+	}
+	ExportHeaderOnlyFlag = cli.BoolFlag{
+		Name:  "header-only",
+		Usage: "Export block headers only, for light node and relayer bootstrap",
+	}
 
 	//PreExecute switcher
+	TxpoolCapacityFlag = cli.UintFlag{
+		Name:  "tx-pool-capacity",
+		Usage: "Max verified transaction `<number>` held by tx pool",
+		Value: config.DEFAULT_TXPOOL_CAPACITY,
+	}
 	TxpoolPreExecDisableFlag = cli.BoolFlag{
 		Name:  "disable-tx-pool-pre-exec",
 		Usage: "Disable preExecute in tx pool",
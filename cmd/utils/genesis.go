@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/joeqian10/neo3-gogogo/crypto"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+)
+
+//GenesisSpec is the declarative description of a new relay network
+type GenesisSpec struct {
+	NetworkId   uint32             `json:"network_id"` //network magic, also the chain id of header and transaction
+	SeedList    []string           `json:"seed_list"`
+	DNSSeedList []string           `json:"dns_seed_list,omitempty"`
+	VBFT        *config.VBFTConfig `json:"vbft"`
+	config.GenesisGovernanceConfig
+}
+
+//Validate check the spec before genesis block built, the governance transactions are not executed
+//until node started, so errors rejected by native contracts are caught here
+func (this *GenesisSpec) Validate() error {
+	switch this.NetworkId {
+	case config.NETWORK_ID_MAIN_NET, config.NETWORK_ID_TEST_NET, config.NETWORK_ID_SOLO_NET:
+		return fmt.Errorf("network id %d is reserved", this.NetworkId)
+	}
+	if this.VBFT == nil {
+		return fmt.Errorf("missing vbft config")
+	}
+	if len(this.VBFT.Peers) < config.VBFT_MIN_NODE_NUM {
+		return fmt.Errorf("VBFT consensus at least need %d peers in config", config.VBFT_MIN_NODE_NUM)
+	}
+	if err := node_manager.CheckVBFTConfig(this.VBFT); err != nil {
+		return fmt.Errorf("vbft config error: %v", err)
+	}
+	if err := this.GenesisGovernanceConfig.Validate(); err != nil {
+		return err
+	}
+	for _, redeem := range this.BtcRedeems {
+		script, _ := hex.DecodeString(redeem.Redeem)
+		ty, _, _, err := txscript.ExtractPkScriptAddrs(script, &chaincfg.TestNet3Params)
+		if err != nil {
+			return fmt.Errorf("btc redeem of chain %d error: %v", redeem.RedeemChainID, err)
+		}
+		if ty != txscript.MultiSigTy {
+			return fmt.Errorf("btc redeem of chain %d is not multisig script: %s", redeem.RedeemChainID, ty.String())
+		}
+		if redeem.FeeRate > 0 && redeem.MinChange < 2000 {
+			return fmt.Errorf("btc redeem of chain %d min-change can't less than 2000", redeem.RedeemChainID)
+		}
+	}
+	for _, sv := range this.StateValidators {
+		if _, err := crypto.NewECPointFromString(sv); err != nil {
+			return fmt.Errorf("state validator %s is not a hex public key: %v", sv, err)
+		}
+	}
+	return nil
+}
+
+//GenesisConfig return the genesis config of node, loaded by --config
+func (this *GenesisSpec) GenesisConfig() *config.GenesisConfig {
+	genesisConfig := config.NewGenesisConfig()
+	genesisConfig.ConsensusType = config.CONSENSUS_TYPE_VBFT
+	if this.SeedList != nil {
+		genesisConfig.SeedList = this.SeedList
+	}
+	if this.DNSSeedList != nil {
+		genesisConfig.DNSSeedList = this.DNSSeedList
+	}
+	genesisConfig.VBFT = this.VBFT
+	gov := this.GenesisGovernanceConfig
+	if len(gov.SideChains) > 0 || len(gov.BtcRedeems) > 0 || len(gov.Relayers) > 0 || len(gov.StateValidators) > 0 {
+		genesisConfig.Governance = &gov
+	}
+	return genesisConfig
+}
+
+//BuildGenesis build the genesis config and block of spec. The genesis block reads network id and genesis
+//of config.DefConfig, so they are set to the spec's as node started with the emitted config
+func BuildGenesis(spec *GenesisSpec) (*config.GenesisConfig, *types.Block, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, nil, err
+	}
+	genesisConfig := spec.GenesisConfig()
+	config.DefConfig.P2PNode.NetworkId = spec.NetworkId
+	config.DefConfig.P2PNode.NetworkMagic = config.GetNetworkMagic(spec.NetworkId)
+	config.DefConfig.Genesis = genesisConfig
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetBookkeepers error:%s", err)
+	}
+	block, err := genesis.BuildGenesisBlock(bookkeepers, genesisConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("BuildGenesisBlock error:%s", err)
+	}
+	return genesisConfig, block, nil
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/neo3_state_manager"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testRedeem         = "552102dec9a415b6384ec0a9331d0cdf02020f0f1e5731c327b86e2b5a92455a289748210365b1066bcfa21987c3e207b92e309b95ca6bee5f1133cf04d6ed4ed265eafdbc21031104e387cd1a103c27fdc8a52d5c68dec25ddfb2f574fbdca405edfd8c5187de21031fdb4b44a9f20883aff505009ebc18702774c105cb04b1eecebcb294d404b1cb210387cda955196cc2b2fc0adbbbac1776f8de77b563c6d2a06a77d96457dc3d0d1f2102dd7767b6a7cc83693343ba721e0f5f4c7b4b8d85eeb7aec20d227625ec0f59d321034ad129efdab75061e8d4def08f5911495af2dae6d3e9a4b6e7aeb5186fa432fc57ae"
+	testStateValidator = "023e9b32ea89b94d066e649b124fd50e396ee91369e8e2a6ae1b11c170d022256d"
+)
+
+func newGenesisSpec() *GenesisSpec {
+	vbft := *config.MainNetConfig.VBFT
+	spec := &GenesisSpec{
+		NetworkId: 100,
+		SeedList:  []string{"127.0.0.1:20338"},
+		VBFT:      &vbft,
+	}
+	spec.SideChains = []*config.GenesisSideChain{
+		{
+			ChainId:     2,
+			Router:      2,
+			Name:        "eth",
+			CCMCAddress: "9a20bed97360d28ae93c21750e9492ea8f85989f",
+			ExtraInfo:   "",
+		},
+	}
+	spec.Relayers = []string{common.ADDRESS_EMPTY.ToBase58()}
+	return spec
+}
+
+func TestBuildGenesis(t *testing.T) {
+	spec := newGenesisSpec()
+	genesisConfig, block, err := BuildGenesis(spec)
+	assert.Nil(t, err)
+	assert.Equal(t, config.CONSENSUS_TYPE_VBFT, genesisConfig.ConsensusType)
+	assert.NotNil(t, genesisConfig.Governance)
+	assert.Equal(t, 4, len(block.Transactions))
+	assert.Equal(t, uint64(100), block.Header.ChainID)
+
+	_, again, err := BuildGenesis(newGenesisSpec())
+	assert.Nil(t, err)
+	assert.Equal(t, block.Hash(), again.Hash())
+
+	//no governance, only node manager init
+	spec = newGenesisSpec()
+	spec.SideChains = nil
+	spec.Relayers = nil
+	genesisConfig, block, err = BuildGenesis(spec)
+	assert.Nil(t, err)
+	assert.Nil(t, genesisConfig.Governance)
+	assert.Equal(t, 1, len(block.Transactions))
+}
+
+func TestGenesisSpecValidate(t *testing.T) {
+	spec := newGenesisSpec()
+	spec.NetworkId = config.NETWORK_ID_MAIN_NET
+	assert.NotNil(t, spec.Validate())
+
+	spec = newGenesisSpec()
+	spec.SideChains = append(spec.SideChains, spec.SideChains[0])
+	assert.NotNil(t, spec.Validate())
+
+	spec = newGenesisSpec()
+	spec.Relayers = []string{"invalid"}
+	assert.NotNil(t, spec.Validate())
+
+	spec = newGenesisSpec()
+	spec.BtcRedeems = []*config.GenesisBtcRedeem{{RedeemChainID: 1, Redeem: "0102"}}
+	assert.NotNil(t, spec.Validate())
+
+	spec = newGenesisSpec()
+	spec.VBFT.Peers = spec.VBFT.Peers[:1]
+	assert.NotNil(t, spec.Validate())
+
+	spec = newGenesisSpec()
+	spec.StateValidators = []string{"0102"}
+	assert.NotNil(t, spec.Validate())
+
+	spec = newGenesisSpec()
+	spec.StateValidators = []string{testStateValidator}
+	assert.Nil(t, spec.Validate())
+}
+
+func TestGenesisExecute(t *testing.T) {
+	spec := newGenesisSpec()
+	spec.BtcRedeems = []*config.GenesisBtcRedeem{
+		{
+			RedeemChainID:   1,
+			ContractChainID: 2,
+			Redeem:          testRedeem,
+			ContractAddress: "9a20bed97360d28ae93c21750e9492ea8f85989f",
+			FeeRate:         2,
+			MinChange:       2000,
+		},
+	}
+	spec.StateValidators = []string{testStateValidator}
+	_, block, err := BuildGenesis(spec)
+	assert.Nil(t, err)
+	bookkeepers, err := config.DefConfig.GetBookkeepers()
+	assert.Nil(t, err)
+
+	//only the governance contracts executed in genesis block
+	native.Contracts[utils.NodeManagerContractAddress] = node_manager.RegisterNodeManagerContract
+	native.Contracts[utils.SideChainManagerContractAddress] = side_chain_manager.RegisterSideChainManagerContract
+	native.Contracts[utils.RelayerManagerContractAddress] = relayer_manager.RegisterRelayerManagerContract
+	native.Contracts[utils.Neo3StateManagerContractAddress] = neo3_state_manager.RegisterStateValidatorManagerContract
+
+	dir, err := ioutil.TempDir("", "genesis")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	ldg, err := ledger.NewLedger(dir)
+	assert.Nil(t, err)
+	defer ldg.Close()
+	assert.Nil(t, ldg.Init(bookkeepers, block))
+
+	//every init transaction executed successfully
+	for _, tx := range block.Transactions {
+		notify, err := ldg.GetEventNotifyByTx(tx.Hash())
+		assert.Nil(t, err)
+		assert.Equal(t, byte(1), notify.State)
+	}
+
+	raw, err := ldg.GetStorageItem(utils.SideChainManagerContractAddress,
+		append([]byte(side_chain_manager.SIDE_CHAIN), utils.GetUint64Bytes(2)...))
+	assert.Nil(t, err)
+	sideChain := new(side_chain_manager.SideChain)
+	assert.Nil(t, sideChain.Deserialization(common.NewZeroCopySource(raw)))
+	assert.Equal(t, "eth", sideChain.Name)
+	assert.Equal(t, "9a20bed97360d28ae93c21750e9492ea8f85989f", hex.EncodeToString(sideChain.CCMCAddress))
+
+	raw, err = ldg.GetStorageItem(utils.RelayerManagerContractAddress,
+		append([]byte(relayer_manager.RELAYER), common.ADDRESS_EMPTY[:]...))
+	assert.Nil(t, err)
+	assert.Equal(t, common.ADDRESS_EMPTY[:], raw)
+
+	redeem, _ := hex.DecodeString(testRedeem)
+	raw, err = ldg.GetStorageItem(utils.SideChainManagerContractAddress,
+		bytes.Join([][]byte{[]byte(side_chain_manager.REDEEM_SCRIPT), utils.GetUint64Bytes(1),
+			[]byte(hex.EncodeToString(btcutil.Hash160(redeem)))}, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, redeem, raw)
+
+	raw, err = ldg.GetStorageItem(utils.Neo3StateManagerContractAddress, []byte(neo3_state_manager.STATE_VALIDATOR))
+	assert.Nil(t, err)
+	svs, err := neo3_state_manager.DeserializeStringArray(raw)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{testStateValidator}, svs)
+}
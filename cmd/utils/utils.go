@@ -159,6 +159,23 @@ func GetBlockData(hashOrHeight interface{}) ([]byte, error) {
 	return blockData, nil
 }
 
+func GetHeaderData(height uint32) ([]byte, error) {
+	data, ontErr := sendRpcRequest("getheaderbyheight", []interface{}{height})
+	if ontErr != nil {
+		return nil, ontErr.Error
+	}
+	hexStr := ""
+	err := json.Unmarshal(data, &hexStr)
+	if err != nil {
+		return nil, fmt.Errorf("json.Unmarshal error:%s", err)
+	}
+	headerData, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, fmt.Errorf("hex.DecodeString error:%s", err)
+	}
+	return headerData, nil
+}
+
 func GetBlockCount() (uint32, error) {
 	data, ontErr := sendRpcRequest("getblockcount", []interface{}{})
 	if ontErr != nil {
@@ -46,8 +46,16 @@ const (
 	CONSENSUS_TYPE_SOLO = "solo"
 	CONSENSUS_TYPE_VBFT = "vbft"
 
+	P2P_COMPRESSION_NONE   = "none"
+	P2P_COMPRESSION_SNAPPY = "snappy"
+	P2P_COMPRESSION_ZSTD   = "zstd"
+
+	DB_BACKEND_LEVELDB = "leveldb"
+	DB_BACKEND_BADGER  = "badger"
+
 	DEFAULT_LOG_LEVEL                       = log.InfoLog
 	DEFAULT_MAX_LOG_SIZE                    = 100 //MByte
+	DEFAULT_LOG_FORMAT                      = "text"
 	DEFAULT_NODE_PORT                       = uint(20338)
 	DEFAULT_CONSENSUS_PORT                  = uint(20339)
 	DEFAULT_RPC_PORT                        = uint(20336)
@@ -59,7 +67,10 @@ const (
 	DEFAULT_MAX_CONN_OUT_BOUND              = uint(1024)
 	DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP = uint(16)
 	DEFAULT_HTTP_INFO_PORT                  = uint(0)
+	DEFAULT_P2P_COMPRESSION                 = P2P_COMPRESSION_SNAPPY
 	DEFAULT_MAX_TX_IN_BLOCK                 = 60000
+	DEFAULT_TXPOOL_CAPACITY                 = uint(100140)
+	DEFAULT_RPC_MAX_REQUESTS                = uint(0)
 	DEFAULT_MAX_SYNC_HEADER                 = 500
 	DEFAULT_ENABLE_CONSENSUS                = true
 	DEFAULT_ENABLE_EVENT_LOG                = true
@@ -69,7 +80,13 @@ const (
 	DEFAULT_GAS_PRICE                       = 500
 
 	DEFAULT_DATA_DIR      = "./Chain"
+	DEFAULT_DB_BACKEND    = DB_BACKEND_LEVELDB
 	DEFAULT_RESERVED_FILE = "./peers.rsv"
+
+	DEFAULT_PRUNE_BLOCKS = 0    //keep all blocks and events
+	MIN_PRUNE_BLOCKS     = 1000 //consensus and block sync need the latest blocks
+
+	DEFAULT_ROLLBACK_BLOCKS = 1000 //keep undo data of the latest blocks for db rollback
 )
 
 const (
@@ -118,6 +135,16 @@ var HECO120_HEIGHT = map[uint32]uint64{
 	NETWORK_ID_TEST_NET: constants.HECO120_HEIGHT_TESTNET,
 }
 
+var BTC_VAULT_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.BTC_VAULT_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.BTC_VAULT_HEIGHT_TESTNET,
+}
+
+var VOTE_EXPIRY_HEIGHT = map[uint32]uint32{
+	NETWORK_ID_MAIN_NET: constants.VOTE_EXPIRY_HEIGHT_MAINNET,
+	NETWORK_ID_TEST_NET: constants.VOTE_EXPIRY_HEIGHT_TESTNET,
+}
+
 var POLYGON_SNAP_CHAINID = map[uint32]uint32{
 	NETWORK_ID_MAIN_NET: constants.POLYGON_SNAP_CHAINID_MAINNET,
 }
@@ -165,6 +192,14 @@ func GetExtraInfoHeight(id uint32) uint32 {
 	return EXTRA_INFO_HEIGHT[id]
 }
 
+func GetBtcVaultHeight(id uint32) uint32 {
+	return BTC_VAULT_HEIGHT[id]
+}
+
+func GetVoteExpiryHeight(id uint32) uint32 {
+	return VOTE_EXPIRY_HEIGHT[id]
+}
+
 func GetNetworkName(id uint32) string {
 	name, ok := NETWORK_NAME[id]
 	if ok {
@@ -285,15 +320,18 @@ var DefConfig = NewOntologyConfig()
 
 type GenesisConfig struct {
 	SeedList      []string
+	DNSSeedList   []string //dns names whose txt entries publish signed node records
 	ConsensusType string
 	VBFT          *VBFTConfig
 	DBFT          *DBFTConfig
 	SOLO          *SOLOConfig
+	Governance    *GenesisGovernanceConfig `json:",omitempty"` //registered by genesis block
 }
 
 func NewGenesisConfig() *GenesisConfig {
 	return &GenesisConfig{
 		SeedList:      make([]string, 0),
+		DNSSeedList:   make([]string, 0),
 		ConsensusType: CONSENSUS_TYPE_DBFT,
 		VBFT:          &VBFTConfig{},
 		DBFT:          &DBFTConfig{},
@@ -378,10 +416,210 @@ func (this *VBFTConfig) Deserialization(source *common.ZeroCopySource) error {
 	return nil
 }
 
+//
+// Governance state registered by genesis block, instead of governance transactions after started
+//
+type GenesisGovernanceConfig struct {
+	SideChains      []*GenesisSideChain `json:"side_chains,omitempty"`
+	BtcRedeems      []*GenesisBtcRedeem `json:"btc_redeems,omitempty"`
+	Relayers        []string            `json:"relayers,omitempty"`         //base58 addresses
+	StateValidators []string            `json:"state_validators,omitempty"` //neo3 state validator public keys
+}
+
+type GenesisSideChain struct {
+	Address      string `json:"address"` //base58 address of owner
+	ChainId      uint64 `json:"chain_id"`
+	Router       uint64 `json:"router"`
+	Name         string `json:"name"`
+	BlocksToWait uint64 `json:"blocks_to_wait"`
+	CCMCAddress  string `json:"ccmc_address"` //hex encoded
+	ExtraInfo    string `json:"extra_info"`   //hex encoded
+}
+
+type GenesisBtcRedeem struct {
+	RedeemChainID   uint64 `json:"redeem_chain_id"`
+	ContractChainID uint64 `json:"contract_chain_id"`
+	Redeem          string `json:"redeem"`           //hex encoded multisig redeem script
+	ContractAddress string `json:"contract_address"` //hex encoded
+	FeeRate         uint64 `json:"fee_rate"`         //btc tx param is not set if 0
+	MinChange       uint64 `json:"min_change"`
+}
+
+//Validate check the encoding of addresses and bytes
+func (this *GenesisGovernanceConfig) Validate() error {
+	chainIds := make(map[uint64]bool)
+	for _, chain := range this.SideChains {
+		if chainIds[chain.ChainId] {
+			return fmt.Errorf("side chain %d duplicated", chain.ChainId)
+		}
+		chainIds[chain.ChainId] = true
+		if chain.Address != "" {
+			if _, err := common.AddressFromBase58(chain.Address); err != nil {
+				return fmt.Errorf("side chain %d address error: %v", chain.ChainId, err)
+			}
+		}
+		if _, err := hex.DecodeString(chain.CCMCAddress); err != nil {
+			return fmt.Errorf("side chain %d ccmc address error: %v", chain.ChainId, err)
+		}
+		if _, err := hex.DecodeString(chain.ExtraInfo); err != nil {
+			return fmt.Errorf("side chain %d extra info error: %v", chain.ChainId, err)
+		}
+	}
+	for _, redeem := range this.BtcRedeems {
+		if _, err := hex.DecodeString(redeem.Redeem); err != nil {
+			return fmt.Errorf("btc redeem of chain %d error: %v", redeem.RedeemChainID, err)
+		}
+		if _, err := hex.DecodeString(redeem.ContractAddress); err != nil {
+			return fmt.Errorf("btc redeem contract address of chain %d error: %v", redeem.ContractChainID, err)
+		}
+	}
+	for _, relayer := range this.Relayers {
+		if _, err := common.AddressFromBase58(relayer); err != nil {
+			return fmt.Errorf("relayer %s error: %v", relayer, err)
+		}
+	}
+	return nil
+}
+
+func (this *GenesisGovernanceConfig) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.SideChains)))
+	for _, chain := range this.SideChains {
+		sink.WriteString(chain.Address)
+		sink.WriteVarUint(chain.ChainId)
+		sink.WriteVarUint(chain.Router)
+		sink.WriteString(chain.Name)
+		sink.WriteVarUint(chain.BlocksToWait)
+		sink.WriteString(chain.CCMCAddress)
+		sink.WriteString(chain.ExtraInfo)
+	}
+	sink.WriteVarUint(uint64(len(this.BtcRedeems)))
+	for _, redeem := range this.BtcRedeems {
+		sink.WriteVarUint(redeem.RedeemChainID)
+		sink.WriteVarUint(redeem.ContractChainID)
+		sink.WriteString(redeem.Redeem)
+		sink.WriteString(redeem.ContractAddress)
+		sink.WriteVarUint(redeem.FeeRate)
+		sink.WriteVarUint(redeem.MinChange)
+	}
+	sink.WriteVarUint(uint64(len(this.Relayers)))
+	for _, relayer := range this.Relayers {
+		sink.WriteString(relayer)
+	}
+	sink.WriteVarUint(uint64(len(this.StateValidators)))
+	for _, sv := range this.StateValidators {
+		sink.WriteString(sv)
+	}
+}
+
+func (this *GenesisGovernanceConfig) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint32, deserialize side chains length error!")
+	}
+	sideChains := make([]*GenesisSideChain, 0, n)
+	for i := uint64(0); i < n; i++ {
+		chain := new(GenesisSideChain)
+		chain.Address, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize side chain address error!")
+		}
+		chain.ChainId, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize side chain chain id error!")
+		}
+		chain.Router, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize side chain router error!")
+		}
+		chain.Name, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize side chain name error!")
+		}
+		chain.BlocksToWait, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize side chain blocks to wait error!")
+		}
+		chain.CCMCAddress, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize side chain ccmc address error!")
+		}
+		chain.ExtraInfo, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize side chain extra info error!")
+		}
+		sideChains = append(sideChains, chain)
+	}
+	n, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint32, deserialize btc redeems length error!")
+	}
+	redeems := make([]*GenesisBtcRedeem, 0, n)
+	for i := uint64(0); i < n; i++ {
+		redeem := new(GenesisBtcRedeem)
+		redeem.RedeemChainID, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize btc redeem chain id error!")
+		}
+		redeem.ContractChainID, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize btc redeem contract chain id error!")
+		}
+		redeem.Redeem, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize btc redeem script error!")
+		}
+		redeem.ContractAddress, eof = source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize btc redeem contract address error!")
+		}
+		redeem.FeeRate, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize btc redeem fee rate error!")
+		}
+		redeem.MinChange, eof = source.NextVarUint()
+		if eof {
+			return fmt.Errorf("serialization.ReadUint64, deserialize btc redeem min change error!")
+		}
+		redeems = append(redeems, redeem)
+	}
+	n, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint32, deserialize relayers length error!")
+	}
+	relayers := make([]string, 0, n)
+	for i := uint64(0); i < n; i++ {
+		relayer, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize relayer error!")
+		}
+		relayers = append(relayers, relayer)
+	}
+	n, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("serialization.ReadUint32, deserialize state validators length error!")
+	}
+	svs := make([]string, 0, n)
+	for i := uint64(0); i < n; i++ {
+		sv, eof := source.NextString()
+		if eof {
+			return fmt.Errorf("serialization.ReadString, deserialize state validator error!")
+		}
+		svs = append(svs, sv)
+	}
+	this.SideChains = sideChains
+	this.BtcRedeems = redeems
+	this.Relayers = relayers
+	this.StateValidators = svs
+	return nil
+}
+
 type VBFTPeerInfo struct {
 	Index      uint32 `json:"index"`
 	PeerPubkey string `json:"peerPubkey"`
 	Address    string `json:"address"`
+	// hex encoded bls key registered in node_manager, not serialized
+	BlsKey string `json:"blsKey,omitempty"`
 }
 
 func (this *VBFTPeerInfo) Serialization(sink *common.ZeroCopySink) error {
@@ -428,22 +666,36 @@ type SOLOConfig struct {
 
 type CommonConfig struct {
 	LogLevel       uint
+	LogFormat      string //text or json
+	LogModules     string //level overrides of modules, such as "consensus=debug,p2p=warn"
+	LogMaxSize     uint   //MByte, a new log file is opened when current one exceeds it
+	LogMaxAge      uint   //days, older log files are removed, 0 keeps all
 	NodeType       string
 	EnableEventLog bool
 	SystemFee      map[string]int64
 	GasLimit       uint64
 	GasPrice       uint64
 	DataDir        string
+	LightNode      bool   //only sync verified headers and fetch proofs from full nodes
+	DBBackend      string //kv engine of new stores, existing stores keep their engine until migrated
+	PruneBlocks    uint32 //keep transactions and events of the latest PruneBlocks blocks only, 0 keeps all
+	RollbackBlocks uint32 //keep undo data of the latest RollbackBlocks blocks, 0 disables rollback
 }
 
 type ConsensusConfig struct {
 	EnableConsensus bool
 	MaxTxInBlock    uint
+	BlsKeyPath      string
+}
+
+type TxPoolConfig struct {
+	Capacity uint //max verified transactions held by tx pool
 }
 
 type P2PRsvConfig struct {
 	ReservedPeers []string `json:"reserved"`
 	MaskPeers     []string `json:"mask"`
+	ReservedKeys  []string `json:"reservedKeys"` // hex encoded node keys, take the place of reserved addresses with noise
 }
 
 type P2PNodeConfig struct {
@@ -459,17 +711,24 @@ type P2PNodeConfig struct {
 	CertPath                  string
 	KeyPath                   string
 	CAPath                    string
+	IsNoise                   bool
 	HttpInfoPort              uint
 	MaxHdrSyncReqs            uint
 	MaxConnInBound            uint
 	MaxConnOutBound           uint
 	MaxConnInBoundForSingleIP uint
+	Compression               string //payload compression offered to peers, snappy, zstd or none
+	MaxIngressRate            uint   //bytes per second received from all peers, 0 for unlimited
+	MaxEgressRate             uint   //bytes per second sent to all peers, 0 for unlimited
+	MaxPeerIngressRate        uint   //bytes per second received from one link, 0 for unlimited
+	MaxPeerEgressRate         uint   //bytes per second sent to one link, 0 for unlimited
 }
 
 type RpcConfig struct {
 	EnableHttpJsonRpc bool
 	HttpJsonPort      uint
 	HttpLocalPort     uint
+	MaxRequests       uint //json rpc requests served per second, 0 for unlimited
 }
 
 type RestfulConfig struct {
@@ -491,6 +750,7 @@ type OntologyConfig struct {
 	Genesis   *GenesisConfig
 	Common    *CommonConfig
 	Consensus *ConsensusConfig
+	TxPool    *TxPoolConfig
 	P2PNode   *P2PNodeConfig
 	Rpc       *RpcConfig
 	Restful   *RestfulConfig
@@ -502,15 +762,23 @@ func NewOntologyConfig() *OntologyConfig {
 		Genesis: MainNetConfig,
 		Common: &CommonConfig{
 			LogLevel:       DEFAULT_LOG_LEVEL,
+			LogFormat:      DEFAULT_LOG_FORMAT,
+			LogMaxSize:     DEFAULT_MAX_LOG_SIZE,
 			EnableEventLog: DEFAULT_ENABLE_EVENT_LOG,
 			SystemFee:      make(map[string]int64),
 			GasLimit:       DEFAULT_GAS_LIMIT,
 			DataDir:        DEFAULT_DATA_DIR,
+			DBBackend:      DEFAULT_DB_BACKEND,
+			PruneBlocks:    DEFAULT_PRUNE_BLOCKS,
+			RollbackBlocks: DEFAULT_ROLLBACK_BLOCKS,
 		},
 		Consensus: &ConsensusConfig{
 			EnableConsensus: true,
 			MaxTxInBlock:    DEFAULT_MAX_TX_IN_BLOCK,
 		},
+		TxPool: &TxPoolConfig{
+			Capacity: DEFAULT_TXPOOL_CAPACITY,
+		},
 		P2PNode: &P2PNodeConfig{
 			ReservedCfg:               &P2PRsvConfig{},
 			ReservedPeersOnly:         false,
@@ -523,17 +791,20 @@ func NewOntologyConfig() *OntologyConfig {
 			IsTLS:                     false,
 			CertPath:                  "",
 			KeyPath:                   "",
+			IsNoise:                   false,
 			CAPath:                    "",
 			HttpInfoPort:              DEFAULT_HTTP_INFO_PORT,
 			MaxHdrSyncReqs:            DEFAULT_MAX_SYNC_HEADER,
 			MaxConnInBound:            DEFAULT_MAX_CONN_IN_BOUND,
 			MaxConnOutBound:           DEFAULT_MAX_CONN_OUT_BOUND,
 			MaxConnInBoundForSingleIP: DEFAULT_MAX_CONN_IN_BOUND_FOR_SINGLE_IP,
+			Compression:               DEFAULT_P2P_COMPRESSION,
 		},
 		Rpc: &RpcConfig{
 			EnableHttpJsonRpc: true,
 			HttpJsonPort:      DEFAULT_RPC_PORT,
 			HttpLocalPort:     DEFAULT_RPC_LOCAL_PORT,
+			MaxRequests:       DEFAULT_RPC_MAX_REQUESTS,
 		},
 		Restful: &RestfulConfig{
 			EnableHttpRestful: true,
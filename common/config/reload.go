@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/polynetwork/poly/common/log"
+)
+
+//reloadLock guards the reloadable fields, which are read by the getters below while running
+var reloadLock sync.RWMutex
+
+//ReloadableConfig is the subset of config which can be changed at runtime, nil fields are kept
+type ReloadableConfig struct {
+	LogLevel                  *uint    `json:"log_level,omitempty"`
+	LogModules                *string  `json:"log_modules,omitempty"`
+	ReservedPeersOnly         *bool    `json:"reserved_peers_only,omitempty"`
+	ReservedPeers             []string `json:"reserved_peers,omitempty"`
+	MaskPeers                 []string `json:"mask_peers,omitempty"`
+	MaxConnInBound            *uint    `json:"max_conn_in_bound,omitempty"`
+	MaxConnOutBound           *uint    `json:"max_conn_out_bound,omitempty"`
+	MaxConnInBoundForSingleIP *uint    `json:"max_conn_in_bound_for_single_ip,omitempty"`
+	MaxTxInBlock              *uint    `json:"max_tx_in_block,omitempty"`
+	TxPoolCapacity            *uint    `json:"tx_pool_capacity,omitempty"`
+	RpcMaxRequests            *uint    `json:"rpc_max_requests,omitempty"`
+}
+
+//GetReloadableConfig return the current values of reloadable config
+func (this *OntologyConfig) GetReloadableConfig() *ReloadableConfig {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+	logLevel := this.Common.LogLevel
+	logModules := this.Common.LogModules
+	rsvOnly := this.P2PNode.ReservedPeersOnly
+	inBound := this.P2PNode.MaxConnInBound
+	outBound := this.P2PNode.MaxConnOutBound
+	singleIP := this.P2PNode.MaxConnInBoundForSingleIP
+	maxTx := this.Consensus.MaxTxInBlock
+	capacity := this.TxPool.Capacity
+	maxRequests := this.Rpc.MaxRequests
+	rsvCfg := this.P2PNode.ReservedCfg
+	if rsvCfg == nil {
+		rsvCfg = &P2PRsvConfig{}
+	}
+	return &ReloadableConfig{
+		LogLevel:                  &logLevel,
+		LogModules:                &logModules,
+		ReservedPeersOnly:         &rsvOnly,
+		ReservedPeers:             append([]string{}, rsvCfg.ReservedPeers...),
+		MaskPeers:                 append([]string{}, rsvCfg.MaskPeers...),
+		MaxConnInBound:            &inBound,
+		MaxConnOutBound:           &outBound,
+		MaxConnInBoundForSingleIP: &singleIP,
+		MaxTxInBlock:              &maxTx,
+		TxPoolCapacity:            &capacity,
+		RpcMaxRequests:            &maxRequests,
+	}
+}
+
+//Reload validate the update against current config and apply it when all fields are valid.
+//Return the changes applied, one line for each field.
+func (this *OntologyConfig) Reload(update *ReloadableConfig) ([]string, error) {
+	reloadLock.Lock()
+	defer reloadLock.Unlock()
+
+	rsvCfg := &P2PRsvConfig{}
+	if this.P2PNode.ReservedCfg != nil {
+		*rsvCfg = *this.P2PNode.ReservedCfg
+	}
+	logLevel := this.Common.LogLevel
+	rsvOnly := this.P2PNode.ReservedPeersOnly
+	inBound := this.P2PNode.MaxConnInBound
+	outBound := this.P2PNode.MaxConnOutBound
+	singleIP := this.P2PNode.MaxConnInBoundForSingleIP
+	if update.LogLevel != nil {
+		logLevel = *update.LogLevel
+	}
+	if update.ReservedPeersOnly != nil {
+		rsvOnly = *update.ReservedPeersOnly
+	}
+	if update.ReservedPeers != nil {
+		rsvCfg.ReservedPeers = update.ReservedPeers
+	}
+	if update.MaskPeers != nil {
+		rsvCfg.MaskPeers = update.MaskPeers
+	}
+	if update.MaxConnInBound != nil {
+		inBound = *update.MaxConnInBound
+	}
+	if update.MaxConnOutBound != nil {
+		outBound = *update.MaxConnOutBound
+	}
+	if update.MaxConnInBoundForSingleIP != nil {
+		singleIP = *update.MaxConnInBoundForSingleIP
+	}
+
+	//only the constraints of updated fields are checked, so fields set at startup are not rejected
+	if logLevel > log.MaxLevelLog {
+		return nil, fmt.Errorf("log level should not be greater than %d", log.MaxLevelLog)
+	}
+	if update.LogModules != nil {
+		if _, err := log.ParseModuleLevels(*update.LogModules); err != nil {
+			return nil, err
+		}
+	}
+	if update.ReservedPeersOnly != nil || update.ReservedPeers != nil || update.MaskPeers != nil {
+		for _, peer := range append(append([]string{}, rsvCfg.ReservedPeers...), rsvCfg.MaskPeers...) {
+			if peer == "" {
+				return nil, fmt.Errorf("empty reserved or mask peer")
+			}
+		}
+		if rsvOnly && len(rsvCfg.ReservedPeers) == 0 && len(rsvCfg.ReservedKeys) == 0 {
+			return nil, fmt.Errorf("reserved peers only without reserved peers")
+		}
+	}
+	if update.MaxConnInBound != nil || update.MaxConnOutBound != nil || update.MaxConnInBoundForSingleIP != nil {
+		if inBound == 0 || outBound == 0 || singleIP == 0 {
+			return nil, fmt.Errorf("connection limits should be greater than 0")
+		}
+		if singleIP > inBound {
+			return nil, fmt.Errorf("max inbound connections for single ip %d greater than max inbound connections %d",
+				singleIP, inBound)
+		}
+	}
+	if update.TxPoolCapacity != nil && *update.TxPoolCapacity == 0 {
+		return nil, fmt.Errorf("tx pool capacity should be greater than 0")
+	}
+
+	changes := make([]string, 0)
+	record := func(name string, old, cur interface{}) {
+		if fmt.Sprint(old) != fmt.Sprint(cur) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", name, old, cur))
+		}
+	}
+	if logLevel != this.Common.LogLevel {
+		if err := log.Log.SetDebugLevel(int(logLevel)); err != nil {
+			return nil, fmt.Errorf("set log level error: %s", err)
+		}
+		record("Common.LogLevel", this.Common.LogLevel, logLevel)
+		this.Common.LogLevel = logLevel
+	}
+	if update.LogModules != nil && *update.LogModules != this.Common.LogModules {
+		if err := log.SetModuleLevels(*update.LogModules); err != nil {
+			return nil, fmt.Errorf("set log modules error: %s", err)
+		}
+		record("Common.LogModules", this.Common.LogModules, *update.LogModules)
+		this.Common.LogModules = *update.LogModules
+	}
+	if this.P2PNode.ReservedCfg != nil {
+		record("P2PNode.ReservedCfg.ReservedPeers", this.P2PNode.ReservedCfg.ReservedPeers, rsvCfg.ReservedPeers)
+		record("P2PNode.ReservedCfg.MaskPeers", this.P2PNode.ReservedCfg.MaskPeers, rsvCfg.MaskPeers)
+	} else {
+		record("P2PNode.ReservedCfg.ReservedPeers", []string(nil), rsvCfg.ReservedPeers)
+		record("P2PNode.ReservedCfg.MaskPeers", []string(nil), rsvCfg.MaskPeers)
+	}
+	//replaced instead of modified, readers keep the old one
+	this.P2PNode.ReservedCfg = rsvCfg
+	record("P2PNode.ReservedPeersOnly", this.P2PNode.ReservedPeersOnly, rsvOnly)
+	this.P2PNode.ReservedPeersOnly = rsvOnly
+	record("P2PNode.MaxConnInBound", this.P2PNode.MaxConnInBound, inBound)
+	this.P2PNode.MaxConnInBound = inBound
+	record("P2PNode.MaxConnOutBound", this.P2PNode.MaxConnOutBound, outBound)
+	this.P2PNode.MaxConnOutBound = outBound
+	record("P2PNode.MaxConnInBoundForSingleIP", this.P2PNode.MaxConnInBoundForSingleIP, singleIP)
+	this.P2PNode.MaxConnInBoundForSingleIP = singleIP
+	if update.MaxTxInBlock != nil {
+		record("Consensus.MaxTxInBlock", this.Consensus.MaxTxInBlock, *update.MaxTxInBlock)
+		this.Consensus.MaxTxInBlock = *update.MaxTxInBlock
+	}
+	if update.TxPoolCapacity != nil {
+		record("TxPool.Capacity", this.TxPool.Capacity, *update.TxPoolCapacity)
+		this.TxPool.Capacity = *update.TxPoolCapacity
+	}
+	if update.RpcMaxRequests != nil {
+		record("Rpc.MaxRequests", this.Rpc.MaxRequests, *update.RpcMaxRequests)
+		this.Rpc.MaxRequests = *update.RpcMaxRequests
+	}
+	return changes, nil
+}
+
+//GetReservedCfg return the reserved peers only flag together with the reserved config it applies to
+func (this *OntologyConfig) GetReservedCfg() (bool, *P2PRsvConfig) {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	rsvCfg := this.P2PNode.ReservedCfg
+	if rsvCfg == nil {
+		rsvCfg = &P2PRsvConfig{}
+	}
+	return this.P2PNode.ReservedPeersOnly, rsvCfg
+}
+
+//GetMaxConnLimits return max inbound, outbound and inbound for single ip connections
+func (this *OntologyConfig) GetMaxConnLimits() (uint, uint, uint) {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return this.P2PNode.MaxConnInBound, this.P2PNode.MaxConnOutBound, this.P2PNode.MaxConnInBoundForSingleIP
+}
+
+func (this *OntologyConfig) GetMaxTxInBlock() uint {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return this.Consensus.MaxTxInBlock
+}
+
+func (this *OntologyConfig) GetTxPoolCapacity() uint {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return this.TxPool.Capacity
+}
+
+func (this *OntologyConfig) GetRpcMaxRequests() uint {
+	reloadLock.RLock()
+	defer reloadLock.RUnlock()
+	return this.Rpc.MaxRequests
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/polynetwork/poly/common/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	cfg := NewOntologyConfig()
+	inBound, singleIP, level := uint(100), uint(10), uint(log.DebugLog)
+	changes, err := cfg.Reload(&ReloadableConfig{
+		LogLevel:                  &level,
+		MaxConnInBound:            &inBound,
+		MaxConnInBoundForSingleIP: &singleIP,
+		MaskPeers:                 []string{"127.0.0.1:20338"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(changes))
+	assert.Equal(t, uint(log.DebugLog), cfg.Common.LogLevel)
+	assert.Equal(t, inBound, cfg.P2PNode.MaxConnInBound)
+	assert.Equal(t, DEFAULT_MAX_CONN_OUT_BOUND, cfg.P2PNode.MaxConnOutBound)
+	assert.Equal(t, []string{"127.0.0.1:20338"}, cfg.P2PNode.ReservedCfg.MaskPeers)
+
+	//nothing changed
+	changes, err = cfg.Reload(&ReloadableConfig{MaxConnInBound: &inBound})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(changes))
+
+	current := cfg.GetReloadableConfig()
+	assert.Equal(t, inBound, *current.MaxConnInBound)
+	assert.Equal(t, DEFAULT_TXPOOL_CAPACITY, *current.TxPoolCapacity)
+	log.Log.SetDebugLevel(log.InfoLog)
+
+	modules := "consensus=debug,p2p=warn"
+	changes, err = cfg.Reload(&ReloadableConfig{LogModules: &modules})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Common.LogModules:  -> consensus=debug,p2p=warn"}, changes)
+	assert.Equal(t, modules, *cfg.GetReloadableConfig().LogModules)
+	log.SetModuleLevels("")
+}
+
+func TestReloadInvalid(t *testing.T) {
+	cfg := NewOntologyConfig()
+	zero, big, level := uint(0), uint(2048), uint(log.MaxLevelLog+1)
+	rsvOnly := true
+	modules := "consensus=verbose"
+	invalids := []*ReloadableConfig{
+		{LogLevel: &level},
+		{LogModules: &modules},
+		{MaxConnOutBound: &zero},
+		{MaxConnInBoundForSingleIP: &big},
+		{ReservedPeersOnly: &rsvOnly},
+		{ReservedPeers: []string{""}},
+		{TxPoolCapacity: &zero},
+	}
+	for _, update := range invalids {
+		//the valid field is not applied either
+		maxTx := uint(1)
+		update.MaxTxInBlock = &maxTx
+		_, err := cfg.Reload(update)
+		assert.NotNil(t, err)
+		assert.Equal(t, uint(DEFAULT_MAX_TX_IN_BLOCK), cfg.Consensus.MaxTxInBlock)
+	}
+	assert.Equal(t, DEFAULT_MAX_CONN_OUT_BOUND, cfg.P2PNode.MaxConnOutBound)
+	assert.False(t, cfg.P2PNode.ReservedPeersOnly)
+}
+
+func TestReloadWhileReading(t *testing.T) {
+	cfg := NewOntologyConfig()
+	stop := make(chan struct{})
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			rsvOnly, rsvCfg := cfg.GetReservedCfg()
+			//flag is only set with the reserved peers of the same reload
+			if rsvOnly && len(rsvCfg.ReservedPeers) == 0 {
+				t.Errorf("reserved peers only without reserved peers")
+				return
+			}
+			cfg.GetMaxConnLimits()
+			cfg.GetMaxTxInBlock()
+			cfg.GetTxPoolCapacity()
+			cfg.GetRpcMaxRequests()
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		rsvOnly := i%2 == 0
+		peers := []string{}
+		if rsvOnly {
+			peers = []string{fmt.Sprintf("127.0.0.%d", i%250+1)}
+		}
+		limit := uint(i + 100)
+		_, err := cfg.Reload(&ReloadableConfig{
+			ReservedPeersOnly: &rsvOnly,
+			ReservedPeers:     peers,
+			MaxConnInBound:    &limit,
+			MaxTxInBlock:      &limit,
+			TxPoolCapacity:    &limit,
+			RpcMaxRequests:    &limit,
+		})
+		assert.Nil(t, err)
+	}
+	close(stop)
+	wg.Wait()
+
+	inBound, _, _ := cfg.GetMaxConnLimits()
+	assert.Equal(t, uint(299), inBound)
+	assert.Equal(t, uint(299), cfg.GetRpcMaxRequests())
+}
@@ -19,6 +19,7 @@
 package constants
 
 import (
+	"math"
 	"time"
 )
 
@@ -56,3 +57,11 @@ const POLYGON_SNAP_CHAINID_MAINNET = 16
 
 // eth arrow glacier upgrade
 const ETH4345_HEIGHT_MAINNET = 13_773_000
+
+// btc vault utxo consolidation and fee bumping height, not scheduled yet
+const BTC_VAULT_HEIGHT_MAINNET = math.MaxUint32
+const BTC_VAULT_HEIGHT_TESTNET = math.MaxUint32
+
+// consensus vote config, expiry and index height, not scheduled yet
+const VOTE_EXPIRY_HEIGHT_MAINNET = math.MaxUint32
+const VOTE_EXPIRY_HEIGHT_TESTNET = math.MaxUint32
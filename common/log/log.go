@@ -113,16 +113,19 @@ func NameLevel(name string) int {
 }
 
 type Logger struct {
-	level   int
-	logger  *log.Logger
-	logFile *os.File
+	level      int
+	logger     *log.Logger
+	jsonLogger *log.Logger //without prefix, lines are formatted as json
+	logFile    *os.File
+	writer     *RotateWriter
 }
 
 func New(out io.Writer, prefix string, flag, level int, file *os.File) *Logger {
 	return &Logger{
-		level:   level,
-		logger:  log.New(out, prefix, flag),
-		logFile: file,
+		level:      level,
+		logger:     log.New(out, prefix, flag),
+		jsonLogger: log.New(out, "", 0),
+		logFile:    file,
 	}
 }
 
@@ -135,26 +138,41 @@ func (l *Logger) SetDebugLevel(level int) error {
 	return nil
 }
 
-func (l *Logger) Output(level int, a ...interface{}) error {
-	if level >= l.level {
-		gid := GetGID()
-		gidStr := strconv.FormatUint(gid, 10)
+//moduleLevel return the level of module, which is overridden by module levels
+func (l *Logger) moduleLevel(module string) int {
+	if module != "" {
+		if level, ok := getModuleLevels().match(module); ok {
+			return level
+		}
+	}
+	return l.level
+}
 
-		a = append([]interface{}{LevelName(level), "GID",
-			gidStr + ","}, a...)
+//minLevel return the lowest level of global and modules, logs below it are skipped without locating caller
+func (l *Logger) minLevel() int {
+	if ml := getModuleLevels(); ml != nil && ml.min < l.level {
+		return ml.min
+	}
+	return l.level
+}
 
-		return l.logger.Output(CALL_DEPTH, fmt.Sprintln(a...))
+func (l *Logger) write(e *entry) error {
+	if IsJsonFormat() {
+		return l.jsonLogger.Output(CALL_DEPTH, e.json())
+	}
+	return l.logger.Output(CALL_DEPTH, e.text())
+}
+
+func (l *Logger) Output(level int, a ...interface{}) error {
+	if level >= l.level {
+		return l.write(&entry{level: level, msg: sprint(a)})
 	}
 	return nil
 }
 
 func (l *Logger) Outputf(level int, format string, v ...interface{}) error {
 	if level >= l.level {
-		gid := GetGID()
-		v = append([]interface{}{LevelName(level), "GID",
-			gid}, v...)
-
-		return l.logger.Output(CALL_DEPTH, fmt.Sprintf("%s %s %d, "+format+"\n", v...))
+		return l.write(&entry{level: level, msg: sprintf(format, v)})
 	}
 	return nil
 }
@@ -207,108 +225,117 @@ func (l *Logger) Fatalf(format string, a ...interface{}) {
 	l.Outputf(FatalLog, format, a...)
 }
 
-func Trace(a ...interface{}) {
-	if TraceLog < Log.level {
+const (
+	callerNone = iota
+	callerDebug
+	callerTrace
+)
+
+//output write the log of the caller of package functions, the module is derived from package of caller
+//when it is needed by json format or module levels
+func output(level int, callerType int, msg func() string) {
+	l := Log
+	if level < l.minLevel() {
 		return
 	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	nameFull := f.Name()
-	nameEnd := filepath.Ext(nameFull)
-	funcName := strings.TrimPrefix(nameEnd, ".")
-
-	a = append([]interface{}{funcName + "()", fileName + ":" + strconv.Itoa(line)}, a...)
-
-	Log.Trace(a...)
-}
-
-func Tracef(format string, a ...interface{}) {
-	if TraceLog < Log.level {
+	var pc uintptr
+	if callerType != callerNone || needModule() {
+		pcs := make([]uintptr, 1)
+		//skip runtime.Callers, output and the package function
+		runtime.Callers(3, pcs)
+		pc = pcs[0]
+	}
+	module := ""
+	if needModule() {
+		module = pcModule(pc)
+	}
+	if level < l.moduleLevel(module) {
 		return
 	}
+	l.write(&entry{level: level, module: module, caller: callerOf(pc, callerType), msg: msg()})
+}
 
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
+//callerOf return the function and line of pc
+func callerOf(pc uintptr, callerType int) string {
+	if callerType == callerNone {
+		return ""
+	}
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return ""
+	}
+	file, line := f.FileLine(pc)
 	fileName := filepath.Base(file)
+	if callerType == callerTrace {
+		nameEnd := filepath.Ext(f.Name())
+		funcName := strings.TrimPrefix(nameEnd, ".")
+		return funcName + "() " + fileName + ":" + strconv.Itoa(line)
+	}
+	return f.Name() + " " + fileName + ":" + strconv.Itoa(line)
+}
 
-	nameFull := f.Name()
-	nameEnd := filepath.Ext(nameFull)
-	funcName := strings.TrimPrefix(nameEnd, ".")
-
-	a = append([]interface{}{funcName, fileName, line}, a...)
+func Trace(a ...interface{}) {
+	output(TraceLog, callerTrace, func() string { return sprint(a) })
+}
 
-	Log.Tracef("%s() %s:%d "+format, a...)
+func Tracef(format string, a ...interface{}) {
+	output(TraceLog, callerTrace, func() string { return sprintf(format, a) })
 }
 
 func Debug(a ...interface{}) {
-	if DebugLog < Log.level {
-		return
-	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	a = append([]interface{}{f.Name(), fileName + ":" + strconv.Itoa(line)}, a...)
-
-	Log.Debug(a...)
+	output(DebugLog, callerDebug, func() string { return sprint(a) })
 }
 
 func Debugf(format string, a ...interface{}) {
-	if DebugLog < Log.level {
-		return
-	}
-
-	pc := make([]uintptr, 10)
-	runtime.Callers(2, pc)
-	f := runtime.FuncForPC(pc[0])
-	file, line := f.FileLine(pc[0])
-	fileName := filepath.Base(file)
-
-	a = append([]interface{}{f.Name(), fileName, line}, a...)
-
-	Log.Debugf("%s %s:%d "+format, a...)
+	output(DebugLog, callerDebug, func() string { return sprintf(format, a) })
 }
 
 func Info(a ...interface{}) {
-	Log.Info(a...)
+	output(InfoLog, callerNone, func() string { return sprint(a) })
 }
 
 func Warn(a ...interface{}) {
-	Log.Warn(a...)
+	output(WarnLog, callerNone, func() string { return sprint(a) })
 }
 
 func Error(a ...interface{}) {
-	Log.Error(a...)
+	output(ErrorLog, callerNone, func() string { return sprint(a) })
 }
 
 func Fatal(a ...interface{}) {
-	Log.Fatal(a...)
+	output(FatalLog, callerNone, func() string { return sprint(a) })
 }
 
 func Infof(format string, a ...interface{}) {
-	Log.Infof(format, a...)
+	output(InfoLog, callerNone, func() string { return sprintf(format, a) })
 }
 
 func Warnf(format string, a ...interface{}) {
-	Log.Warnf(format, a...)
+	output(WarnLog, callerNone, func() string { return sprintf(format, a) })
 }
 
 func Errorf(format string, a ...interface{}) {
-	Log.Errorf(format, a...)
+	output(ErrorLog, callerNone, func() string { return sprintf(format, a) })
 }
 
 func Fatalf(format string, a ...interface{}) {
-	Log.Fatalf(format, a...)
+	output(FatalLog, callerNone, func() string { return sprintf(format, a) })
+}
+
+//Auditf write the log of operations for audit at warn level, it is kept whatever the global and
+//module levels are, since the operations may change them
+func Auditf(format string, a ...interface{}) {
+	Log.write(&entry{level: WarnLog, module: "audit", named: true, msg: sprintf(format, a)})
+}
+
+//sprint format operands as fmt.Sprintln without the newline. Operands are passed as slice,
+//so the log functions are not taken as print wrappers by vet
+func sprint(a []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(a...), "\n")
+}
+
+func sprintf(format string, a []interface{}) string {
+	return fmt.Sprintf(format, a...)
 }
 
 func FileOpen(path string) (*os.File, error) {
@@ -341,7 +368,7 @@ func Init(a ...interface{}) {
 
 func InitLog(logLevel int, a ...interface{}) {
 	writers := []io.Writer{}
-	var logFile *os.File
+	var logWriter *RotateWriter
 	var err error
 	if len(a) == 0 {
 		writers = append(writers, ioutil.Discard)
@@ -349,12 +376,12 @@ func InitLog(logLevel int, a ...interface{}) {
 		for _, o := range a {
 			switch o.(type) {
 			case string:
-				logFile, err = FileOpen(o.(string))
+				logWriter, err = NewRotateWriter(o.(string), rotateMaxSize, rotateMaxAge)
 				if err != nil {
 					fmt.Println("error: open log file failed")
 					os.Exit(1)
 				}
-				writers = append(writers, logFile)
+				writers = append(writers, logWriter)
 			case *os.File:
 				writers = append(writers, o.(*os.File))
 			default:
@@ -364,10 +391,17 @@ func InitLog(logLevel int, a ...interface{}) {
 		}
 	}
 	fileAndStdoutWrite := io.MultiWriter(writers...)
-	Log = New(fileAndStdoutWrite, "", log.Ldate|log.Lmicroseconds, logLevel, logFile)
+	Log = New(fileAndStdoutWrite, "", log.Ldate|log.Lmicroseconds, logLevel, nil)
+	Log.writer = logWriter
 }
 
 func GetLogFileSize() (int64, error) {
+	if Log.writer != nil {
+		return Log.writer.Size(), nil
+	}
+	if Log.logFile == nil {
+		return 0, errors.New("no log file")
+	}
 	f, e := Log.logFile.Stat()
 	if e != nil {
 		return 0, e
@@ -383,6 +417,7 @@ func GetMaxLogChangeInterval(maxLogSize int64) int64 {
 	}
 }
 
+//CheckIfNeedNewFile deprecated, log file is rotated by RotateWriter of InitLog
 func CheckIfNeedNewFile() bool {
 	logFileSize, err := GetLogFileSize()
 	maxLogFileSize := GetMaxLogChangeInterval(0)
@@ -398,6 +433,9 @@ func CheckIfNeedNewFile() bool {
 
 func ClosePrintLog() error {
 	var err error
+	if Log.writer != nil {
+		err = Log.writer.Close()
+	}
 	if Log.logFile != nil {
 		err = Log.logFile.Close()
 	}
@@ -0,0 +1,371 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	FORMAT_TEXT = "text"
+	FORMAT_JSON = "json"
+
+	REPO_PACKAGE_PREFIX = "github.com/polynetwork/poly/"
+)
+
+var (
+	levelTexts = map[int]string{
+		TraceLog: "trace",
+		DebugLog: "debug",
+		InfoLog:  "info",
+		WarnLog:  "warn",
+		ErrorLog: "error",
+		FatalLog: "fatal",
+	}
+	//short module names of packages
+	moduleAlias = map[string]string{
+		"p2p":    "p2pserver",
+		"header": "native/service/header_sync",
+		"cross":  "native/service/cross_chain_manager",
+	}
+
+	jsonFormat   int32
+	moduleLevels atomic.Value //*moduleLevelMap
+	pcModules    sync.Map     //pc of caller to module
+)
+
+// Fields are the structured fields of log line, such as chainID, height and txHash
+type Fields map[string]interface{}
+
+// entry is a log line
+type entry struct {
+	level  int
+	module string
+	named  bool //module named by Module, printed in text format too
+	fields Fields
+	caller string
+	msg    string
+}
+
+func (this *entry) text() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(LevelName(this.level))
+	buf.WriteString(" GID ")
+	buf.WriteString(strconv.FormatUint(GetGID(), 10))
+	buf.WriteString(", ")
+	if this.named {
+		buf.WriteString("[" + this.module + "] ")
+	}
+	if this.caller != "" {
+		buf.WriteString(this.caller + " ")
+	}
+	buf.WriteString(this.msg)
+	for _, k := range sortedKeys(this.fields) {
+		fmt.Fprintf(buf, " %s=%v", k, this.fields[k])
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+func (this *entry) json() string {
+	buf := new(bytes.Buffer)
+	buf.WriteString(`{"time":`)
+	writeJsonValue(buf, time.Now().Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteString(`,"level":`)
+	writeJsonValue(buf, LevelText(this.level))
+	if this.module != "" {
+		buf.WriteString(`,"module":`)
+		writeJsonValue(buf, this.module)
+	}
+	buf.WriteString(`,"gid":`)
+	buf.WriteString(strconv.FormatUint(GetGID(), 10))
+	if this.caller != "" {
+		buf.WriteString(`,"caller":`)
+		writeJsonValue(buf, this.caller)
+	}
+	buf.WriteString(`,"msg":`)
+	writeJsonValue(buf, this.msg)
+	for _, k := range sortedKeys(this.fields) {
+		buf.WriteString(",")
+		writeJsonValue(buf, k)
+		buf.WriteString(":")
+		writeJsonValue(buf, this.fields[k])
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+func writeJsonValue(buf *bytes.Buffer, v interface{}) {
+	if s, ok := v.(fmt.Stringer); ok {
+		v = s.String()
+	} else if e, ok := v.(error); ok {
+		v = e.Error()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(data)
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LevelText return the plain name of level used by json format and module levels
+func LevelText(level int) string {
+	if text, ok := levelTexts[level]; ok {
+		return text
+	}
+	return strconv.Itoa(level)
+}
+
+// ParseLevel parse level from name or number
+func ParseLevel(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for level, text := range levelTexts {
+		if s == text {
+			return level, nil
+		}
+	}
+	level, err := strconv.Atoi(s)
+	if err != nil || level < 0 || level > MaxLevelLog {
+		return 0, fmt.Errorf("invalid log level %s", s)
+	}
+	return level, nil
+}
+
+// SetFormat set the format of log lines, text or json
+func SetFormat(format string) error {
+	switch format {
+	case FORMAT_TEXT, "":
+		atomic.StoreInt32(&jsonFormat, 0)
+	case FORMAT_JSON:
+		atomic.StoreInt32(&jsonFormat, 1)
+	default:
+		return fmt.Errorf("unknown log format %s", format)
+	}
+	return nil
+}
+
+func IsJsonFormat() bool {
+	return atomic.LoadInt32(&jsonFormat) == 1
+}
+
+// moduleLevelMap is the level overrides of modules
+type moduleLevelMap struct {
+	levels map[string]int
+	min    int
+}
+
+// match return the level of the longest module matched. A module matches the package path
+// relative to repo, its prefix directories, or the directories in it, such as consensus, p2pserver
+// or header_sync
+func (this *moduleLevelMap) match(module string) (int, bool) {
+	if this == nil {
+		return 0, false
+	}
+	matched, level := "", 0
+	for name, l := range this.levels {
+		if len(name) <= len(matched) {
+			continue
+		}
+		if module == name || strings.HasPrefix(module, name+"/") || strings.HasSuffix(module, "/"+name) ||
+			strings.Contains(module, "/"+name+"/") {
+			matched, level = name, l
+		}
+	}
+	return level, matched != ""
+}
+
+func getModuleLevels() *moduleLevelMap {
+	ml, _ := moduleLevels.Load().(*moduleLevelMap)
+	return ml
+}
+
+// ParseModuleLevels parse module levels like "consensus=debug,p2p=warn"
+func ParseModuleLevels(spec string) (map[string]int, error) {
+	levels := make(map[string]int)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kv := strings.SplitN(item, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid module level %s, should be module=level", item)
+		}
+		level, err := ParseLevel(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		module := strings.Trim(strings.TrimSpace(kv[0]), "/")
+		if alias, ok := moduleAlias[module]; ok {
+			module = alias
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// SetModuleLevels override the level of modules by spec like "consensus=debug,p2p=warn", empty spec clears them
+func SetModuleLevels(spec string) error {
+	levels, err := ParseModuleLevels(spec)
+	if err != nil {
+		return err
+	}
+	if len(levels) == 0 {
+		moduleLevels.Store((*moduleLevelMap)(nil))
+		return nil
+	}
+	ml := &moduleLevelMap{levels: levels, min: MaxLevelLog}
+	for _, level := range levels {
+		if level < ml.min {
+			ml.min = level
+		}
+	}
+	moduleLevels.Store(ml)
+	return nil
+}
+
+// needModule return whether the module of caller is needed
+func needModule() bool {
+	return IsJsonFormat() || getModuleLevels() != nil
+}
+
+// pcModule return the package path of pc relative to repo
+func pcModule(pc uintptr) string {
+	if module, ok := pcModules.Load(pc); ok {
+		return module.(string)
+	}
+	module := ""
+	if f := runtime.FuncForPC(pc); f != nil {
+		name := f.Name()
+		//package path ends at the first dot after the last slash
+		slash := strings.LastIndex(name, "/")
+		if dot := strings.Index(name[slash+1:], "."); dot >= 0 {
+			name = name[:slash+1+dot]
+		}
+		module = strings.TrimPrefix(name, REPO_PACKAGE_PREFIX)
+	}
+	pcModules.Store(pc, module)
+	return module
+}
+
+// ModuleLogger log with module name and structured fields
+type ModuleLogger struct {
+	module string
+	fields Fields
+}
+
+// Module return the logger of module, the level of module can be overridden by SetModuleLevels
+func Module(module string) *ModuleLogger {
+	return &ModuleLogger{module: module}
+}
+
+// With return a logger with fields added
+func (this *ModuleLogger) With(fields Fields) *ModuleLogger {
+	merged := make(Fields, len(this.fields)+len(fields))
+	for k, v := range this.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &ModuleLogger{module: this.module, fields: merged}
+}
+
+//output write the log with caller as package functions do
+func (this *ModuleLogger) output(level int, callerType int, msg func() string) {
+	l := Log
+	if level < l.moduleLevel(this.module) {
+		return
+	}
+	var pc uintptr
+	if callerType != callerNone {
+		pcs := make([]uintptr, 1)
+		//skip runtime.Callers, output and the logger method
+		runtime.Callers(3, pcs)
+		pc = pcs[0]
+	}
+	l.write(&entry{level: level, module: this.module, named: true, fields: this.fields, caller: callerOf(pc, callerType),
+		msg: msg()})
+}
+
+func (this *ModuleLogger) Trace(a ...interface{}) {
+	this.output(TraceLog, callerTrace, func() string { return sprint(a) })
+}
+
+func (this *ModuleLogger) Tracef(format string, a ...interface{}) {
+	this.output(TraceLog, callerTrace, func() string { return sprintf(format, a) })
+}
+
+func (this *ModuleLogger) Debug(a ...interface{}) {
+	this.output(DebugLog, callerDebug, func() string { return sprint(a) })
+}
+
+func (this *ModuleLogger) Debugf(format string, a ...interface{}) {
+	this.output(DebugLog, callerDebug, func() string { return sprintf(format, a) })
+}
+
+func (this *ModuleLogger) Info(a ...interface{}) {
+	this.output(InfoLog, callerNone, func() string { return sprint(a) })
+}
+
+func (this *ModuleLogger) Infof(format string, a ...interface{}) {
+	this.output(InfoLog, callerNone, func() string { return sprintf(format, a) })
+}
+
+func (this *ModuleLogger) Warn(a ...interface{}) {
+	this.output(WarnLog, callerNone, func() string { return sprint(a) })
+}
+
+func (this *ModuleLogger) Warnf(format string, a ...interface{}) {
+	this.output(WarnLog, callerNone, func() string { return sprintf(format, a) })
+}
+
+func (this *ModuleLogger) Error(a ...interface{}) {
+	this.output(ErrorLog, callerNone, func() string { return sprint(a) })
+}
+
+func (this *ModuleLogger) Errorf(format string, a ...interface{}) {
+	this.output(ErrorLog, callerNone, func() string { return sprintf(format, a) })
+}
+
+func (this *ModuleLogger) Fatal(a ...interface{}) {
+	this.output(FatalLog, callerNone, func() string { return sprint(a) })
+}
+
+func (this *ModuleLogger) Fatalf(format string, a ...interface{}) {
+	this.output(FatalLog, callerNone, func() string { return sprintf(format, a) })
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func captureLog(level int) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	Log = New(buf, "", 0, level, nil)
+	return buf
+}
+
+func TestJsonFormat(t *testing.T) {
+	defer InitLog(InfoLog, Stdout)
+	defer SetFormat(FORMAT_TEXT)
+	buf := captureLog(InfoLog)
+	assert.Nil(t, SetFormat(FORMAT_JSON))
+	assert.NotNil(t, SetFormat("xml"))
+
+	Infof("info %d", 1)
+	Module("header_sync").With(Fields{"chainID": 2, "height": 10}).With(Fields{"txHash": "ab"}).Warn("warn", "header")
+	Debug("skipped")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	m := make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &m))
+	assert.Equal(t, "info", m["level"])
+	assert.Equal(t, "common/log", m["module"])
+	assert.Equal(t, "info 1", m["msg"])
+	m = make(map[string]interface{})
+	assert.Nil(t, json.Unmarshal([]byte(lines[1]), &m))
+	assert.Equal(t, "warn", m["level"])
+	assert.Equal(t, "header_sync", m["module"])
+	assert.Equal(t, "warn header", m["msg"])
+	assert.Equal(t, float64(2), m["chainID"])
+	assert.Equal(t, float64(10), m["height"])
+	assert.Equal(t, "ab", m["txHash"])
+}
+
+func TestTextFormat(t *testing.T) {
+	defer InitLog(InfoLog, Stdout)
+	buf := captureLog(InfoLog)
+	Info("info", 1)
+	Module("p2pserver").With(Fields{"height": 10}).Infof("info %d", 2)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], LevelName(InfoLog)+" GID "))
+	assert.True(t, strings.HasSuffix(lines[0], ", info 1"))
+	assert.True(t, strings.HasSuffix(lines[1], ", [p2pserver] info 2 height=10"))
+
+	//debug and trace lines keep the caller as package functions
+	buf = captureLog(TraceLog)
+	Module("core/store").With(Fields{"height": 10}).Debugf("debug %d", 3)
+	Module("core/store").Trace("trace")
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Regexp(t, `, \[core/store\] github.com/polynetwork/poly/common/log.TestTextFormat module_test.go:\d+ debug 3 height=10$`, lines[0])
+	assert.Regexp(t, `, \[core/store\] TestTextFormat\(\) module_test.go:\d+ trace$`, lines[1])
+}
+
+func TestModuleLevels(t *testing.T) {
+	defer InitLog(InfoLog, Stdout)
+	defer SetModuleLevels("")
+	levels, err := ParseModuleLevels("consensus=debug, p2p=warn,core/store=1")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"consensus": DebugLog, "p2pserver": WarnLog, "core/store": DebugLog}, levels)
+	for _, spec := range []string{"consensus", "consensus=verbose", "=debug", "p2p=7"} {
+		_, err := ParseModuleLevels(spec)
+		assert.NotNil(t, err, spec)
+	}
+
+	assert.Nil(t, SetModuleLevels("consensus=debug,p2p=warn,common=error"))
+	ml := getModuleLevels()
+	assert.Equal(t, DebugLog, ml.min)
+	for module, expected := range map[string]int{
+		"consensus/vbft":           DebugLog,
+		"p2pserver/net/netserver":  WarnLog,
+		"native/service/consensus": DebugLog,
+		"common/log":               ErrorLog,
+	} {
+		level, ok := ml.match(module)
+		assert.True(t, ok, module)
+		assert.Equal(t, expected, level, module)
+	}
+	_, ok := ml.match("txnpool")
+	assert.False(t, ok)
+
+	buf := captureLog(InfoLog)
+	//common/log is at error level
+	Warn("skipped")
+	Error("error")
+	Module("consensus").Debug("debug")
+	Module("p2pserver").Info("skipped")
+	Module("txnpool").Info("info")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 3, len(lines))
+
+	assert.Nil(t, SetModuleLevels(""))
+	assert.Nil(t, getModuleLevels())
+}
+
+func TestAudit(t *testing.T) {
+	defer InitLog(InfoLog, Stdout)
+	defer SetModuleLevels("")
+	buf := captureLog(FatalLog)
+	assert.Nil(t, SetModuleLevels("common=fatal"))
+	Warn("skipped")
+	Auditf("config reloaded %s", "logLevel")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Equal(t, 1, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], LevelName(WarnLog)+" GID "))
+	assert.True(t, strings.HasSuffix(lines[0], ", [audit] config reloaded logLevel"))
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const LOG_FILE_SUFFIX = "_LOG.log"
+
+var (
+	rotateMaxSize = int64(DEFAULT_MAX_LOG_SIZE * BYTE_TO_MB)
+	rotateMaxAge  time.Duration //zero keeps all log files
+)
+
+// SetRotation set the max size in MB of a log file and the max age in days of log files,
+// zero size uses the default and zero age keeps all files
+func SetRotation(maxSizeMB, maxAgeDays uint) {
+	rotateMaxSize = GetMaxLogChangeInterval(int64(maxSizeMB))
+	rotateMaxAge = time.Duration(maxAgeDays) * 24 * time.Hour
+	if Log != nil && Log.writer != nil {
+		Log.writer.setLimits(rotateMaxSize, rotateMaxAge)
+	}
+}
+
+// RotateWriter write log to files in dir, a new file is opened when the file exceeds max size,
+// and files older than max age are removed
+type RotateWriter struct {
+	sync.Mutex
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+	file    *os.File
+	size    int64
+}
+
+func NewRotateWriter(dir string, maxSize int64, maxAge time.Duration) (*RotateWriter, error) {
+	this := &RotateWriter{dir: dir, maxSize: maxSize, maxAge: maxAge}
+	if err := this.openNew(); err != nil {
+		return nil, err
+	}
+	//files left by former runs are removed on start, not only on rotation
+	this.prune()
+	return this, nil
+}
+
+func (this *RotateWriter) setLimits(maxSize int64, maxAge time.Duration) {
+	this.Lock()
+	defer this.Unlock()
+	this.maxSize = maxSize
+	this.maxAge = maxAge
+}
+
+// openNew open a new log file named by current time
+func (this *RotateWriter) openNew() error {
+	if err := os.MkdirAll(this.dir, 0766); err != nil {
+		return err
+	}
+	name := filepath.Join(this.dir, time.Now().Format("2006-01-02_15.04.05")+LOG_FILE_SUFFIX)
+	//files rotated in the same second are suffixed
+	for i := 1; ; i++ {
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			break
+		}
+		name = filepath.Join(this.dir, time.Now().Format("2006-01-02_15.04.05")+LOG_FILE_SUFFIX+"."+strconv.Itoa(i))
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	if this.file != nil {
+		this.file.Close()
+	}
+	this.file = file
+	this.size = 0
+	return nil
+}
+
+func (this *RotateWriter) Write(p []byte) (int, error) {
+	this.Lock()
+	defer this.Unlock()
+	if this.file == nil {
+		return 0, os.ErrClosed
+	}
+	if this.maxSize > 0 && this.size > 0 && this.size+int64(len(p)) > this.maxSize {
+		if err := this.openNew(); err != nil {
+			return 0, err
+		}
+		this.prune()
+	}
+	n, err := this.file.Write(p)
+	this.size += int64(n)
+	return n, err
+}
+
+// prune remove log files older than max age except the current one
+func (this *RotateWriter) prune() {
+	if this.maxAge <= 0 {
+		return
+	}
+	files, err := filepath.Glob(filepath.Join(this.dir, "*"+LOG_FILE_SUFFIX+"*"))
+	if err != nil {
+		return
+	}
+	deadline := time.Now().Add(-this.maxAge)
+	for _, name := range files {
+		if name == this.file.Name() || !strings.Contains(filepath.Base(name), LOG_FILE_SUFFIX) {
+			continue
+		}
+		if fi, err := os.Stat(name); err == nil && !fi.IsDir() && fi.ModTime().Before(deadline) {
+			os.Remove(name)
+		}
+	}
+}
+
+// Size return the size of current log file
+func (this *RotateWriter) Size() int64 {
+	this.Lock()
+	defer this.Unlock()
+	return this.size
+}
+
+func (this *RotateWriter) Close() error {
+	this.Lock()
+	defer this.Unlock()
+	if this.file == nil {
+		return nil
+	}
+	err := this.file.Close()
+	this.file = nil
+	return err
+}
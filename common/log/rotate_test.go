@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	old := filepath.Join(dir, "2000-01-01_00.00.00"+LOG_FILE_SUFFIX)
+	assert.Nil(t, ioutil.WriteFile(old, []byte("old"), 0666))
+	past := time.Now().Add(-48 * time.Hour)
+	assert.Nil(t, os.Chtimes(old, past, past))
+	other := filepath.Join(dir, "other.txt")
+	assert.Nil(t, ioutil.WriteFile(other, []byte("other"), 0666))
+	assert.Nil(t, os.Chtimes(other, past, past))
+
+	w, err := NewRotateWriter(dir, 10, 24*time.Hour)
+	assert.Nil(t, err)
+	//old file is pruned on start
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+LOG_FILE_SUFFIX+"*"))
+	assert.Equal(t, 1, len(files))
+	_, err = os.Stat(old)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(other)
+	assert.Nil(t, err)
+
+	_, err = w.Write([]byte("0123456789abc"))
+	assert.Nil(t, err)
+	//a line larger than max size is written to the empty file
+	assert.Equal(t, int64(13), w.Size())
+	files, _ = filepath.Glob(filepath.Join(dir, "*"+LOG_FILE_SUFFIX+"*"))
+	assert.Equal(t, 1, len(files))
+
+	//file rotated out is pruned on rotation once it is too old
+	assert.Nil(t, os.Chtimes(files[0], past, past))
+	_, err = w.Write([]byte("0123"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), w.Size())
+	rotated, _ := filepath.Glob(filepath.Join(dir, "*"+LOG_FILE_SUFFIX+"*"))
+	assert.Equal(t, 1, len(rotated))
+	assert.NotEqual(t, files[0], rotated[0])
+
+	assert.Nil(t, w.Close())
+	_, err = w.Write([]byte("closed"))
+	assert.NotNil(t, err)
+}
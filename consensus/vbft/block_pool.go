@@ -20,9 +20,11 @@ package vbft
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 
 	"github.com/polynetwork/poly/core/store/overlaydb"
@@ -30,6 +32,11 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/signature/bls"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
 )
 
 type BlockList []*Block
@@ -41,6 +48,7 @@ var errDupCommit = errors.New("multi commit from same committer")
 type CandidateEndorseSigInfo struct {
 	EndorsedProposer uint32
 	Signature        []byte
+	BlsSignature     []byte
 	ForEmpty         bool
 }
 
@@ -64,6 +72,23 @@ type CandidateInfo struct {
 
 	// indexed by endorserIndex
 	EndorseSigs map[uint32][]*CandidateEndorseSigInfo
+
+	// proposed headers and header signatures of peers, for equivocation detecting
+	Headers    map[common.Uint256]*ProposedHeader
+	HeaderSigs map[uint32]map[headerSig][]byte // signer -> sig kind and header hash -> sig
+}
+
+// consensus msg kind and hash of a signed header
+type headerSig struct {
+	kind node_manager.SigKind
+	hash common.Uint256
+}
+
+type ProposedHeader struct {
+	Header   *types.Header
+	Proposer uint32
+	View     uint32 // last config block num of the proposal
+	Empty    bool   // empty block of proposal, or block without user txs
 }
 
 type BlockPool struct {
@@ -136,6 +161,27 @@ func (pool *BlockPool) newBlockProposal(msg *blockProposalMsg) error {
 	blkNum := msg.GetBlockNum()
 	candidate := pool.getCandidateInfoLocked(blkNum)
 
+	// record proposed headers, the empty block of proposal is excluded from equivocation
+	proposer := msg.Block.getProposer()
+	header := msg.Block.Block.Header
+	pool.addHeaderSigLocked(blkNum, &ProposedHeader{
+		Header:   header,
+		Proposer: proposer,
+		View:     msg.Block.getLastConfigBlockNum(),
+		Empty:    msg.Block.Info.NewChainConfig != nil || header.TransactionsRoot == common.UINT256_EMPTY,
+	})
+	if msg.Block.EmptyBlock != nil {
+		pool.addHeaderSigLocked(blkNum, &ProposedHeader{
+			Header:   msg.Block.EmptyBlock.Header,
+			Proposer: proposer,
+			View:     msg.Block.getLastConfigBlockNum(),
+			Empty:    true,
+		})
+	}
+	for signer := range candidate.HeaderSigs {
+		pool.checkEquivocationLocked(blkNum, signer)
+	}
+
 	// check dup-proposal from same proposer
 	for _, p := range candidate.Proposals {
 		if p.Block.getProposer() == msg.Block.getProposer() {
@@ -150,12 +196,14 @@ func (pool *BlockPool) newBlockProposal(msg *blockProposalMsg) error {
 	candidate.Proposals = append(candidate.Proposals, msg)
 
 	// add endorse-sig
-	proposer := msg.Block.getProposer()
 	eSig := &CandidateEndorseSigInfo{
 		EndorsedProposer: proposer,
 		Signature:        msg.Block.Block.Header.SigData[0],
 		ForEmpty:         false,
 	}
+	if header := msg.Block.Block.Header; header.Version == types.BLS_HEADER_VERSION && len(header.SigData) > 1 {
+		eSig.BlsSignature = header.SigData[1]
+	}
 	return pool.addBlockEndorsementLocked(msg.GetBlockNum(), proposer, eSig, false)
 }
 
@@ -289,6 +337,104 @@ func (pool *BlockPool) addBlockEndorsementLocked(blkNum uint32, endorser uint32,
 	return nil
 }
 
+func (pool *BlockPool) addHeaderSigLocked(blkNum uint32, proposed *ProposedHeader) {
+	candidate := pool.getCandidateInfoLocked(blkNum)
+	if candidate.Headers == nil {
+		candidate.Headers = make(map[common.Uint256]*ProposedHeader)
+	}
+	hash := proposed.Header.Hash()
+	if _, present := candidate.Headers[hash]; !present {
+		candidate.Headers[hash] = proposed
+	}
+	if len(proposed.Header.SigData) > 0 {
+		pool.addSigLocked(blkNum, proposed.Proposer, node_manager.ProposalSig, hash, proposed.Header.SigData[0])
+	}
+}
+
+//
+// record the signature of signer on header hash, the signature is verified
+// since endorsers' sigs in commit msgs are not checked in msg verification
+//
+func (pool *BlockPool) addSigLocked(blkNum uint32, signer uint32, kind node_manager.SigKind, hash common.Uint256, sig []byte) {
+	candidate := pool.getCandidateInfoLocked(blkNum)
+	if candidate.HeaderSigs == nil {
+		candidate.HeaderSigs = make(map[uint32]map[headerSig][]byte)
+	}
+	sigs := candidate.HeaderSigs[signer]
+	if sigs == nil {
+		sigs = make(map[headerSig][]byte)
+		candidate.HeaderSigs[signer] = sigs
+	}
+	key := headerSig{kind: kind, hash: hash}
+	if _, present := sigs[key]; present {
+		return
+	}
+	pk := pool.server.peerPool.GetPeerPubKey(signer)
+	if pk == nil {
+		return
+	}
+	if err := signature.Verify(pk, hash[:], sig); err != nil {
+		return
+	}
+	sigs[key] = sig
+}
+
+//
+// check if signer has signed two non-empty headers in one round, i.e. at the same
+// height and chain config view, by the same kind of msg. An honest peer proposes
+// one block, endorses one block and commits one block in a round, the blocks it
+// endorses and commits may differ, so each kind of msg is checked separately.
+// Signatures of a peer on its own proposal only count as proposal, as node_manager
+// can not tell them from endorsements
+//
+func (pool *BlockPool) checkEquivocationLocked(blkNum uint32, signer uint32) {
+	type round struct {
+		height uint32
+		view   uint32 // last config block num
+		kind   node_manager.SigKind
+	}
+	candidate := pool.getCandidateInfoLocked(blkNum)
+	rounds := make(map[round][]common.Uint256)
+	for key := range candidate.HeaderSigs[signer] {
+		proposed, present := candidate.Headers[key.hash]
+		if !present || proposed.Empty {
+			continue
+		}
+		if (proposed.Proposer == signer) != (key.kind == node_manager.ProposalSig) {
+			continue
+		}
+		r := round{height: proposed.Header.Height, view: proposed.View, kind: key.kind}
+		rounds[r] = append(rounds[r], key.hash)
+	}
+	for r, hashes := range rounds {
+		if len(hashes) < 2 {
+			continue
+		}
+		pk := pool.server.peerPool.GetPeerPubKey(signer)
+		if pk == nil {
+			return
+		}
+		sort.Slice(hashes, func(i, j int) bool {
+			return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+		})
+		param := &node_manager.ReportEquivocationParam{
+			PeerPubkey: vconfig.PubkeyID(pk),
+			Kind:       r.kind,
+		}
+		for _, hash := range hashes[:2] {
+			sink := common.NewZeroCopySink(nil)
+			if err := candidate.Headers[hash].Header.Serialization(sink); err != nil {
+				log.Errorf("failed to serialize header %s: %s", hash.ToHexString(), err)
+				return
+			}
+			param.Headers = append(param.Headers, sink.Bytes())
+			param.Sigs = append(param.Sigs, candidate.HeaderSigs[signer][headerSig{kind: r.kind, hash: hash}])
+		}
+		pool.server.evidencePool.addEvidence(blkNum, param)
+		return
+	}
+}
+
 //
 // add endorsement msg to CandidateInfo
 //
@@ -296,9 +442,13 @@ func (pool *BlockPool) newBlockEndorsement(msg *blockEndorseMsg) error {
 	pool.lock.Lock()
 	defer pool.lock.Unlock()
 
+	pool.addSigLocked(msg.GetBlockNum(), msg.Endorser, node_manager.EndorseSig, msg.EndorsedBlockHash, msg.EndorserSig)
+	pool.checkEquivocationLocked(msg.GetBlockNum(), msg.Endorser)
+
 	eSig := &CandidateEndorseSigInfo{
 		EndorsedProposer: msg.EndorsedProposer,
 		Signature:        msg.EndorserSig,
+		BlsSignature:     msg.EndorserBlsSig,
 		ForEmpty:         msg.EndorseForEmpty,
 	}
 	return pool.addBlockEndorsementLocked(msg.GetBlockNum(), msg.Endorser, eSig, false)
@@ -454,6 +604,14 @@ func (pool *BlockPool) newBlockCommitment(msg *blockCommitMsg) error {
 	blkNum := msg.GetBlockNum()
 	candidate := pool.getCandidateInfoLocked(blkNum)
 
+	// record commit and endorse sigs
+	pool.addSigLocked(blkNum, msg.Committer, node_manager.CommitSig, msg.CommitBlockHash, msg.CommitterSig)
+	pool.checkEquivocationLocked(blkNum, msg.Committer)
+	for endorser, sig := range msg.EndorsersSig {
+		pool.addSigLocked(blkNum, endorser, node_manager.EndorseSig, msg.CommitBlockHash, sig)
+		pool.checkEquivocationLocked(blkNum, endorser)
+	}
+
 	// check dup-commit
 	for _, c := range candidate.CommitMsgs {
 		if c.Committer == msg.Committer {
@@ -470,6 +628,7 @@ func (pool *BlockPool) newBlockCommitment(msg *blockCommitMsg) error {
 		eSig := &CandidateEndorseSigInfo{
 			EndorsedProposer: msg.BlockProposer,
 			Signature:        sig,
+			BlsSignature:     msg.EndorsersBlsSig[endorser],
 			ForEmpty:         msg.CommitForEmpty,
 		}
 		if err := pool.addBlockEndorsementLocked(blkNum, endorser, eSig, false); err != nil {
@@ -481,6 +640,7 @@ func (pool *BlockPool) newBlockCommitment(msg *blockCommitMsg) error {
 	pool.addBlockEndorsementLocked(blkNum, msg.Committer, &CandidateEndorseSigInfo{
 		EndorsedProposer: msg.BlockProposer,
 		Signature:        msg.CommitterSig,
+		BlsSignature:     msg.CommitterBlsSig,
 		ForEmpty:         msg.CommitForEmpty,
 	}, true)
 
@@ -592,19 +752,22 @@ func (pool *BlockPool) addSignaturesToBlockLocked(block *Block, forEmpty bool) e
 
 	bookkeepers := make([]keypair.PublicKey, 0)
 	sigData := make([][]byte, 0)
+	blsSigs := make(map[uint32][]byte)
 
 	// add proposer sig
 	proposer := block.getProposer()
 	proposerPk := pool.server.peerPool.GetPeerPubKey(proposer)
-	if !forEmpty {
-		bookkeepers = append(bookkeepers, proposerPk)
-		sigData = append(sigData, block.Block.Header.SigData[0])
-	} else {
+	header := block.Block.Header
+	if forEmpty {
 		if block.EmptyBlock == nil {
 			return fmt.Errorf("block has no empty candidate")
 		}
-		bookkeepers = append(bookkeepers, proposerPk)
-		sigData = append(sigData, block.EmptyBlock.Header.SigData[0])
+		header = block.EmptyBlock.Header
+	}
+	bookkeepers = append(bookkeepers, proposerPk)
+	sigData = append(sigData, header.SigData[0])
+	if header.Version == types.BLS_HEADER_VERSION && len(header.SigData) > 1 {
+		blsSigs[proposer] = header.SigData[1]
 	}
 
 	// add endorsers' sig
@@ -615,11 +778,25 @@ func (pool *BlockPool) addSignaturesToBlockLocked(block *Block, forEmpty bool) e
 				if endoresrPk != nil {
 					bookkeepers = append(bookkeepers, endoresrPk)
 					sigData = append(sigData, sig.Signature)
+					if len(sig.BlsSignature) > 0 {
+						blsSigs[endorser] = sig.BlsSignature
+					}
 				}
 				break
 			}
 		}
 	}
+
+	// seal with bls aggregate signature, fallback to the signature list if
+	// not enough valid bls signatures collected
+	if header.Version == types.BLS_HEADER_VERSION {
+		err := pool.sealBlsHeaderLocked(header, blsSigs)
+		if err == nil {
+			return nil
+		}
+		log.Warnf("failed to seal block %d with bls signatures: %s", blkNum, err)
+	}
+
 	if !forEmpty {
 		block.Block.Header.Bookkeepers = bookkeepers
 		block.Block.Header.SigData = sigData
@@ -631,6 +808,31 @@ func (pool *BlockPool) addSignaturesToBlockLocked(block *Block, forEmpty bool) e
 	return nil
 }
 
+func (pool *BlockPool) sealBlsHeaderLocked(header *types.Header, blsSigs map[uint32][]byte) error {
+	peers := pool.server.config.Peers
+	hash := header.Hash()
+	validSigs := make(map[uint32][]byte)
+	for _, p := range peers {
+		sig, present := blsSigs[p.Index]
+		if !present {
+			continue
+		}
+		key, err := hex.DecodeString(p.BlsKey)
+		if err != nil {
+			return fmt.Errorf("invalid bls key of peer %d: %s", p.Index, err)
+		}
+		if err := bls.Verify(key, hash[:], sig); err != nil {
+			log.Errorf("invalid bls signature from peer %d for block %d: %s", p.Index, header.Height, err)
+			continue
+		}
+		validSigs[p.Index] = sig
+	}
+	if m := len(peers) - (len(peers)-1)/3; len(validSigs) < m {
+		return fmt.Errorf("%d valid bls signatures less than %d", len(validSigs), m)
+	}
+	return vconfig.SealBlsHeader(header, peers, validSigs)
+}
+
 func (pool *BlockPool) setBlockSealed(block *Block, forEmpty bool, sigdata bool) error {
 	pool.lock.Lock()
 	defer pool.lock.Unlock()
@@ -0,0 +1,264 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+)
+
+func constructEquivocationPool(t *testing.T, n int) (*BlockPool, map[uint32]*account.Account) {
+	server := &Server{clock: SystemClock}
+	server.peerPool = NewPeerPool(n, server)
+	server.evidencePool = newEvidencePool(server)
+	accts := make(map[uint32]*account.Account)
+	for i := uint32(1); i <= uint32(n); i++ {
+		acct := account.NewAccount("")
+		if err := server.peerPool.addPeer(&vconfig.PeerConfig{Index: i, ID: vconfig.PubkeyID(acct.PublicKey)}); err != nil {
+			t.Fatalf("addPeer failed: %v", err)
+		}
+		accts[i] = acct
+	}
+	pool := &BlockPool{
+		server:          server,
+		candidateBlocks: make(map[uint32]*CandidateInfo),
+	}
+	return pool, accts
+}
+
+func signTestHeader(t *testing.T, acct *account.Account, header *types.Header) {
+	hash := header.Hash()
+	sig, err := signature.Sign(acct, hash[:])
+	if err != nil {
+		t.Fatalf("sign header failed: %v", err)
+	}
+	header.SigData = [][]byte{sig}
+}
+
+func constructTestProposal(t *testing.T, acct *account.Account, proposer, blkNum uint32, nonce uint64) *blockProposalMsg {
+	info := &vconfig.VbftBlockInfo{
+		Proposer: proposer,
+	}
+	payload, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("marshal block info failed: %v", err)
+	}
+	blk := &types.Header{
+		Height:           blkNum,
+		TransactionsRoot: common.Uint256{1},
+		ConsensusData:    nonce,
+		ConsensusPayload: payload,
+	}
+	empty := &types.Header{
+		Height:           blkNum,
+		ConsensusData:    nonce + 1,
+		ConsensusPayload: payload,
+	}
+	signTestHeader(t, acct, blk)
+	signTestHeader(t, acct, empty)
+	return &blockProposalMsg{
+		Block: &Block{
+			Block:      &types.Block{Header: blk},
+			EmptyBlock: &types.Block{Header: empty},
+			Info:       info,
+		},
+	}
+}
+
+func constructTestEndorse(t *testing.T, acct *account.Account, endorser uint32, proposal *blockProposalMsg, forEmpty bool) *blockEndorseMsg {
+	hash := proposal.Block.Block.Hash()
+	if forEmpty {
+		hash = proposal.Block.EmptyBlock.Hash()
+	}
+	sig, err := signature.Sign(acct, hash[:])
+	if err != nil {
+		t.Fatalf("sign endorsement failed: %v", err)
+	}
+	return &blockEndorseMsg{
+		Endorser:          endorser,
+		EndorsedProposer:  proposal.Block.getProposer(),
+		BlockNum:          proposal.GetBlockNum(),
+		EndorsedBlockHash: hash,
+		EndorseForEmpty:   forEmpty,
+		EndorserSig:       sig,
+	}
+}
+
+func constructTestCommit(t *testing.T, acct *account.Account, committer uint32, proposal *blockProposalMsg) *blockCommitMsg {
+	hash := proposal.Block.Block.Hash()
+	sig, err := signature.Sign(acct, hash[:])
+	if err != nil {
+		t.Fatalf("sign commitment failed: %v", err)
+	}
+	return &blockCommitMsg{
+		Committer:       committer,
+		BlockProposer:   proposal.Block.getProposer(),
+		BlockNum:        proposal.GetBlockNum(),
+		CommitBlockHash: hash,
+		CommitterSig:    sig,
+	}
+}
+
+func evidenceOf(pool *BlockPool, acct *account.Account) *pendingEvidence {
+	return pool.server.evidencePool.pending[vconfig.PubkeyID(acct.PublicKey)]
+}
+
+func TestEquivocationTwoHeaders(t *testing.T) {
+	pool, accts := constructEquivocationPool(t, 4)
+
+	// block and empty block of one proposal
+	p1 := constructTestProposal(t, accts[1], 1, 10, 1)
+	pool.newBlockProposal(p1)
+	if evidenceOf(pool, accts[1]) != nil {
+		t.Fatalf("proposal with its empty block reported")
+	}
+
+	// endorsing the block and then the empty block
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[3], 3, p1, false))
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[3], 3, p1, true))
+	if evidenceOf(pool, accts[3]) != nil {
+		t.Fatalf("endorsement of block and empty block reported")
+	}
+
+	// second non-empty proposal of the same proposer
+	p2 := constructTestProposal(t, accts[1], 1, 10, 3)
+	if err := pool.newBlockProposal(p2); err != errDupProposal {
+		t.Fatalf("dup proposal not detected: %v", err)
+	}
+	evidence := evidenceOf(pool, accts[1])
+	if evidence == nil {
+		t.Fatalf("double proposal not reported")
+	}
+	if len(evidence.param.Headers) != 2 || len(evidence.param.Sigs) != 2 {
+		t.Fatalf("evidence with %d headers, %d sigs", len(evidence.param.Headers), len(evidence.param.Sigs))
+	}
+	if evidence.param.Kind != node_manager.ProposalSig {
+		t.Fatalf("double proposal reported with sig kind %d", evidence.param.Kind)
+	}
+	hashes := make(map[common.Uint256]bool)
+	for i, raw := range evidence.param.Headers {
+		header, err := types.HeaderFromRawBytes(raw)
+		if err != nil {
+			t.Fatalf("evidence header deserialize failed: %v", err)
+		}
+		hash := header.Hash()
+		if err := signature.Verify(accts[1].PublicKey, hash[:], evidence.param.Sigs[i]); err != nil {
+			t.Fatalf("evidence sig verify failed: %v", err)
+		}
+		hashes[hash] = true
+	}
+	if !hashes[p1.Block.Block.Hash()] || !hashes[p2.Block.Block.Hash()] {
+		t.Fatalf("evidence headers are not the conflicting blocks")
+	}
+}
+
+func TestEquivocationCrossProposer(t *testing.T) {
+	pool, accts := constructEquivocationPool(t, 4)
+
+	p1 := constructTestProposal(t, accts[1], 1, 10, 1)
+	p2 := constructTestProposal(t, accts[2], 2, 10, 3)
+	pool.newBlockProposal(p1)
+	pool.newBlockProposal(p2)
+
+	// proposer endorsing another proposal
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[2], 2, p1, false))
+	if evidenceOf(pool, accts[2]) != nil {
+		t.Fatalf("proposer endorsing other proposal reported")
+	}
+
+	// block of one proposer and empty block of another
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[4], 4, p1, false))
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[4], 4, p2, true))
+	if evidenceOf(pool, accts[4]) != nil {
+		t.Fatalf("endorsement of block and empty block reported")
+	}
+
+	// blocks of two proposers endorsed
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[3], 3, p1, false))
+	if evidenceOf(pool, accts[3]) != nil {
+		t.Fatalf("single endorsement reported")
+	}
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[3], 3, p2, false))
+	evidence := evidenceOf(pool, accts[3])
+	if evidence == nil {
+		t.Fatalf("double endorsement not reported")
+	}
+	if len(evidence.param.Headers) != 2 {
+		t.Fatalf("evidence with %d headers", len(evidence.param.Headers))
+	}
+	if evidence.param.Kind != node_manager.EndorseSig {
+		t.Fatalf("double endorsement reported with sig kind %d", evidence.param.Kind)
+	}
+
+	// endorsement carried in commit msg
+	pool, accts = constructEquivocationPool(t, 4)
+	pool.newBlockProposal(p1)
+	pool.newBlockProposal(p2)
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[3], 3, p1, false))
+	endorse := constructTestEndorse(t, accts[3], 3, p2, false)
+	commit := constructTestEndorse(t, accts[4], 4, p2, false)
+	pool.newBlockCommitment(&blockCommitMsg{
+		Committer:       4,
+		BlockProposer:   2,
+		BlockNum:        10,
+		CommitBlockHash: p2.Block.Block.Hash(),
+		EndorsersSig:    map[uint32][]byte{3: endorse.EndorserSig},
+		CommitterSig:    commit.EndorserSig,
+	})
+	if evidenceOf(pool, accts[3]) == nil {
+		t.Fatalf("double endorsement in commit msg not reported")
+	}
+	if evidenceOf(pool, accts[4]) != nil {
+		t.Fatalf("single commitment reported")
+	}
+}
+
+func TestEquivocationEndorseAndCommit(t *testing.T) {
+	pool, accts := constructEquivocationPool(t, 4)
+
+	p1 := constructTestProposal(t, accts[1], 1, 10, 1)
+	p2 := constructTestProposal(t, accts[2], 2, 10, 3)
+	pool.newBlockProposal(p1)
+	pool.newBlockProposal(p2)
+
+	// endorsing one block and committing another
+	pool.newBlockEndorsement(constructTestEndorse(t, accts[3], 3, p1, false))
+	pool.newBlockCommitment(constructTestCommit(t, accts[3], 3, p2))
+	if evidenceOf(pool, accts[3]) != nil {
+		t.Fatalf("endorsement and commitment of different blocks reported")
+	}
+
+	// committing blocks of two proposers
+	pool.newBlockCommitment(constructTestCommit(t, accts[4], 4, p1))
+	pool.newBlockCommitment(constructTestCommit(t, accts[4], 4, p2))
+	evidence := evidenceOf(pool, accts[4])
+	if evidence == nil {
+		t.Fatalf("double commitment not reported")
+	}
+	if evidence.param.Kind != node_manager.CommitSig {
+		t.Fatalf("double commitment reported with sig kind %d", evidence.param.Kind)
+	}
+}
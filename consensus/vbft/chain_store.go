@@ -151,15 +151,29 @@ func (self *ChainStore) AddBlock(block *Block) error {
 				})
 		}
 	}()
-	err := self.submitBlock(blkNum - 1)
-	if err != nil {
+	// execute the new block on top of the pending write set of its parent
+	// while the parent is being persisted
+	parent := self.pendingBlocks[blkNum-1]
+	pipelined := parent != nil && !parent.hasSubmitted
+	submitC := make(chan error, 1)
+	go func() {
+		submitC <- self.submitBlock(blkNum - 1)
+	}()
+	var execResult store.ExecuteResult
+	var execErr error
+	if pipelined {
+		execResult, execErr = self.db.ExecuteBlockOnParent(block.Block, *parent.execResult)
+	}
+	if err := <-submitC; err != nil {
 		log.Errorf("chainstore blkNum:%d, SubmitBlock: %s", blkNum-1, err)
 		return fmt.Errorf("chainstore AddBlock submitBlock: %s", err)
 	}
-	execResult, err := self.db.ExecuteBlock(block.Block)
-	if err != nil {
-		log.Errorf("chainstore AddBlock GetBlockExecResult: %s", err)
-		return fmt.Errorf("chainstore AddBlock GetBlockExecResult: %s", err)
+	if !pipelined {
+		execResult, execErr = self.db.ExecuteBlock(block.Block)
+	}
+	if execErr != nil {
+		log.Errorf("chainstore AddBlock GetBlockExecResult: %s", execErr)
+		return fmt.Errorf("chainstore AddBlock GetBlockExecResult: %s", execErr)
 	}
 	self.pendingBlocks[blkNum] = &PendingBlock{block: block, execResult: &execResult, hasSubmitted: false}
 	shouldTell = true
@@ -20,6 +20,7 @@ package vbft
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"testing"
 
@@ -32,7 +33,7 @@ import (
 )
 
 func newChainStore() *ChainStore {
-	log.Init(log.PATH, log.Stdout)
+	log.InitLog(log.InfoLog, log.Stdout)
 	var err error
 	acct := account.NewAccount("SHA256withECDSA")
 	if acct == nil {
@@ -40,7 +41,12 @@ func newChainStore() *ChainStore {
 		os.Exit(1)
 	}
 
-	db, err := ledger.NewLedger(config.DEFAULT_DATA_DIR)
+	dir, err := ioutil.TempDir("", "vbft")
+	if err != nil {
+		log.Fatalf("TempDir error %s", err)
+		os.Exit(1)
+	}
+	db, err := ledger.NewLedger(dir)
 	if err != nil {
 		log.Fatalf("NewLedger error %s", err)
 		os.Exit(1)
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vconfig
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/core/signature/bls"
+	"github.com/polynetwork/poly/core/types"
+)
+
+// BlsSeal returns if blocks of the chain config are sealed with bls aggregate signatures,
+// which requires every peer to have registered a bls key in node_manager
+func (cc *ChainConfig) BlsSeal() bool {
+	if len(cc.Peers) == 0 {
+		return false
+	}
+	for _, p := range cc.Peers {
+		if p.BlsKey == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// IsBlsSealed returns if the header carries a bls aggregate signature. A sealed bls
+// header has no bookkeepers and SigData is [aggregate signature, signer bitmap], bit i
+// of the bitmap (lsb first) standing for the i-th peer of the chain config.
+func IsBlsSealed(header *types.Header) bool {
+	return header.Version == types.BLS_HEADER_VERSION && len(header.Bookkeepers) == 0 && len(header.SigData) == 2
+}
+
+// SealBlsHeader aggregates the bls signatures of peers, indexed by peer index, into header
+func SealBlsHeader(header *types.Header, peers []*PeerConfig, sigs map[uint32][]byte) error {
+	if header.Version != types.BLS_HEADER_VERSION {
+		return fmt.Errorf("header version %d is not bls header", header.Version)
+	}
+	bitmap := make([]byte, (len(peers)+7)/8)
+	aggSigs := make([][]byte, 0)
+	for i, p := range peers {
+		sig, present := sigs[p.Index]
+		if !present {
+			continue
+		}
+		bitmap[i/8] |= 1 << uint(i%8)
+		aggSigs = append(aggSigs, sig)
+	}
+	aggSig, err := bls.AggregateSignatures(aggSigs)
+	if err != nil {
+		return fmt.Errorf("aggregate signatures: %s", err)
+	}
+	header.Bookkeepers = nil
+	header.SigData = [][]byte{aggSig, bitmap}
+	return nil
+}
+
+// VerifyBlsHeader checks the aggregate signature of header is signed by enough peers
+func VerifyBlsHeader(header *types.Header, peers []*PeerConfig) error {
+	if !IsBlsSealed(header) {
+		return fmt.Errorf("header is not bls sealed")
+	}
+	bitmap := header.SigData[1]
+	if len(bitmap) != (len(peers)+7)/8 {
+		return fmt.Errorf("invalid signer bitmap length %d for %d peers", len(bitmap), len(peers))
+	}
+	keys := make([][]byte, 0)
+	for i, p := range peers {
+		if bitmap[i/8]&(1<<uint(i%8)) == 0 {
+			continue
+		}
+		key, err := hex.DecodeString(p.BlsKey)
+		if err != nil {
+			return fmt.Errorf("invalid bls key of peer %d: %s", p.Index, err)
+		}
+		keys = append(keys, key)
+	}
+	m := len(peers) - (len(peers)-1)/3
+	if len(keys) < m {
+		return fmt.Errorf("header signed by %d peers less than %d", len(keys), m)
+	}
+	hash := header.Hash()
+	if err := bls.VerifyAggregate(keys, hash[:], header.SigData[0]); err != nil {
+		return fmt.Errorf("verify aggregate signature: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vconfig
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/polynetwork/poly/core/signature/bls"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlsSeal(t *testing.T) {
+	peers := make([]*PeerConfig, 0)
+	keys := make([]*bls.PrivateKey, 0)
+	for i := 0; i < 4; i++ {
+		sk, err := bls.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		keys = append(keys, sk)
+		peers = append(peers, &PeerConfig{Index: uint32(i + 1), BlsKey: hex.EncodeToString(sk.PublicKey())})
+	}
+	cfg := &ChainConfig{Peers: peers}
+	assert.True(t, cfg.BlsSeal())
+
+	header := &types.Header{Version: types.BLS_HEADER_VERSION, Height: 10}
+	hash := header.Hash()
+	sigs := make(map[uint32][]byte)
+	for i := 0; i < 2; i++ {
+		sigs[peers[i].Index] = keys[i].Sign(hash[:])
+	}
+	assert.NoError(t, SealBlsHeader(header, peers, sigs))
+	assert.True(t, IsBlsSealed(header))
+	assert.Error(t, VerifyBlsHeader(header, peers))
+
+	sigs[peers[3].Index] = keys[3].Sign(hash[:])
+	assert.NoError(t, SealBlsHeader(header, peers, sigs))
+	assert.Equal(t, []byte{0x0b}, header.SigData[1])
+	assert.NoError(t, VerifyBlsHeader(header, peers))
+
+	// bitmap claiming a signer which did not sign
+	header.SigData[1] = []byte{0x0f}
+	assert.Error(t, VerifyBlsHeader(header, peers))
+
+	peers[2].BlsKey = ""
+	assert.False(t, cfg.BlsSeal())
+}
@@ -35,8 +35,9 @@ var (
 )
 
 type PeerConfig struct {
-	Index uint32 `json:"index"`
-	ID    string `json:"id"`
+	Index  uint32 `json:"index"`
+	ID     string `json:"id"`
+	BlsKey string `json:"bls_key,omitempty"`
 }
 
 type ChainConfig struct {
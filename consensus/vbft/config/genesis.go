@@ -124,8 +124,9 @@ func GenesisChainConfig(conf *config.VBFTConfig, peers []*config.VBFTPeerInfo, h
 	for i := 0; i < int(k); i++ {
 		nodeId := peers[i].PeerPubkey
 		chainPeers[peers[i].Index] = &PeerConfig{
-			Index: peers[i].Index,
-			ID:    nodeId,
+			Index:  peers[i].Index,
+			ID:     nodeId,
+			BlsKey: peers[i].BlsKey,
 		}
 		for j := uint64(0); j < peerRanks[i]; j++ {
 			posTable = append(posTable, peers[i].Index)
@@ -0,0 +1,148 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/polynetwork/poly/consensus/vbft"
+)
+
+// SimClock is a simulated clock shared by all nodes of a devnet. Time only moves
+// when the test advances it, timers due are fired in deadline order.
+type SimClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	seq    uint64
+	timers timerHeap
+}
+
+type simTimer struct {
+	clock    *SimClock
+	deadline time.Time
+	seq      uint64
+	f        func()
+	index    int // index in timer heap, -1 if not scheduled
+}
+
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+func (self *SimClock) Now() time.Time {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return self.now
+}
+
+func (self *SimClock) AfterFunc(d time.Duration, f func()) vbft.Timer {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	t := &simTimer{clock: self, f: f, index: -1}
+	self.scheduleLocked(t, d)
+	return t
+}
+
+func (self *SimClock) scheduleLocked(t *simTimer, d time.Duration) {
+	self.seq++
+	t.deadline = self.now.Add(d)
+	t.seq = self.seq
+	heap.Push(&self.timers, t)
+}
+
+// Advance moves the clock forward by d, firing the timers due on the way. Timers
+// are fired without the clock lock held, so they can start or reset timers.
+func (self *SimClock) Advance(d time.Duration) {
+	self.lock.Lock()
+	target := self.now.Add(d)
+	for len(self.timers) > 0 && !self.timers[0].deadline.After(target) {
+		t := heap.Pop(&self.timers).(*simTimer)
+		if t.deadline.After(self.now) {
+			self.now = t.deadline
+		}
+		self.lock.Unlock()
+		t.f()
+		self.lock.Lock()
+	}
+	self.now = target
+	self.lock.Unlock()
+}
+
+// Pending returns the number of timers not fired yet
+func (self *SimClock) Pending() int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	return len(self.timers)
+}
+
+func (self *simTimer) Stop() bool {
+	self.clock.lock.Lock()
+	defer self.clock.lock.Unlock()
+	if self.index < 0 {
+		return false
+	}
+	heap.Remove(&self.clock.timers, self.index)
+	return true
+}
+
+func (self *simTimer) Reset(d time.Duration) bool {
+	self.clock.lock.Lock()
+	defer self.clock.lock.Unlock()
+	active := self.index >= 0
+	if active {
+		heap.Remove(&self.clock.timers, self.index)
+	}
+	self.clock.scheduleLocked(self, d)
+	return active
+}
+
+type timerHeap []*simTimer
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	if h[i].deadline.Equal(h[j].deadline) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].deadline.Before(h[j].deadline)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*simTimer)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*h = old[:n-1]
+	return t
+}
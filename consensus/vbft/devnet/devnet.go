@@ -0,0 +1,410 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package devnet runs a network of VBFT servers in one process for tests. Nodes keep
+// their ledgers in memory, talk over an in-memory p2p network, and share a simulated
+// clock, so that tests can drive consensus rounds, governance epochs and faults like
+// partitions, delays and crashed nodes without standing up real nodes.
+//
+// The genesis config is process wide, only one devnet may run at a time. Only the
+// governance native contracts are registered, header sync and cross chain transactions
+// are not executed by devnet nodes.
+package devnet
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ontio/ontology-crypto/ec"
+	"github.com/ontio/ontology-crypto/keypair"
+	s "github.com/ontio/ontology-crypto/signature"
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/constants"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/consensus/vbft"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/neo3_state_manager"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	netActor "github.com/polynetwork/poly/p2pserver/actor/server"
+	msgpack "github.com/polynetwork/poly/p2pserver/message/msg_pack"
+	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+const (
+	DEFAULT_NODES                 = 4
+	DEFAULT_BLOCK_MSG_DELAY       = 10000 // ms, minimum accepted by node_manager is 5000
+	DEFAULT_HASH_MSG_DELAY        = 10000 // ms
+	DEFAULT_HANDSHAKE_TIMEOUT     = 10    // s
+	DEFAULT_MAX_BLOCK_CHANGE_VIEW = 60000
+
+	STEP  = 100 * time.Millisecond // simulated time advanced in one step
+	YIELD = 2 * time.Millisecond   // real time nodes are given to process each step
+)
+
+func init() {
+	native.Contracts[utils.SideChainManagerContractAddress] = side_chain_manager.RegisterSideChainManagerContract
+	native.Contracts[utils.NodeManagerContractAddress] = node_manager.RegisterNodeManagerContract
+	native.Contracts[utils.RelayerManagerContractAddress] = relayer_manager.RegisterRelayerManagerContract
+	native.Contracts[utils.Neo3StateManagerContractAddress] = neo3_state_manager.RegisterStateValidatorManagerContract
+}
+
+// devnetSeq makes actor names of devnets in one process unique
+var devnetSeq uint32
+
+// Config of a devnet
+type Config struct {
+	Nodes              int    // number of consensus nodes
+	Seed               int64  // seed of node keys, same seed gives same keys and genesis block
+	MaxBlockChangeView uint32 // blocks between governance epoch transitions
+}
+
+// Node is a consensus node of devnet
+type Node struct {
+	Index   uint32 // peer index in vbft config, starts from 1
+	Account *account.Account
+	Ledger  *ledger.Ledger
+	P2P     *MemP2P
+	Server  *vbft.Server
+
+	name    string
+	pool    *txPool
+	poolPid *actor.PID
+	p2pPid  *actor.PID
+	quitC   chan struct{}
+	crashed bool
+}
+
+// Devnet is a network of in-process vbft nodes
+type Devnet struct {
+	Clock   *SimClock
+	Network *Network
+	Nodes   []*Node
+	Genesis *types.Block
+
+	prevGenesis *config.GenesisConfig
+	started     bool
+}
+
+// New builds a devnet of nodes sharing a genesis block. The nodes are started by Start.
+func New(cfg *Config) (*Devnet, error) {
+	n := cfg.Nodes
+	if n == 0 {
+		n = DEFAULT_NODES
+	}
+	maxBlockChangeView := cfg.MaxBlockChangeView
+	if maxBlockChangeView == 0 {
+		maxBlockChangeView = DEFAULT_MAX_BLOCK_CHANGE_VIEW
+	}
+	seq := atomic.AddUint32(&devnetSeq, 1)
+
+	self := &Devnet{
+		Clock: NewSimClock(time.Unix(int64(constants.GENESIS_BLOCK_TIMESTAMP), 0).Add(time.Hour)),
+	}
+	self.Network = NewNetwork(self.Clock)
+
+	peers := make([]*config.VBFTPeerInfo, 0, n)
+	bookkeepers := make([]keypair.PublicKey, 0, n)
+	for i := 1; i <= n; i++ {
+		acc := newAccount(cfg.Seed, uint32(i))
+		self.Nodes = append(self.Nodes, &Node{
+			Index:   uint32(i),
+			Account: acc,
+			name:    fmt.Sprintf("devnet%d_node%d", seq, i),
+		})
+		peers = append(peers, &config.VBFTPeerInfo{
+			Index:      uint32(i),
+			PeerPubkey: vconfig.PubkeyID(acc.PublicKey),
+			Address:    acc.Address.ToBase58(),
+		})
+		bookkeepers = append(bookkeepers, acc.PublicKey)
+	}
+
+	genesisConfig := &config.GenesisConfig{
+		ConsensusType: config.CONSENSUS_TYPE_VBFT,
+		VBFT: &config.VBFTConfig{
+			BlockMsgDelay:        DEFAULT_BLOCK_MSG_DELAY,
+			HashMsgDelay:         DEFAULT_HASH_MSG_DELAY,
+			PeerHandshakeTimeout: DEFAULT_HANDSHAKE_TIMEOUT,
+			MaxBlockChangeView:   maxBlockChangeView,
+			VrfValue:             config.PolarisConfig.VBFT.VrfValue,
+			VrfProof:             config.PolarisConfig.VBFT.VrfProof,
+			Peers:                peers,
+		},
+		DBFT: &config.DBFTConfig{},
+		SOLO: &config.SOLOConfig{},
+	}
+	self.prevGenesis = config.DefConfig.Genesis
+	config.DefConfig.Genesis = genesisConfig
+
+	genesisBlock, err := genesis.BuildGenesisBlock(bookkeepers, genesisConfig)
+	if err != nil {
+		self.restore()
+		return nil, fmt.Errorf("build genesis block: %s", err)
+	}
+	self.Genesis = genesisBlock
+
+	for _, node := range self.Nodes {
+		if err := self.initNode(node, bookkeepers); err != nil {
+			self.Stop()
+			return nil, fmt.Errorf("init node %d: %s", node.Index, err)
+		}
+	}
+	return self, nil
+}
+
+func (self *Devnet) initNode(node *Node, bookkeepers []keypair.PublicKey) error {
+	lgr, err := ledger.NewMemLedger()
+	if err != nil {
+		return err
+	}
+	if err := lgr.Init(bookkeepers, self.Genesis); err != nil {
+		return err
+	}
+	node.Ledger = lgr
+	node.P2P = self.Network.NewP2P(uint64(node.Index))
+	node.quitC = make(chan struct{})
+
+	node.pool = &txPool{ledger: lgr}
+	node.poolPid, err = actor.SpawnNamed(actor.FromProducer(func() actor.Actor { return node.pool }),
+		node.name+"_txpool")
+	if err != nil {
+		return err
+	}
+	p2pActor := &p2pActor{p2p: node.P2P}
+	node.p2pPid, err = actor.SpawnNamed(actor.FromProducer(func() actor.Actor { return p2pActor }),
+		node.name+"_p2p")
+	return err
+}
+
+// Start starts vbft servers of all nodes
+func (self *Devnet) Start() error {
+	for _, node := range self.Nodes {
+		server, err := vbft.NewVbftServerWithConfig(node.Account, node.poolPid, node.p2pPid, &vbft.ServerConfig{
+			Name:   node.name + "_consensus",
+			Ledger: node.Ledger,
+			Clock:  self.Clock,
+		})
+		if err != nil {
+			return fmt.Errorf("new vbft server of node %d: %s", node.Index, err)
+		}
+		node.Server = server
+		go node.route()
+	}
+	self.started = true
+	for _, node := range self.Nodes {
+		if err := node.Server.Start(); err != nil {
+			return fmt.Errorf("start vbft server of node %d: %s", node.Index, err)
+		}
+	}
+	return nil
+}
+
+// Stop stops all nodes and restores the genesis config
+func (self *Devnet) Stop() {
+	for _, node := range self.Nodes {
+		if node.Server != nil && !node.crashed {
+			node.Server.Halt()
+			close(node.quitC)
+		}
+		if node.poolPid != nil {
+			node.poolPid.Stop()
+		}
+		if node.p2pPid != nil {
+			node.p2pPid.Stop()
+		}
+	}
+	self.restore()
+}
+
+func (self *Devnet) restore() {
+	if self.prevGenesis != nil {
+		config.DefConfig.Genesis = self.prevGenesis
+		self.prevGenesis = nil
+	}
+}
+
+// Run advances the simulated clock by d, step by step, giving nodes real time to
+// process the events of every step
+func (self *Devnet) Run(d time.Duration) {
+	for elapsed := time.Duration(0); elapsed < d; elapsed += STEP {
+		self.Clock.Advance(STEP)
+		time.Sleep(YIELD)
+	}
+}
+
+// WaitForHeight runs the devnet until all nodes not crashed have saved block of height,
+// or fails after timeout of simulated time
+func (self *Devnet) WaitForHeight(height uint32, timeout time.Duration) error {
+	for elapsed := time.Duration(0); ; elapsed += STEP {
+		reached := true
+		for _, node := range self.Nodes {
+			if !node.crashed && node.Height() < height {
+				reached = false
+				break
+			}
+		}
+		if reached {
+			return nil
+		}
+		if elapsed >= timeout {
+			return fmt.Errorf("height %d not reached in %s, heights: %v", height, timeout, self.Heights())
+		}
+		self.Clock.Advance(STEP)
+		time.Sleep(YIELD)
+	}
+}
+
+// Heights returns the ledger heights of nodes
+func (self *Devnet) Heights() []uint32 {
+	heights := make([]uint32, 0, len(self.Nodes))
+	for _, node := range self.Nodes {
+		heights = append(heights, node.Height())
+	}
+	return heights
+}
+
+// SubmitTx gossips tx to the transaction pools of all nodes
+func (self *Devnet) SubmitTx(tx *types.Transaction) {
+	for _, node := range self.Nodes {
+		node.pool.append(tx)
+	}
+}
+
+// Partition splits nodes into groups by their positions in Nodes, nodes not in
+// any group are isolated
+func (self *Devnet) Partition(groups ...[]int) {
+	idGroups := make([][]uint64, 0, len(groups))
+	for _, group := range groups {
+		ids := make([]uint64, 0, len(group))
+		for _, i := range group {
+			ids = append(ids, self.Nodes[i].P2P.GetID())
+		}
+		idGroups = append(idGroups, ids)
+	}
+	self.Network.Partition(idGroups...)
+}
+
+// Heal removes the partition
+func (self *Devnet) Heal() {
+	self.Network.Heal()
+}
+
+// SetDelay delays messages from node to node, by their positions in Nodes
+func (self *Devnet) SetDelay(from, to int, d time.Duration) {
+	self.Network.SetDelay(self.Nodes[from].P2P.GetID(), self.Nodes[to].P2P.GetID(), d)
+}
+
+// Crash stops the node at position i, it is cut off from network and its ledger is kept
+func (self *Devnet) Crash(i int) {
+	node := self.Nodes[i]
+	if node.crashed {
+		return
+	}
+	node.crashed = true
+	self.Network.SetDown(node.P2P.GetID(), true)
+	if node.Server != nil {
+		node.Server.Halt()
+		close(node.quitC)
+	}
+}
+
+// Height returns the height of ledger of node
+func (self *Node) Height() uint32 {
+	return self.Ledger.GetCurrentBlockHeight()
+}
+
+// Crashed returns whether the node is crashed
+func (self *Node) Crashed() bool {
+	return self.crashed
+}
+
+// route dispatches consensus messages received from network to vbft server, as
+// the p2p message router of a normal node does
+func (self *Node) route() {
+	consC := self.P2P.GetMsgChan(true)
+	for {
+		select {
+		case data := <-consC:
+			cons, ok := data.Payload.(*p2pmsg.Consensus)
+			if !ok {
+				continue
+			}
+			if err := cons.Cons.Verify(); err != nil {
+				log.Warnf("devnet: node %d receives invalid consensus msg: %s", self.Index, err)
+				continue
+			}
+			cons.Cons.PeerId = data.Id
+			self.Server.GetPID().Tell(&cons.Cons)
+		case <-self.quitC:
+			return
+		}
+	}
+}
+
+// p2pActor serves the p2p requests of vbft server on the in-memory network
+type p2pActor struct {
+	p2p *MemP2P
+}
+
+func (self *p2pActor) Receive(context actor.Context) {
+	switch msg := context.Message().(type) {
+	case *p2pmsg.ConsensusPayload:
+		self.p2p.Xmit(msgpack.NewConsensus(msg), true)
+	case *netActor.TransmitConsensusMsgReq:
+		if p := self.p2p.GetPeer(msg.Target); p != nil {
+			if err := self.p2p.Send(p, msg.Msg, true); err != nil {
+				log.Warnf("devnet: transmit consensus msg to %d: %s", msg.Target, err)
+			}
+		}
+	}
+}
+
+// newAccount derives the account of node index from seed
+func newAccount(seed int64, index uint32) *account.Account {
+	curve := elliptic.P256()
+	h := sha256.Sum256([]byte(fmt.Sprintf("poly devnet %d %d", seed, index)))
+	d := new(big.Int).SetBytes(h[:])
+	d.Mod(d, new(big.Int).Sub(curve.Params().N, big.NewInt(1)))
+	d.Add(d, big.NewInt(1))
+
+	priv := &ecdsa.PrivateKey{D: d}
+	priv.PublicKey.Curve = curve
+	priv.PublicKey.X, priv.PublicKey.Y = curve.ScalarBaseMult(d.Bytes())
+	pri := &ec.PrivateKey{Algorithm: ec.ECDSA, PrivateKey: priv}
+	pub := &ec.PublicKey{Algorithm: ec.ECDSA, PublicKey: &priv.PublicKey}
+	return &account.Account{
+		PrivateKey: pri,
+		PublicKey:  pub,
+		Address:    types.AddressFromPubKey(pub),
+		SigScheme:  s.SHA256withECDSA,
+	}
+}
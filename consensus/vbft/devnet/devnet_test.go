@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"testing"
+	"time"
+)
+
+func startDevnet(t *testing.T, cfg *Config) *Devnet {
+	net, err := New(cfg)
+	if err != nil {
+		t.Fatalf("new devnet: %s", err)
+	}
+	t.Cleanup(net.Stop)
+	if err := net.Start(); err != nil {
+		t.Fatalf("start devnet: %s", err)
+	}
+	return net
+}
+
+func TestDevnetProducesBlocks(t *testing.T) {
+	net := startDevnet(t, &Config{Nodes: 4})
+	if err := net.WaitForHeight(3, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	hash := net.Nodes[0].Ledger.GetBlockHash(3)
+	for _, node := range net.Nodes[1:] {
+		if node.Ledger.GetBlockHash(3) != hash {
+			t.Fatalf("node %d diverged at height 3", node.Index)
+		}
+	}
+}
+
+func TestDevnetCrashedNode(t *testing.T) {
+	net := startDevnet(t, &Config{Nodes: 4})
+	if err := net.WaitForHeight(1, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	net.Crash(3)
+	crashedHeight := net.Nodes[3].Height()
+	if err := net.WaitForHeight(crashedHeight+2, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if net.Nodes[3].Height() != crashedHeight {
+		t.Fatalf("crashed node moved from %d to %d", crashedHeight, net.Nodes[3].Height())
+	}
+}
+
+func TestDevnetPartition(t *testing.T) {
+	net := startDevnet(t, &Config{Nodes: 4})
+	if err := net.WaitForHeight(1, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	net.Partition([]int{0, 1}, []int{2, 3})
+	heights := net.Heights()
+	net.Run(2 * time.Minute)
+	for i, h := range net.Heights() {
+		if h > heights[i]+1 {
+			t.Fatalf("node %d progressed without quorum: %d -> %d", i, heights[i], h)
+		}
+	}
+	net.Heal()
+	if err := net.WaitForHeight(maxHeight(net.Heights())+2, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevnetDelayedLink(t *testing.T) {
+	net := startDevnet(t, &Config{Nodes: 4})
+	for i := range net.Nodes {
+		net.SetDelay(0, i, 500*time.Millisecond)
+	}
+	if err := net.WaitForHeight(3, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func maxHeight(heights []uint32) uint32 {
+	var max uint32
+	for _, h := range heights {
+		if h > max {
+			max = h
+		}
+	}
+	return max
+}
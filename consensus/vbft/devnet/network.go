@@ -0,0 +1,392 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/message/types"
+	p2p "github.com/polynetwork/poly/p2pserver/net/protocol"
+	"github.com/polynetwork/poly/p2pserver/peer"
+)
+
+const (
+	BASE_PORT    = 20338 // port of first node, only to make up peer addresses
+	CAP_MSG_CHAN = 1024
+)
+
+type link struct {
+	from uint64
+	to   uint64
+}
+
+// Network connects the in-memory p2p endpoints of a devnet. Every message is
+// serialized as on the wire, and dropped or delayed according to the injected faults.
+type Network struct {
+	lock      sync.RWMutex
+	clock     *SimClock
+	nodes     map[uint64]*MemP2P
+	partition map[uint64]int // partition group of node, nodes of different groups are unreachable
+	delays    map[link]time.Duration
+	down      map[uint64]bool
+}
+
+func NewNetwork(clock *SimClock) *Network {
+	return &Network{
+		clock:     clock,
+		nodes:     make(map[uint64]*MemP2P),
+		partition: make(map[uint64]int),
+		delays:    make(map[link]time.Duration),
+		down:      make(map[uint64]bool),
+	}
+}
+
+// NewP2P creates an endpoint with id and connects it with all endpoints created before
+func (self *Network) NewP2P(id uint64) *MemP2P {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	node := &MemP2P{
+		net:   self,
+		id:    id,
+		addr:  fmt.Sprintf("127.0.0.1:%d", BASE_PORT+len(self.nodes)),
+		np:    &peer.NbrPeers{},
+		bans:  peer.NewBanList(""),
+		peers: peer.NewPeerDB(""),
+		syncC: make(chan *types.MsgPayload, CAP_MSG_CHAN),
+		consC: make(chan *types.MsgPayload, CAP_MSG_CHAN),
+	}
+	node.np.Init()
+	for _, other := range self.nodes {
+		other.np.AddNbrNode(newPeer(node))
+		node.np.AddNbrNode(newPeer(other))
+	}
+	self.nodes[id] = node
+	return node
+}
+
+func newPeer(node *MemP2P) *peer.Peer {
+	p := peer.NewPeer()
+	p.UpdateInfo(time.Now(), common.PROTOCOL_VERSION, 0, 0, 0, node.id, 1, 0, "devnet")
+	p.SyncLink.SetAddr(node.addr)
+	p.ConsLink.SetAddr(node.addr)
+	p.SetSyncState(common.ESTABLISH)
+	p.SetConsState(common.ESTABLISH)
+	return p
+}
+
+// Partition splits the network, nodes can only reach nodes in the same group.
+// Nodes not in any group are put into a group of their own.
+func (self *Network) Partition(groups ...[]uint64) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.partition = make(map[uint64]int)
+	for i, group := range groups {
+		for _, id := range group {
+			self.partition[id] = i + 1
+		}
+	}
+	next := len(groups) + 1
+	for id := range self.nodes {
+		if _, present := self.partition[id]; !present {
+			self.partition[id] = next
+			next++
+		}
+	}
+}
+
+// Heal removes the partition
+func (self *Network) Heal() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.partition = make(map[uint64]int)
+}
+
+// SetDelay delays messages from node to node on the simulated clock, zero removes the delay
+func (self *Network) SetDelay(from, to uint64, d time.Duration) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if d == 0 {
+		delete(self.delays, link{from, to})
+	} else {
+		self.delays[link{from, to}] = d
+	}
+}
+
+// SetDown marks node as crashed, messages from and to it are dropped
+func (self *Network) SetDown(id uint64, down bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.down[id] = down
+}
+
+func (self *Network) reachable(from, to uint64) bool {
+	if self.down[from] || self.down[to] {
+		return false
+	}
+	return self.partition[from] == self.partition[to]
+}
+
+func (self *Network) deliver(from, to uint64, msg types.Message, isConsensus bool) error {
+	self.lock.RLock()
+	dst, present := self.nodes[to]
+	reachable := self.reachable(from, to)
+	delay := self.delays[link{from, to}]
+	src := self.nodes[from]
+	self.lock.RUnlock()
+	if !present {
+		return fmt.Errorf("unknown peer %d", to)
+	}
+	if !reachable {
+		return nil
+	}
+
+	sink := comm.NewZeroCopySink(nil)
+	if err := types.WriteMessage(sink, msg); err != nil {
+		return fmt.Errorf("serialize message %s: %s", msg.CmdType(), err)
+	}
+	m, size, err := types.ReadMessage(bytes.NewReader(sink.Bytes()))
+	if err != nil {
+		return fmt.Errorf("deserialize message %s: %s", msg.CmdType(), err)
+	}
+	payload := &types.MsgPayload{
+		Id:          from,
+		Addr:        src.addr,
+		PayloadSize: size,
+		Payload:     m,
+	}
+	if delay == 0 {
+		dst.receive(payload, isConsensus)
+		return nil
+	}
+	self.clock.AfterFunc(delay, func() {
+		// faults injected during the delay also apply
+		self.lock.RLock()
+		reachable := self.reachable(from, to)
+		self.lock.RUnlock()
+		if reachable {
+			dst.receive(payload, isConsensus)
+		}
+	})
+	return nil
+}
+
+// MemP2P is an in-memory p2p endpoint implementing p2p.P2P
+type MemP2P struct {
+	net    *Network
+	id     uint64
+	addr   string
+	height uint64
+	np     *peer.NbrPeers
+	bans   *peer.BanList
+	peers  *peer.PeerDB
+	syncC  chan *types.MsgPayload
+	consC  chan *types.MsgPayload
+}
+
+var _ p2p.P2P = (*MemP2P)(nil)
+
+func (self *MemP2P) receive(payload *types.MsgPayload, isConsensus bool) {
+	C := self.syncC
+	if isConsensus {
+		C = self.consC
+	}
+	select {
+	case C <- payload:
+	default:
+		log.Warnf("devnet: node %d drops message %s from %d, channel full",
+			self.id, payload.Payload.CmdType(), payload.Id)
+	}
+}
+
+func (self *MemP2P) Start() {}
+
+func (self *MemP2P) Halt() {
+	self.net.SetDown(self.id, true)
+}
+
+func (self *MemP2P) Connect(addr string, isConsensus bool) error {
+	if self.GetPeerFromAddr(addr) == nil {
+		return fmt.Errorf("unknown devnet address %s", addr)
+	}
+	return nil
+}
+
+func (self *MemP2P) GetID() uint64 {
+	return self.id
+}
+
+func (self *MemP2P) GetVersion() uint32 {
+	return common.PROTOCOL_VERSION
+}
+
+func (self *MemP2P) GetSyncPort() uint16 {
+	return 0
+}
+
+func (self *MemP2P) GetConsPort() uint16 {
+	return 0
+}
+
+func (self *MemP2P) GetHttpInfoPort() uint16 {
+	return 0
+}
+
+func (self *MemP2P) GetRelay() bool {
+	return true
+}
+
+func (self *MemP2P) GetHeight() uint64 {
+	return atomic.LoadUint64(&self.height)
+}
+
+func (self *MemP2P) GetTime() int64 {
+	return self.net.clock.Now().UnixNano()
+}
+
+func (self *MemP2P) GetServices() uint64 {
+	return 0
+}
+
+func (self *MemP2P) GetNeighbors() []*peer.Peer {
+	return self.np.GetNeighbors()
+}
+
+func (self *MemP2P) GetNeighborAddrs() []common.PeerAddr {
+	return self.np.GetNeighborAddrs()
+}
+
+func (self *MemP2P) GetConnectionCnt() uint32 {
+	return self.np.GetNbrNodeCnt()
+}
+
+func (self *MemP2P) GetNp() *peer.NbrPeers {
+	return self.np
+}
+
+func (self *MemP2P) GetBanList() *peer.BanList {
+	return self.bans
+}
+
+func (self *MemP2P) GetPeerDB() *peer.PeerDB {
+	return self.peers
+}
+
+func (self *MemP2P) GetNodeRecord() *types.NodeRecord {
+	return nil
+}
+
+func (self *MemP2P) GetPeer(id uint64) *peer.Peer {
+	return self.np.GetPeer(id)
+}
+
+func (self *MemP2P) SetHeight(height uint64) {
+	atomic.StoreUint64(&self.height, height)
+}
+
+func (self *MemP2P) IsPeerEstablished(p *peer.Peer) bool {
+	return p != nil && self.np.NodeEstablished(p.GetID())
+}
+
+func (self *MemP2P) Send(p *peer.Peer, msg types.Message, isConsensus bool) error {
+	if p == nil {
+		return fmt.Errorf("send to nil peer")
+	}
+	return self.net.deliver(self.id, p.GetID(), msg, isConsensus)
+}
+
+func (self *MemP2P) GetMsgChan(isConsensus bool) chan *types.MsgPayload {
+	if isConsensus {
+		return self.consC
+	}
+	return self.syncC
+}
+
+func (self *MemP2P) GetPeerFromAddr(addr string) *peer.Peer {
+	for _, p := range self.np.GetNeighbors() {
+		if p.GetAddr() == addr {
+			return p
+		}
+	}
+	return nil
+}
+
+func (self *MemP2P) AddOutConnectingList(addr string) bool {
+	return false
+}
+
+func (self *MemP2P) GetOutConnRecordLen() int {
+	return 0
+}
+
+func (self *MemP2P) RemoveFromConnectingList(addr string) {}
+
+func (self *MemP2P) RemoveFromOutConnRecord(addr string) {}
+
+func (self *MemP2P) RemoveFromInConnRecord(addr string) {}
+
+func (self *MemP2P) AddPeerSyncAddress(addr string, p *peer.Peer) {}
+
+func (self *MemP2P) AddPeerConsAddress(addr string, p *peer.Peer) {}
+
+func (self *MemP2P) GetOutConnectingListLen() uint {
+	return 0
+}
+
+func (self *MemP2P) RemovePeerSyncAddress(addr string) {}
+
+func (self *MemP2P) RemovePeerConsAddress(addr string) {}
+
+func (self *MemP2P) AddNbrNode(p *peer.Peer) {
+	self.np.AddNbrNode(p)
+}
+
+func (self *MemP2P) DelNbrNode(id uint64) (*peer.Peer, bool) {
+	return self.np.DelNbrNode(id)
+}
+
+func (self *MemP2P) NodeEstablished(id uint64) bool {
+	return self.np.NodeEstablished(id)
+}
+
+func (self *MemP2P) Xmit(msg types.Message, isConsensus bool) {
+	for _, p := range self.np.GetNeighbors() {
+		if err := self.net.deliver(self.id, p.GetID(), msg, isConsensus); err != nil {
+			log.Warnf("devnet: node %d xmit to %d: %s", self.id, p.GetID(), err)
+		}
+	}
+}
+
+func (self *MemP2P) SetOwnAddress(addr string) {}
+
+func (self *MemP2P) IsOwnAddress(addr string) bool {
+	return addr == self.addr
+}
+
+func (self *MemP2P) IsAddrFromConnecting(addr string) bool {
+	return false
+}
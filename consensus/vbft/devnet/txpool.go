@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package devnet
+
+import (
+	"sync"
+
+	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/errors"
+	tc "github.com/polynetwork/poly/txnpool/common"
+)
+
+// txPool is the transaction pool actor of a devnet node. Transactions submitted
+// to the devnet are proposed until they are saved in the ledger of the node.
+type txPool struct {
+	lock   sync.Mutex
+	ledger *ledger.Ledger
+	txs    []*types.Transaction
+}
+
+func (self *txPool) append(tx *types.Transaction) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.txs = append(self.txs, tx)
+}
+
+func (self *txPool) pending() []*tc.TXEntry {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	txs := self.txs[:0]
+	entries := make([]*tc.TXEntry, 0, len(self.txs))
+	for _, tx := range self.txs {
+		if saved, _ := self.ledger.IsContainTransaction(tx.Hash()); saved {
+			continue
+		}
+		txs = append(txs, tx)
+		entries = append(entries, &tc.TXEntry{Tx: tx})
+	}
+	self.txs = txs
+	return entries
+}
+
+func (self *txPool) Receive(context actor.Context) {
+	switch msg := context.Message().(type) {
+	case *tc.GetTxnPoolReq:
+		context.Sender().Request(&tc.GetTxnPoolRsp{TxnPool: self.pending()}, context.Self())
+	case *tc.VerifyBlockReq:
+		rsp := &tc.VerifyBlockRsp{TxnPool: make([]*tc.VerifyTxResult, 0, len(msg.Txs))}
+		for _, tx := range msg.Txs {
+			rsp.TxnPool = append(rsp.TxnPool, &tc.VerifyTxResult{
+				Height:  msg.Height,
+				Tx:      tx,
+				ErrCode: errors.ErrNoError,
+			})
+		}
+		context.Sender().Request(rsp, context.Self())
+	}
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vbft
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+)
+
+type pendingEvidence struct {
+	blockNum uint32
+	param    *node_manager.ReportEquivocationParam
+	txHash   common.Uint256 // hash of latest tx submitting the evidence
+}
+
+// EvidencePool keeps equivocation evidences detected by BlockPool, which are
+// submitted to node_manager within the proposals of this server until sealed.
+type EvidencePool struct {
+	lock    sync.Mutex
+	server  *Server
+	pending map[string]*pendingEvidence // indexed by peer pubkey
+}
+
+func newEvidencePool(server *Server) *EvidencePool {
+	return &EvidencePool{
+		server:  server,
+		pending: make(map[string]*pendingEvidence),
+	}
+}
+
+func (pool *EvidencePool) addEvidence(blkNum uint32, param *node_manager.ReportEquivocationParam) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if _, present := pool.pending[param.PeerPubkey]; present {
+		return
+	}
+	log.Warnf("equivocation of peer %s detected in block %d, %d conflicting headers",
+		param.PeerPubkey, blkNum, len(param.Headers))
+	pool.pending[param.PeerPubkey] = &pendingEvidence{
+		blockNum: blkNum,
+		param:    param,
+	}
+}
+
+// getEvidenceTxs builds transactions reporting pending evidences, signed by the
+// account of this server
+func (pool *EvidencePool) getEvidenceTxs(blkNum uint32) []*types.Transaction {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	txs := make([]*types.Transaction, 0)
+	for peer, e := range pool.pending {
+		tx, err := pool.newEvidenceTx(e.param, blkNum)
+		if err != nil {
+			log.Errorf("failed to build evidence tx of peer %s: %s", peer, err)
+			continue
+		}
+		e.txHash = tx.Hash()
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+func (pool *EvidencePool) newEvidenceTx(param *node_manager.ReportEquivocationParam, blkNum uint32) (*types.Transaction, error) {
+	acc := pool.server.account
+	param.Address = acc.Address
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	contractInvokeParam := &states.ContractInvokeParam{Address: utils.NodeManagerContractAddress,
+		Method: node_manager.REPORT_EQUIVOCATION, Args: sink.Bytes()}
+	invokeCode := new(common.ZeroCopySink)
+	contractInvokeParam.Serialization(invokeCode)
+	tx := genesis.NewInvokeTransaction(invokeCode.Bytes(), blkNum)
+
+	txHash := tx.Hash()
+	sig, err := signature.Sign(acc, txHash[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign tx: %s", err)
+	}
+	tx.Sigs = []types.Sig{{
+		PubKeys: []keypair.PublicKey{acc.PublicKey},
+		M:       1,
+		SigData: [][]byte{sig},
+	}}
+	sink = common.NewZeroCopySink(nil)
+	if err := tx.Serialization(sink); err != nil {
+		return nil, fmt.Errorf("serialize tx: %s", err)
+	}
+	return types.TransactionFromRawBytes(sink.Bytes())
+}
+
+// onBlockSealed drops evidences submitted in the sealed block, and evidences
+// failed to be submitted within the msg history
+func (pool *EvidencePool) onBlockSealed(blkNum uint32) {
+	blk, _ := pool.server.blockPool.getSealedBlock(blkNum)
+	if blk == nil {
+		return
+	}
+	txs := make(map[common.Uint256]bool)
+	for _, tx := range blk.Block.Transactions {
+		txs[tx.Hash()] = true
+	}
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	for peer, e := range pool.pending {
+		if txs[e.txHash] || e.blockNum+pool.server.msgHistoryDuration < blkNum {
+			delete(pool.pending, peer)
+		}
+	}
+}
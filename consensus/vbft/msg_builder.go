@@ -22,14 +22,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
-	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/signature"
 	"github.com/polynetwork/poly/core/types"
 )
@@ -163,7 +161,9 @@ func (self *Server) constructHeartbeatMsg() (*peerHeartbeatMsg, error) {
 			bookkeepers = append(bookkeepers, keypair.SerializePublicKey(endorsePks[i]))
 		}
 	} else {
-		log.Errorf("Invalid signature counts in block %d: %d vs %d", blkNum, len(endorsePks), len(sigData))
+		if !vconfig.IsBlsSealed(block.Block.Header) {
+			log.Errorf("Invalid signature counts in block %d: %d vs %d", blkNum, len(endorsePks), len(sigData))
+		}
 		sigData = make([][]byte, 0)
 	}
 
@@ -192,14 +192,18 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 	}
 	txRoot := common.ComputeMerkleRoot(txHash)
 
-	blockRoot := ledger.DefLedger.GetBlockRootWithPreBlockHashes(blkNum-1, []common.Uint256{lastBlock.Block.Header.PrevBlockHash, prevBlkHash})
+	blockRoot := self.ledger.GetBlockRootWithPreBlockHashes(blkNum-1, []common.Uint256{lastBlock.Block.Header.PrevBlockHash, prevBlkHash})
 	crossStateRoot, err := self.blockPool.getCrossStatesRoot(blkNum - 1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to GetCrossStatesRoot: %s,blkNum:%d", err, (blkNum - 1))
 	}
 
+	version := uint32(types.CURR_HEADER_VERSION)
+	if self.config.BlsSeal() {
+		version = types.BLS_HEADER_VERSION
+	}
 	blkHeader := &types.Header{
-		Version:          types.CURR_HEADER_VERSION,
+		Version:          version,
 		ChainID:          config.GetChainIdByNetId(config.DefConfig.P2PNode.NetworkId),
 		PrevBlockHash:    prevBlkHash,
 		TransactionsRoot: txRoot,
@@ -223,6 +227,9 @@ func (self *Server) constructBlock(blkNum uint32, prevBlkHash common.Uint256, tx
 	}
 	blkHeader.Bookkeepers = []keypair.PublicKey{self.account.PublicKey}
 	blkHeader.SigData = [][]byte{sig}
+	if blkHeader.Version == types.BLS_HEADER_VERSION && self.blsKey != nil {
+		blkHeader.SigData = append(blkHeader.SigData, self.blsKey.Sign(blkHash[:]))
+	}
 
 	return blk, nil
 }
@@ -233,7 +240,7 @@ func (self *Server) constructProposalMsg(blkNum uint32, sysTxs, userTxs []*types
 	if prevBlk == nil {
 		return nil, fmt.Errorf("failed to get prevBlock (%d)", blkNum-1)
 	}
-	blocktimestamp := uint32(time.Now().Unix())
+	blocktimestamp := uint32(self.clock.Now().Unix())
 	if prevBlk.Block.Header.Timestamp >= blocktimestamp {
 		blocktimestamp = prevBlk.Block.Header.Timestamp + 1
 	}
@@ -332,6 +339,7 @@ func (self *Server) constructEndorseMsg(proposal *blockProposalMsg, forEmpty boo
 		EndorseForEmpty:   forEmpty,
 		ProposerSig:       proposerSig,
 		EndorserSig:       endorserSig,
+		EndorserBlsSig:    self.signBls(blkHash),
 	}
 
 	return msg, nil
@@ -363,8 +371,15 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 	}
 
 	endorsersSig := make(map[uint32][]byte)
+	var endorsersBlsSig map[uint32][]byte
 	for _, e := range endorses {
 		endorsersSig[e.Endorser] = e.EndorserSig
+		if len(e.EndorserBlsSig) > 0 {
+			if endorsersBlsSig == nil {
+				endorsersBlsSig = make(map[uint32][]byte)
+			}
+			endorsersBlsSig[e.Endorser] = e.EndorserBlsSig
+		}
 	}
 
 	msg := &blockCommitMsg{
@@ -376,6 +391,8 @@ func (self *Server) constructCommitMsg(proposal *blockProposalMsg, endorses []*b
 		ProposerSig:     proposerSig,
 		EndorsersSig:    endorsersSig,
 		CommitterSig:    committerSig,
+		EndorsersBlsSig: endorsersBlsSig,
+		CommitterBlsSig: self.signBls(blkHash),
 	}
 
 	return msg, nil
@@ -134,6 +134,7 @@ type blockEndorseMsg struct {
 	FaultyProposals   []*FaultyReport `json:"faulty_proposals"`
 	ProposerSig       []byte          `json:"proposer_sig"`
 	EndorserSig       []byte          `json:"endorser_sig"`
+	EndorserBlsSig    []byte          `json:"endorser_bls_sig,omitempty"`
 }
 
 func (msg *blockEndorseMsg) Type() MsgType {
@@ -170,6 +171,8 @@ type blockCommitMsg struct {
 	ProposerSig     []byte            `json:"proposer_sig"`
 	EndorsersSig    map[uint32][]byte `json:"endorsers_sig"`
 	CommitterSig    []byte            `json:"committer_sig"`
+	EndorsersBlsSig map[uint32][]byte `json:"endorsers_bls_sig,omitempty"`
+	CommitterBlsSig []byte            `json:"committer_bls_sig,omitempty"`
 }
 
 func (msg *blockCommitMsg) Type() MsgType {
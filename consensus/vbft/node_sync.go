@@ -21,10 +21,8 @@ package vbft
 import (
 	"fmt"
 	"sync"
-	"time"
 
 	"github.com/polynetwork/poly/common/log"
-	"github.com/polynetwork/poly/core/ledger"
 )
 
 type SyncCheckReq struct {
@@ -180,7 +178,7 @@ func (self *Syncer) run() {
 			for self.nextReqBlkNum <= self.targetBlkNum {
 				// FIXME: compete with ledger syncing
 				var blk *Block
-				if self.nextReqBlkNum <= ledger.DefLedger.GetCurrentBlockHeight() {
+				if self.nextReqBlkNum <= self.server.ledger.GetCurrentBlockHeight() {
 					blk, _ = self.server.chainStore.getBlock(self.nextReqBlkNum)
 				}
 				if blk == nil {
@@ -419,7 +417,8 @@ func (self *PeerSyncer) requestBlock(blkNum uint32) (*Block, error) {
 		Msg:    msg,
 	}
 
-	t := time.NewTimer(makeProposalTimeout * 2)
+	timeoutC := make(chan struct{})
+	t := self.server.clock.AfterFunc(makeProposalTimeout*2, func() { close(timeoutC) })
 	defer t.Stop()
 
 	select {
@@ -435,7 +434,7 @@ func (self *PeerSyncer) requestBlock(blkNum uint32) (*Block, error) {
 			}
 			return pMsg.BlockData, nil
 		}
-	case <-t.C:
+	case <-timeoutC:
 		return nil, fmt.Errorf("timeout fetch block %d from peer %d", blkNum, self.peerIdx)
 	case <-self.server.quitC:
 		return nil, fmt.Errorf("peer syncing %d quit, failed fetching Block %d", self.peerIdx, blkNum)
@@ -454,7 +453,8 @@ func (self *PeerSyncer) requestBlockInfo(startBlkNum uint32) ([]*BlockInfo_, err
 		Msg:    msg,
 	}
 
-	t := time.NewTimer(makeProposalTimeout * 2)
+	timeoutC := make(chan struct{})
+	t := self.server.clock.AfterFunc(makeProposalTimeout*2, func() { close(timeoutC) })
 	defer t.Stop()
 
 	select {
@@ -470,7 +470,7 @@ func (self *PeerSyncer) requestBlockInfo(startBlkNum uint32) ([]*BlockInfo_, err
 			}
 			return pMsg.Blocks, nil
 		}
-	case <-t.C:
+	case <-timeoutC:
 		return nil, fmt.Errorf("timeout fetch blockInfo %d from peer %d", startBlkNum, self.peerIdx)
 	case <-self.server.quitC:
 		return nil, fmt.Errorf("peer syncer %d - %d quit, failed fetching BlockInfo %d",
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/signature"
@@ -449,3 +450,12 @@ func (self *Server) broadcastToAll(data []byte) error {
 	self.p2p.Broadcast(msg)
 	return nil
 }
+
+// signBls signs the block hash with the bls key of this node, returns nil if
+// current chain config does not seal blocks with bls signatures
+func (self *Server) signBls(blkHash common.Uint256) []byte {
+	if self.blsKey == nil || !self.config.BlsSeal() {
+		return nil
+	}
+	return self.blsKey.Sign(blkHash[:])
+}
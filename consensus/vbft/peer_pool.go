@@ -143,7 +143,7 @@ func (pool *PeerPool) peerConnected(peerIdx uint32) error {
 	pool.peers[peerIdx] = &Peer{
 		Index:          peerIdx,
 		PubKey:         pool.peers[peerIdx].PubKey,
-		LastUpdateTime: time.Now(),
+		LastUpdateTime: pool.server.clock.Now(),
 		connected:      true,
 	}
 	if C, present := pool.peerConnectionWaitings[peerIdx]; present {
@@ -157,15 +157,15 @@ func (pool *PeerPool) peerDisconnected(peerIdx uint32) error {
 	pool.lock.Lock()
 	defer pool.lock.Unlock()
 
-	var lastUpdateTime time.Time
-	if p, present := pool.peers[peerIdx]; present {
-		lastUpdateTime = p.LastUpdateTime
+	p, present := pool.peers[peerIdx]
+	if !present {
+		return nil
 	}
 
 	pool.peers[peerIdx] = &Peer{
 		Index:          peerIdx,
-		PubKey:         pool.peers[peerIdx].PubKey,
-		LastUpdateTime: lastUpdateTime,
+		PubKey:         p.PubKey,
+		LastUpdateTime: p.LastUpdateTime,
 		connected:      false,
 	}
 	return nil
@@ -180,7 +180,7 @@ func (pool *PeerPool) peerHandshake(peerIdx uint32, msg *peerHandshakeMsg) error
 		PubKey:         pool.peers[peerIdx].PubKey,
 		handShake:      msg,
 		LatestInfo:     pool.peers[peerIdx].LatestInfo,
-		LastUpdateTime: time.Now(),
+		LastUpdateTime: pool.server.clock.Now(),
 		connected:      true,
 	}
 
@@ -202,7 +202,7 @@ func (pool *PeerPool) peerHeartbeat(peerIdx uint32, msg *peerHeartbeatMsg) error
 		PubKey:         pool.peers[peerIdx].PubKey,
 		handShake:      pool.peers[peerIdx].handShake,
 		LatestInfo:     msg,
-		LastUpdateTime: time.Now(),
+		LastUpdateTime: pool.server.clock.Now(),
 		connected:      true,
 	}
 
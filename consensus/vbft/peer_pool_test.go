@@ -39,6 +39,7 @@ func constructPeerPool(connect bool) *PeerPool {
 		configs: make(map[uint32]*vconfig.PeerConfig),
 		IDMap:   make(map[string]uint32),
 		peers:   peers,
+		server:  &Server{clock: SystemClock},
 	}
 	return peerpool
 }
@@ -31,12 +31,14 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	actorTypes "github.com/polynetwork/poly/consensus/actor"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/payload"
+	"github.com/polynetwork/poly/core/signature/bls"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/events"
 	"github.com/polynetwork/poly/events/message"
@@ -44,6 +46,7 @@ import (
 	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/states"
 	p2pmsg "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/polynetwork/poly/p2pserver/net/noise"
 	"github.com/polynetwork/poly/validator/increment"
 )
 
@@ -89,9 +92,11 @@ type p2pMsgPayload struct {
 type Server struct {
 	Index         uint32
 	account       *account.Account
+	blsKey        *bls.PrivateKey
 	poolActor     *actorTypes.TxPoolActor
 	p2p           *actorTypes.P2PActor
 	ledger        *ledger.Ledger
+	clock         Clock
 	incrValidator *increment.IncrementValidator
 	pid           *actor.PID
 
@@ -111,13 +116,15 @@ type Server struct {
 	config                   *vconfig.ChainConfig
 	currentParticipantConfig *BlockParticipantConfig
 
-	chainStore *ChainStore // block store
-	msgPool    *MsgPool    // consensus msg pool
-	blockPool  *BlockPool  // received block proposals
-	peerPool   *PeerPool   // consensus peers
-	syncer     *Syncer
-	stateMgr   *StateMgr
-	timer      *EventTimer
+	chainStore   *ChainStore   // block store
+	msgPool      *MsgPool      // consensus msg pool
+	blockPool    *BlockPool    // received block proposals
+	evidencePool *EvidencePool // detected equivocation evidences
+	peerPool     *PeerPool     // consensus peers
+	syncer       *Syncer
+	stateMgr     *StateMgr
+	timer        *EventTimer
+	timeouts     *roundTimeouts
 
 	msgRecvC   map[uint32]chan *p2pMsgPayload
 	msgC       chan ConsensusMsg
@@ -129,27 +136,61 @@ type Server struct {
 	quitWg     sync.WaitGroup
 }
 
+// ServerConfig holds the dependencies of vbft server which are process wide in a normal node.
+// Servers of an in-process test network take their own actor name and ledger, and share a
+// simulated clock.
+type ServerConfig struct {
+	Name   string         // actor name of the server
+	Ledger *ledger.Ledger // ledger sealed blocks are saved to
+	Clock  Clock          // time source of consensus timers
+}
+
 func NewVbftServer(account *account.Account, txpool, p2p *actor.PID) (*Server, error) {
+	server, err := NewVbftServerWithConfig(account, txpool, p2p, &ServerConfig{
+		Name:   "consensus_vbft",
+		Ledger: ledger.DefLedger,
+		Clock:  SystemClock,
+	})
+	if err != nil {
+		return nil, err
+	}
+	setStatusServer(server)
+	return server, nil
+}
+
+func NewVbftServerWithConfig(account *account.Account, txpool, p2p *actor.PID, cfg *ServerConfig) (*Server, error) {
 	server := &Server{
 		msgHistoryDuration: 64,
 		account:            account,
 		poolActor:          &actorTypes.TxPoolActor{Pool: txpool},
 		p2p:                &actorTypes.P2PActor{P2P: p2p},
-		ledger:             ledger.DefLedger,
+		ledger:             cfg.Ledger,
+		clock:              cfg.Clock,
 		incrValidator:      increment.NewIncrementValidator(20),
 	}
+	if path := config.DefConfig.Consensus.BlsKeyPath; path != "" {
+		blsKey, err := loadBlsKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("vbft server load bls key failed: %s", err)
+		}
+		server.blsKey = blsKey
+	}
 	server.stateMgr = newStateMgr(server)
 
 	props := actor.FromProducer(func() actor.Actor {
 		return server
 	})
 
-	pid, err := actor.SpawnNamed(props, "consensus_vbft")
+	pid, err := actor.SpawnNamed(props, cfg.Name)
 	if err != nil {
 		return nil, err
 	}
 	server.pid = pid
-	server.sub = events.NewActorSubscriber(pid)
+	if cfg.Ledger == ledger.DefLedger {
+		// ledger of the node publishes saved blocks on the default event hub, servers
+		// with their own ledgers learn saved blocks from chain store only
+		server.sub = events.NewActorSubscriber(pid)
+	}
 
 	if err := server.initialize(); err != nil {
 		return nil, fmt.Errorf("vbft server start failed: %s", err)
@@ -236,6 +277,11 @@ func (self *Server) NewConsensusPayload(payload *p2pmsg.ConsensusPayload) {
 		log.Debugf("invalid consensus node: %s", peerID)
 		return
 	}
+	if config.DefConfig.P2PNode.IsNoise && payload.PeerId != noise.PeerID(payload.Owner) {
+		// with noise, p2p id of consensus node is bound to its key
+		log.Warnf("consensus msg of node %s from unbound p2p peer %d", peerID, payload.PeerId)
+		return
+	}
 	if self.peerPool.isNewPeer(peerIdx) {
 		self.peerPool.peerConnected(peerIdx)
 	}
@@ -423,8 +469,10 @@ func (self *Server) initialize() error {
 		return fmt.Errorf("init blockpool: %s", err)
 	}
 	self.msgPool = newMsgPool(self, self.msgHistoryDuration)
+	self.evidencePool = newEvidencePool(self)
 	self.peerPool = NewPeerPool(0, self) // FIXME: maxSize
 	self.timer = NewEventTimer(self)
+	self.timeouts = &roundTimeouts{}
 	self.syncer = newSyncer(self)
 
 	self.msgRecvC = make(map[uint32]chan *p2pMsgPayload)
@@ -462,7 +510,9 @@ func (self *Server) initialize() error {
 	} else {
 		self.Index = math.MaxUint32
 	}
-	self.sub.Subscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	if self.sub != nil {
+		self.sub.Subscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	}
 	go self.syncer.run()
 	go self.stateMgr.run()
 	go self.msgSendLoop()
@@ -523,7 +573,10 @@ func (self *Server) start() error {
 func (self *Server) stop() error {
 
 	self.incrValidator.Clean()
-	self.sub.Unsubscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	clearStatusServer(self)
+	if self.sub != nil {
+		self.sub.Unsubscribe(message.TOPIC_SAVE_BLOCK_COMPLETE)
+	}
 	// stop syncer, statemgr, msgSendLoop, timer, actionLoop, msgProcessingLoop
 	self.quit = true
 	close(self.quitC)
@@ -1087,7 +1140,7 @@ func (self *Server) processProposalMsg(msg *blockProposalMsg) {
 
 	prevBlockTimestamp := blk.Block.Header.Timestamp
 	currentBlockTimestamp := msg.Block.Block.Header.Timestamp
-	if currentBlockTimestamp <= prevBlockTimestamp || currentBlockTimestamp > uint32(time.Now().Add(time.Minute*10).Unix()) {
+	if currentBlockTimestamp <= prevBlockTimestamp || currentBlockTimestamp > uint32(self.clock.Now().Add(time.Minute*10).Unix()) {
 		log.Errorf("BlockPrposalMessage check  blocknum:%d,prevBlockTimestamp:%d,currentBlockTimestamp:%d", msg.GetBlockNum(), prevBlockTimestamp, currentBlockTimestamp)
 		self.msgPool.DropMsg(msg)
 		return
@@ -1245,7 +1298,10 @@ func (self *Server) processMsgEvent() error {
 				// add proposal to block-pool
 				if err := self.blockPool.newBlockProposal(pMsg); err != nil {
 					if err == errDupProposal {
-						// TODO: faulty proposer detected
+						// faulty proposer detected, evidence recorded by block pool, dup proposal is not relayed
+						log.Warnf("server %d: dropped dup proposal (%d) of proposer %d", self.Index, msgBlkNum,
+							pMsg.Block.getProposer())
+						return nil
 					}
 					log.Errorf("failed to add block proposal (%d): %s", msgBlkNum, err)
 					return nil
@@ -1672,6 +1728,7 @@ func (self *Server) actionLoop() {
 }
 
 func (self *Server) processTimerEvent(evt *TimerEvent) error {
+	self.timeouts.addTimeout(evt)
 	switch evt.evtType {
 	case EventProposalBackoff:
 		// 1. if endorsed, return
@@ -2066,7 +2123,8 @@ func (self *Server) fastForwardBlock(block *Block) error {
 	if self.GetCurrentBlockNo() == block.getBlockNum() {
 		// block from peer syncer, there should only one candidate block
 		flag := false
-		if len(block.Block.Header.SigData) <= 1 {
+		header := block.Block.Header
+		if !vconfig.IsBlsSealed(header) && len(header.Bookkeepers) <= 1 {
 			flag = true
 		}
 		return self.sealBlock(block, false, flag)
@@ -2097,6 +2155,7 @@ func (self *Server) sealBlock(block *Block, empty bool, sigdata bool) error {
 	self.timer.onBlockSealed(sealedBlkNum)
 	self.msgPool.onBlockSealed(sealedBlkNum)
 	self.blockPool.onBlockSealed(sealedBlkNum)
+	self.evidencePool.onBlockSealed(sealedBlkNum)
 
 	_, h := self.blockPool.getSealedBlock(sealedBlkNum)
 	prevBlkHash := block.getPrevBlockHash()
@@ -2176,7 +2235,7 @@ func (self *Server) checkNeedUpdateChainConfig(blockNum uint32) bool {
 
 //checkUpdateChainConfig query leveldb check is force update
 func (self *Server) checkUpdateChainConfig(blkNum uint32) bool {
-	force, err := isUpdate(self.blockPool.getExecWriteSet(blkNum-1), self.config.View)
+	force, err := isUpdate(self.blockPool.getExecWriteSet(blkNum-1), self.ledger, self.config.View)
 	if err != nil {
 		log.Errorf("checkUpdateChainConfig err:%s", err)
 		return false
@@ -2226,7 +2285,7 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 	cfg := &vconfig.ChainConfig{}
 	cfg = nil
 	if self.checkNeedUpdateChainConfig(blkNum) || self.checkUpdateChainConfig(blkNum) {
-		chainconfig, err := getChainConfig(self.blockPool.getExecWriteSet(blkNum-1), blkNum)
+		chainconfig, err := getChainConfig(self.blockPool.getExecWriteSet(blkNum-1), self.ledger, blkNum)
 		if err != nil {
 			return fmt.Errorf("getChainConfig failed:%s", err)
 		}
@@ -2249,6 +2308,7 @@ func (self *Server) makeProposal(blkNum uint32, forEmpty bool) error {
 				userTxs = append(userTxs, e.Tx)
 			}
 		}
+		userTxs = append(userTxs, self.evidencePool.getEvidenceTxs(blkNum)...)
 	}
 	proposal, err := self.constructProposalMsg(blkNum, sysTxs, userTxs, cfg)
 	if err != nil {
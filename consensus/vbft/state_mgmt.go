@@ -83,7 +83,7 @@ type StateMgr struct {
 	StateEventC      chan *StateEvent
 	peers            map[uint32]*PeerState
 
-	liveTicker             *time.Timer
+	liveTicker             Timer
 	lastTickChainHeight    uint32
 	lastBlockSyncReqHeight uint32
 }
@@ -103,7 +103,7 @@ func (self *StateMgr) getState() ServerState {
 }
 
 func (self *StateMgr) run() {
-	self.liveTicker = time.AfterFunc(peerHandshakeTimeout*5, func() {
+	self.liveTicker = self.server.clock.AfterFunc(peerHandshakeTimeout*5, func() {
 		self.StateEventC <- &StateEvent{
 			Type:     LiveTick,
 			blockNum: self.server.GetCommittedBlockNo(),
@@ -367,7 +367,7 @@ func (self *StateMgr) setSyncedReady() error {
 	if prevState <= SyncReady {
 		log.Infof("server %d start sync ready", self.server.Index)
 		blkNum := self.server.GetCurrentBlockNo()
-		time.AfterFunc(self.syncReadyTimeout, func() {
+		self.server.clock.AfterFunc(self.syncReadyTimeout, func() {
 			self.StateEventC <- &StateEvent{
 				Type:     SyncReadyTimeout,
 				blockNum: blkNum,
@@ -0,0 +1,201 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package vbft
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+)
+
+const MAX_TIMEOUT_HISTORY = 32
+
+var timerEventNames = map[TimerEventType]string{
+	EventProposeBlockTimeout:      "ProposeBlockTimeout",
+	EventPropose2ndBlockTimeout:   "Propose2ndBlockTimeout",
+	EventEndorseBlockTimeout:      "EndorseBlockTimeout",
+	EventEndorseEmptyBlockTimeout: "EndorseEmptyBlockTimeout",
+	EventCommitBlockTimeout:       "CommitBlockTimeout",
+	EventTxBlockTimeout:           "TxBlockTimeout",
+}
+
+// PeerStatus reports the liveness and progress of one consensus peer as seen by this node
+type PeerStatus struct {
+	Index                uint32
+	ID                   string
+	Alive                bool   // peer is connected or heard from recently
+	Active               bool   // peer is alive and not far behind local committed block
+	CommittedBlockNumber uint32 // committed block number in latest heartbeat of peer
+	LastUpdateTime       int64  // unix time of latest message from peer, 0 if never heard
+}
+
+// RoundTimeout reports one consensus timer which fired, such as a propose, endorse or commit timeout
+type RoundTimeout struct {
+	BlockNum uint32
+	Event    string
+	Time     int64 // unix time the timeout fired
+}
+
+// ConsensusStatus reports the round state of the local vbft server, returned by getconsensusstatus rpc
+type ConsensusStatus struct {
+	CurrentBlockNum    uint32
+	CommittedBlockNum  uint32
+	ChainConfigView    uint32
+	MaxBlockChangeView uint32
+	Proposers          []uint32 // proposers of current round in vrf ranking
+	Endorsers          []uint32 // endorsers of current round in vrf ranking
+	Committers         []uint32 // committers of current round in vrf ranking
+	Proposed           []uint32 // peers sent proposal in current round
+	Endorsed           []uint32 // peers sent endorsement in current round
+	Committed          []uint32 // peers sent commitment in current round
+	Peers              []PeerStatus
+	Timeouts           []RoundTimeout // latest timeouts, oldest first
+}
+
+type roundTimeouts struct {
+	sync.RWMutex
+	timeouts []RoundTimeout
+}
+
+func (t *roundTimeouts) addTimeout(evt *TimerEvent) {
+	name, present := timerEventNames[evt.evtType]
+	if !present {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.timeouts = append(t.timeouts, RoundTimeout{
+		BlockNum: evt.blockNum,
+		Event:    name,
+		Time:     time.Now().Unix(),
+	})
+	if len(t.timeouts) > MAX_TIMEOUT_HISTORY {
+		t.timeouts = t.timeouts[len(t.timeouts)-MAX_TIMEOUT_HISTORY:]
+	}
+}
+
+func (t *roundTimeouts) snapshot() []RoundTimeout {
+	t.RLock()
+	defer t.RUnlock()
+	return append([]RoundTimeout{}, t.timeouts...)
+}
+
+var statusServer struct {
+	sync.RWMutex
+	server *Server
+}
+
+func setStatusServer(server *Server) {
+	statusServer.Lock()
+	defer statusServer.Unlock()
+	statusServer.server = server
+}
+
+func clearStatusServer(server *Server) {
+	statusServer.Lock()
+	defer statusServer.Unlock()
+	if statusServer.server == server {
+		statusServer.server = nil
+	}
+}
+
+// GetConsensusStatus returns the round status of the running vbft server
+func GetConsensusStatus() (*ConsensusStatus, error) {
+	statusServer.RLock()
+	server := statusServer.server
+	statusServer.RUnlock()
+	if server == nil {
+		return nil, errors.New("vbft server not running")
+	}
+	return server.getConsensusStatus(), nil
+}
+
+func (self *Server) getConsensusStatus() *ConsensusStatus {
+	blkNum := self.GetCurrentBlockNo()
+	status := &ConsensusStatus{
+		CurrentBlockNum:   blkNum,
+		CommittedBlockNum: self.GetCommittedBlockNo(),
+		Timeouts:          self.timeouts.snapshot(),
+	}
+
+	var peers []*vconfig.PeerConfig
+	self.metaLock.RLock()
+	if self.config != nil {
+		status.ChainConfigView = self.config.View
+		status.MaxBlockChangeView = self.config.MaxBlockChangeView
+		peers = self.config.Peers
+	}
+	if cfg := self.currentParticipantConfig; cfg != nil && cfg.BlockNum == blkNum {
+		status.Proposers = append([]uint32{}, cfg.Proposers...)
+		status.Endorsers = append([]uint32{}, cfg.Endorsers...)
+		status.Committers = append([]uint32{}, cfg.Committers...)
+	}
+	self.metaLock.RUnlock()
+
+	status.Proposed, status.Endorsed, status.Committed = self.blockPool.getRoundParticipants(blkNum)
+
+	for _, p := range peers {
+		ps := PeerStatus{
+			Index:  p.Index,
+			ID:     p.ID,
+			Alive:  self.isPeerAlive(p.Index, blkNum),
+			Active: self.isPeerActive(p.Index, blkNum),
+		}
+		if peer := self.peerPool.getPeer(p.Index); peer != nil {
+			if peer.LatestInfo != nil {
+				ps.CommittedBlockNumber = peer.LatestInfo.CommittedBlockNumber
+			}
+			if !peer.LastUpdateTime.IsZero() {
+				ps.LastUpdateTime = peer.LastUpdateTime.Unix()
+			}
+		}
+		status.Peers = append(status.Peers, ps)
+	}
+	return status
+}
+
+//
+// getRoundParticipants returns peers which proposed, endorsed and committed for block
+//
+func (pool *BlockPool) getRoundParticipants(blkNum uint32) ([]uint32, []uint32, []uint32) {
+	pool.lock.RLock()
+	defer pool.lock.RUnlock()
+
+	proposed, endorsed, committed := make([]uint32, 0), make([]uint32, 0), make([]uint32, 0)
+	candidate := pool.candidateBlocks[blkNum]
+	if candidate == nil {
+		return proposed, endorsed, committed
+	}
+	for _, p := range candidate.Proposals {
+		proposed = append(proposed, p.Block.getProposer())
+	}
+	for endorser := range candidate.EndorseSigs {
+		endorsed = append(endorsed, endorser)
+	}
+	for _, c := range candidate.CommitMsgs {
+		committed = append(committed, c.Committer)
+	}
+	for _, peers := range [][]uint32{proposed, endorsed, committed} {
+		sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+	}
+	return proposed, endorsed, committed
+}
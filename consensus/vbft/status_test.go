@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package vbft
+
+import (
+	"reflect"
+	"testing"
+
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+)
+
+func constructStatusServer(t *testing.T) *Server {
+	pool, accts := constructEquivocationPool(t, 4)
+	server := pool.server
+	server.Index = 1
+	server.blockPool = pool
+	server.currentBlockNum = 10
+	server.chainStore = &ChainStore{chainedBlockNum: 9}
+	server.timeouts = &roundTimeouts{}
+	server.config = &vconfig.ChainConfig{
+		View:               3,
+		MaxBlockChangeView: 100,
+	}
+	for i := uint32(1); i <= 4; i++ {
+		server.config.Peers = append(server.config.Peers, &vconfig.PeerConfig{
+			Index: i,
+			ID:    vconfig.PubkeyID(accts[i].PublicKey),
+		})
+	}
+	server.currentParticipantConfig = &BlockParticipantConfig{
+		BlockNum:   10,
+		Proposers:  []uint32{2, 1, 3},
+		Endorsers:  []uint32{3, 4, 1},
+		Committers: []uint32{4, 1, 2},
+	}
+
+	// peer 2 sent heartbeat, peer 3 connected, peer 4 is offline
+	server.peerPool.peerHeartbeat(2, &peerHeartbeatMsg{CommittedBlockNumber: 8})
+	server.peerPool.peerConnected(3)
+
+	// proposals of peer 2 and peer 1, peer 3 endorsed and peer 4 committed proposal of peer 2
+	p2 := constructTestProposal(t, accts[2], 2, 10, 1)
+	p1 := constructTestProposal(t, accts[1], 1, 10, 3)
+	pool.newBlockProposal(p2)
+	pool.newBlockProposal(p1)
+	endorse := constructTestEndorse(t, accts[3], 3, p2, false)
+	pool.newBlockEndorsement(endorse)
+	commit := constructTestEndorse(t, accts[4], 4, p2, false)
+	pool.newBlockCommitment(&blockCommitMsg{
+		Committer:       4,
+		BlockProposer:   2,
+		BlockNum:        10,
+		CommitBlockHash: p2.Block.Block.Hash(),
+		EndorsersSig:    map[uint32][]byte{3: endorse.EndorserSig},
+		CommitterSig:    commit.EndorserSig,
+	})
+	return server
+}
+
+func TestGetConsensusStatus(t *testing.T) {
+	server := constructStatusServer(t)
+	server.timeouts.addTimeout(&TimerEvent{evtType: EventProposeBlockTimeout, blockNum: 9})
+	server.timeouts.addTimeout(&TimerEvent{evtType: EventPeerHeartbeat, blockNum: 10})
+	server.timeouts.addTimeout(&TimerEvent{evtType: EventEndorseBlockTimeout, blockNum: 10})
+
+	status := server.getConsensusStatus()
+	if status.CurrentBlockNum != 10 || status.CommittedBlockNum != 9 {
+		t.Fatalf("block num %d, committed %d", status.CurrentBlockNum, status.CommittedBlockNum)
+	}
+	if status.ChainConfigView != 3 || status.MaxBlockChangeView != 100 {
+		t.Fatalf("chain config view %d, max block change view %d", status.ChainConfigView, status.MaxBlockChangeView)
+	}
+
+	// ranking of round is kept
+	if !reflect.DeepEqual(status.Proposers, []uint32{2, 1, 3}) {
+		t.Fatalf("proposers %v", status.Proposers)
+	}
+	if !reflect.DeepEqual(status.Endorsers, []uint32{3, 4, 1}) {
+		t.Fatalf("endorsers %v", status.Endorsers)
+	}
+	if !reflect.DeepEqual(status.Committers, []uint32{4, 1, 2}) {
+		t.Fatalf("committers %v", status.Committers)
+	}
+	if !reflect.DeepEqual(status.Proposed, []uint32{1, 2}) {
+		t.Fatalf("proposed %v", status.Proposed)
+	}
+	// proposers endorse their own proposals, committer endorses the committed proposal
+	if !reflect.DeepEqual(status.Endorsed, []uint32{1, 2, 3, 4}) {
+		t.Fatalf("endorsed %v", status.Endorsed)
+	}
+	if !reflect.DeepEqual(status.Committed, []uint32{4}) {
+		t.Fatalf("committed %v", status.Committed)
+	}
+
+	if len(status.Peers) != 4 {
+		t.Fatalf("%d peers", len(status.Peers))
+	}
+	expected := []struct {
+		alive, active bool
+		committed     uint32
+		updated       bool
+	}{
+		{alive: true, active: true},                              // self
+		{alive: true, active: true, committed: 8, updated: true}, // heartbeat
+		{alive: true, active: true, updated: true},               // connected
+		{alive: false, active: false},                            // offline
+	}
+	for i, ps := range status.Peers {
+		if ps.Index != uint32(i+1) || ps.ID != server.config.Peers[i].ID {
+			t.Fatalf("peer %d: index %d, id %s", i, ps.Index, ps.ID)
+		}
+		if ps.Alive != expected[i].alive || ps.Active != expected[i].active {
+			t.Errorf("peer %d: alive %v, active %v", ps.Index, ps.Alive, ps.Active)
+		}
+		if ps.CommittedBlockNumber != expected[i].committed {
+			t.Errorf("peer %d: committed block %d", ps.Index, ps.CommittedBlockNumber)
+		}
+		if (ps.LastUpdateTime != 0) != expected[i].updated {
+			t.Errorf("peer %d: last update time %d", ps.Index, ps.LastUpdateTime)
+		}
+	}
+
+	// heartbeat event is not a round timeout
+	if len(status.Timeouts) != 2 {
+		t.Fatalf("%d timeouts", len(status.Timeouts))
+	}
+	if status.Timeouts[0].BlockNum != 9 || status.Timeouts[0].Event != "ProposeBlockTimeout" {
+		t.Errorf("timeout 0: %+v", status.Timeouts[0])
+	}
+	if status.Timeouts[1].BlockNum != 10 || status.Timeouts[1].Event != "EndorseBlockTimeout" {
+		t.Errorf("timeout 1: %+v", status.Timeouts[1])
+	}
+
+	// participants of a round not started are not reported
+	server.currentBlockNum = 11
+	status = server.getConsensusStatus()
+	if status.Proposers != nil || len(status.Proposed) != 0 || len(status.Endorsed) != 0 || len(status.Committed) != 0 {
+		t.Fatalf("participants of round 11: %+v", status)
+	}
+}
+
+func TestConsensusTimeoutHistory(t *testing.T) {
+	timeouts := &roundTimeouts{}
+	for i := uint32(0); i < MAX_TIMEOUT_HISTORY+5; i++ {
+		timeouts.addTimeout(&TimerEvent{evtType: EventCommitBlockTimeout, blockNum: i})
+	}
+	history := timeouts.snapshot()
+	if len(history) != MAX_TIMEOUT_HISTORY {
+		t.Fatalf("%d timeouts kept", len(history))
+	}
+	// oldest first
+	if history[0].BlockNum != 5 || history[MAX_TIMEOUT_HISTORY-1].BlockNum != MAX_TIMEOUT_HISTORY+4 {
+		t.Fatalf("history from %d to %d", history[0].BlockNum, history[MAX_TIMEOUT_HISTORY-1].BlockNum)
+	}
+	// snapshot is not changed by new timeouts
+	timeouts.addTimeout(&TimerEvent{evtType: EventTxBlockTimeout, blockNum: 100})
+	if history[MAX_TIMEOUT_HISTORY-1].BlockNum != MAX_TIMEOUT_HISTORY+4 {
+		t.Fatalf("snapshot changed")
+	}
+}
+
+func TestGetConsensusStatusServer(t *testing.T) {
+	if _, err := GetConsensusStatus(); err == nil {
+		t.Fatalf("status of server not running")
+	}
+
+	server := constructStatusServer(t)
+	setStatusServer(server)
+	status, err := GetConsensusStatus()
+	if err != nil {
+		t.Fatalf("GetConsensusStatus failed: %v", err)
+	}
+	if status.CurrentBlockNum != 10 || len(status.Peers) != 4 {
+		t.Fatalf("status of server: %+v", status)
+	}
+
+	// stopped server of other instance does not clear status server
+	clearStatusServer(constructStatusServer(t))
+	if _, err := GetConsensusStatus(); err != nil {
+		t.Fatalf("status server cleared by other server: %v", err)
+	}
+	clearStatusServer(server)
+	if _, err := GetConsensusStatus(); err == nil {
+		t.Fatalf("status of stopped server")
+	}
+}
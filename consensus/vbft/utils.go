@@ -21,8 +21,12 @@ package vbft
 import (
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"strings"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-crypto/vrf"
 	"github.com/polynetwork/poly/account"
@@ -31,6 +35,7 @@ import (
 	"github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/signature/bls"
 	"github.com/polynetwork/poly/core/states"
 	scommon "github.com/polynetwork/poly/core/store/common"
 	"github.com/polynetwork/poly/core/store/overlaydb"
@@ -125,8 +130,8 @@ func verifyVrf(pk keypair.PublicKey, blkNum uint32, prevVrf, newVrf, proof []byt
 	}
 	return nil
 }
-func GetVbftConfigInfo(memdb *overlaydb.MemDB) (*config.VBFTConfig, error) {
-	data, err := GetStorageValue(memdb, ledger.DefLedger, nutils.NodeManagerContractAddress, []byte(node_manager.VBFT_CONFIG))
+func GetVbftConfigInfo(memdb *overlaydb.MemDB, backend *ledger.Ledger) (*config.VBFTConfig, error) {
+	data, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, []byte(node_manager.VBFT_CONFIG))
 	if err != nil {
 		return nil, err
 	}
@@ -145,14 +150,14 @@ func GetVbftConfigInfo(memdb *overlaydb.MemDB) (*config.VBFTConfig, error) {
 	return chainconfig, nil
 }
 
-func GetPeersConfig(memdb *overlaydb.MemDB) ([]*config.VBFTPeerInfo, error) {
-	goveranceview, err := GetGovernanceView(memdb)
+func GetPeersConfig(memdb *overlaydb.MemDB, backend *ledger.Ledger) ([]*config.VBFTPeerInfo, error) {
+	goveranceview, err := GetGovernanceView(memdb, backend)
 	if err != nil {
 		return nil, err
 	}
 	viewBytes := nutils.GetUint32Bytes(goveranceview.View)
 	key := append([]byte(node_manager.PEER_POOL), viewBytes...)
-	data, err := GetStorageValue(memdb, ledger.DefLedger, nutils.NodeManagerContractAddress, key)
+	data, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, key)
 	if err != nil {
 		return nil, err
 	}
@@ -166,9 +171,14 @@ func GetPeersConfig(memdb *overlaydb.MemDB) ([]*config.VBFTPeerInfo, error) {
 	var peerstakes []*config.VBFTPeerInfo
 	for _, id := range peerMap.PeerPoolMap {
 		if id.Status == node_manager.CandidateStatus || id.Status == node_manager.ConsensusStatus {
+			blsKey, err := getBlsKey(memdb, backend, id.PeerPubkey)
+			if err != nil {
+				return nil, err
+			}
 			config := &config.VBFTPeerInfo{
 				Index:      uint32(id.Index),
 				PeerPubkey: id.PeerPubkey,
+				BlsKey:     blsKey,
 			}
 			peerstakes = append(peerstakes, config)
 		}
@@ -176,8 +186,25 @@ func GetPeersConfig(memdb *overlaydb.MemDB) ([]*config.VBFTPeerInfo, error) {
 	return peerstakes, nil
 }
 
-func isUpdate(memdb *overlaydb.MemDB, view uint32) (bool, error) {
-	goveranceview, err := GetGovernanceView(memdb)
+// getBlsKey returns the hex encoded bls key registered for peer, empty if not registered
+func getBlsKey(memdb *overlaydb.MemDB, backend *ledger.Ledger, peerPubkey string) (string, error) {
+	pubkey, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return "", fmt.Errorf("invalid peer pubkey %s: %s", peerPubkey, err)
+	}
+	key := append([]byte(node_manager.BLS_KEY), pubkey...)
+	data, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, key)
+	if err == scommon.ErrNotFound {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+func isUpdate(memdb *overlaydb.MemDB, backend *ledger.Ledger, view uint32) (bool, error) {
+	goveranceview, err := GetGovernanceView(memdb, backend)
 	if err != nil {
 		return false, err
 	}
@@ -211,8 +238,8 @@ func GetStorageValue(memdb *overlaydb.MemDB, backend *ledger.Ledger, addr common
 	return
 }
 
-func GetGovernanceView(memdb *overlaydb.MemDB) (*node_manager.GovernanceView, error) {
-	value, err := GetStorageValue(memdb, ledger.DefLedger, nutils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW))
+func GetGovernanceView(memdb *overlaydb.MemDB, backend *ledger.Ledger) (*node_manager.GovernanceView, error) {
+	value, err := GetStorageValue(memdb, backend, nutils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW))
 	if err != nil {
 		return nil, err
 	}
@@ -224,17 +251,17 @@ func GetGovernanceView(memdb *overlaydb.MemDB) (*node_manager.GovernanceView, er
 	return governanceView, nil
 }
 
-func getChainConfig(memdb *overlaydb.MemDB, blkNum uint32) (*vconfig.ChainConfig, error) {
-	config, err := GetVbftConfigInfo(memdb)
+func getChainConfig(memdb *overlaydb.MemDB, backend *ledger.Ledger, blkNum uint32) (*vconfig.ChainConfig, error) {
+	config, err := GetVbftConfigInfo(memdb, backend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chainconfig from leveldb: %s", err)
 	}
 
-	peersinfo, err := GetPeersConfig(memdb)
+	peersinfo, err := GetPeersConfig(memdb, backend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get peersinfo from leveldb: %s", err)
 	}
-	goverview, err := GetGovernanceView(memdb)
+	goverview, err := GetGovernanceView(memdb, backend)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get governanceview failed:%s", err)
 	}
@@ -246,3 +273,16 @@ func getChainConfig(memdb *overlaydb.MemDB, blkNum uint32) (*vconfig.ChainConfig
 	cfg.View = goverview.View
 	return cfg, err
 }
+
+// loadBlsKey reads the hex encoded bls private key of this node from file
+func loadBlsKey(path string) (*bls.PrivateKey, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read bls key file %s: %s", path, err)
+	}
+	raw, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode bls key: %s", err)
+	}
+	return bls.PrivateKeyFromBytes(raw)
+}
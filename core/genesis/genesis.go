@@ -37,7 +37,8 @@ const (
 	BlockVersion uint32 = 0
 	GenesisNonce uint64 = 2083236893
 
-	INIT_CONFIG = "initConfig"
+	INIT_CONFIG  = "initConfig"
+	INIT_GENESIS = "initGenesis"
 )
 
 var GenBlockTime = (config.DEFAULT_GEN_BLOCK_TIME * time.Second)
@@ -84,6 +85,11 @@ func BuildGenesisBlock(defaultBookkeeper []keypair.PublicKey, genesisConfig *con
 			nodeManagerConfig,
 		},
 	}
+	if genesisConfig.Governance != nil {
+		gov := common.NewZeroCopySink(nil)
+		genesisConfig.Governance.Serialization(gov)
+		genesisBlock.Transactions = append(genesisBlock.Transactions, NewInitGovernanceTransactions(gov.Bytes())...)
+	}
 	genesisBlock.RebuildMerkleRoot()
 	return genesisBlock, nil
 }
@@ -128,3 +134,22 @@ func NewInitNodeManagerTransaction(
 
 	return NewInvokeTransaction(invokeCode.Bytes(), 0), nil
 }
+
+//NewInitGovernanceTransactions return the transactions registering side chains, btc redeems, relayers
+//and neo3 state validators of genesis config
+func NewInitGovernanceTransactions(paramBytes []byte) []*types.Transaction {
+	contracts := []common.Address{
+		utils.SideChainManagerContractAddress,
+		utils.RelayerManagerContractAddress,
+		utils.Neo3StateManagerContractAddress,
+	}
+	txs := make([]*types.Transaction, 0, len(contracts))
+	for _, contract := range contracts {
+		contractInvokeParam := &states.ContractInvokeParam{Address: contract,
+			Method: INIT_GENESIS, Args: paramBytes}
+		invokeCode := new(common.ZeroCopySink)
+		contractInvokeParam.Serialization(invokeCode)
+		txs = append(txs, NewInvokeTransaction(invokeCode.Bytes(), 0))
+	}
+	return txs
+}
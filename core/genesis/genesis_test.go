@@ -41,3 +41,24 @@ func TestGenesisBlockInit(t *testing.T) {
 	assert.NotNil(t, block)
 	assert.NotEqual(t, block.Header.TransactionsRoot, common.UINT256_EMPTY)
 }
+
+func TestGenesisBlockGovernance(t *testing.T) {
+	_, pub, _ := keypair.GenerateKeyPair(keypair.PK_ECDSA, keypair.P256)
+	conf := &config.GenesisConfig{}
+	block, err := BuildGenesisBlock([]keypair.PublicKey{pub}, conf)
+	assert.Nil(t, err)
+
+	conf.Governance = &config.GenesisGovernanceConfig{
+		Relayers: []string{common.ADDRESS_EMPTY.ToBase58()},
+	}
+	govBlock, err := BuildGenesisBlock([]keypair.PublicKey{pub}, conf)
+	assert.Nil(t, err)
+	assert.Equal(t, len(block.Transactions)+3, len(govBlock.Transactions))
+	assert.Equal(t, block.Transactions[0].Hash(), govBlock.Transactions[0].Hash())
+	assert.NotEqual(t, block.Hash(), govBlock.Hash())
+
+	//deterministic
+	again, err := BuildGenesisBlock([]keypair.PublicKey{pub}, conf)
+	assert.Nil(t, err)
+	assert.Equal(t, govBlock.Hash(), again.Hash())
+}
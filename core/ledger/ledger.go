@@ -28,6 +28,7 @@ import (
 	"github.com/polynetwork/poly/core/store"
 	"github.com/polynetwork/poly/core/store/ledgerstore"
 	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/events/stream"
 	"github.com/polynetwork/poly/native/event"
 	cstate "github.com/polynetwork/poly/native/states"
 )
@@ -48,6 +49,28 @@ func NewLedger(dataDir string) (*Ledger, error) {
 	}, nil
 }
 
+// NewMemLedger returns a ledger kept in memory, used by in-process test networks
+func NewMemLedger() (*Ledger, error) {
+	ldgStore, err := ledgerstore.NewMemLedgerStore()
+	if err != nil {
+		return nil, fmt.Errorf("NewMemLedgerStore error %s", err)
+	}
+	return &Ledger{
+		ldgStore: ldgStore,
+	}, nil
+}
+
+// NewLightLedger returns a ledger which only syncs verified headers, used by light node
+func NewLightLedger(dataDir string) (*Ledger, error) {
+	ldgStore, err := ledgerstore.NewLightStore(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("NewLightStore error %s", err)
+	}
+	return &Ledger{
+		ldgStore: ldgStore,
+	}, nil
+}
+
 func (self *Ledger) GetStore() store.LedgerStore {
 	return self.ldgStore
 }
@@ -76,6 +99,10 @@ func (self *Ledger) ExecuteBlock(b *types.Block) (store.ExecuteResult, error) {
 	return self.ldgStore.ExecuteBlock(b)
 }
 
+func (self *Ledger) ExecuteBlockOnParent(b *types.Block, parent store.ExecuteResult) (store.ExecuteResult, error) {
+	return self.ldgStore.ExecuteBlockOnParent(b, parent)
+}
+
 func (self *Ledger) SubmitBlock(b *types.Block, exec store.ExecuteResult) error {
 	return self.ldgStore.SubmitBlock(b, exec)
 }
@@ -189,6 +216,10 @@ func (self *Ledger) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify
 	return self.ldgStore.GetEventNotifyByBlock(height)
 }
 
+func (self *Ledger) SubscribeEvents(height uint32, bufSize int) (*stream.Subscription, error) {
+	return self.ldgStore.SubscribeEvents(height, bufSize)
+}
+
 func (self *Ledger) Close() error {
 	return self.ldgStore.Close()
 }
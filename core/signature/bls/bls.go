@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package bls implements BLS signatures on BLS12-381 with public keys in G1 and
+// signatures in G2. Signatures of the same message can be aggregated into one, keys
+// are required to come with a proof of possession to rule out rogue key attacks.
+package bls
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+const (
+	PRIVATE_KEY_SIZE = 32
+	PUBLIC_KEY_SIZE  = 96
+	SIGNATURE_SIZE   = 192
+)
+
+var (
+	SIG_DST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	POP_DST = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+)
+
+type PrivateKey struct {
+	k *big.Int
+}
+
+// GenerateKey returns a private key with a scalar read from rand
+func GenerateKey(rand io.Reader) (*PrivateKey, error) {
+	order := bls12381.NewG1().Q()
+	for {
+		buf := make([]byte, PRIVATE_KEY_SIZE+16)
+		if _, err := io.ReadFull(rand, buf); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).Mod(new(big.Int).SetBytes(buf), order)
+		if k.Sign() != 0 {
+			return &PrivateKey{k: k}, nil
+		}
+	}
+}
+
+func PrivateKeyFromBytes(data []byte) (*PrivateKey, error) {
+	if len(data) != PRIVATE_KEY_SIZE {
+		return nil, fmt.Errorf("invalid private key length %d", len(data))
+	}
+	k := new(big.Int).SetBytes(data)
+	if k.Sign() == 0 || k.Cmp(bls12381.NewG1().Q()) >= 0 {
+		return nil, errors.New("private key out of range")
+	}
+	return &PrivateKey{k: k}, nil
+}
+
+func (this *PrivateKey) Bytes() []byte {
+	buf := make([]byte, PRIVATE_KEY_SIZE)
+	fillBytes(this.k, buf)
+	return buf
+}
+
+// PublicKey returns the uncompressed G1 point of the key
+func (this *PrivateKey) PublicKey() []byte {
+	g1 := bls12381.NewG1()
+	pk := g1.MulScalar(g1.New(), g1.One(), this.k)
+	return g1.ToBytes(pk)
+}
+
+func (this *PrivateKey) sign(msg, dst []byte) []byte {
+	g2 := bls12381.NewG2()
+	h, err := hashToG2(g2, msg, dst)
+	if err != nil {
+		// hash to field always gives valid field elements
+		panic(err)
+	}
+	return g2.ToBytes(g2.MulScalar(g2.New(), h, this.k))
+}
+
+// Sign returns the signature of msg
+func (this *PrivateKey) Sign(msg []byte) []byte {
+	return this.sign(msg, SIG_DST)
+}
+
+// ProvePossession signs the public key to prove the key is owned by the signer
+func (this *PrivateKey) ProvePossession() []byte {
+	return this.sign(this.PublicKey(), POP_DST)
+}
+
+// Verify checks sig is the signature of pubKey over msg
+func Verify(pubKey, msg, sig []byte) error {
+	return VerifyAggregate([][]byte{pubKey}, msg, sig)
+}
+
+// VerifyPossession checks proof is the proof of possession of pubKey
+func VerifyPossession(pubKey, proof []byte) error {
+	return verify([][]byte{pubKey}, pubKey, proof, POP_DST)
+}
+
+// VerifyAggregate checks sig is the aggregate signature of all pubKeys over msg.
+// Callers must make sure each key has a valid proof of possession.
+func VerifyAggregate(pubKeys [][]byte, msg, sig []byte) error {
+	return verify(pubKeys, msg, sig, SIG_DST)
+}
+
+func verify(pubKeys [][]byte, msg, sig, dst []byte) error {
+	if len(pubKeys) == 0 {
+		return errors.New("no public key")
+	}
+	g1 := bls12381.NewG1()
+	pk := g1.Zero()
+	for _, v := range pubKeys {
+		p, err := decodeG1(g1, v)
+		if err != nil {
+			return fmt.Errorf("invalid public key: %v", err)
+		}
+		g1.Add(pk, pk, p)
+	}
+	g2 := bls12381.NewG2()
+	s, err := decodeG2(g2, sig)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	h, err := hashToG2(g2, msg, dst)
+	if err != nil {
+		return err
+	}
+	// e(pk, H(m)) == e(g1, sig)
+	engine := bls12381.NewPairingEngine()
+	engine.AddPair(pk, h).AddPairInv(g1.One(), s)
+	if !engine.Check() {
+		return errors.New("signature verification failed")
+	}
+	return nil
+}
+
+// AggregateSignatures adds signatures of the same message up
+func AggregateSignatures(sigs [][]byte) ([]byte, error) {
+	if len(sigs) == 0 {
+		return nil, errors.New("no signature")
+	}
+	g2 := bls12381.NewG2()
+	agg := g2.Zero()
+	for _, v := range sigs {
+		s, err := decodeG2(g2, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature: %v", err)
+		}
+		g2.Add(agg, agg, s)
+	}
+	return g2.ToBytes(agg), nil
+}
+
+// ValidatePublicKey checks pubKey is a valid non identity G1 point
+func ValidatePublicKey(pubKey []byte) error {
+	_, err := decodeG1(bls12381.NewG1(), pubKey)
+	return err
+}
+
+func decodeG1(g1 *bls12381.G1, data []byte) (*bls12381.PointG1, error) {
+	p, err := g1.FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if g1.IsZero(p) || !g1.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in G1")
+	}
+	return p, nil
+}
+
+func decodeG2(g2 *bls12381.G2, data []byte) (*bls12381.PointG2, error) {
+	p, err := g2.FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	if !g2.InCorrectSubgroup(p) {
+		return nil, errors.New("point is not in G2")
+	}
+	return p, nil
+}
+
+// hashToG2 is hash_to_curve of RFC 9380 with suite BLS12381G2_XMD:SHA-256_SSWU_RO_. The library has no
+// hash_to_curve, its MapToCurve clears the cofactor of each mapped point, which sums to the same point
+func hashToG2(g2 *bls12381.G2, msg, dst []byte) (*bls12381.PointG2, error) {
+	uniform := expandMessageXMD(msg, dst, 256)
+	p := g2.Zero()
+	for i := 0; i < 2; i++ {
+		// fp2 element is encoded as c1 || c0
+		in := make([]byte, 96)
+		c0 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[i*128:i*128+64]), fieldModulus)
+		c1 := new(big.Int).Mod(new(big.Int).SetBytes(uniform[i*128+64:i*128+128]), fieldModulus)
+		fillBytes(c1, in[:48])
+		fillBytes(c0, in[48:])
+		q, err := g2.MapToCurve(in)
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(p, p, q)
+	}
+	return p, nil
+}
+
+// fillBytes writes x as a big endian number padded to the length of buf
+func fillBytes(x *big.Int, buf []byte) {
+	b := x.Bytes()
+	copy(buf[len(buf)-len(b):], b)
+}
+
+func expandMessageXMD(msg, dst []byte, length int) []byte {
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha256.New()
+	h.Write(make([]byte, sha256.BlockSize))
+	h.Write(msg)
+	h.Write([]byte{byte(length >> 8), byte(length), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, length)
+	bi := make([]byte, sha256.Size)
+	for i := 1; len(out) < length; i++ {
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		out = append(out, bi...)
+	}
+	return out[:length]
+}
@@ -0,0 +1,169 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package badgerstore
+
+import (
+	"os"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/store/common"
+)
+
+// Badger store
+type BadgerStore struct {
+	db       *badger.DB
+	batch    *badger.WriteBatch
+	batchErr error //first error met by the current batch
+}
+
+// badgerLogger route badger logs to poly log
+type badgerLogger struct{}
+
+func (badgerLogger) Errorf(format string, args ...interface{}) {
+	log.Errorf("[badger]"+format, args...)
+}
+
+func (badgerLogger) Warningf(format string, args ...interface{}) {
+	log.Warnf("[badger]"+format, args...)
+}
+
+func (badgerLogger) Infof(format string, args ...interface{}) {
+	log.Debugf("[badger]"+format, args...)
+}
+
+func (badgerLogger) Debugf(format string, args ...interface{}) {
+	log.Tracef("[badger]"+format, args...)
+}
+
+// NewBadgerStore return BadgerStore instance
+func NewBadgerStore(dir string) (*BadgerStore, error) {
+	//badger only creates the last level of dir
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(badgerLogger{}))
+	if err != nil {
+		return nil, err
+	}
+	return &BadgerStore{db: db}, nil
+}
+
+// NewMemBadgerStore return BadgerStore instance kept in memory
+func NewMemBadgerStore() (*BadgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions("").WithInMemory(true).WithLogger(badgerLogger{}))
+	if err != nil {
+		return nil, err
+	}
+	return &BadgerStore{db: db}, nil
+}
+
+// Put a key-value pair to badger
+func (self *BadgerStore) Put(key []byte, value []byte) error {
+	return self.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(key, value)
+	})
+}
+
+// Get the value of a key from badger
+func (self *BadgerStore) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := self.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		value, err = item.ValueCopy(nil)
+		return err
+	})
+	if err == badger.ErrKeyNotFound {
+		return nil, common.ErrNotFound
+	}
+	return value, err
+}
+
+// Has return whether the key is exist in badger
+func (self *BadgerStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == common.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Delete the the in badger
+func (self *BadgerStore) Delete(key []byte) error {
+	return self.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(key)
+	})
+}
+
+// NewBatch start commit batch. Batch larger than the transaction limit of badger is
+// committed in several transactions
+func (self *BadgerStore) NewBatch() {
+	if self.batch != nil {
+		self.batch.Cancel()
+	}
+	self.batch = self.db.NewWriteBatch()
+	self.batchErr = nil
+}
+
+// BatchPut put a key-value pair to badger batch
+func (self *BadgerStore) BatchPut(key []byte, value []byte) {
+	//badger keeps the slices until flush while callers may reuse them
+	k := append([]byte(nil), key...)
+	v := append([]byte(nil), value...)
+	if err := self.batch.Set(k, v); err != nil && self.batchErr == nil {
+		self.batchErr = err
+	}
+}
+
+// BatchDelete delete a key to badger batch
+func (self *BadgerStore) BatchDelete(key []byte) {
+	k := append([]byte(nil), key...)
+	if err := self.batch.Delete(k); err != nil && self.batchErr == nil {
+		self.batchErr = err
+	}
+}
+
+// BatchCommit commit batch to badger
+func (self *BadgerStore) BatchCommit() error {
+	if self.batchErr != nil {
+		self.batch.Cancel()
+		self.batch = nil
+		return self.batchErr
+	}
+	err := self.batch.Flush()
+	self.batch = nil
+	return err
+}
+
+// Close badger
+func (self *BadgerStore) Close() error {
+	if self.batch != nil {
+		self.batch.Cancel()
+		self.batch = nil
+	}
+	return self.db.Close()
+}
+
+// NewIterator return a iterator of badger with the key prefix
+func (self *BadgerStore) NewIterator(prefix []byte) common.StoreIterator {
+	return newIterator(self.db.NewTransaction(false), prefix)
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package badgerstore
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/polynetwork/poly/core/store/common"
+)
+
+var testBadger *BadgerStore
+
+func TestMain(m *testing.M) {
+	dbFile := "./test"
+	var err error
+	testBadger, err = NewBadgerStore(dbFile)
+	if err != nil {
+		fmt.Printf("NewBadgerStore error:%s\n", err)
+		return
+	}
+	m.Run()
+	testBadger.Close()
+	os.RemoveAll(dbFile)
+	os.RemoveAll("ActorLog")
+}
+
+func TestBadger(t *testing.T) {
+	key := "foo"
+	value := "bar"
+	err := testBadger.Put([]byte(key), []byte(value))
+	if err != nil {
+		t.Errorf("Put error:%s", err)
+		return
+	}
+	v, err := testBadger.Get([]byte(key))
+	if err != nil {
+		t.Errorf("Get error:%s", err)
+		return
+	}
+	if string(v) != value {
+		t.Errorf("Get error %s != %s", v, value)
+		return
+	}
+	err = testBadger.Delete([]byte(key))
+	if err != nil {
+		t.Errorf("Delete error:%s", err)
+		return
+	}
+	ok, err := testBadger.Has([]byte(key))
+	if err != nil {
+		t.Errorf("Has error:%s", err)
+		return
+	}
+	if ok {
+		t.Errorf("Key:%s shoule delete", key)
+		return
+	}
+	_, err = testBadger.Get([]byte(key))
+	if err != common.ErrNotFound {
+		t.Errorf("Get deleted key error:%v", err)
+		return
+	}
+}
+
+func TestBatch(t *testing.T) {
+	testBadger.NewBatch()
+
+	key1 := "foo1"
+	value1 := "bar1"
+	testBadger.BatchPut([]byte(key1), []byte(value1))
+
+	key2 := "foo2"
+	value2 := "bar2"
+	testBadger.BatchPut([]byte(key2), []byte(value2))
+	testBadger.BatchDelete([]byte(key2))
+
+	err := testBadger.BatchCommit()
+	if err != nil {
+		t.Errorf("BatchCommit error:%s", err)
+		return
+	}
+
+	v1, err := testBadger.Get([]byte(key1))
+	if err != nil {
+		t.Errorf("Get error:%s", err)
+		return
+	}
+	if string(v1) != value1 {
+		t.Errorf("Get %s != %s", v1, value1)
+		return
+	}
+	ok, err := testBadger.Has([]byte(key2))
+	if err != nil || ok {
+		t.Errorf("Key:%s should delete in batch, err:%v", key2, err)
+		return
+	}
+}
+
+func TestIterator(t *testing.T) {
+	store, err := NewMemBadgerStore()
+	if err != nil {
+		t.Fatalf("NewMemBadgerStore error:%s", err)
+	}
+	defer store.Close()
+	for _, key := range []string{"a", "fo", "foo", "foo1", "foo3", "fp"} {
+		if err := store.Put([]byte(key), []byte("v"+key)); err != nil {
+			t.Fatalf("Put error:%s", err)
+		}
+	}
+
+	iter := store.NewIterator([]byte("fo"))
+	defer iter.Release()
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+		if string(iter.Value()) != "v"+string(iter.Key()) {
+			t.Errorf("Key:%s value:%s", iter.Key(), iter.Value())
+		}
+	}
+	if fmt.Sprint(keys) != "[fo foo foo1 foo3]" {
+		t.Errorf("forward keys %v", keys)
+	}
+
+	keys = keys[:0]
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		keys = append(keys, string(iter.Key()))
+	}
+	if fmt.Sprint(keys) != "[foo3 foo1 foo fo]" {
+		t.Errorf("backward keys %v", keys)
+	}
+
+	if !iter.Seek([]byte("foo2")) || string(iter.Key()) != "foo3" {
+		t.Errorf("Seek foo2 got %s", iter.Key())
+	}
+	if !iter.Prev() || string(iter.Key()) != "foo1" {
+		t.Errorf("Prev after seek got %s", iter.Key())
+	}
+	if !iter.Next() || string(iter.Key()) != "foo3" {
+		t.Errorf("Next after prev got %s", iter.Key())
+	}
+	if iter.Seek([]byte("fp")) {
+		t.Errorf("Seek beyond prefix got %s", iter.Key())
+	}
+	if !iter.Prev() || string(iter.Key()) != "foo3" {
+		t.Errorf("Prev after exhausted got %s", iter.Key())
+	}
+	if err := iter.Error(); err != nil {
+		t.Errorf("iterator error:%s", err)
+	}
+}
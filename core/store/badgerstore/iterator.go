@@ -0,0 +1,184 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package badgerstore
+
+import (
+	"bytes"
+
+	"github.com/dgraph-io/badger/v2"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+//Iterator iterate the keys with prefix in a badger snapshot. Badger iterators only move in
+//one direction, so the underlying iterator is reopened when direction changes
+type Iterator struct {
+	txn        *badger.Txn
+	prefix     []byte
+	limit      []byte //smallest key greater than all keys with prefix, nil if none
+	iter       *badger.Iterator
+	reverse    bool
+	key, value []byte
+	err        error
+}
+
+func newIterator(txn *badger.Txn, prefix []byte) *Iterator {
+	return &Iterator{
+		txn:    txn,
+		prefix: prefix,
+		limit:  util.BytesPrefix(prefix).Limit,
+	}
+}
+
+func (self *Iterator) open(reverse bool) {
+	if self.iter != nil {
+		if self.reverse == reverse {
+			return
+		}
+		self.iter.Close()
+	}
+	opts := badger.DefaultIteratorOptions
+	opts.Reverse = reverse
+	self.iter = self.txn.NewIterator(opts)
+	self.reverse = reverse
+}
+
+//fill load the current item, return false if iterator moved out of prefix
+func (self *Iterator) fill() bool {
+	if self.err != nil || !self.iter.Valid() {
+		self.key, self.value = nil, nil
+		return false
+	}
+	item := self.iter.Item()
+	key := item.KeyCopy(nil)
+	if !bytes.HasPrefix(key, self.prefix) {
+		self.key, self.value = nil, nil
+		return false
+	}
+	value, err := item.ValueCopy(nil)
+	if err != nil {
+		self.err = err
+		self.key, self.value = nil, nil
+		return false
+	}
+	self.key, self.value = key, value
+	return true
+}
+
+//First move to the first item
+func (self *Iterator) First() bool {
+	self.open(false)
+	self.iter.Seek(self.prefix)
+	return self.fill()
+}
+
+//Last move to the last item
+func (self *Iterator) Last() bool {
+	self.open(true)
+	if self.limit == nil {
+		self.iter.Rewind()
+	} else {
+		self.iter.Seek(self.limit)
+		if self.iter.Valid() && bytes.Equal(self.iter.Item().Key(), self.limit) {
+			self.iter.Next()
+		}
+	}
+	return self.fill()
+}
+
+//Seek move to the first item whose key is not less than key
+func (self *Iterator) Seek(key []byte) bool {
+	if bytes.Compare(key, self.prefix) < 0 {
+		key = self.prefix
+	}
+	self.open(false)
+	self.iter.Seek(key)
+	return self.fill()
+}
+
+//Next move to the next item
+func (self *Iterator) Next() bool {
+	if self.iter == nil {
+		return self.First()
+	}
+	if self.reverse {
+		key := self.key
+		if key == nil {
+			return self.First()
+		}
+		self.open(false)
+		self.iter.Seek(key)
+		if self.iter.Valid() && bytes.Equal(self.iter.Item().Key(), key) {
+			self.iter.Next()
+		}
+		return self.fill()
+	}
+	if self.key == nil {
+		return false
+	}
+	self.iter.Next()
+	return self.fill()
+}
+
+//Prev move to the previous item
+func (self *Iterator) Prev() bool {
+	if self.iter == nil {
+		return self.Last()
+	}
+	if !self.reverse {
+		key := self.key
+		if key == nil {
+			return self.Last()
+		}
+		self.open(true)
+		self.iter.Seek(key)
+		if self.iter.Valid() && bytes.Equal(self.iter.Item().Key(), key) {
+			self.iter.Next()
+		}
+		return self.fill()
+	}
+	if self.key == nil {
+		return false
+	}
+	self.iter.Next()
+	return self.fill()
+}
+
+//Key return the current item key
+func (self *Iterator) Key() []byte {
+	return self.key
+}
+
+//Value return the current item value
+func (self *Iterator) Value() []byte {
+	return self.value
+}
+
+//Release close the iterator and its snapshot
+func (self *Iterator) Release() {
+	if self.iter != nil {
+		self.iter.Close()
+		self.iter = nil
+	}
+	self.txn.Discard()
+}
+
+//Error return any accumulated error
+func (self *Iterator) Error() error {
+	return self.err
+}
@@ -45,6 +45,10 @@ const (
 	SYS_STATE_MERKLE_TREE  DataEntryPrefix = 0x20 // state merkle tree root key prefix
 	SYS_CROSS_STATES       DataEntryPrefix = 0x22
 	SYS_CROSS_STATES_HASH  DataEntryPrefix = 0x23
+	SYS_PRUNED_HEIGHT      DataEntryPrefix = 0x24 //Height below which transactions or events are pruned
+	SYS_STATE_UNDO         DataEntryPrefix = 0x25 //Block height => values overwritten by the block, for rollback
+	SYS_STREAM_START       DataEntryPrefix = 0x26 //Height of the first block saved with stream events
 
 	EVENT_NOTIFY DataEntryPrefix = 0x14 //Event notify key prefix
+	EVENT_STREAM DataEntryPrefix = 0x15 //Block height => seq of the first stream event and event count
 )
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package kvstore opens the PersistStore of the configured kv engine
+package kvstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/store/badgerstore"
+	"github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+)
+
+//NewStore return the store of backend at path
+func NewStore(backend, path string) (common.PersistStore, error) {
+	if exist := DetectBackend(path); exist != "" && exist != backend {
+		return nil, fmt.Errorf("store at %s is kept by %s, not %s", path, exist, backend)
+	}
+	switch backend {
+	case config.DB_BACKEND_LEVELDB:
+		return leveldbstore.NewLevelDBStore(path)
+	case config.DB_BACKEND_BADGER:
+		return badgerstore.NewBadgerStore(path)
+	default:
+		return nil, fmt.Errorf("unknown db backend %s", backend)
+	}
+}
+
+//NewMemStore return the store of backend kept in memory
+func NewMemStore(backend string) (common.PersistStore, error) {
+	switch backend {
+	case config.DB_BACKEND_LEVELDB:
+		return leveldbstore.NewMemLevelDBStore()
+	case config.DB_BACKEND_BADGER:
+		return badgerstore.NewMemBadgerStore()
+	default:
+		return nil, fmt.Errorf("unknown db backend %s", backend)
+	}
+}
+
+//Open return the store at path. Existing store is opened by the backend keeping it,
+//new store is created by the configured backend
+func Open(path string) (common.PersistStore, error) {
+	backend := config.DefConfig.Common.DBBackend
+	if backend == "" {
+		backend = config.DEFAULT_DB_BACKEND
+	}
+	if exist := DetectBackend(path); exist != "" && exist != backend {
+		log.Warnf("store at %s is kept by %s while %s configured, migrate it with `poly db migrate`", path, exist, backend)
+		backend = exist
+	}
+	return NewStore(backend, path)
+}
+
+//DetectBackend return the backend keeping the store at path, empty if no store there
+func DetectBackend(path string) string {
+	if fileExisted(filepath.Join(path, "CURRENT")) {
+		return config.DB_BACKEND_LEVELDB
+	}
+	if fileExisted(filepath.Join(path, "KEYREGISTRY")) {
+		return config.DB_BACKEND_BADGER
+	}
+	return ""
+}
+
+func fileExisted(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package kvstore
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/common"
+)
+
+func TestDetectBackend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kvstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, backend := range []string{config.DB_BACKEND_LEVELDB, config.DB_BACKEND_BADGER} {
+		path := filepath.Join(dir, backend)
+		if exist := DetectBackend(path); exist != "" {
+			t.Errorf("detect %s before created", exist)
+		}
+		store, err := NewStore(backend, path)
+		if err != nil {
+			t.Fatalf("NewStore %s error:%s", backend, err)
+		}
+		store.Close()
+		if exist := DetectBackend(path); exist != backend {
+			t.Errorf("detect %s as %s", backend, exist)
+		}
+	}
+	_, err = NewStore(config.DB_BACKEND_BADGER, filepath.Join(dir, config.DB_BACKEND_LEVELDB))
+	if err == nil {
+		t.Errorf("open leveldb store by badger should fail")
+	}
+	_, err = NewStore("rocksdb", filepath.Join(dir, "rocksdb"))
+	if err == nil {
+		t.Errorf("open unknown backend should fail")
+	}
+}
+
+//the same moves on every backend should visit the same keys
+func TestIteratorConsistent(t *testing.T) {
+	moves := func(store common.PersistStore) string {
+		for _, key := range []string{"a", "b1", "b2", "b4", "b5", "c"} {
+			store.Put([]byte(key), []byte(key))
+		}
+		iter := store.NewIterator([]byte("b"))
+		defer iter.Release()
+		trace := ""
+		visit := func(ok bool) {
+			if ok {
+				trace += string(iter.Key()) + " "
+			} else {
+				trace += "- "
+			}
+		}
+		visit(iter.Seek([]byte("b3")))
+		visit(iter.Prev())
+		visit(iter.Prev())
+		visit(iter.Prev())
+		visit(iter.Next())
+		visit(iter.Last())
+		visit(iter.Next())
+		visit(iter.Prev())
+		visit(iter.First())
+		visit(iter.Seek([]byte("a")))
+		visit(iter.Seek([]byte("c")))
+		visit(iter.Prev())
+		return trace
+	}
+
+	expect := "b4 b2 b1 - b1 b5 - b5 b1 b1 - b5 "
+	for _, backend := range []string{config.DB_BACKEND_LEVELDB, config.DB_BACKEND_BADGER} {
+		store, err := NewMemStore(backend)
+		if err != nil {
+			t.Fatalf("NewMemStore %s error:%s", backend, err)
+		}
+		trace := moves(store)
+		store.Close()
+		if trace != expect {
+			t.Errorf("%s moves %s, expect %s", backend, trace, expect)
+		}
+	}
+}
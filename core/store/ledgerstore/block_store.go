@@ -22,10 +22,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/serialization"
 	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/kvstore"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/types"
 	"io"
@@ -33,10 +35,12 @@ import (
 
 //Block store save the data of block & transaction
 type BlockStore struct {
-	enableCache bool                       //Is enable lru cache
-	dbDir       string                     //The path of store file
-	cache       *BlockCache                //The cache of block, if have.
-	store       *leveldbstore.LevelDBStore //block store handler
+	enableCache bool              //Is enable lru cache
+	dbDir       string            //The path of store file
+	cache       *BlockCache       //The cache of block, if have.
+	store       scom.PersistStore //block store handler
+
+	prunedHeight uint32 //transactions of blocks below the height are pruned, accessed atomically
 }
 
 //NewBlockStore return the block store instance
@@ -50,7 +54,7 @@ func NewBlockStore(dbDir string, enableCache bool) (*BlockStore, error) {
 		}
 	}
 
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := kvstore.Open(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -60,9 +64,37 @@ func NewBlockStore(dbDir string, enableCache bool) (*BlockStore, error) {
 		store:       store,
 		cache:       cache,
 	}
+	prunedHeight, err := blockStore.loadPrunedHeight()
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("loadPrunedHeight error %s", err)
+	}
+	blockStore.prunedHeight = prunedHeight
 	return blockStore, nil
 }
 
+//NewMemBlockStore return the block store instance kept in memory
+func NewMemBlockStore(enableCache bool) (*BlockStore, error) {
+	var cache *BlockCache
+	var err error
+	if enableCache {
+		cache, err = NewBlockCache()
+		if err != nil {
+			return nil, fmt.Errorf("NewBlockCache error %s", err)
+		}
+	}
+
+	store, err := leveldbstore.NewMemLevelDBStore()
+	if err != nil {
+		return nil, err
+	}
+	return &BlockStore{
+		enableCache: enableCache,
+		store:       store,
+		cache:       cache,
+	}, nil
+}
+
 //NewBatch start a commit batch
 func (this *BlockStore) NewBatch() {
 	this.store.NewBatch()
@@ -113,6 +145,9 @@ func (this *BlockStore) GetBlock(blockHash common.Uint256) (*types.Block, error)
 	if this.enableCache {
 		block = this.cache.GetBlock(blockHash)
 		if block != nil {
+			if block.Header.Height < this.GetPrunedHeight() {
+				return nil, scom.ErrPruned
+			}
 			return block, nil
 		}
 	}
@@ -120,6 +155,9 @@ func (this *BlockStore) GetBlock(blockHash common.Uint256) (*types.Block, error)
 	if err != nil {
 		return nil, err
 	}
+	if header.Height < this.GetPrunedHeight() {
+		return nil, scom.ErrPruned
+	}
 	txList := make([]*types.Transaction, 0, len(txHashes))
 	for _, txHash := range txHashes {
 		tx, _, err := this.GetTransaction(txHash)
@@ -328,6 +366,9 @@ func (this *BlockStore) GetTransaction(txHash common.Uint256) (*types.Transactio
 	if this.enableCache {
 		tx, height := this.cache.GetTransaction(txHash)
 		if tx != nil {
+			if height < this.GetPrunedHeight() {
+				return nil, height, scom.ErrPruned
+			}
 			return tx, height, nil
 		}
 	}
@@ -356,6 +397,10 @@ func (this *BlockStore) loadTransaction(txHash common.Uint256) (*types.Transacti
 	if eof {
 		return nil, 0, io.ErrUnexpectedEOF
 	}
+	//only height is kept for pruned transactions
+	if source.Len() == 0 && height < this.GetPrunedHeight() {
+		return nil, height, scom.ErrPruned
+	}
 	tx = new(types.Transaction)
 	err = tx.Deserialization(source)
 	if err != nil {
@@ -383,6 +428,125 @@ func (this *BlockStore) ContainTransaction(txHash common.Uint256) (bool, error)
 	return true, nil
 }
 
+//PruneBlock drop the transactions of block at height, only their heights are kept to tell
+//the transactions existed. Blocks should be pruned in order of height
+func (this *BlockStore) PruneBlock(height uint32) error {
+	blockHash, err := this.GetBlockHash(height)
+	if err != nil {
+		return fmt.Errorf("GetBlockHash error %s", err)
+	}
+	_, txHashes, err := this.loadHeaderWithTx(blockHash)
+	if err != nil {
+		return fmt.Errorf("loadHeaderWithTx error %s", err)
+	}
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height)
+	for _, txHash := range txHashes {
+		this.store.BatchPut(this.getTransactionKey(txHash), value.Bytes())
+	}
+	value.Reset()
+	serialization.WriteUint32(value, height+1)
+	this.store.BatchPut(this.getPrunedHeightKey(), value.Bytes())
+	atomic.StoreUint32(&this.prunedHeight, height+1)
+	return nil
+}
+
+//GetPrunedHeight return the height below which transactions are pruned
+func (this *BlockStore) GetPrunedHeight() uint32 {
+	return atomic.LoadUint32(&this.prunedHeight)
+}
+
+func (this *BlockStore) loadPrunedHeight() (uint32, error) {
+	value, err := this.store.Get(this.getPrunedHeightKey())
+	if err == scom.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+//Rollback delete the blocks above height and the header index lists containing them in one batch
+func (this *BlockStore) Rollback(height uint32) error {
+	_, currHeight, err := this.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("GetCurrentBlock error %s", err)
+	}
+	if currHeight <= height {
+		return nil
+	}
+	blockHash, err := this.GetBlockHash(height)
+	if err != nil {
+		return fmt.Errorf("GetBlockHash height:%d error %s", height, err)
+	}
+	this.NewBatch()
+	err = this.rollback(height, currHeight)
+	if err != nil {
+		this.NewBatch() // reset the batch
+		return err
+	}
+	prunedHeight := this.GetPrunedHeight()
+	if prunedHeight > height+1 {
+		prunedHeight = height + 1
+		value := bytes.NewBuffer(nil)
+		serialization.WriteUint32(value, prunedHeight)
+		this.store.BatchPut(this.getPrunedHeightKey(), value.Bytes())
+	}
+	err = this.SaveCurrentBlock(height, blockHash)
+	if err != nil {
+		this.NewBatch()
+		return fmt.Errorf("SaveCurrentBlock error %s", err)
+	}
+	err = this.CommitTo()
+	if err != nil {
+		return fmt.Errorf("CommitTo error %s", err)
+	}
+	atomic.StoreUint32(&this.prunedHeight, prunedHeight)
+	if this.enableCache {
+		this.cache, err = NewBlockCache()
+		if err != nil {
+			return fmt.Errorf("NewBlockCache error %s", err)
+		}
+	}
+	return nil
+}
+
+func (this *BlockStore) rollback(height, currHeight uint32) error {
+	for h := height + 1; h <= currHeight; h++ {
+		blockHash, err := this.GetBlockHash(h)
+		if err != nil {
+			return fmt.Errorf("GetBlockHash height:%d error %s", h, err)
+		}
+		_, txHashes, err := this.loadHeaderWithTx(blockHash)
+		if err != nil {
+			return fmt.Errorf("loadHeaderWithTx height:%d error %s", h, err)
+		}
+		for _, txHash := range txHashes {
+			this.store.BatchDelete(this.getTransactionKey(txHash))
+		}
+		this.store.BatchDelete(this.getHeaderKey(blockHash))
+		this.store.BatchDelete(this.getBlockHashKey(h))
+	}
+	//a header index list is saved after the block following its last height saved
+	iter := this.store.NewIterator([]byte{byte(scom.IX_HEADER_HASH_LIST)})
+	defer iter.Release()
+	for iter.Next() {
+		startHeight, err := this.getStartHeightByHeaderIndexKey(iter.Key())
+		if err != nil {
+			return fmt.Errorf("getStartHeightByHeaderIndexKey error %s", err)
+		}
+		count, err := serialization.ReadUint32(bytes.NewReader(iter.Value()))
+		if err != nil {
+			return fmt.Errorf("serialization.ReadUint32 count error %s", err)
+		}
+		if startHeight+count > height {
+			this.store.BatchDelete(this.getHeaderIndexListKey(startHeight))
+		}
+	}
+	return iter.Error()
+}
+
 //GetVersion return the version of store
 func (this *BlockStore) GetVersion() (byte, error) {
 	key := this.getVersionKey()
@@ -411,6 +575,7 @@ func (this *BlockStore) ClearAll() error {
 	if err := iter.Error(); err != nil {
 		return err
 	}
+	atomic.StoreUint32(&this.prunedHeight, 0)
 	return this.CommitTo()
 }
 
@@ -454,6 +619,10 @@ func (this *BlockStore) getBlockMerkleTreeKey() []byte {
 	return []byte{byte(scom.SYS_BLOCK_MERKLE_TREE)}
 }
 
+func (this *BlockStore) getPrunedHeightKey() []byte {
+	return []byte{byte(scom.SYS_PRUNED_HEIGHT)}
+}
+
 func (this *BlockStore) getVersionKey() []byte {
 	return []byte{byte(scom.SYS_VERSION)}
 }
@@ -23,29 +23,52 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
+
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/common/serialization"
 	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/kvstore"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/native/event"
 )
 
 //Saving event notifies gen by smart contract execution
 type EventStore struct {
-	dbDir string                     //Store path
-	store *leveldbstore.LevelDBStore //Store handler
+	dbDir string            //Store path
+	store scom.PersistStore //Store handler
+
+	prunedHeight uint32 //events of blocks below the height are pruned, accessed atomically
 }
 
 //NewEventStore return event store instance
 func NewEventStore(dbDir string) (*EventStore, error) {
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := kvstore.Open(dbDir)
 	if err != nil {
 		return nil, err
 	}
-	return &EventStore{
+	eventStore := &EventStore{
 		dbDir: dbDir,
 		store: store,
+	}
+	prunedHeight, err := eventStore.loadPrunedHeight()
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("loadPrunedHeight error %s", err)
+	}
+	eventStore.prunedHeight = prunedHeight
+	return eventStore, nil
+}
+
+//NewMemEventStore return event store instance kept in memory
+func NewMemEventStore() (*EventStore, error) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	if err != nil {
+		return nil, err
+	}
+	return &EventStore{
+		store: store,
 	}, nil
 }
 
@@ -88,6 +111,42 @@ func (this *EventStore) SaveEventNotifyByBlock(height uint32, txHashs []common.U
 	return nil
 }
 
+//SaveStreamSeq persist seq of the first stream event of block at height and count of events
+func (this *EventStore) SaveStreamSeq(height uint32, seq uint64, count uint32) {
+	value := make([]byte, 12)
+	binary.LittleEndian.PutUint64(value, seq)
+	binary.LittleEndian.PutUint32(value[8:], count)
+	this.store.BatchPut(this.getStreamSeqKey(height), value)
+}
+
+//GetStreamSeq return seq of the first stream event of block at height and count of events
+func (this *EventStore) GetStreamSeq(height uint32) (uint64, uint32, error) {
+	value, err := this.store.Get(this.getStreamSeqKey(height))
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(value) != 12 {
+		return 0, 0, fmt.Errorf("invalid stream seq of height %d", height)
+	}
+	return binary.LittleEndian.Uint64(value), binary.LittleEndian.Uint32(value[8:]), nil
+}
+
+//SaveStreamStartHeight persist height of the first block saved with stream events
+func (this *EventStore) SaveStreamStartHeight(height uint32) {
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height)
+	this.store.BatchPut(this.getStreamStartKey(), value.Bytes())
+}
+
+//GetStreamStartHeight return height of the first block saved with stream events
+func (this *EventStore) GetStreamStartHeight() (uint32, error) {
+	value, err := this.store.Get(this.getStreamStartKey())
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
 //GetEventNotifyByTx return event notify by trasanction hash
 func (this *EventStore) GetEventNotifyByTx(txHash common.Uint256) (*event.ExecuteNotify, error) {
 	key := this.getEventNotifyByTxKey(txHash)
@@ -104,6 +163,26 @@ func (this *EventStore) GetEventNotifyByTx(txHash common.Uint256) (*event.Execut
 
 //GetEventNotifyByBlock return all event notify of transaction in block
 func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error) {
+	if height < this.GetPrunedHeight() {
+		return nil, scom.ErrPruned
+	}
+	txHashes, err := this.getEventTxsByBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	evtNotifies := make([]*event.ExecuteNotify, 0)
+	for _, txHash := range txHashes {
+		evtNotify, err := this.GetEventNotifyByTx(txHash)
+		if err != nil {
+			log.Errorf("getEventNotifyByTx Height:%d by txhash:%s error:%s", height, txHash.ToHexString(), err)
+			continue
+		}
+		evtNotifies = append(evtNotifies, evtNotify)
+	}
+	return evtNotifies, nil
+}
+
+func (this *EventStore) getEventTxsByBlock(height uint32) ([]common.Uint256, error) {
 	key, err := this.getEventNotifyByBlockKey(height)
 	if err != nil {
 		return nil, err
@@ -117,21 +196,111 @@ func (this *EventStore) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNo
 	if err != nil {
 		return nil, fmt.Errorf("ReadUint32 error %s", err)
 	}
-	evtNotifies := make([]*event.ExecuteNotify, 0)
+	txHashes := make([]common.Uint256, 0, size)
 	for i := uint32(0); i < size; i++ {
 		var txHash common.Uint256
 		err = txHash.Deserialize(reader)
 		if err != nil {
 			return nil, fmt.Errorf("txHash.Deserialize error %s", err)
 		}
-		evtNotify, err := this.GetEventNotifyByTx(txHash)
+		txHashes = append(txHashes, txHash)
+	}
+	return txHashes, nil
+}
+
+//PruneBlock delete the event notifies of block at height. Blocks should be pruned in order of height
+func (this *EventStore) PruneBlock(height uint32) error {
+	txHashes, err := this.getEventTxsByBlock(height)
+	if err != nil && err != scom.ErrNotFound {
+		return err
+	}
+	for _, txHash := range txHashes {
+		this.store.BatchDelete(this.getEventNotifyByTxKey(txHash))
+	}
+	key, err := this.getEventNotifyByBlockKey(height)
+	if err != nil {
+		return err
+	}
+	this.store.BatchDelete(key)
+	value := bytes.NewBuffer(nil)
+	serialization.WriteUint32(value, height+1)
+	this.store.BatchPut(this.getPrunedHeightKey(), value.Bytes())
+	atomic.StoreUint32(&this.prunedHeight, height+1)
+	return nil
+}
+
+//GetPrunedHeight return the height below which events are pruned
+func (this *EventStore) GetPrunedHeight() uint32 {
+	return atomic.LoadUint32(&this.prunedHeight)
+}
+
+func (this *EventStore) loadPrunedHeight() (uint32, error) {
+	value, err := this.store.Get(this.getPrunedHeightKey())
+	if err == scom.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return serialization.ReadUint32(bytes.NewReader(value))
+}
+
+//Rollback delete the event notifies of blocks above height in one batch
+func (this *EventStore) Rollback(height uint32, blockHash common.Uint256) error {
+	_, currHeight, err := this.GetCurrentBlock()
+	if err == scom.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("GetCurrentBlock error %s", err)
+	}
+	if currHeight <= height {
+		return nil
+	}
+	this.NewBatch()
+	for h := height + 1; h <= currHeight; h++ {
+		txHashes, err := this.getEventTxsByBlock(h)
+		if err != nil && err != scom.ErrNotFound {
+			this.NewBatch() // reset the batch
+			return fmt.Errorf("getEventTxsByBlock height:%d error %s", h, err)
+		}
+		for _, txHash := range txHashes {
+			this.store.BatchDelete(this.getEventNotifyByTxKey(txHash))
+		}
+		key, err := this.getEventNotifyByBlockKey(h)
 		if err != nil {
-			log.Errorf("getEventNotifyByTx Height:%d by txhash:%s error:%s", height, txHash.ToHexString(), err)
-			continue
+			this.NewBatch()
+			return err
 		}
-		evtNotifies = append(evtNotifies, evtNotify)
+		this.store.BatchDelete(key)
+		this.store.BatchDelete(this.getStreamSeqKey(h))
 	}
-	return evtNotifies, nil
+	streamStart, err := this.GetStreamStartHeight()
+	if err != nil && err != scom.ErrNotFound {
+		this.NewBatch()
+		return fmt.Errorf("GetStreamStartHeight error %s", err)
+	}
+	if err == nil && streamStart > height {
+		this.store.BatchDelete(this.getStreamStartKey())
+	}
+	prunedHeight := this.GetPrunedHeight()
+	if prunedHeight > height+1 {
+		prunedHeight = height + 1
+		value := bytes.NewBuffer(nil)
+		serialization.WriteUint32(value, prunedHeight)
+		this.store.BatchPut(this.getPrunedHeightKey(), value.Bytes())
+	}
+	err = this.SaveCurrentBlock(height, blockHash)
+	if err != nil {
+		this.NewBatch()
+		return fmt.Errorf("SaveCurrentBlock error %s", err)
+	}
+	err = this.CommitTo()
+	if err != nil {
+		return fmt.Errorf("CommitTo error %s", err)
+	}
+	atomic.StoreUint32(&this.prunedHeight, prunedHeight)
+	return nil
 }
 
 //CommitTo event store batch to store
@@ -155,6 +324,7 @@ func (this *EventStore) ClearAll() error {
 	if err := iter.Error(); err != nil {
 		return err
 	}
+	atomic.StoreUint32(&this.prunedHeight, 0)
 	return this.CommitTo()
 }
 
@@ -193,6 +363,14 @@ func (this *EventStore) getCurrentBlockKey() []byte {
 	return []byte{byte(scom.SYS_CURRENT_BLOCK)}
 }
 
+func (this *EventStore) getPrunedHeightKey() []byte {
+	return []byte{byte(scom.SYS_PRUNED_HEIGHT)}
+}
+
+func (this *EventStore) getStreamStartKey() []byte {
+	return []byte{byte(scom.SYS_STREAM_START)}
+}
+
 func (this *EventStore) getEventNotifyByBlockKey(height uint32) ([]byte, error) {
 	key := make([]byte, 5, 5)
 	key[0] = byte(scom.EVENT_NOTIFY)
@@ -200,6 +378,13 @@ func (this *EventStore) getEventNotifyByBlockKey(height uint32) ([]byte, error)
 	return key, nil
 }
 
+func (this *EventStore) getStreamSeqKey(height uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(scom.EVENT_STREAM)
+	binary.LittleEndian.PutUint32(key[1:], height)
+	return key
+}
+
 func (this *EventStore) getEventNotifyByTxKey(txHash common.Uint256) []byte {
 	data := txHash.ToArray()
 	key := make([]byte, 1+len(data))
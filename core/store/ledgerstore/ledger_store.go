@@ -41,6 +41,7 @@ import (
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/events"
 	"github.com/polynetwork/poly/events/message"
+	"github.com/polynetwork/poly/events/stream"
 	"github.com/polynetwork/poly/merkle"
 	"github.com/polynetwork/poly/native/event"
 	cstates "github.com/polynetwork/poly/native/states"
@@ -51,6 +52,7 @@ import (
 const (
 	SYSTEM_VERSION          = byte(1)      //Version of ledger store
 	HEADER_INDEX_BATCH_SIZE = uint32(2000) //Bath size of saving header index
+	PRUNE_BLOCKS_PER_SAVE   = 64           //Max blocks pruned with each saved block, so switching to pruning catches up gradually
 )
 
 var (
@@ -72,13 +74,17 @@ type LedgerStoreImp struct {
 	headerCache          map[common.Uint256]*types.Header //BlockHash => Header
 	headerIndex          map[uint32]common.Uint256        //Header index, Mapping header height => block hash
 	savingBlockSemaphore chan bool
-	vbftPeerInfoheader   map[string]uint32 //pubInfo save pubkey,peerindex
-	vbftPeerInfoblock    map[string]uint32 //pubInfo save pubkey,peerindex
+	vbftPeerInfoheader   map[string]uint32     //pubInfo save pubkey,peerindex
+	vbftPeerInfoblock    map[string]uint32     //pubInfo save pubkey,peerindex
+	vbftBlsPeersheader   []*vconfig.PeerConfig //peers in config order if blocks are bls sealed
+	vbftBlsPeersblock    []*vconfig.PeerConfig //peers in config order if blocks are bls sealed
+	pruneBlocks          uint32                //keep transactions and events of the latest blocks only, 0 keeps all
+	rollbackBlocks       uint32                //keep undo data of the latest blocks for rollback, 0 keeps none
+	eventBus             *stream.EventBus      //typed event stream of saved blocks
 	lock                 sync.RWMutex
 }
 
-//NewLedgerStore return LedgerStoreImp instance
-func NewLedgerStore(dataDir string) (*LedgerStoreImp, error) {
+func newLedgerStore() *LedgerStoreImp {
 	ledgerStore := &LedgerStoreImp{
 		headerIndex:          make(map[uint32]common.Uint256),
 		headerCache:          make(map[common.Uint256]*types.Header, 0),
@@ -86,6 +92,13 @@ func NewLedgerStore(dataDir string) (*LedgerStoreImp, error) {
 		vbftPeerInfoblock:    make(map[string]uint32),
 		savingBlockSemaphore: make(chan bool, 1),
 	}
+	ledgerStore.eventBus = stream.NewEventBus(ledgerStore, stream.DEFAULT_RECENT_EVENTS)
+	return ledgerStore
+}
+
+//NewLedgerStore return LedgerStoreImp instance
+func NewLedgerStore(dataDir string) (*LedgerStoreImp, error) {
+	ledgerStore := newLedgerStore()
 
 	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirBlock), true)
 	if err != nil {
@@ -106,6 +119,28 @@ func NewLedgerStore(dataDir string) (*LedgerStoreImp, error) {
 		return nil, fmt.Errorf("NewEventStore error %s", err)
 	}
 	ledgerStore.eventStore = eventState
+	ledgerStore.pruneBlocks = config.DefConfig.Common.PruneBlocks
+	ledgerStore.rollbackBlocks = config.DefConfig.Common.RollbackBlocks
+
+	return ledgerStore, nil
+}
+
+//NewMemLedgerStore return LedgerStoreImp instance kept in memory, used by in-process test networks
+func NewMemLedgerStore() (*LedgerStoreImp, error) {
+	ledgerStore := newLedgerStore()
+
+	blockStore, err := NewMemBlockStore(true)
+	if err != nil {
+		return nil, fmt.Errorf("NewMemBlockStore error %s", err)
+	}
+	ledgerStore.blockStore = blockStore
+	ledgerStore.stateStore = NewMemStateStore(0)
+
+	eventState, err := NewMemEventStore()
+	if err != nil {
+		return nil, fmt.Errorf("NewMemEventStore error %s", err)
+	}
+	ledgerStore.eventStore = eventState
 
 	return ledgerStore, nil
 }
@@ -174,27 +209,10 @@ func (this *LedgerStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.
 		if err != nil {
 			return err
 		}
-		blkInfo, err := vconfig.VbftBlock(header)
+		cfg, err := vbftChainConfig(header, this.GetHeaderByHeight)
 		if err != nil {
 			return err
 		}
-		var cfg *vconfig.ChainConfig
-		if blkInfo.NewChainConfig != nil {
-			cfg = blkInfo.NewChainConfig
-		} else {
-			cfgHeader, err := this.GetHeaderByHeight(blkInfo.LastConfigBlockNum)
-			if err != nil {
-				return err
-			}
-			Info, err := vconfig.VbftBlock(cfgHeader)
-			if err != nil {
-				return err
-			}
-			if Info.NewChainConfig == nil {
-				return fmt.Errorf("getNewChainConfig error block num:%d", blkInfo.LastConfigBlockNum)
-			}
-			cfg = Info.NewChainConfig
-		}
 		this.lock.Lock()
 		this.vbftPeerInfoheader = make(map[string]uint32)
 		this.vbftPeerInfoblock = make(map[string]uint32)
@@ -202,6 +220,8 @@ func (this *LedgerStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.
 			this.vbftPeerInfoheader[p.ID] = p.Index
 			this.vbftPeerInfoblock[p.ID] = p.Index
 		}
+		this.vbftBlsPeersheader = blsPeers(cfg)
+		this.vbftBlsPeersblock = blsPeers(cfg)
 		this.lock.Unlock()
 	}
 	return err
@@ -296,7 +316,7 @@ func (this *LedgerStoreImp) recoverStore() error {
 		if err != nil {
 			return fmt.Errorf("save to state store height:%d error:%s", i, err)
 		}
-		err = this.saveBlockToEventStore(block)
+		_, err = this.saveBlockToEventStore(block, result.Notify)
 		if err != nil {
 			return fmt.Errorf("save to event store height:%d error:%s", i, err)
 		}
@@ -403,77 +423,164 @@ func (this *LedgerStoreImp) getHeaderCache(blockHash common.Uint256) *types.Head
 	return header
 }
 
-func (this *LedgerStoreImp) verifyHeader(header *types.Header, vbftPeerInfo map[string]uint32) (map[string]uint32, error) {
+//vbftChainConfig return the vbft chain config in effect at header
+func vbftChainConfig(header *types.Header, getHeaderByHeight func(uint32) (*types.Header, error)) (*vconfig.ChainConfig, error) {
+	blkInfo, err := vconfig.VbftBlock(header)
+	if err != nil {
+		return nil, err
+	}
+	if blkInfo.NewChainConfig != nil {
+		return blkInfo.NewChainConfig, nil
+	}
+	cfgHeader, err := getHeaderByHeight(blkInfo.LastConfigBlockNum)
+	if err != nil {
+		return nil, err
+	}
+	info, err := vconfig.VbftBlock(cfgHeader)
+	if err != nil {
+		return nil, err
+	}
+	if info.NewChainConfig == nil {
+		return nil, fmt.Errorf("getNewChainConfig error block num:%d", blkInfo.LastConfigBlockNum)
+	}
+	return info.NewChainConfig, nil
+}
+
+//blsPeers return the peers of chain config if blocks are sealed with bls signatures
+func blsPeers(cfg *vconfig.ChainConfig) []*vconfig.PeerConfig {
+	if !cfg.BlsSeal() {
+		return nil
+	}
+	return cfg.Peers
+}
+
+func (this *LedgerStoreImp) verifyHeader(header *types.Header, vbftPeerInfo map[string]uint32,
+	vbftBlsPeers []*vconfig.PeerConfig) (map[string]uint32, []*vconfig.PeerConfig, error) {
 	if header.Height == 0 {
-		return vbftPeerInfo, nil
+		return vbftPeerInfo, vbftBlsPeers, nil
 	}
 	var prevHeader *types.Header
 	prevHeaderHash := header.PrevBlockHash
 	prevHeader, err := this.GetHeaderByHash(prevHeaderHash)
 	if err != nil && err != scom.ErrNotFound {
-		return vbftPeerInfo, fmt.Errorf("get prev header error %s", err)
+		return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("get prev header error %s", err)
 	}
 	if prevHeader == nil {
-		return vbftPeerInfo, fmt.Errorf("cannot find pre header by blockHash %s", prevHeaderHash.ToHexString())
+		return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("cannot find pre header by blockHash %s", prevHeaderHash.ToHexString())
 	}
+	return verifyHeaderWithPrev(prevHeader, header, vbftPeerInfo, vbftBlsPeers)
+}
 
+//verifyHeaderWithPrev check header against its previous header and the bookkeepers in effect,
+//return the bookkeepers for the next header
+func verifyHeaderWithPrev(prevHeader, header *types.Header, vbftPeerInfo map[string]uint32,
+	vbftBlsPeers []*vconfig.PeerConfig) (map[string]uint32, []*vconfig.PeerConfig, error) {
 	if prevHeader.Height+1 != header.Height {
-		return vbftPeerInfo, fmt.Errorf("block height is incorrect")
+		return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("block height is incorrect")
 	}
 
 	if prevHeader.Timestamp >= header.Timestamp {
-		return vbftPeerInfo, fmt.Errorf("block timestamp is incorrect")
+		return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("block timestamp is incorrect")
 	}
 	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
 	if consensusType == "vbft" {
-		//check bookkeeppers
-		m := len(vbftPeerInfo) - (len(vbftPeerInfo)-1)/3
-		if len(header.Bookkeepers) < m {
-			return vbftPeerInfo, fmt.Errorf("header Bookkeepers %d more than 2/3 len vbftPeerInfo%d", len(header.Bookkeepers), len(vbftPeerInfo))
-		}
-		for _, bookkeeper := range header.Bookkeepers {
-			pubkey := vconfig.PubkeyID(bookkeeper)
-			_, present := vbftPeerInfo[pubkey]
-			if !present {
-				log.Errorf("invalid pubkey :%v,height:%d", pubkey, header.Height)
-				return vbftPeerInfo, fmt.Errorf("invalid pubkey :%v", pubkey)
+		if vconfig.IsBlsSealed(header) {
+			//check bls aggregate signature
+			if vbftBlsPeers == nil {
+				return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("bls sealed header while chain config not bls sealed")
+			}
+			if err := vconfig.VerifyBlsHeader(header, vbftBlsPeers); err != nil {
+				log.Errorf("VerifyBlsHeader:%s,heigh:%d", err, header.Height)
+				return vbftPeerInfo, vbftBlsPeers, err
+			}
+		} else {
+			//check bookkeeppers
+			m := len(vbftPeerInfo) - (len(vbftPeerInfo)-1)/3
+			if len(header.Bookkeepers) < m {
+				return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("header Bookkeepers %d more than 2/3 len vbftPeerInfo%d", len(header.Bookkeepers), len(vbftPeerInfo))
+			}
+			for _, bookkeeper := range header.Bookkeepers {
+				pubkey := vconfig.PubkeyID(bookkeeper)
+				_, present := vbftPeerInfo[pubkey]
+				if !present {
+					log.Errorf("invalid pubkey :%v,height:%d", pubkey, header.Height)
+					return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("invalid pubkey :%v", pubkey)
+				}
+			}
+			hash := header.Hash()
+			err := signature.VerifyMultiSignature(hash[:], header.Bookkeepers, m, header.SigData)
+			if err != nil {
+				log.Errorf("VerifyMultiSignature:%s,Bookkeepers:%d,pubkey:%d,heigh:%d", err, len(header.Bookkeepers), len(vbftPeerInfo), header.Height)
+				return vbftPeerInfo, vbftBlsPeers, err
 			}
-		}
-		hash := header.Hash()
-		err = signature.VerifyMultiSignature(hash[:], header.Bookkeepers, m, header.SigData)
-		if err != nil {
-			log.Errorf("VerifyMultiSignature:%s,Bookkeepers:%d,pubkey:%d,heigh:%d", err, len(header.Bookkeepers), len(vbftPeerInfo), header.Height)
-			return vbftPeerInfo, err
 		}
 		blkInfo, err := vconfig.VbftBlock(header)
 		if err != nil {
-			return vbftPeerInfo, err
+			return vbftPeerInfo, vbftBlsPeers, err
 		}
 		if blkInfo.NewChainConfig != nil {
 			peerInfo := make(map[string]uint32)
 			for _, p := range blkInfo.NewChainConfig.Peers {
 				peerInfo[p.ID] = p.Index
 			}
-			return peerInfo, nil
+			return peerInfo, blsPeers(blkInfo.NewChainConfig), nil
 		}
-		return vbftPeerInfo, nil
+		return vbftPeerInfo, vbftBlsPeers, nil
 	} else {
 		address, err := types.AddressFromBookkeepers(header.Bookkeepers)
 		if err != nil {
-			return vbftPeerInfo, err
+			return vbftPeerInfo, vbftBlsPeers, err
 		}
 		if prevHeader.NextBookkeeper != address {
-			return vbftPeerInfo, fmt.Errorf("bookkeeper address error")
+			return vbftPeerInfo, vbftBlsPeers, fmt.Errorf("bookkeeper address error")
 		}
 
 		m := len(header.Bookkeepers) - (len(header.Bookkeepers)-1)/3
 		hash := header.Hash()
 		err = signature.VerifyMultiSignature(hash[:], header.Bookkeepers, m, header.SigData)
 		if err != nil {
-			return vbftPeerInfo, err
+			return vbftPeerInfo, vbftBlsPeers, err
 		}
 	}
-	return vbftPeerInfo, nil
+	return vbftPeerInfo, vbftBlsPeers, nil
+}
+
+//HeaderVerifier check headers following a verified header by the bookkeepers in effect, nothing is saved
+type HeaderVerifier struct {
+	prevHeader   *types.Header
+	vbftPeerInfo map[string]uint32
+	vbftBlsPeers []*vconfig.PeerConfig
+}
+
+//NewHeaderVerifier return HeaderVerifier of headers following header, getHeaderByHeight loads the block
+//of chain config in effect
+func NewHeaderVerifier(header *types.Header, getHeaderByHeight func(uint32) (*types.Header, error)) (*HeaderVerifier, error) {
+	verifier := &HeaderVerifier{prevHeader: header}
+	if strings.ToLower(config.DefConfig.Genesis.ConsensusType) == "vbft" {
+		cfg, err := vbftChainConfig(header, getHeaderByHeight)
+		if err != nil {
+			return nil, fmt.Errorf("vbftChainConfig error %s", err)
+		}
+		verifier.vbftPeerInfo = make(map[string]uint32)
+		for _, p := range cfg.Peers {
+			verifier.vbftPeerInfo[p.ID] = p.Index
+		}
+		verifier.vbftBlsPeers = blsPeers(cfg)
+	}
+	return verifier, nil
+}
+
+//Verify check header follows the previous one and is signed by the bookkeepers in effect
+func (this *HeaderVerifier) Verify(header *types.Header) error {
+	if prevHash := this.prevHeader.Hash(); header.PrevBlockHash != prevHash {
+		return fmt.Errorf("prev block hash %s mismatch %s", header.PrevBlockHash.ToHexString(), prevHash.ToHexString())
+	}
+	peerInfo, blsPeers, err := verifyHeaderWithPrev(this.prevHeader, header, this.vbftPeerInfo, this.vbftBlsPeers)
+	if err != nil {
+		return err
+	}
+	this.prevHeader, this.vbftPeerInfo, this.vbftBlsPeers = header, peerInfo, blsPeers
+	return nil
 }
 
 //AddHeader add header to cache, and add the mapping of block height to block hash. Using in block sync
@@ -483,7 +590,7 @@ func (this *LedgerStoreImp) AddHeader(header *types.Header) error {
 		return fmt.Errorf("header height %d not equal next header height %d", header.Height, nextHeaderHeight)
 	}
 	var err error
-	this.vbftPeerInfoheader, err = this.verifyHeader(header, this.vbftPeerInfoheader)
+	this.vbftPeerInfoheader, this.vbftBlsPeersheader, err = this.verifyHeader(header, this.vbftPeerInfoheader, this.vbftBlsPeersheader)
 	if err != nil {
 		return fmt.Errorf("verifyHeader error %s", err)
 	}
@@ -533,6 +640,38 @@ func (this *LedgerStoreImp) ExecuteBlock(block *types.Block) (result store.Execu
 	return
 }
 
+//ExecuteBlockOnParent execute block on the write set of its parent block, so that the execution
+//overlaps with submitting the parent. Only the merkle root calculation waits for the submitting.
+func (this *LedgerStoreImp) ExecuteBlockOnParent(block *types.Block, parent store.ExecuteResult) (result store.ExecuteResult, err error) {
+	blockHeight := block.Header.Height
+	if blockHeight <= this.GetCurrentBlockHeight()+1 {
+		//parent already submitted
+		return this.ExecuteBlock(block)
+	}
+	if blockHeight > this.GetCurrentBlockHeight()+2 {
+		err = fmt.Errorf("block height %d is beyond next pending block height %d", blockHeight, this.GetCurrentBlockHeight()+1)
+		return
+	}
+
+	overlay := overlaydb.NewOverlayDB(overlaydb.NewPendingStore(parent.WriteSet, this.stateStore.store))
+	result, err = this.executeTransactions(overlay, block)
+	if err != nil {
+		return
+	}
+
+	this.getSavingBlockLock()
+	defer this.releaseSavingBlockLock()
+	switch this.GetCurrentBlockHeight() {
+	case blockHeight - 1:
+		result.MerkleRoot = this.stateStore.GetStateMerkleRootWithNewHash(result.Hash)
+	case blockHeight - 2:
+		result.MerkleRoot = this.stateStore.GetStateMerkleRootWithNewHashes([]common.Uint256{parent.Hash, result.Hash})
+	default:
+		err = fmt.Errorf("block height %d not equal next block height %d", blockHeight, this.GetCurrentBlockHeight()+1)
+	}
+	return
+}
+
 func (this *LedgerStoreImp) SubmitBlock(block *types.Block, result store.ExecuteResult) error {
 	this.getSavingBlockLock()
 	defer this.releaseSavingBlockLock()
@@ -546,7 +685,7 @@ func (this *LedgerStoreImp) SubmitBlock(block *types.Block, result store.Execute
 		return fmt.Errorf("block height %d not equal next block height %d", blockHeight, nextBlockHeight)
 	}
 	var err error
-	this.vbftPeerInfoblock, err = this.verifyHeader(block.Header, this.vbftPeerInfoblock)
+	this.vbftPeerInfoblock, this.vbftBlsPeersblock, err = this.verifyHeader(block.Header, this.vbftPeerInfoblock, this.vbftBlsPeersblock)
 	if err != nil {
 		return fmt.Errorf("verifyHeader error %s", err)
 	}
@@ -572,7 +711,7 @@ func (this *LedgerStoreImp) AddBlock(block *types.Block, stateMerkleRoot common.
 		return fmt.Errorf("block height %d not equal next block height %d", blockHeight, nextBlockHeight)
 	}
 	var err error
-	this.vbftPeerInfoblock, err = this.verifyHeader(block.Header, this.vbftPeerInfoblock)
+	this.vbftPeerInfoblock, this.vbftBlsPeersblock, err = this.verifyHeader(block.Header, this.vbftPeerInfoblock, this.vbftBlsPeersblock)
 	if err != nil {
 		return fmt.Errorf("verifyHeader error %s", err)
 	}
@@ -624,7 +763,16 @@ func (this *LedgerStoreImp) saveBlockToBlockStore(block *types.Block) error {
 
 func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.ExecuteResult, err error) {
 	overlay := this.stateStore.NewOverlayDB()
+	result, err = this.executeTransactions(overlay, block)
+	if err != nil {
+		return
+	}
+	result.MerkleRoot = this.stateStore.GetStateMerkleRootWithNewHash(result.Hash)
+	return
+}
 
+//executeTransactions execute transactions of block on overlay, the merkle root is left to caller
+func (this *LedgerStoreImp) executeTransactions(overlay *overlaydb.OverlayDB, block *types.Block) (result store.ExecuteResult, err error) {
 	cache := storage.NewCacheDB(overlay)
 	for _, tx := range block.Transactions {
 		cache.Reset()
@@ -643,7 +791,6 @@ func (this *LedgerStoreImp) executeBlock(block *types.Block) (result store.Execu
 	}
 	result.Hash = overlay.ChangeHash()
 	result.WriteSet = overlay.GetWriteSet()
-	result.MerkleRoot = this.stateStore.GetStateMerkleRootWithNewHash(result.Hash)
 	return
 }
 
@@ -658,6 +805,16 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 		}
 	}
 
+	if this.rollbackBlocks > 0 {
+		err := this.stateStore.SaveUndo(blockHeight, result.WriteSet)
+		if err != nil {
+			return fmt.Errorf("SaveUndo error %s", err)
+		}
+		if blockHeight >= this.rollbackBlocks {
+			this.stateStore.DeleteUndo(blockHeight - this.rollbackBlocks)
+		}
+	}
+
 	err := this.stateStore.AddStateMerkleTreeRoot(blockHeight, result.Hash)
 	if err != nil {
 		return fmt.Errorf("AddBlockMerkleTreeRoot error %s", err)
@@ -691,7 +848,8 @@ func (this *LedgerStoreImp) saveBlockToStateStore(block *types.Block, result sto
 	return nil
 }
 
-func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) error {
+//saveBlockToEventStore return seq of the first stream event of block
+func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block, notifies []*event.ExecuteNotify) (uint64, error) {
 	blockHash := block.Hash()
 	blockHeight := block.Header.Height
 	txs := make([]common.Uint256, 0)
@@ -702,12 +860,55 @@ func (this *LedgerStoreImp) saveBlockToEventStore(block *types.Block) error {
 	if len(txs) > 0 {
 		err := this.eventStore.SaveEventNotifyByBlock(block.Header.Height, txs)
 		if err != nil {
-			return fmt.Errorf("SaveEventNotifyByBlock error %s", err)
+			return 0, fmt.Errorf("SaveEventNotifyByBlock error %s", err)
 		}
 	}
-	err := this.eventStore.SaveCurrentBlock(blockHeight, blockHash)
+	streamSeq, err := this.nextStreamSeq(blockHeight)
 	if err != nil {
-		return fmt.Errorf("SaveCurrentBlock error %s", err)
+		return 0, fmt.Errorf("nextStreamSeq error %s", err)
+	}
+	this.eventStore.SaveStreamSeq(blockHeight, streamSeq, uint32(len(notifies))+1)
+	err = this.eventStore.SaveCurrentBlock(blockHeight, blockHash)
+	if err != nil {
+		return 0, fmt.Errorf("SaveCurrentBlock error %s", err)
+	}
+	return streamSeq, nil
+}
+
+//nextStreamSeq return seq following events of the previous block. Stream of ledger saved before
+//the stream recorded starts from 0 at the first block saved, whose height is kept as the stream start
+func (this *LedgerStoreImp) nextStreamSeq(height uint32) (uint64, error) {
+	if height == 0 {
+		this.eventStore.SaveStreamStartHeight(height)
+		return 0, nil
+	}
+	seq, count, err := this.eventStore.GetStreamSeq(height - 1)
+	if err == scom.ErrNotFound {
+		this.eventStore.SaveStreamStartHeight(height)
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return seq + uint64(count), nil
+}
+
+//pruneStore drop transactions and events of blocks out of the prune window in the current batches.
+//Headers, header index, merkle trees and cross state roots of all blocks are kept
+func (this *LedgerStoreImp) pruneStore(blockHeight uint32) error {
+	if this.pruneBlocks == 0 || blockHeight < this.pruneBlocks {
+		return nil
+	}
+	end := blockHeight - this.pruneBlocks
+	for h, n := this.blockStore.GetPrunedHeight(), 0; h <= end && n < PRUNE_BLOCKS_PER_SAVE; h, n = h+1, n+1 {
+		if err := this.blockStore.PruneBlock(h); err != nil {
+			return fmt.Errorf("blockStore.PruneBlock height:%d error:%s", h, err)
+		}
+	}
+	for h, n := this.eventStore.GetPrunedHeight(), 0; h <= end && n < PRUNE_BLOCKS_PER_SAVE; h, n = h+1, n+1 {
+		if err := this.eventStore.PruneBlock(h); err != nil {
+			return fmt.Errorf("eventStore.PruneBlock height:%d error:%s", h, err)
+		}
 	}
 	return nil
 }
@@ -755,10 +956,14 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 	if err != nil {
 		return fmt.Errorf("save to state store height:%d error:%s", blockHeight, err)
 	}
-	err = this.saveBlockToEventStore(block)
+	streamSeq, err := this.saveBlockToEventStore(block, result.Notify)
 	if err != nil {
 		return fmt.Errorf("save to event store height:%d error:%s", blockHeight, err)
 	}
+	err = this.pruneStore(blockHeight)
+	if err != nil {
+		return fmt.Errorf("prune store height:%d error:%s", blockHeight, err)
+	}
 	err = this.blockStore.CommitTo()
 	if err != nil {
 		return fmt.Errorf("blockStore.CommitTo height:%d error %s", blockHeight, err)
@@ -774,6 +979,7 @@ func (this *LedgerStoreImp) submitBlock(block *types.Block, result store.Execute
 	}
 	this.setCurrentBlock(blockHeight, blockHash)
 
+	this.eventBus.Publish(stream.NewBlockEvents(streamSeq, block, result.Notify))
 	if events.DefActorPublisher != nil {
 		events.DefActorPublisher.Publish(
 			message.TOPIC_SAVE_BLOCK_COMPLETE,
@@ -817,7 +1023,8 @@ func (this *LedgerStoreImp) handleTransaction(overlay *overlaydb.OverlayDB, cach
 			return nil, nil, fmt.Errorf("HandleInvokeTransaction tx %s error %s", txHash.ToHexString(), overlay.Error())
 		}
 		if err != nil {
-			log.Debugf("HandleInvokeTransaction tx %s error %s", txHash.ToHexString(), err)
+			log.Module("core/store/ledgerstore").With(log.Fields{"height": block.Header.Height, "txHash": txHash.ToHexString()}).
+				Debugf("HandleInvokeTransaction error %s", err)
 		}
 		return notify, crossHashes, nil
 	} else {
@@ -963,7 +1170,14 @@ func (this *LedgerStoreImp) GetStorageItem(key *states.StorageKey) (*states.Stor
 
 //GetEventNotifyByTx return the events notify gen by executing of smart contract.  Wrap function of EventStore.GetEventNotifyByTx
 func (this *LedgerStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
-	return this.eventStore.GetEventNotifyByTx(tx)
+	notify, err := this.eventStore.GetEventNotifyByTx(tx)
+	if err == scom.ErrNotFound {
+		//notify of pruned transaction is pruned too
+		if _, _, txErr := this.blockStore.GetTransaction(tx); txErr == scom.ErrPruned {
+			return nil, scom.ErrPruned
+		}
+	}
+	return notify, err
 }
 
 //GetEventNotifyByBlock return the transaction hash which have event notice after execution of smart contract. Wrap function of EventStore.GetEventNotifyByBlock
@@ -971,8 +1185,55 @@ func (this *LedgerStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.Execu
 	return this.eventStore.GetEventNotifyByBlock(height)
 }
 
+//GetStreamEvents return the stream events of block at height, nil if the block is not saved yet.
+//Return stream.NotIndexedError if the block is saved before the stream recorded or its events are pruned
+func (this *LedgerStoreImp) GetStreamEvents(height uint32) ([]*stream.Event, error) {
+	currentHeight := this.GetCurrentBlockHeight()
+	if height > currentHeight || this.GetCurrentBlockHash() == common.UINT256_EMPTY {
+		return nil, nil
+	}
+	prunedHeight := this.eventStore.GetPrunedHeight()
+	seq, count, err := this.eventStore.GetStreamSeq(height)
+	if err == scom.ErrNotFound || (err == nil && height < prunedHeight) {
+		start, err := this.eventStore.GetStreamStartHeight()
+		if err == scom.ErrNotFound {
+			start = currentHeight + 1
+		} else if err != nil {
+			return nil, fmt.Errorf("GetStreamStartHeight error %s", err)
+		}
+		if start < prunedHeight {
+			start = prunedHeight
+		}
+		return nil, &stream.NotIndexedError{Height: height, Start: start}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetStreamSeq height:%d error %s", height, err)
+	}
+	block, err := this.GetBlockByHeight(height)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockByHeight height:%d error %s", height, err)
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block of height:%d not found", height)
+	}
+	notifies, err := this.eventStore.GetEventNotifyByBlock(height)
+	if err != nil && err != scom.ErrNotFound {
+		return nil, fmt.Errorf("GetEventNotifyByBlock height:%d error %s", height, err)
+	}
+	if uint32(len(notifies))+1 != count {
+		return nil, fmt.Errorf("stream events of height:%d count %d mismatch %d", height, len(notifies)+1, count)
+	}
+	return stream.NewBlockEvents(seq, block, notifies), nil
+}
+
+//SubscribeEvents return subscription of the stream events from the block at height
+func (this *LedgerStoreImp) SubscribeEvents(height uint32, bufSize int) (*stream.Subscription, error) {
+	return this.eventBus.Subscribe(height, bufSize), nil
+}
+
 //Close ledger store.
 func (this *LedgerStoreImp) Close() error {
+	this.eventBus.Close()
 	err := this.blockStore.Close()
 	if err != nil {
 		return fmt.Errorf("blockStore close error %s", err)
@@ -0,0 +1,464 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/events/stream"
+	"github.com/polynetwork/poly/merkle"
+	"github.com/polynetwork/poly/native/event"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	cstates "github.com/polynetwork/poly/native/states"
+)
+
+var (
+	//Storage save path of light node
+	DBDirLight = "lightheader"
+)
+
+//ErrLightNode is returned for data which light node does not keep
+var ErrLightNode = errors.New("not supported by light node")
+
+//ProofFetcher fetch proofs from full nodes for the light store. verify is called on every
+//candidate proof, fetcher should try another source when verify fails
+type ProofFetcher interface {
+	//FetchMerkleProof fetch the proof of block hash at proofHeight in block root of rootHeight
+	FetchMerkleProof(proofHeight, rootHeight uint32, verify func(proof []byte) error) ([]byte, error)
+	//FetchCrossStatesProof fetch the proof of key in cross states of height
+	FetchCrossStatesProof(height uint32, key []byte, verify func(proof []byte) error) ([]byte, error)
+}
+
+//LightStoreImp is the ledger store of light node. It only keeps verified headers and fetches
+//proofs from full nodes on demand, so current block is always the current header
+type LightStoreImp struct {
+	blockStore   *BlockStore           //BlockStore for saving headers
+	currHeight   uint32                //Current header height
+	currHash     common.Uint256        //Current header hash
+	vbftPeerInfo map[string]uint32     //pubInfo save pubkey,peerindex
+	vbftBlsPeers []*vconfig.PeerConfig //peers in config order if blocks are bls sealed
+	fetcher      ProofFetcher
+	lock         sync.RWMutex
+}
+
+//NewLightStore return LightStoreImp instance saving headers in dataDir
+func NewLightStore(dataDir string) (*LightStoreImp, error) {
+	blockStore, err := NewBlockStore(fmt.Sprintf("%s%s%s", dataDir, string(os.PathSeparator), DBDirLight), false)
+	if err != nil {
+		return nil, fmt.Errorf("NewBlockStore error %s", err)
+	}
+	return &LightStoreImp{
+		blockStore:   blockStore,
+		vbftPeerInfo: make(map[string]uint32),
+	}, nil
+}
+
+//NewMemLightStore return LightStoreImp instance kept in memory
+func NewMemLightStore() (*LightStoreImp, error) {
+	blockStore, err := NewMemBlockStore(false)
+	if err != nil {
+		return nil, fmt.Errorf("NewMemBlockStore error %s", err)
+	}
+	return &LightStoreImp{
+		blockStore:   blockStore,
+		vbftPeerInfo: make(map[string]uint32),
+	}, nil
+}
+
+//SetProofFetcher set the source of proofs which light store cannot build itself
+func (this *LightStoreImp) SetProofFetcher(fetcher ProofFetcher) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.fetcher = fetcher
+}
+
+func (this *LightStoreImp) getProofFetcher() ProofFetcher {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.fetcher
+}
+
+//InitLedgerStoreWithGenesisBlock save the genesis header, or load the current header if already saved
+func (this *LightStoreImp) InitLedgerStoreWithGenesisBlock(genesisBlock *types.Block, defaultBookkeeper []keypair.PublicKey) error {
+	genesisHash := genesisBlock.Hash()
+	_, err := this.blockStore.GetVersion()
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("GetVersion error %s", err)
+	}
+	if err == scom.ErrNotFound {
+		err = this.blockStore.ClearAll()
+		if err != nil {
+			return fmt.Errorf("blockStore.ClearAll error %s", err)
+		}
+		this.blockStore.NewBatch()
+		err = this.saveHeader(genesisBlock.Header)
+		if err != nil {
+			return fmt.Errorf("save genesis header error %s", err)
+		}
+		err = this.blockStore.SaveVersion(SYSTEM_VERSION)
+		if err != nil {
+			return fmt.Errorf("SaveVersion error %s", err)
+		}
+		log.Infof("GenesisBlock init success. GenesisBlock hash:%s\n", genesisHash.ToHexString())
+	} else {
+		header, err := this.blockStore.GetHeader(genesisHash)
+		if err != nil || header == nil {
+			return fmt.Errorf("GenesisBlock arenot init correctly")
+		}
+		currHash, currHeight, err := this.blockStore.GetCurrentBlock()
+		if err != nil {
+			return fmt.Errorf("LoadCurrentBlock error %s", err)
+		}
+		log.Infof("InitCurrentHeader currentHeaderHash %s currentHeaderHeight %d", currHash.ToHexString(), currHeight)
+		this.lock.Lock()
+		this.currHash = currHash
+		this.currHeight = currHeight
+		this.lock.Unlock()
+	}
+	//load vbft peerInfo
+	consensusType := strings.ToLower(config.DefConfig.Genesis.ConsensusType)
+	if consensusType == "vbft" {
+		header, err := this.GetHeaderByHash(this.GetCurrentHeaderHash())
+		if err != nil {
+			return err
+		}
+		cfg, err := vbftChainConfig(header, this.GetHeaderByHeight)
+		if err != nil {
+			return err
+		}
+		this.lock.Lock()
+		this.vbftPeerInfo = make(map[string]uint32)
+		for _, p := range cfg.Peers {
+			this.vbftPeerInfo[p.ID] = p.Index
+		}
+		this.vbftBlsPeers = blsPeers(cfg)
+		this.lock.Unlock()
+	}
+	return nil
+}
+
+//saveHeader persist header and make it the current one. blockStore batch must be started
+func (this *LightStoreImp) saveHeader(header *types.Header) error {
+	blockHash := header.Hash()
+	err := this.blockStore.SaveHeader(&types.Block{Header: header})
+	if err != nil {
+		return err
+	}
+	this.blockStore.SaveBlockHash(header.Height, blockHash)
+	err = this.blockStore.SaveCurrentBlock(header.Height, blockHash)
+	if err != nil {
+		return err
+	}
+	err = this.blockStore.CommitTo()
+	if err != nil {
+		return err
+	}
+	this.lock.Lock()
+	this.currHeight = header.Height
+	this.currHash = blockHash
+	this.lock.Unlock()
+	return nil
+}
+
+//AddHeader verify header by the bookkeepers in effect and persist it
+func (this *LightStoreImp) AddHeader(header *types.Header) error {
+	if header.Height != this.GetCurrentHeaderHeight()+1 {
+		return nil
+	}
+	prevHeader, err := this.GetHeaderByHash(header.PrevBlockHash)
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("get prev header error %s", err)
+	}
+	if prevHeader == nil {
+		return fmt.Errorf("cannot find pre header by blockHash %s", header.PrevBlockHash.ToHexString())
+	}
+	this.lock.RLock()
+	peerInfo, blsPeers := this.vbftPeerInfo, this.vbftBlsPeers
+	this.lock.RUnlock()
+	peerInfo, blsPeers, err = verifyHeaderWithPrev(prevHeader, header, peerInfo, blsPeers)
+	if err != nil {
+		return err
+	}
+	this.blockStore.NewBatch()
+	err = this.saveHeader(header)
+	if err != nil {
+		return fmt.Errorf("saveHeader error %s", err)
+	}
+	this.lock.Lock()
+	this.vbftPeerInfo, this.vbftBlsPeers = peerInfo, blsPeers
+	this.lock.Unlock()
+	return nil
+}
+
+//AddHeaders bath add header.
+func (this *LightStoreImp) AddHeaders(headers []*types.Header) error {
+	for _, header := range headers {
+		err := this.AddHeader(header)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//AddBlock is not supported by light node
+func (this *LightStoreImp) AddBlock(block *types.Block, stateMerkleRoot common.Uint256) error {
+	return ErrLightNode
+}
+
+//ExecuteBlock is not supported by light node
+func (this *LightStoreImp) ExecuteBlock(b *types.Block) (store.ExecuteResult, error) {
+	return store.ExecuteResult{}, ErrLightNode
+}
+
+//SubmitBlock is not supported by light node
+func (this *LightStoreImp) SubmitBlock(b *types.Block, exec store.ExecuteResult) error {
+	return ErrLightNode
+}
+
+//ExecuteBlockOnParent is not supported by light node
+func (this *LightStoreImp) ExecuteBlockOnParent(b *types.Block, parent store.ExecuteResult) (store.ExecuteResult, error) {
+	return store.ExecuteResult{}, ErrLightNode
+}
+
+//GetStateMerkleRoot is not supported by light node
+func (this *LightStoreImp) GetStateMerkleRoot(height uint32) (common.Uint256, error) {
+	return common.UINT256_EMPTY, ErrLightNode
+}
+
+//GetCrossStateRoot return the cross state root of height, which is committed in the header of next height
+func (this *LightStoreImp) GetCrossStateRoot(height uint32) (common.Uint256, error) {
+	header, err := this.GetHeaderByHeight(height + 1)
+	if err != nil {
+		return common.UINT256_EMPTY, err
+	}
+	if header == nil {
+		return common.UINT256_EMPTY, fmt.Errorf("header of height %d not synced", height+1)
+	}
+	return header.CrossStateRoot, nil
+}
+
+//GetCurrentBlockHash return the current header hash
+func (this *LightStoreImp) GetCurrentBlockHash() common.Uint256 {
+	return this.GetCurrentHeaderHash()
+}
+
+//GetCurrentBlockHeight return the current header height
+func (this *LightStoreImp) GetCurrentBlockHeight() uint32 {
+	return this.GetCurrentHeaderHeight()
+}
+
+//GetCurrentHeaderHeight return the current header height
+func (this *LightStoreImp) GetCurrentHeaderHeight() uint32 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.currHeight
+}
+
+//GetCurrentHeaderHash return the current header hash
+func (this *LightStoreImp) GetCurrentHeaderHash() common.Uint256 {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.currHash
+}
+
+//GetBlockHash return the block hash by block height
+func (this *LightStoreImp) GetBlockHash(height uint32) common.Uint256 {
+	blockHash, err := this.blockStore.GetBlockHash(height)
+	if err != nil {
+		return common.UINT256_EMPTY
+	}
+	return blockHash
+}
+
+//GetHeaderByHash return the block header by block hash
+func (this *LightStoreImp) GetHeaderByHash(blockHash common.Uint256) (*types.Header, error) {
+	return this.blockStore.GetHeader(blockHash)
+}
+
+//GetHeaderByHeight return the block header by block height
+func (this *LightStoreImp) GetHeaderByHeight(height uint32) (*types.Header, error) {
+	blockHash := this.GetBlockHash(height)
+	if blockHash == common.UINT256_EMPTY {
+		return nil, nil
+	}
+	return this.GetHeaderByHash(blockHash)
+}
+
+//GetBlockByHash is not supported by light node
+func (this *LightStoreImp) GetBlockByHash(blockHash common.Uint256) (*types.Block, error) {
+	return nil, ErrLightNode
+}
+
+//GetBlockByHeight is not supported by light node
+func (this *LightStoreImp) GetBlockByHeight(height uint32) (*types.Block, error) {
+	return nil, ErrLightNode
+}
+
+//GetTransaction is not supported by light node
+func (this *LightStoreImp) GetTransaction(txHash common.Uint256) (*types.Transaction, uint32, error) {
+	return nil, 0, ErrLightNode
+}
+
+//IsContainBlock return whether the header of block is in store
+func (this *LightStoreImp) IsContainBlock(blockHash common.Uint256) (bool, error) {
+	header, err := this.blockStore.GetHeader(blockHash)
+	if err == scom.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return header != nil, nil
+}
+
+//IsContainTransaction is not supported by light node
+func (this *LightStoreImp) IsContainTransaction(txHash common.Uint256) (bool, error) {
+	return false, ErrLightNode
+}
+
+//GetBlockRootWithPreBlockHashes is not supported by light node
+func (this *LightStoreImp) GetBlockRootWithPreBlockHashes(startHeight uint32, txRoots []common.Uint256) common.Uint256 {
+	return common.UINT256_EMPTY
+}
+
+//GetMerkleProof fetch the proof of raw at leaf proofHeight in block root of rootHeight,
+//and verify it against the synced header
+func (this *LightStoreImp) GetMerkleProof(raw []byte, proofHeight, rootHeight uint32) ([]byte, error) {
+	if proofHeight == 0 || proofHeight > rootHeight {
+		return nil, fmt.Errorf("wrong parameters")
+	}
+	header, err := this.GetHeaderByHeight(rootHeight)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		return nil, fmt.Errorf("header of height %d not synced", rootHeight)
+	}
+	fetcher := this.getProofFetcher()
+	if fetcher == nil {
+		return nil, fmt.Errorf("no proof fetcher")
+	}
+	root := header.BlockRoot
+	return fetcher.FetchMerkleProof(proofHeight-1, rootHeight, func(proof []byte) error {
+		return verifyMerkleProof(proof, root, raw)
+	})
+}
+
+//GetCrossStatesProof fetch the proof of key in cross states of height, and verify it against
+//the cross state root in synced header and the key of the proved request
+func (this *LightStoreImp) GetCrossStatesProof(height uint32, key []byte) ([]byte, error) {
+	root, err := this.GetCrossStateRoot(height)
+	if err != nil {
+		return nil, err
+	}
+	fetcher := this.getProofFetcher()
+	if fetcher == nil {
+		return nil, fmt.Errorf("no proof fetcher")
+	}
+	return fetcher.FetchCrossStatesProof(height, key, func(proof []byte) error {
+		return verifyCrossStatesProof(proof, root, key)
+	})
+}
+
+//verifyCrossStatesProof check proof lead to root, and the proved leaf is the cross chain request stored at key
+func verifyCrossStatesProof(proof []byte, root common.Uint256, key []byte) error {
+	leaf, err := merkle.MerkleProve(proof, root[:])
+	if err != nil {
+		return err
+	}
+	value := new(ccmcom.ToMerkleValue)
+	if err := value.Deserialization(common.NewZeroCopySource(leaf)); err != nil {
+		return fmt.Errorf("proved value is not cross chain request: %s", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	value.Serialization(sink)
+	if !bytes.Equal(sink.Bytes(), leaf) {
+		return fmt.Errorf("proved value %x has trailing bytes", leaf)
+	}
+	//request is stored by cross chain manager under its target chain and tx hash
+	expected := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ccmcom.REQUEST),
+		utils.GetUint64Bytes(value.MakeTxParam.ToChainID), value.TxHash)
+	if !bytes.Equal(expected, key) {
+		return fmt.Errorf("proved request key %x mismatch %x", expected, key)
+	}
+	return nil
+}
+
+//verifyMerkleProof check proof lead to root, and if value is not nil, the proved leaf is value
+func verifyMerkleProof(proof []byte, root common.Uint256, value []byte) error {
+	leaf, err := merkle.MerkleProve(proof, root[:])
+	if err != nil {
+		return err
+	}
+	if value != nil && !bytes.Equal(leaf, value) {
+		return fmt.Errorf("proved value %x mismatch %x", leaf, value)
+	}
+	return nil
+}
+
+//GetBookkeeperState is not supported by light node
+func (this *LightStoreImp) GetBookkeeperState() (*states.BookkeeperState, error) {
+	return nil, ErrLightNode
+}
+
+//GetStorageItem is not supported by light node
+func (this *LightStoreImp) GetStorageItem(key *states.StorageKey) (*states.StorageItem, error) {
+	return nil, ErrLightNode
+}
+
+//PreExecuteContract is not supported by light node
+func (this *LightStoreImp) PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error) {
+	return nil, ErrLightNode
+}
+
+//GetEventNotifyByTx is not supported by light node
+func (this *LightStoreImp) GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error) {
+	return nil, ErrLightNode
+}
+
+//GetEventNotifyByBlock is not supported by light node
+func (this *LightStoreImp) GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error) {
+	return nil, ErrLightNode
+}
+
+//SubscribeEvents is not supported by light node
+func (this *LightStoreImp) SubscribeEvents(height uint32, bufSize int) (*stream.Subscription, error) {
+	return nil, ErrLightNode
+}
+
+//Close light store
+func (this *LightStoreImp) Close() error {
+	return this.blockStore.Close()
+}
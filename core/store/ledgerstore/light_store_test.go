@@ -0,0 +1,231 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+	ccmcom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type stubProofFetcher struct {
+	proofs [][]byte
+	tried  int
+}
+
+func (this *stubProofFetcher) fetch(verify func(proof []byte) error) ([]byte, error) {
+	for _, proof := range this.proofs {
+		this.tried++
+		if err := verify(proof); err == nil {
+			return proof, nil
+		}
+	}
+	return nil, fmt.Errorf("no valid proof")
+}
+
+func (this *stubProofFetcher) FetchMerkleProof(proofHeight, rootHeight uint32, verify func(proof []byte) error) ([]byte, error) {
+	return this.fetch(verify)
+}
+
+func (this *stubProofFetcher) FetchCrossStatesProof(height uint32, key []byte, verify func(proof []byte) error) ([]byte, error) {
+	return this.fetch(verify)
+}
+
+func newLightTestHeader(t *testing.T, prev *types.Header, signer *account.Account, crossRoot common.Uint256) *types.Header {
+	bookkeeper, err := types.AddressFromBookkeepers([]keypair.PublicKey{signer.PublicKey})
+	if err != nil {
+		t.Fatalf("AddressFromBookkeepers error %s", err)
+	}
+	header := &types.Header{
+		PrevBlockHash:  prev.Hash(),
+		Timestamp:      prev.Timestamp + 1,
+		Height:         prev.Height + 1,
+		NextBookkeeper: bookkeeper,
+		CrossStateRoot: crossRoot,
+	}
+	hash := header.Hash()
+	sig, err := signature.Sign(signer, hash[:])
+	if err != nil {
+		t.Fatalf("Sign error %s", err)
+	}
+	header.Bookkeepers = []keypair.PublicKey{signer.PublicKey}
+	header.SigData = [][]byte{sig}
+	return header
+}
+
+func newTestLightStore(t *testing.T, signer *account.Account) (*LightStoreImp, *types.Header) {
+	consensusType := config.DefConfig.Genesis.ConsensusType
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	t.Cleanup(func() { config.DefConfig.Genesis.ConsensusType = consensusType })
+
+	bookkeeper, err := types.AddressFromBookkeepers([]keypair.PublicKey{signer.PublicKey})
+	if err != nil {
+		t.Fatalf("AddressFromBookkeepers error %s", err)
+	}
+	genesis := &types.Block{Header: &types.Header{Timestamp: 1, NextBookkeeper: bookkeeper}}
+	lightStore, err := NewMemLightStore()
+	if err != nil {
+		t.Fatalf("NewMemLightStore error %s", err)
+	}
+	err = lightStore.InitLedgerStoreWithGenesisBlock(genesis, []keypair.PublicKey{signer.PublicKey})
+	if err != nil {
+		t.Fatalf("InitLedgerStoreWithGenesisBlock error %s", err)
+	}
+	return lightStore, genesis.Header
+}
+
+func TestLightStoreAddHeaders(t *testing.T) {
+	signer := account.NewAccount("")
+	lightStore, genesis := newTestLightStore(t, signer)
+	defer lightStore.Close()
+
+	crossRoot := merkle.HashLeaf([]byte("cross"))
+	header1 := newLightTestHeader(t, genesis, signer, crossRoot)
+	forged := newLightTestHeader(t, header1, account.NewAccount(""), common.UINT256_EMPTY)
+
+	if err := lightStore.AddHeaders([]*types.Header{header1}); err != nil {
+		t.Fatalf("AddHeaders error %s", err)
+	}
+	if err := lightStore.AddHeaders([]*types.Header{forged}); err == nil {
+		t.Fatalf("header signed by unknown bookkeeper accepted")
+	}
+	if height := lightStore.GetCurrentBlockHeight(); height != 1 {
+		t.Fatalf("current height %d != 1", height)
+	}
+	if lightStore.GetCurrentHeaderHash() != header1.Hash() {
+		t.Fatalf("current header hash mismatch")
+	}
+	header, err := lightStore.GetHeaderByHeight(1)
+	if err != nil || header == nil || header.Hash() != header1.Hash() {
+		t.Fatalf("GetHeaderByHeight error %v", err)
+	}
+	root, err := lightStore.GetCrossStateRoot(0)
+	if err != nil {
+		t.Fatalf("GetCrossStateRoot error %s", err)
+	}
+	if root != crossRoot {
+		t.Fatalf("cross state root %x != %x", root, crossRoot)
+	}
+	if _, err := lightStore.GetBlockByHeight(1); err != ErrLightNode {
+		t.Fatalf("GetBlockByHeight error %v, expect %v", err, ErrLightNode)
+	}
+}
+
+func newTestCrossRequest(txHash byte, toChainID uint64) ([]byte, []byte) {
+	value := &ccmcom.ToMerkleValue{
+		TxHash:      []byte{txHash},
+		FromChainID: 1,
+		MakeTxParam: &ccmcom.MakeTxParam{TxHash: []byte{txHash}, ToChainID: toChainID, Method: "unlock"},
+	}
+	sink := common.NewZeroCopySink(nil)
+	value.Serialization(sink)
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ccmcom.REQUEST),
+		utils.GetUint64Bytes(toChainID), value.TxHash)
+	return sink.Bytes(), key
+}
+
+func TestLightStoreCrossStatesProof(t *testing.T) {
+	var values, keys [][]byte
+	for i := 0; i < 3; i++ {
+		value, key := newTestCrossRequest(byte(i), 2)
+		values = append(values, value)
+		keys = append(keys, key)
+	}
+	hashes := make([]common.Uint256, 0, len(values))
+	for _, v := range values {
+		hashes = append(hashes, merkle.HashLeaf(v))
+	}
+	crossRoot := merkle.TreeHasher{}.HashFullTreeWithLeafHash(hashes)
+	proof, err := merkle.MerkleLeafPath(values[1], hashes)
+	if err != nil {
+		t.Fatalf("MerkleLeafPath error %s", err)
+	}
+	bad, err := merkle.MerkleLeafPath(values[1], hashes[:2])
+	if err != nil {
+		t.Fatalf("MerkleLeafPath error %s", err)
+	}
+	//valid proof of other request in the same block
+	other, err := merkle.MerkleLeafPath(values[0], hashes)
+	if err != nil {
+		t.Fatalf("MerkleLeafPath error %s", err)
+	}
+
+	signer := account.NewAccount("")
+	lightStore, genesis := newTestLightStore(t, signer)
+	defer lightStore.Close()
+	if _, err := lightStore.GetCrossStatesProof(0, keys[1]); err == nil {
+		t.Fatalf("proof returned before header synced")
+	}
+	header1 := newLightTestHeader(t, genesis, signer, crossRoot)
+	if err := lightStore.AddHeaders([]*types.Header{header1}); err != nil {
+		t.Fatalf("AddHeaders error %s", err)
+	}
+	if _, err := lightStore.GetCrossStatesProof(0, keys[1]); err == nil {
+		t.Fatalf("proof returned without fetcher")
+	}
+
+	fetcher := &stubProofFetcher{proofs: [][]byte{bad, other, proof}}
+	lightStore.SetProofFetcher(fetcher)
+	res, err := lightStore.GetCrossStatesProof(0, keys[1])
+	if err != nil {
+		t.Fatalf("GetCrossStatesProof error %s", err)
+	}
+	if string(res) != string(proof) || fetcher.tried != 3 {
+		t.Fatalf("invalid proof accepted, tried %d", fetcher.tried)
+	}
+
+	//proof of request to other chain under the same tx hash
+	_, otherChainKey := newTestCrossRequest(1, 3)
+	if err := verifyCrossStatesProof(proof, crossRoot, otherChainKey); err == nil {
+		t.Fatalf("proof of request to other chain accepted")
+	}
+	if err := verifyCrossStatesProof(proof, crossRoot, keys[1]); err != nil {
+		t.Fatalf("verifyCrossStatesProof error %s", err)
+	}
+}
+
+func TestVerifyMerkleProof(t *testing.T) {
+	tree := merkle.NewTree(0, nil, merkle.NewMemHashStore())
+	var leaves [][]byte
+	for i := 0; i < 5; i++ {
+		leaf := merkle.HashLeaf([]byte{byte(i)})
+		leaves = append(leaves, leaf[:])
+		tree.Append(leaf[:])
+	}
+	root := tree.Root()
+	proof, err := tree.MerkleInclusionLeafPath(leaves[2], 2, 5)
+	if err != nil {
+		t.Fatalf("MerkleInclusionLeafPath error %s", err)
+	}
+	if err := verifyMerkleProof(proof, root, leaves[2]); err != nil {
+		t.Fatalf("verifyMerkleProof error %s", err)
+	}
+	if err := verifyMerkleProof(proof, root, leaves[3]); err == nil {
+		t.Fatalf("proof of other leaf accepted")
+	}
+}
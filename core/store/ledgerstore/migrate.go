@@ -0,0 +1,205 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/polynetwork/poly/common/log"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/kvstore"
+)
+
+const MIGRATE_BATCH_SIZE = 10000 //Count of keys committed in one batch while migrating
+
+//MigrateStore copy all the stores under dataDir to targetDir kept by backend,
+//then verify the current block and merkle roots at the tip of both sides
+func MigrateStore(dataDir, targetDir, backend string) error {
+	if filepath.Clean(dataDir) == filepath.Clean(targetDir) {
+		return fmt.Errorf("target dir should differ from data dir")
+	}
+	migrated := 0
+	for _, dir := range []string{DBDirBlock, DBDirState, DBDirEvent, DBDirLight} {
+		srcDir := filepath.Join(dataDir, dir)
+		srcBackend := kvstore.DetectBackend(srcDir)
+		if srcBackend == "" {
+			continue
+		}
+		dstDir := filepath.Join(targetDir, dir)
+		if exist := kvstore.DetectBackend(dstDir); exist != "" {
+			return fmt.Errorf("target store %s existed", dstDir)
+		}
+		count, err := migrateKV(srcBackend, srcDir, backend, dstDir)
+		if err != nil {
+			return fmt.Errorf("migrate %s error %s", dir, err)
+		}
+		log.Infof("migrate %s from %s to %s, %d keys", dir, srcBackend, backend, count)
+		migrated++
+	}
+	if migrated == 0 {
+		return fmt.Errorf("no store found under %s", dataDir)
+	}
+	err := copyFile(filepath.Join(dataDir, MerkleTreeStorePath), filepath.Join(targetDir, MerkleTreeStorePath))
+	if err != nil {
+		return fmt.Errorf("copy merkle tree error %s", err)
+	}
+	return VerifyMigration(dataDir, targetDir)
+}
+
+//VerifyMigration check the stores under targetDir have the same tip with the stores under dataDir
+func VerifyMigration(dataDir, targetDir string) error {
+	if kvstore.DetectBackend(filepath.Join(dataDir, DBDirState)) != "" {
+		if err := verifyStateStore(dataDir, targetDir); err != nil {
+			return err
+		}
+	}
+	for _, dir := range []string{DBDirBlock, DBDirLight} {
+		if kvstore.DetectBackend(filepath.Join(dataDir, dir)) == "" {
+			continue
+		}
+		if err := verifyBlockStore(filepath.Join(dataDir, dir), filepath.Join(targetDir, dir)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateKV(srcBackend, srcDir, dstBackend, dstDir string) (int, error) {
+	src, err := kvstore.NewStore(srcBackend, srcDir)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	dst, err := kvstore.NewStore(dstBackend, dstDir)
+	if err != nil {
+		return 0, err
+	}
+	defer dst.Close()
+
+	count := 0
+	iter := src.NewIterator(nil)
+	defer iter.Release()
+	dst.NewBatch()
+	for iter.Next() {
+		dst.BatchPut(iter.Key(), iter.Value())
+		count++
+		if count%MIGRATE_BATCH_SIZE == 0 {
+			if err := dst.BatchCommit(); err != nil {
+				return count, err
+			}
+			dst.NewBatch()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return count, err
+	}
+	return count, dst.BatchCommit()
+}
+
+func verifyStateStore(dataDir, targetDir string) error {
+	src, err := NewStateStore(filepath.Join(dataDir, DBDirState), filepath.Join(dataDir, MerkleTreeStorePath))
+	if err != nil {
+		return fmt.Errorf("open state store error %s", err)
+	}
+	defer src.Close()
+	dst, err := NewStateStore(filepath.Join(targetDir, DBDirState), filepath.Join(targetDir, MerkleTreeStorePath))
+	if err != nil {
+		return fmt.Errorf("open migrated state store error %s", err)
+	}
+	defer dst.Close()
+
+	srcHash, height, err := src.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("GetCurrentBlock error %s", err)
+	}
+	dstHash, dstHeight, err := dst.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("migrated GetCurrentBlock error %s", err)
+	}
+	if srcHash != dstHash || height != dstHeight {
+		return fmt.Errorf("current block mismatch, %d %s vs %d %s", height, srcHash.ToHexString(),
+			dstHeight, dstHash.ToHexString())
+	}
+	srcRoot, err := src.GetStateMerkleRoot(height)
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Errorf("GetStateMerkleRoot error %s", err)
+	}
+	dstRoot, dstErr := dst.GetStateMerkleRoot(height)
+	if dstErr != err {
+		return fmt.Errorf("migrated GetStateMerkleRoot error %v", dstErr)
+	}
+	if srcRoot != dstRoot {
+		return fmt.Errorf("state merkle root mismatch at %d, %s vs %s", height, srcRoot.ToHexString(), dstRoot.ToHexString())
+	}
+	if src.merkleTree.Root() != dst.merkleTree.Root() {
+		return fmt.Errorf("block merkle root mismatch at %d", height)
+	}
+	return nil
+}
+
+func verifyBlockStore(srcDir, dstDir string) error {
+	src, err := NewBlockStore(srcDir, false)
+	if err != nil {
+		return fmt.Errorf("open block store error %s", err)
+	}
+	defer src.Close()
+	dst, err := NewBlockStore(dstDir, false)
+	if err != nil {
+		return fmt.Errorf("open migrated block store error %s", err)
+	}
+	defer dst.Close()
+
+	srcHash, height, err := src.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("GetCurrentBlock error %s", err)
+	}
+	dstHash, dstHeight, err := dst.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("migrated GetCurrentBlock error %s", err)
+	}
+	if srcHash != dstHash || height != dstHeight {
+		return fmt.Errorf("current block mismatch in %s, %d %s vs %d %s", srcDir, height, srcHash.ToHexString(),
+			dstHeight, dstHash.ToHexString())
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
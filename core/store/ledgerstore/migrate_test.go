@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/store/kvstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	dataDir := filepath.Join(dir, "src")
+	targetDir := filepath.Join(dir, "dst")
+
+	blockStore, err := NewBlockStore(filepath.Join(dataDir, DBDirBlock), false)
+	assert.Nil(t, err)
+	stateStore, err := NewStateStore(filepath.Join(dataDir, DBDirState), filepath.Join(dataDir, MerkleTreeStorePath))
+	assert.Nil(t, err)
+	H := uint32(100)
+	for height := uint32(0); height < H; height++ {
+		var blockHash, writeSetHash common.Uint256
+		rand.Read(blockHash[:])
+		rand.Read(writeSetHash[:])
+		blockStore.NewBatch()
+		blockStore.SaveBlockHash(height, blockHash)
+		blockStore.SaveCurrentBlock(height, blockHash)
+		assert.Nil(t, blockStore.CommitTo())
+		stateStore.NewBatch()
+		stateStore.AddBlockMerkleTreeRoot(blockHash)
+		stateStore.AddStateMerkleTreeRoot(height, writeSetHash)
+		stateStore.SaveCurrentBlock(height, blockHash)
+		assert.Nil(t, stateStore.CommitTo())
+	}
+	blockStore.Close()
+	stateStore.Close()
+
+	err = MigrateStore(dataDir, targetDir, config.DB_BACKEND_BADGER)
+	assert.Nil(t, err)
+	assert.Equal(t, config.DB_BACKEND_BADGER, kvstore.DetectBackend(filepath.Join(targetDir, DBDirBlock)))
+	assert.Equal(t, config.DB_BACKEND_BADGER, kvstore.DetectBackend(filepath.Join(targetDir, DBDirState)))
+	assert.Equal(t, "", kvstore.DetectBackend(filepath.Join(targetDir, DBDirEvent)))
+
+	//migrate again into existed stores should fail
+	err = MigrateStore(dataDir, targetDir, config.DB_BACKEND_BADGER)
+	assert.NotNil(t, err)
+
+	//migrated stores can be moved back
+	backDir := filepath.Join(dir, "back")
+	err = MigrateStore(targetDir, backDir, config.DB_BACKEND_LEVELDB)
+	assert.Nil(t, err)
+	assert.Equal(t, config.DB_BACKEND_LEVELDB, kvstore.DetectBackend(filepath.Join(backDir, DBDirState)))
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"os"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/store"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/relayer_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/states"
+	"github.com/stretchr/testify/assert"
+)
+
+type pipelineChain struct {
+	dir     string
+	acc     *account.Account
+	genesis *types.Block
+	ledger  *LedgerStoreImp
+}
+
+func newPipelineChain(t *testing.T, dir string) *pipelineChain {
+	native.Contracts[utils.RelayerManagerContractAddress] = relayer_manager.RegisterRelayerManagerContract
+	assert.Nil(t, os.RemoveAll(dir))
+	//single bookkeeper chain, so blocks are verified by the bookkeeper address of previous block
+	consensusType := config.DefConfig.Genesis.ConsensusType
+	config.DefConfig.Genesis.ConsensusType = config.CONSENSUS_TYPE_SOLO
+	t.Cleanup(func() { config.DefConfig.Genesis.ConsensusType = consensusType })
+	acc := account.NewAccount("")
+	genesisBlock, err := genesis.BuildGenesisBlock([]keypair.PublicKey{acc.PublicKey}, config.DefConfig.Genesis)
+	assert.Nil(t, err)
+	chain := &pipelineChain{dir: dir, acc: acc, genesis: genesisBlock}
+	chain.open(t)
+	return chain
+}
+
+func (this *pipelineChain) open(t *testing.T) {
+	ledger, err := NewLedgerStore(this.dir)
+	assert.Nil(t, err)
+	err = ledger.InitLedgerStoreWithGenesisBlock(this.genesis, []keypair.PublicKey{this.acc.PublicKey})
+	assert.Nil(t, err)
+	this.ledger = ledger
+}
+
+// crash drops everything not persisted and reopens the ledger
+func (this *pipelineChain) crash(t *testing.T) {
+	assert.Nil(t, this.ledger.Close())
+	this.open(t)
+}
+
+// newBlock builds a block registering a relayer on top of prev, every relayer apply bumps the apply id,
+// so a block executed on a stale state has a different write set
+func (this *pipelineChain) newBlock(t *testing.T, prev *types.Header) *types.Block {
+	param := &relayer_manager.RelayerListParam{AddressList: []common.Address{this.acc.Address}, Address: this.acc.Address}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	invokeParam := &states.ContractInvokeParam{Address: utils.RelayerManagerContractAddress,
+		Method: relayer_manager.REGISTER_RELAYER, Args: sink.Bytes()}
+	invokeCode := common.NewZeroCopySink(nil)
+	invokeParam.Serialization(invokeCode)
+	tx := genesis.NewInvokeTransaction(invokeCode.Bytes(), prev.Height+1)
+	txHash := tx.Hash()
+	sig, err := signature.Sign(this.acc, txHash[:])
+	assert.Nil(t, err)
+	tx.Sigs = []types.Sig{{PubKeys: []keypair.PublicKey{this.acc.PublicKey}, M: 1, SigData: [][]byte{sig}}}
+	sink = common.NewZeroCopySink(nil)
+	assert.Nil(t, tx.Serialization(sink))
+	tx, err = types.TransactionFromRawBytes(sink.Bytes())
+	assert.Nil(t, err)
+
+	bookkeeper, err := types.AddressFromBookkeepers([]keypair.PublicKey{this.acc.PublicKey})
+	assert.Nil(t, err)
+	prevHash := prev.Hash()
+	header := &types.Header{
+		Version:          types.CURR_HEADER_VERSION,
+		ChainID:          prev.ChainID,
+		PrevBlockHash:    prevHash,
+		TransactionsRoot: common.ComputeMerkleRoot([]common.Uint256{tx.Hash()}),
+		BlockRoot: this.ledger.GetBlockRootWithPreBlockHashes(prev.Height,
+			[]common.Uint256{prev.PrevBlockHash, prevHash}),
+		Timestamp:      prev.Timestamp + 1,
+		Height:         prev.Height + 1,
+		NextBookkeeper: bookkeeper,
+		Bookkeepers:    []keypair.PublicKey{this.acc.PublicKey},
+	}
+	blkHash := header.Hash()
+	sig, err = signature.Sign(this.acc, blkHash[:])
+	assert.Nil(t, err)
+	header.SigData = [][]byte{sig}
+	return &types.Block{Header: header, Transactions: []*types.Transaction{tx}}
+}
+
+func assertSameResult(t *testing.T, expected, actual store.ExecuteResult) {
+	assert.Equal(t, expected.Hash, actual.Hash)
+	assert.Equal(t, expected.MerkleRoot, actual.MerkleRoot)
+}
+
+func TestExecuteBlockOnParent(t *testing.T) {
+	chain := newPipelineChain(t, "test/pipeline/normal")
+	defer chain.ledger.Close()
+
+	block1 := chain.newBlock(t, chain.genesis.Header)
+	result1, err := chain.ledger.ExecuteBlock(block1)
+	assert.Nil(t, err)
+	block2 := chain.newBlock(t, block1.Header)
+	result2, err := chain.ledger.ExecuteBlockOnParent(block2, result1)
+	assert.Nil(t, err)
+	assert.NotEqual(t, result1.Hash, result2.Hash)
+
+	//block beyond the pending parent
+	_, err = chain.ledger.ExecuteBlockOnParent(&types.Block{Header: &types.Header{Height: 3}}, result2)
+	assert.NotNil(t, err)
+
+	assert.Nil(t, chain.ledger.SubmitBlock(block1, result1))
+	assert.Nil(t, chain.ledger.SubmitBlock(block2, result2))
+	block3 := chain.newBlock(t, block2.Header)
+	result3, err := chain.ledger.ExecuteBlockOnParent(block3, result2)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block3, result3))
+	assert.Equal(t, uint32(3), chain.ledger.GetCurrentBlockHeight())
+	root, err := chain.ledger.GetStateMerkleRoot(3)
+	assert.Nil(t, err)
+	assert.Equal(t, result3.MerkleRoot, root)
+}
+
+func TestExecuteBlockOnParentCrashBeforeParentSubmitted(t *testing.T) {
+	chain := newPipelineChain(t, "test/pipeline/before")
+	block1 := chain.newBlock(t, chain.genesis.Header)
+	result1, err := chain.ledger.ExecuteBlock(block1)
+	assert.Nil(t, err)
+	block2 := chain.newBlock(t, block1.Header)
+	pipelined, err := chain.ledger.ExecuteBlockOnParent(block2, result1)
+	assert.Nil(t, err)
+
+	//crash after executing the child, before the parent is persisted
+	chain.crash(t)
+	defer chain.ledger.Close()
+	assert.Equal(t, uint32(0), chain.ledger.GetCurrentBlockHeight())
+
+	result1, err = chain.ledger.ExecuteBlock(block1)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block1, result1))
+	result2, err := chain.ledger.ExecuteBlock(block2)
+	assert.Nil(t, err)
+	assertSameResult(t, result2, pipelined)
+	assert.Nil(t, chain.ledger.SubmitBlock(block2, result2))
+	assert.Equal(t, uint32(2), chain.ledger.GetCurrentBlockHeight())
+}
+
+func TestExecuteBlockOnParentCrashAfterParentSubmitted(t *testing.T) {
+	chain := newPipelineChain(t, "test/pipeline/after")
+	block1 := chain.newBlock(t, chain.genesis.Header)
+	result1, err := chain.ledger.ExecuteBlock(block1)
+	assert.Nil(t, err)
+	block2 := chain.newBlock(t, block1.Header)
+	pipelined, err := chain.ledger.ExecuteBlockOnParent(block2, result1)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block1, result1))
+
+	//crash after the parent is persisted, before the child is persisted
+	chain.crash(t)
+	defer chain.ledger.Close()
+	assert.Equal(t, uint32(1), chain.ledger.GetCurrentBlockHeight())
+
+	result2, err := chain.ledger.ExecuteBlock(block2)
+	assert.Nil(t, err)
+	assertSameResult(t, result2, pipelined)
+	assert.Nil(t, chain.ledger.SubmitBlock(block2, pipelined))
+	root, err := chain.ledger.GetStateMerkleRoot(2)
+	assert.Nil(t, err)
+	assert.Equal(t, pipelined.MerkleRoot, root)
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneStore(t *testing.T) {
+	chain := newPipelineChain(t, "test/prune")
+	chain.ledger.pruneBlocks = 2
+
+	prev := chain.genesis.Header
+	txHashes := []common.Uint256{common.UINT256_EMPTY}
+	for i := 0; i < 5; i++ {
+		block := chain.newBlock(t, prev)
+		result, err := chain.ledger.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+		txHashes = append(txHashes, block.Transactions[0].Hash())
+		prev = block.Header
+	}
+	//blocks up to 5-2 are pruned
+	assertPruned := func(ledger *LedgerStoreImp) {
+		for height := uint32(0); height <= 5; height++ {
+			pruned := height <= 3
+			header, err := ledger.GetHeaderByHeight(height)
+			assert.Nil(t, err)
+			assert.Equal(t, height, header.Height)
+
+			block, err := ledger.GetBlockByHeight(height)
+			if pruned {
+				assert.Equal(t, scom.ErrPruned, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, header.Hash(), block.Hash())
+			}
+			_, err = ledger.GetEventNotifyByBlock(height)
+			if pruned {
+				assert.Equal(t, scom.ErrPruned, err)
+			} else {
+				assert.Nil(t, err)
+			}
+			if height == 0 {
+				continue
+			}
+			exist, err := ledger.IsContainTransaction(txHashes[height])
+			assert.Nil(t, err)
+			assert.True(t, exist)
+			_, txHeight, err := ledger.GetTransaction(txHashes[height])
+			assert.Equal(t, height, txHeight)
+			_, evtErr := ledger.GetEventNotifyByTx(txHashes[height])
+			if pruned {
+				assert.Equal(t, scom.ErrPruned, err)
+				assert.Equal(t, scom.ErrPruned, evtErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Nil(t, evtErr)
+			}
+		}
+		//block merkle proof and cross state root are kept
+		blockHash := ledger.GetBlockHash(1)
+		_, err := ledger.GetMerkleProof(blockHash.ToArray(), 2, 4)
+		assert.Nil(t, err)
+		_, err = ledger.GetCrossStateRoot(1)
+		assert.Nil(t, err)
+	}
+	assertPruned(chain.ledger)
+
+	//pruned height is persisted
+	chain.crash(t)
+	defer chain.ledger.Close()
+	assertPruned(chain.ledger)
+}
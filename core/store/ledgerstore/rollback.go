@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import "fmt"
+
+//Rollback revert the ledger to block at height. State store is reverted first by the undo data of blocks above,
+//then event store, block store and header index. Each store is reverted in one batch, if interrupted, the stores
+//left behind block store are replayed when ledger inits, and the rollback can be run again.
+//The ledger should be opened without init
+func (this *LedgerStoreImp) Rollback(height uint32) error {
+	_, blockHeight, err := this.blockStore.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("blockStore.GetCurrentBlock error %s", err)
+	}
+	if height > blockHeight {
+		return fmt.Errorf("height %d beyond current block height %d", height, blockHeight)
+	}
+	blockHash, err := this.blockStore.GetBlockHash(height)
+	if err != nil {
+		return fmt.Errorf("blockStore.GetBlockHash height:%d error %s", height, err)
+	}
+	err = this.stateStore.Rollback(height, blockHash)
+	if err != nil {
+		return fmt.Errorf("stateStore.Rollback error %s", err)
+	}
+	err = this.eventStore.Rollback(height, blockHash)
+	if err != nil {
+		return fmt.Errorf("eventStore.Rollback error %s", err)
+	}
+	err = this.blockStore.Rollback(height)
+	if err != nil {
+		return fmt.Errorf("blockStore.Rollback error %s", err)
+	}
+	this.eventBus.Reset()
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/store"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollback(t *testing.T) {
+	chain := newPipelineChain(t, "test/rollback")
+	prev := chain.genesis.Header
+	var blocks []*types.Block
+	var results []store.ExecuteResult
+	for i := 0; i < 5; i++ {
+		block := chain.newBlock(t, prev)
+		result, err := chain.ledger.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+		blocks = append(blocks, block)
+		results = append(results, result)
+		prev = block.Header
+	}
+	assert.Nil(t, chain.ledger.Close())
+
+	ledger, err := NewLedgerStore(chain.dir)
+	assert.Nil(t, err)
+	//header index lists of heights 0-1 and 2-3, the latter contains blocks rolled back
+	hashes := []common.Uint256{chain.genesis.Hash()}
+	for _, block := range blocks {
+		hashes = append(hashes, block.Hash())
+	}
+	ledger.blockStore.NewBatch()
+	assert.Nil(t, ledger.blockStore.SaveHeaderIndexList(0, hashes[0:2]))
+	assert.Nil(t, ledger.blockStore.SaveHeaderIndexList(2, hashes[2:4]))
+	assert.Nil(t, ledger.blockStore.CommitTo())
+	assert.NotNil(t, ledger.Rollback(6))
+	assert.Nil(t, ledger.Rollback(2))
+	headerIndex, err := ledger.blockStore.GetHeaderIndexList()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(headerIndex))
+	assert.Nil(t, ledger.Close())
+
+	chain.open(t)
+	assert.Equal(t, uint32(2), chain.ledger.GetCurrentBlockHeight())
+	assert.Equal(t, blocks[1].Hash(), chain.ledger.GetCurrentBlockHash())
+	for height := uint32(3); height <= 5; height++ {
+		assert.Equal(t, common.UINT256_EMPTY, chain.ledger.GetBlockHash(height))
+		_, err = chain.ledger.GetStateMerkleRoot(height)
+		assert.NotNil(t, err)
+		exist, err := chain.ledger.IsContainTransaction(blocks[height-1].Transactions[0].Hash())
+		assert.Nil(t, err)
+		assert.False(t, exist)
+		_, err = chain.ledger.GetEventNotifyByTx(blocks[height-1].Transactions[0].Hash())
+		assert.NotNil(t, err)
+	}
+	_, txHeight, err := chain.ledger.GetTransaction(blocks[1].Transactions[0].Hash())
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), txHeight)
+
+	//the reverted state executes the same blocks to the same results
+	for i := 2; i < 5; i++ {
+		result, err := chain.ledger.ExecuteBlock(blocks[i])
+		assert.Nil(t, err)
+		assertSameResult(t, results[i], result)
+		assert.Nil(t, chain.ledger.SubmitBlock(blocks[i], result))
+	}
+	root, err := chain.ledger.GetStateMerkleRoot(5)
+	assert.Nil(t, err)
+	assert.Equal(t, results[4].MerkleRoot, root)
+	_, err = chain.ledger.GetMerkleProof(hashes[3].ToArray(), 4, 5)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.Close())
+
+	//only blocks with undo data kept can be reverted
+	chain.open(t)
+	chain.ledger.rollbackBlocks = 1
+	block := chain.newBlock(t, blocks[4].Header)
+	result, err := chain.ledger.ExecuteBlock(block)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+	assert.Nil(t, chain.ledger.Close())
+	ledger, err = NewLedgerStore(chain.dir)
+	assert.Nil(t, err)
+	assert.NotNil(t, ledger.Rollback(4))
+	_, stateHeight, err := ledger.stateStore.GetCurrentBlock()
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(6), stateHeight)
+	assert.Nil(t, ledger.Rollback(5))
+	assert.Nil(t, ledger.Close())
+
+	chain.open(t)
+	defer chain.ledger.Close()
+	assert.Equal(t, uint32(5), chain.ledger.GetCurrentBlockHeight())
+	result, err = chain.ledger.ExecuteBlock(block)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/polynetwork/poly/common/serialization"
 	"github.com/polynetwork/poly/core/states"
 	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/kvstore"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/merkle"
@@ -52,7 +53,7 @@ type StateStore struct {
 //NewStateStore return state store instance
 func NewStateStore(dbDir, merklePath string) (*StateStore, error) {
 	var err error
-	store, err := leveldbstore.NewLevelDBStore(dbDir)
+	store, err := kvstore.Open(dbDir)
 	if err != nil {
 		return nil, err
 	}
@@ -75,9 +76,11 @@ func NewStateStore(dbDir, merklePath string) (*StateStore, error) {
 // for test
 func NewMemStateStore(stateHashHeight uint32) *StateStore {
 	store, _ := leveldbstore.NewMemLevelDBStore()
+	hashStore := merkle.NewMemHashStore()
 	stateStore := &StateStore{
 		store:                store,
-		merkleTree:           merkle.NewTree(0, nil, nil),
+		merkleTree:           merkle.NewTree(0, nil, hashStore),
+		merkleHashStore:      hashStore,
 		deltaMerkleTree:      merkle.NewTree(0, nil, nil),
 		stateHashCheckHeight: stateHashHeight,
 	}
@@ -141,6 +144,10 @@ func (self *StateStore) getMerkleTree(key []byte) (uint32, []common.Uint256, err
 	if err != nil {
 		return 0, nil, err
 	}
+	return parseMerkleTree(data)
+}
+
+func parseMerkleTree(data []byte) (uint32, []common.Uint256, error) {
 	value := bytes.NewBuffer(data)
 	treeSize, err := serialization.ReadUint32(value)
 	if err != nil {
@@ -375,9 +382,13 @@ func (self *StateStore) GetCurrentBlock() (common.Uint256, uint32, error) {
 	if err != nil {
 		return common.Uint256{}, 0, err
 	}
+	return parseCurrentBlock(data)
+}
+
+func parseCurrentBlock(data []byte) (common.Uint256, uint32, error) {
 	reader := bytes.NewReader(data)
 	blockHash := common.Uint256{}
-	err = blockHash.Deserialize(reader)
+	err := blockHash.Deserialize(reader)
 	if err != nil {
 		return common.Uint256{}, 0, err
 	}
@@ -429,6 +440,10 @@ func (self *StateStore) GetStateMerkleRootWithNewHash(writeSetHash common.Uint25
 	return self.deltaMerkleTree.GetRootWithNewLeaf(writeSetHash)
 }
 
+func (self *StateStore) GetStateMerkleRootWithNewHashes(writeSetHashes []common.Uint256) common.Uint256 {
+	return self.deltaMerkleTree.GetRootWithNewLeaves(writeSetHashes)
+}
+
 func (self *StateStore) GetBlockRootWithPreBlockHashes(preBlockHashes []common.Uint256) common.Uint256 {
 	return self.merkleTree.GetRootWithNewLeaves(preBlockHashes)
 }
@@ -462,6 +477,151 @@ func (self *StateStore) genStateMerkleRootKey(height uint32) []byte {
 	return key
 }
 
+func genStateUndoKey(height uint32) []byte {
+	key := make([]byte, 5, 5)
+	key[0] = byte(scom.SYS_STATE_UNDO)
+	binary.LittleEndian.PutUint32(key[1:], height)
+	return key
+}
+
+//SaveUndo keep the values overwritten by block at height, including current block, merkle trees and
+//the write set, so the block can be reverted by Rollback
+func (self *StateStore) SaveUndo(height uint32, writeSet *overlaydb.MemDB) error {
+	keys := [][]byte{self.getCurrentBlockKey(), self.genBlockMerkleTreeKey(), self.genStateMerkleTreeKey()}
+	writeSet.ForEach(func(key, val []byte) {
+		keys = append(keys, key)
+	})
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(keys)))
+	for _, key := range keys {
+		value, err := self.store.Get(key)
+		if err != nil && err != scom.ErrNotFound {
+			return fmt.Errorf("get key %x error %s", key, err)
+		}
+		sink.WriteVarBytes(key)
+		sink.WriteBool(err == nil)
+		sink.WriteVarBytes(value)
+	}
+	self.store.BatchPut(genStateUndoKey(height), sink.Bytes())
+	return nil
+}
+
+//DeleteUndo drop the undo data of block at height, the block can not be reverted any more
+func (self *StateStore) DeleteUndo(height uint32) {
+	self.store.BatchDelete(genStateUndoKey(height))
+}
+
+//undo revert blocks from currHeight down to height+1 in current batch, return the restored values,
+//nil for deleted keys
+func (self *StateStore) undo(height, currHeight uint32) (map[string][]byte, error) {
+	restored := make(map[string][]byte)
+	for h := currHeight; h > height; h-- {
+		data, err := self.store.Get(genStateUndoKey(h))
+		if err == scom.ErrNotFound {
+			return nil, fmt.Errorf("undo data of height %d not found, only the latest rollback-blocks blocks can be reverted", h)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get undo data of height %d error %s", h, err)
+		}
+		source := common.NewZeroCopySource(data)
+		count, eof := source.NextVarUint()
+		for i := uint64(0); i < count && !eof; i++ {
+			key, keyEof := source.NextVarBytes()
+			exist, existEof := source.NextBool()
+			value, valueEof := source.NextVarBytes()
+			if eof = keyEof || existEof || valueEof; eof {
+				break
+			}
+			if exist {
+				self.store.BatchPut(key, value)
+				restored[string(key)] = value
+			} else {
+				self.store.BatchDelete(key)
+				restored[string(key)] = nil
+			}
+		}
+		if eof {
+			return nil, fmt.Errorf("undo data of height %d error %s", h, io.ErrUnexpectedEOF)
+		}
+		self.store.BatchDelete(genStateUndoKey(h))
+		self.store.BatchDelete(self.genStateMerkleRootKey(h))
+		self.store.BatchDelete(genCrossStatesKey(h))
+		self.store.BatchDelete(genCrossStatesRootKey(h))
+	}
+	return restored, nil
+}
+
+//Rollback revert state store to block at height in one batch by the undo data of blocks above.
+//Hashes of block merkle tree appended after are dropped from merkle tree store once the batch committed
+func (self *StateStore) Rollback(height uint32, blockHash common.Uint256) error {
+	_, currHeight, err := self.GetCurrentBlock()
+	if err != nil {
+		return fmt.Errorf("GetCurrentBlock error %s", err)
+	}
+	if currHeight <= height {
+		return nil
+	}
+	self.store.NewBatch()
+	treeSize, hashes, deltaTree, err := self.rollback(height, currHeight, blockHash)
+	if err != nil {
+		self.store.NewBatch() // reset the batch
+		return err
+	}
+	err = self.store.BatchCommit()
+	if err != nil {
+		return fmt.Errorf("BatchCommit error %s", err)
+	}
+	if self.merkleHashStore != nil {
+		err = self.merkleHashStore.Truncate(treeSize)
+		if err != nil {
+			return fmt.Errorf("truncate merkle tree store error %s", err)
+		}
+	}
+	self.merkleTree = merkle.NewTree(treeSize, hashes, self.merkleHashStore)
+	self.deltaMerkleTree = deltaTree
+	return nil
+}
+
+func (self *StateStore) rollback(height, currHeight uint32, blockHash common.Uint256) (uint32, []common.Uint256,
+	*merkle.CompactMerkleTree, error) {
+	restored, err := self.undo(height, currHeight)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	restoredHash, restoredHeight, err := parseCurrentBlock(restored[string(self.getCurrentBlockKey())])
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("restored current block error %s", err)
+	}
+	if restoredHeight != height || restoredHash != blockHash {
+		return 0, nil, nil, fmt.Errorf("restored current block %d %s mismatch block %s", restoredHeight,
+			restoredHash.ToHexString(), blockHash.ToHexString())
+	}
+	treeSize, hashes, err := parseMerkleTree(restored[string(self.genBlockMerkleTreeKey())])
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("restored block merkle tree error %s", err)
+	}
+	if treeSize != height+1 {
+		return 0, nil, nil, fmt.Errorf("restored block merkle tree size %d mismatch height %d", treeSize, height)
+	}
+	deltaTree := merkle.NewTree(0, nil, nil)
+	if data := restored[string(self.genStateMerkleTreeKey())]; data != nil {
+		deltaSize, deltaHashes, err := parseMerkleTree(data)
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("restored state merkle tree error %s", err)
+		}
+		deltaTree = merkle.NewTree(deltaSize, deltaHashes, nil)
+		root, err := self.GetStateMerkleRoot(height)
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("GetStateMerkleRoot height:%d error %s", height, err)
+		}
+		if deltaRoot := deltaTree.Root(); deltaRoot != root {
+			return 0, nil, nil, fmt.Errorf("restored state merkle root %s mismatch %s", deltaRoot.ToHexString(),
+				root.ToHexString())
+		}
+	}
+	return treeSize, hashes, deltaTree, nil
+}
+
 //ClearAll clear all data in state store
 func (self *StateStore) ClearAll() error {
 	self.store.NewBatch()
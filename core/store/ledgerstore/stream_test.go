@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+	"time"
+
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/events/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func receiveStreamEvents(t *testing.T, sub *stream.Subscription, height uint32) []*stream.Event {
+	var events []*stream.Event
+	for {
+		select {
+		case evt := <-sub.Events():
+			assert.NotNil(t, evt, "subscription closed: %v", sub.Err())
+			if evt.Height > height {
+				t.Fatalf("unexpected event of height %d", evt.Height)
+			}
+			events = append(events, evt)
+		case <-time.After(100 * time.Millisecond):
+			assert.True(t, len(events) > 0)
+			assert.Equal(t, height, events[len(events)-1].Height)
+			return events
+		}
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	chain := newPipelineChain(t, "test/stream")
+	sub, err := chain.ledger.SubscribeEvents(0, 1)
+	assert.Nil(t, err)
+	prev := chain.genesis.Header
+	for i := 0; i < 3; i++ {
+		block := chain.newBlock(t, prev)
+		result, err := chain.ledger.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+		prev = block.Header
+	}
+	events := receiveStreamEvents(t, sub, 3)
+	for i, evt := range events {
+		assert.Equal(t, uint64(i), evt.Seq)
+		if i == 0 || evt.Height != events[i-1].Height {
+			assert.Equal(t, stream.EVENT_BLOCK, evt.Type)
+			assert.Equal(t, evt.Height, evt.Block.Header.Height)
+		} else {
+			assert.Equal(t, stream.EVENT_NOTIFY, evt.Type)
+			assert.NotNil(t, evt.Notify)
+		}
+	}
+	blockEvents := events[len(events)-2:]
+	assert.Equal(t, stream.EVENT_BLOCK, blockEvents[0].Type)
+
+	//replay from store after restart
+	chain.crash(t)
+	sub, err = chain.ledger.SubscribeEvents(3, 1)
+	assert.Nil(t, err)
+	replayed := receiveStreamEvents(t, sub, 3)
+	assert.Equal(t, len(blockEvents), len(replayed))
+	for i, evt := range replayed {
+		assert.Equal(t, blockEvents[i].Seq, evt.Seq)
+		assert.Equal(t, blockEvents[i].Type, evt.Type)
+	}
+	block := chain.newBlock(t, prev)
+	result, err := chain.ledger.ExecuteBlock(block)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+	events = receiveStreamEvents(t, sub, 4)
+	assert.Equal(t, blockEvents[len(blockEvents)-1].Seq+1, events[0].Seq)
+	sub.Unsubscribe()
+
+	//stream seq of blocks rolled back are deleted
+	assert.Nil(t, chain.ledger.eventStore.Rollback(3, block.Header.PrevBlockHash))
+	_, _, err = chain.ledger.eventStore.GetStreamSeq(4)
+	assert.Equal(t, scom.ErrNotFound, err)
+	assert.Nil(t, chain.ledger.Close())
+}
+
+func TestStreamEventsAfterUpgrade(t *testing.T) {
+	chain := newPipelineChain(t, "test/stream_upgrade")
+	prev := chain.genesis.Header
+	for i := 0; i < 3; i++ {
+		block := chain.newBlock(t, prev)
+		result, err := chain.ledger.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+		prev = block.Header
+	}
+	//drop stream records as the ledger saved before the stream recorded
+	eventStore := chain.ledger.eventStore
+	eventStore.NewBatch()
+	for h := uint32(0); h <= 3; h++ {
+		eventStore.store.BatchDelete(eventStore.getStreamSeqKey(h))
+	}
+	eventStore.store.BatchDelete(eventStore.getStreamStartKey())
+	assert.Nil(t, eventStore.CommitTo())
+	chain.crash(t)
+
+	_, err := chain.ledger.GetStreamEvents(1)
+	assert.Equal(t, &stream.NotIndexedError{Height: 1, Start: 4}, err)
+	sub, err := chain.ledger.SubscribeEvents(1, 1)
+	assert.Nil(t, err)
+	block := chain.newBlock(t, prev)
+	result, err := chain.ledger.ExecuteBlock(block)
+	assert.Nil(t, err)
+	assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+	_, err = chain.ledger.GetStreamEvents(2)
+	assert.Equal(t, &stream.NotIndexedError{Height: 2, Start: 4}, err)
+
+	//replay from a pre-upgrade height starts at the first block saved with the stream
+	events := receiveStreamEvents(t, sub, 4)
+	assert.Equal(t, uint32(4), events[0].Height)
+	assert.Equal(t, stream.EVENT_BLOCK, events[0].Type)
+	assert.Equal(t, uint64(0), events[0].Seq)
+	sub.Unsubscribe()
+	assert.Nil(t, chain.ledger.Close())
+}
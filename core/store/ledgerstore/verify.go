@@ -0,0 +1,245 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	scom "github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/merkle"
+)
+
+//VerifyReport is the result of VerifyLedger
+type VerifyReport struct {
+	BlockHeight     uint32 //Current block height of block store
+	StateHeight     uint32 //Current block height of state store
+	EventHeight     uint32 //Current block height of event store
+	PrunedHeight    uint32 //Transactions below the height are pruned and not checked
+	Divergent       bool   //Whether inconsistency found
+	DivergentHeight uint32 //First height inconsistency found at
+	Reason          string //Inconsistency found at DivergentHeight
+}
+
+//Recoverable return whether the stores only lag behind block store, which is replayed on start
+func (this *VerifyReport) Recoverable() bool {
+	return !this.Divergent && (this.StateHeight < this.BlockHeight || this.EventHeight < this.BlockHeight)
+}
+
+func (this *VerifyReport) diverge(height uint32, format string, args ...interface{}) *VerifyReport {
+	this.Divergent = true
+	this.DivergentHeight = height
+	this.Reason = fmt.Sprintf(format, args...)
+	return this
+}
+
+//VerifyLedger walk block store from genesis, check the header chain and signatures, transaction roots,
+//block merkle tree, cross state roots and event index against each other. The ledger should be opened
+//without init, so nothing is recovered before checked. progress is called after each height checked
+func (this *LedgerStoreImp) VerifyLedger(progress func(height, blockHeight uint32)) (*VerifyReport, error) {
+	report := &VerifyReport{PrunedHeight: this.blockStore.GetPrunedHeight()}
+	var err error
+	_, report.BlockHeight, err = this.blockStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("blockStore.GetCurrentBlock error %s", err)
+	}
+	_, report.StateHeight, err = this.stateStore.GetCurrentBlock()
+	if err != nil {
+		return nil, fmt.Errorf("stateStore.GetCurrentBlock error %s", err)
+	}
+	_, report.EventHeight, err = this.eventStore.GetCurrentBlock()
+	if err != nil && err != scom.ErrNotFound {
+		return nil, fmt.Errorf("eventStore.GetCurrentBlock error %s", err)
+	}
+	if report.StateHeight > report.BlockHeight {
+		return report.diverge(report.BlockHeight+1, "state store height %d beyond block store", report.StateHeight), nil
+	}
+	if report.EventHeight > report.BlockHeight {
+		return report.diverge(report.BlockHeight+1, "event store height %d beyond block store", report.EventHeight), nil
+	}
+	headerIndex, err := this.blockStore.GetHeaderIndexList()
+	if err != nil {
+		return nil, fmt.Errorf("GetHeaderIndexList error %s", err)
+	}
+
+	isVbft := strings.ToLower(config.DefConfig.Genesis.ConsensusType) == "vbft"
+	var vbftPeerInfo map[string]uint32
+	var vbftBlsPeers []*vconfig.PeerConfig
+	blockTree := merkle.NewTree(0, nil, nil)
+	var treeSize uint32 //block merkle tree at state height
+	var treeRoot common.Uint256
+	var prevHeader *types.Header
+	for height := uint32(0); height <= report.BlockHeight; height++ {
+		blockHash, err := this.blockStore.GetBlockHash(height)
+		if err != nil {
+			return report.diverge(height, "GetBlockHash error %s", err), nil
+		}
+		if indexHash, ok := headerIndex[height]; ok && indexHash != blockHash {
+			return report.diverge(height, "header index %s mismatch block hash %s", indexHash.ToHexString(),
+				blockHash.ToHexString()), nil
+		}
+		header, txHashes, err := this.blockStore.loadHeaderWithTx(blockHash)
+		if err != nil {
+			return report.diverge(height, "load header %s error %s", blockHash.ToHexString(), err), nil
+		}
+		if headerHash := header.Hash(); header.Height != height || headerHash != blockHash {
+			return report.diverge(height, "header height %d hash %s mismatch block hash %s", header.Height,
+				headerHash.ToHexString(), blockHash.ToHexString()), nil
+		}
+		if root := common.ComputeMerkleRoot(txHashes); root != header.TransactionsRoot {
+			return report.diverge(height, "transactions root %s mismatch header %s", root.ToHexString(),
+				header.TransactionsRoot.ToHexString()), nil
+		}
+		if height >= report.PrunedHeight {
+			for _, txHash := range txHashes {
+				tx, txHeight, err := this.blockStore.loadTransaction(txHash)
+				if err != nil {
+					return report.diverge(height, "load transaction %s error %s", txHash.ToHexString(), err), nil
+				}
+				if tx.Hash() != txHash || txHeight != height {
+					return report.diverge(height, "transaction %s saved at height %d mismatch", txHash.ToHexString(), txHeight), nil
+				}
+			}
+		}
+
+		if height == 0 {
+			if isVbft {
+				cfg, err := vbftChainConfig(header, func(uint32) (*types.Header, error) {
+					return nil, fmt.Errorf("genesis block without chain config")
+				})
+				if err != nil {
+					return report.diverge(height, "genesis chain config error %s", err), nil
+				}
+				vbftPeerInfo = make(map[string]uint32)
+				for _, p := range cfg.Peers {
+					vbftPeerInfo[p.ID] = p.Index
+				}
+				vbftBlsPeers = blsPeers(cfg)
+			}
+		} else {
+			if header.PrevBlockHash != prevHeader.Hash() {
+				return report.diverge(height, "prev block hash %s mismatch", header.PrevBlockHash.ToHexString()), nil
+			}
+			if root := blockTree.GetRootWithNewLeaves([]common.Uint256{header.PrevBlockHash}); root != header.BlockRoot {
+				return report.diverge(height, "block root %s mismatch header %s", root.ToHexString(),
+					header.BlockRoot.ToHexString()), nil
+			}
+			vbftPeerInfo, vbftBlsPeers, err = verifyHeaderWithPrev(prevHeader, header, vbftPeerInfo, vbftBlsPeers)
+			if err != nil {
+				return report.diverge(height, "verify header error %s", err), nil
+			}
+		}
+		blockTree.Append(header.PrevBlockHash.ToArray())
+		if height == report.StateHeight {
+			treeSize, treeRoot = blockTree.TreeSize(), blockTree.Root()
+		}
+
+		if height <= report.StateHeight {
+			if reason := this.verifyStateAt(height, header, isVbft); reason != "" {
+				return report.diverge(height, reason), nil
+			}
+		}
+		if height <= report.EventHeight && height >= this.eventStore.GetPrunedHeight() {
+			if reason := this.verifyEventAt(height, txHashes); reason != "" {
+				return report.diverge(height, reason), nil
+			}
+		}
+		if progress != nil {
+			progress(height, report.BlockHeight)
+		}
+		prevHeader = header
+	}
+
+	//block merkle tree persisted by state store covers blocks up to state height
+	storedSize, hashes, err := this.stateStore.GetBlockMerkleTree()
+	if err != nil {
+		return report.diverge(report.StateHeight, "GetBlockMerkleTree error %s", err), nil
+	}
+	storedRoot := merkle.NewTree(storedSize, hashes, nil).Root()
+	if storedSize != treeSize || storedRoot != treeRoot {
+		return report.diverge(report.StateHeight, "block merkle tree size %d root %s mismatch %d %s", storedSize,
+			storedRoot.ToHexString(), treeSize, treeRoot.ToHexString()), nil
+	}
+	return report, nil
+}
+
+//verifyStateAt check the state roots saved with block at height, return the inconsistency
+func (this *LedgerStoreImp) verifyStateAt(height uint32, header *types.Header, isVbft bool) string {
+	if _, err := this.stateStore.GetStateMerkleRoot(height); err != nil {
+		return fmt.Sprintf("GetStateMerkleRoot error %s", err)
+	}
+	crossRoot, err := this.stateStore.GetCrossStateRoot(height)
+	if err != nil {
+		return fmt.Sprintf("GetCrossStateRoot error %s", err)
+	}
+	crossStates, err := this.stateStore.GetCrossStates(height)
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Sprintf("GetCrossStates error %s", err)
+	}
+	expected := common.UINT256_EMPTY
+	if len(crossStates) > 0 {
+		expected = merkle.TreeHasher{}.HashFullTreeWithLeafHash(crossStates)
+	}
+	if crossRoot != expected {
+		return fmt.Sprintf("cross state root %s mismatch cross states %s", crossRoot.ToHexString(), expected.ToHexString())
+	}
+	//vbft proposer carries the cross state root of previous block
+	if isVbft && height > 0 {
+		prevRoot, err := this.stateStore.GetCrossStateRoot(height - 1)
+		if err != nil {
+			return fmt.Sprintf("GetCrossStateRoot error %s", err)
+		}
+		if prevRoot != header.CrossStateRoot {
+			return fmt.Sprintf("cross state root %s of previous block mismatch header %s", prevRoot.ToHexString(),
+				header.CrossStateRoot.ToHexString())
+		}
+	}
+	return ""
+}
+
+//verifyEventAt check event index of block at height, return the inconsistency
+func (this *LedgerStoreImp) verifyEventAt(height uint32, txHashes []common.Uint256) string {
+	eventTxs, err := this.eventStore.getEventTxsByBlock(height)
+	if err != nil && err != scom.ErrNotFound {
+		return fmt.Sprintf("event index error %s", err)
+	}
+	if len(eventTxs) != len(txHashes) {
+		return fmt.Sprintf("event index has %d transactions, block has %d", len(eventTxs), len(txHashes))
+	}
+	for i, txHash := range txHashes {
+		if eventTxs[i] != txHash {
+			return fmt.Sprintf("event index transaction %s mismatch %s", eventTxs[i].ToHexString(), txHash.ToHexString())
+		}
+		notify, err := this.eventStore.GetEventNotifyByTx(txHash)
+		if err == scom.ErrNotFound {
+			continue //event log may be disabled
+		}
+		if err != nil {
+			return fmt.Sprintf("GetEventNotifyByTx %s error %s", txHash.ToHexString(), err)
+		}
+		if notifyHash := notify.TxHash; notifyHash != txHash {
+			return fmt.Sprintf("event notify of %s saved for %s", txHash.ToHexString(), notifyHash.ToHexString())
+		}
+	}
+	return ""
+}
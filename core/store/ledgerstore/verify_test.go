@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ledgerstore
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLedger(t *testing.T) {
+	chain := newPipelineChain(t, "test/verify")
+	prev := chain.genesis.Header
+	for i := 0; i < 4; i++ {
+		block := chain.newBlock(t, prev)
+		result, err := chain.ledger.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+		prev = block.Header
+	}
+	assert.Nil(t, chain.ledger.Close())
+
+	verify := func() *VerifyReport {
+		ledger, err := NewLedgerStore(chain.dir)
+		assert.Nil(t, err)
+		defer ledger.Close()
+		checked := uint32(0)
+		report, err := ledger.VerifyLedger(func(height, blockHeight uint32) {
+			assert.Equal(t, checked, height)
+			checked++
+		})
+		assert.Nil(t, err)
+		return report
+	}
+	report := verify()
+	assert.False(t, report.Divergent, report.Reason)
+	assert.False(t, report.Recoverable())
+	assert.Equal(t, uint32(4), report.BlockHeight)
+	assert.Equal(t, uint32(4), report.StateHeight)
+
+	//tamper the cross state root at height 2
+	stateStore, err := NewStateStore(chain.dir+"/"+DBDirState, chain.dir+"/"+MerkleTreeStorePath)
+	assert.Nil(t, err)
+	root := common.Uint256{1}
+	assert.Nil(t, stateStore.store.Put(genCrossStatesRootKey(2), root[:]))
+	assert.Nil(t, stateStore.Close())
+	report = verify()
+	assert.True(t, report.Divergent)
+	assert.Equal(t, uint32(2), report.DivergentHeight)
+
+	//event index diverges before the cross state root
+	eventStore, err := NewEventStore(chain.dir + "/" + DBDirEvent)
+	assert.Nil(t, err)
+	eventStore.NewBatch()
+	assert.Nil(t, eventStore.SaveEventNotifyByBlock(1, []common.Uint256{{2}}))
+	assert.Nil(t, eventStore.CommitTo())
+	assert.Nil(t, eventStore.Close())
+	report = verify()
+	assert.True(t, report.Divergent)
+	assert.Equal(t, uint32(1), report.DivergentHeight)
+}
+
+func TestHeaderVerifier(t *testing.T) {
+	chain := newPipelineChain(t, "test/headerverifier")
+	defer chain.ledger.Close()
+	headers := make([]*types.Header, 0)
+	prev := chain.genesis.Header
+	for i := 0; i < 3; i++ {
+		block := chain.newBlock(t, prev)
+		result, err := chain.ledger.ExecuteBlock(block)
+		assert.Nil(t, err)
+		assert.Nil(t, chain.ledger.SubmitBlock(block, result))
+		headers = append(headers, block.Header)
+		prev = block.Header
+	}
+
+	verifier, err := NewHeaderVerifier(chain.genesis.Header, chain.ledger.GetHeaderByHeight)
+	assert.Nil(t, err)
+	//header not following the verified one
+	assert.NotNil(t, verifier.Verify(headers[1]))
+	for _, header := range headers {
+		assert.Nil(t, verifier.Verify(header))
+	}
+
+	verifier, err = NewHeaderVerifier(chain.genesis.Header, chain.ledger.GetHeaderByHeight)
+	assert.Nil(t, err)
+	tampered := *headers[0]
+	sig := append([]byte{}, tampered.SigData[0]...)
+	sig[len(sig)-1] ^= 0xff
+	tampered.SigData = [][]byte{sig}
+	assert.NotNil(t, verifier.Verify(&tampered))
+}
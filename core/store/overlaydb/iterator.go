@@ -35,8 +35,9 @@ type JoinIter struct {
 	memdb       common.StoreIterator
 	key, value  []byte
 	keyOrigin   KeyOrigin
-	nextMemEnd  bool
-	nextBackEnd bool
+	nextMemEnd  bool //memdb exhausted in current direction
+	nextBackEnd bool //backend exhausted in current direction
+	reverse     bool //moving backward
 	cmp         comparer.BasicComparer
 }
 
@@ -63,48 +64,10 @@ func (iter *JoinIter) First() bool {
 }
 
 func (iter *JoinIter) first() bool {
-	var bkey, bval, mkey, mval []byte
-	back := iter.backend.First()
-	mem := iter.memdb.First()
-	// check error
-	if iter.Error() != nil {
-		return false
-	}
-	if back {
-		bkey = iter.backend.Key()
-		bval = iter.backend.Value()
-		if mem == false {
-			iter.key = bkey
-			iter.value = bval
-			iter.keyOrigin = FromBack
-			return true
-		}
-		mkey = iter.memdb.Key()
-		mval = iter.memdb.Value()
-		cmp := iter.cmp.Compare(mkey, bkey)
-		if cmp < 1 {
-			iter.key = mkey
-			iter.value = mval
-			if cmp == 0 {
-				iter.keyOrigin = FromBoth
-			} else {
-				iter.keyOrigin = FromMem
-			}
-		} else {
-			iter.key = bkey
-			iter.value = bval
-			iter.keyOrigin = FromBack
-		}
-		return true
-	} else {
-		if mem {
-			iter.key = iter.memdb.Key()
-			iter.value = iter.memdb.Value()
-			iter.keyOrigin = FromMem
-			return true
-		}
-		return false
-	}
+	iter.reverse = false
+	iter.nextBackEnd = !iter.backend.First()
+	iter.nextMemEnd = !iter.memdb.First()
+	return iter.merge()
 }
 
 func (iter *JoinIter) Key() []byte {
@@ -131,13 +94,20 @@ func (iter *JoinIter) Next() bool {
 }
 
 func (iter *JoinIter) next() bool {
+	if iter.reverse {
+		return iter.turn(false)
+	}
 	if (iter.keyOrigin == FromMem || iter.keyOrigin == FromBoth) && iter.nextMemEnd == false {
 		iter.nextMemEnd = !iter.memdb.Next()
 	}
 	if (iter.keyOrigin == FromBack || iter.keyOrigin == FromBoth) && iter.nextBackEnd == false {
 		iter.nextBackEnd = !iter.backend.Next()
 	}
+	return iter.merge()
+}
 
+//merge pick the nearer item of memdb and backend in current direction, memdb wins on same key
+func (iter *JoinIter) merge() bool {
 	// check error
 	if iter.Error() != nil {
 		return false
@@ -162,21 +132,22 @@ func (iter *JoinIter) next() bool {
 			bkey := iter.backend.Key()
 			mkey := iter.memdb.Key()
 			cmp := iter.cmp.Compare(mkey, bkey)
-			switch cmp {
-			case -1:
+			if iter.reverse {
+				cmp = -cmp
+			}
+			switch {
+			case cmp < 0:
 				iter.key = mkey
 				iter.value = iter.memdb.Value()
 				iter.keyOrigin = FromMem
-			case 0:
+			case cmp == 0:
 				iter.key = mkey
 				iter.value = iter.memdb.Value()
 				iter.keyOrigin = FromBoth
-			case 1:
+			default:
 				iter.key = bkey
 				iter.value = iter.backend.Value()
 				iter.keyOrigin = FromBack
-			default:
-				panic("unreachable")
 			}
 		}
 	}
@@ -184,6 +155,99 @@ func (iter *JoinIter) next() bool {
 	return true
 }
 
+func (iter *JoinIter) Prev() bool {
+	f := iter.prev()
+	if f == false {
+		return false
+	}
+
+	for len(iter.value) == 0 {
+		if iter.prev() == false {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (iter *JoinIter) prev() bool {
+	if !iter.reverse {
+		return iter.turn(true)
+	}
+	if (iter.keyOrigin == FromMem || iter.keyOrigin == FromBoth) && iter.nextMemEnd == false {
+		iter.nextMemEnd = !iter.memdb.Prev()
+	}
+	if (iter.keyOrigin == FromBack || iter.keyOrigin == FromBoth) && iter.nextBackEnd == false {
+		iter.nextBackEnd = !iter.backend.Prev()
+	}
+	return iter.merge()
+}
+
+func (iter *JoinIter) Last() bool {
+	iter.reverse = true
+	iter.nextBackEnd = !iter.backend.Last()
+	iter.nextMemEnd = !iter.memdb.Last()
+	if iter.merge() == false {
+		return false
+	}
+	for len(iter.value) == 0 {
+		if iter.prev() == false {
+			return false
+		}
+	}
+	return true
+}
+
+func (iter *JoinIter) Seek(key []byte) bool {
+	iter.reverse = false
+	iter.nextBackEnd = !iter.backend.Seek(key)
+	iter.nextMemEnd = !iter.memdb.Seek(key)
+	if iter.merge() == false {
+		return false
+	}
+	for len(iter.value) == 0 {
+		if iter.next() == false {
+			return false
+		}
+	}
+	return true
+}
+
+//turn change the moving direction and step to the item next to current key
+func (iter *JoinIter) turn(reverse bool) bool {
+	iter.reverse = reverse
+	if iter.key == nil {
+		//exhausted in old direction, restart from the other end
+		if reverse {
+			iter.nextBackEnd = !iter.backend.Last()
+			iter.nextMemEnd = !iter.memdb.Last()
+		} else {
+			iter.nextBackEnd = !iter.backend.First()
+			iter.nextMemEnd = !iter.memdb.First()
+		}
+		return iter.merge()
+	}
+	key := append([]byte(nil), iter.key...)
+	iter.nextBackEnd = !iter.step(iter.backend, key)
+	iter.nextMemEnd = !iter.step(iter.memdb, key)
+	return iter.merge()
+}
+
+//step move sub iterator to the nearest item beyond key in current direction
+func (iter *JoinIter) step(sub common.StoreIterator, key []byte) bool {
+	ok := sub.Seek(key)
+	if iter.reverse {
+		if ok {
+			return sub.Prev()
+		}
+		return sub.Last()
+	}
+	if ok && iter.cmp.Compare(sub.Key(), key) == 0 {
+		return sub.Next()
+	}
+	return ok
+}
+
 func (iter *JoinIter) Release() {
 	iter.memdb.Release()
 	iter.backend.Release()
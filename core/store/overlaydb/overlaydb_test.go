@@ -102,3 +102,52 @@ func BenchmarkOverlayDBRandomPut(b *testing.B) {
 	}
 
 }
+
+func TestOverlayIteratorSeekPrev(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+
+	N := 100
+	for i := 0; i < N; i += 2 {
+		store.Put(makeKey(i), []byte("val"+strconv.Itoa(i)))
+	}
+	overlay := NewOverlayDB(store)
+	for i := 1; i < N; i += 2 {
+		overlay.Put(makeKey(i), []byte("val"+strconv.Itoa(i)))
+	}
+	//remove keys with i%3 == 0 from both backend and memdb side
+	var expect []int
+	for i := 0; i < N; i++ {
+		if i%3 == 0 {
+			overlay.Delete(makeKey(i))
+		} else {
+			expect = append(expect, i)
+		}
+	}
+
+	iter := overlay.NewIterator([]byte("key"))
+	assert.True(t, iter.Last())
+	for j := len(expect) - 1; j >= 0; j-- {
+		assert.Equal(t, makeKey(expect[j]), iter.Key())
+		assert.Equal(t, []byte("val"+strconv.Itoa(expect[j])), iter.Value())
+		assert.Equal(t, j > 0, iter.Prev())
+	}
+	assert.True(t, iter.Next())
+	assert.Equal(t, makeKey(expect[0]), iter.Key())
+
+	//seek to a deleted key lands on the next live one, then turn around
+	assert.True(t, iter.Seek(makeKey(51)))
+	assert.Equal(t, makeKey(52), iter.Key())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, makeKey(50), iter.Key())
+	assert.True(t, iter.Prev())
+	assert.Equal(t, makeKey(49), iter.Key())
+	assert.True(t, iter.Next())
+	assert.Equal(t, makeKey(50), iter.Key())
+	assert.True(t, iter.Next())
+	assert.Equal(t, makeKey(52), iter.Key())
+
+	assert.False(t, iter.Seek(makeKey(N)))
+	assert.True(t, iter.First())
+	assert.Equal(t, makeKey(expect[0]), iter.Key())
+}
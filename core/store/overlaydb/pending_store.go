@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package overlaydb
+
+import (
+	"errors"
+
+	"github.com/polynetwork/poly/core/store/common"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var errReadOnly = errors.New("pending store is read only")
+
+// PendingStore is a read only view of the backend store with the write set of a
+// pending block applied, so the next block can be executed before the pending one
+// is committed.
+type PendingStore struct {
+	store    common.PersistStore
+	writeSet *MemDB
+}
+
+func NewPendingStore(writeSet *MemDB, store common.PersistStore) *PendingStore {
+	return &PendingStore{
+		store:    store,
+		writeSet: writeSet,
+	}
+}
+
+func (self *PendingStore) Get(key []byte) ([]byte, error) {
+	if self.writeSet != nil {
+		value, unknown := self.writeSet.Get(key)
+		if !unknown {
+			if len(value) == 0 {
+				return nil, common.ErrNotFound
+			}
+			return value, nil
+		}
+	}
+	return self.store.Get(key)
+}
+
+func (self *PendingStore) Has(key []byte) (bool, error) {
+	_, err := self.Get(key)
+	if err == common.ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// param key is referenced by iterator
+func (self *PendingStore) NewIterator(prefix []byte) common.StoreIterator {
+	backIter := self.store.NewIterator(prefix)
+	if self.writeSet == nil {
+		return backIter
+	}
+	memIter := self.writeSet.NewIterator(util.BytesPrefix(prefix))
+	return NewJoinIter(memIter, backIter)
+}
+
+func (self *PendingStore) Put(key []byte, value []byte) error {
+	return errReadOnly
+}
+
+func (self *PendingStore) Delete(key []byte) error {
+	return errReadOnly
+}
+
+func (self *PendingStore) NewBatch() {
+	panic(errReadOnly)
+}
+
+func (self *PendingStore) BatchPut(key []byte, value []byte) {
+	panic(errReadOnly)
+}
+
+func (self *PendingStore) BatchDelete(key []byte) {
+	panic(errReadOnly)
+}
+
+func (self *PendingStore) BatchCommit() error {
+	return errReadOnly
+}
+
+// Close does nothing, the backend store is owned by caller
+func (self *PendingStore) Close() error {
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+package overlaydb
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/polynetwork/poly/core/store/common"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingStore(t *testing.T) {
+	store, err := leveldbstore.NewMemLevelDBStore()
+	assert.Nil(t, err)
+
+	N := 100
+	for i := 0; i < N; i++ {
+		store.Put(makeKey(i), []byte("val"+strconv.Itoa(i)))
+	}
+
+	// pending block deletes even keys and updates key 1
+	parent := NewOverlayDB(store)
+	for i := 0; i < N; i += 2 {
+		parent.Delete(makeKey(i))
+	}
+	parent.Put(makeKey(1), []byte("new"))
+	parent.Put(makeKey(N), []byte("val"+strconv.Itoa(N)))
+
+	pending := NewPendingStore(parent.GetWriteSet(), store)
+	val, err := pending.Get(makeKey(0))
+	assert.Equal(t, common.ErrNotFound, err)
+	assert.Nil(t, val)
+	val, err = pending.Get(makeKey(1))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("new"), val)
+	has, err := pending.Has(makeKey(N))
+	assert.Nil(t, err)
+	assert.True(t, has)
+
+	// executing on pending store equals executing after the parent committed
+	child := NewOverlayDB(pending)
+	child.Put(makeKey(3), []byte("child"))
+	child.Delete(makeKey(5))
+
+	store.NewBatch()
+	parent.CommitTo()
+	assert.Nil(t, store.BatchCommit())
+	committed := NewOverlayDB(store)
+	committed.Put(makeKey(3), []byte("child"))
+	committed.Delete(makeKey(5))
+	assert.Equal(t, committed.ChangeHash(), child.ChangeHash())
+
+	collect := func(iter common.StoreIterator) []string {
+		kvs := make([]string, 0)
+		for ok := iter.First(); ok; ok = iter.Next() {
+			kvs = append(kvs, string(iter.Key())+"="+string(iter.Value()))
+		}
+		return kvs
+	}
+	assert.Equal(t, collect(committed.NewIterator([]byte("key"))), collect(child.NewIterator([]byte("key"))))
+
+	assert.Equal(t, errReadOnly, pending.Put(makeKey(0), []byte("val")))
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/events/stream"
 	"github.com/polynetwork/poly/native/event"
 	cstates "github.com/polynetwork/poly/native/states"
 )
@@ -45,6 +46,8 @@ type LedgerStore interface {
 	AddBlock(block *types.Block, stateMerkleRoot common.Uint256) error
 	ExecuteBlock(b *types.Block) (ExecuteResult, error)   // called by consensus
 	SubmitBlock(b *types.Block, exec ExecuteResult) error // called by consensus
+	// called by consensus, execute block before its parent submitted
+	ExecuteBlockOnParent(b *types.Block, parent ExecuteResult) (ExecuteResult, error)
 	GetStateMerkleRoot(height uint32) (result common.Uint256, err error)
 	GetCrossStateRoot(height uint32) (result common.Uint256, err error)
 	GetCurrentBlockHash() common.Uint256
@@ -67,4 +70,5 @@ type LedgerStore interface {
 	PreExecuteContract(tx *types.Transaction) (*cstates.PreExecResult, error)
 	GetEventNotifyByTx(tx common.Uint256) (*event.ExecuteNotify, error)
 	GetEventNotifyByBlock(height uint32) ([]*event.ExecuteNotify, error)
+	SubscribeEvents(height uint32, bufSize int) (*stream.Subscription, error)
 }
@@ -67,8 +67,8 @@ func (bd *Header) Serialization(sink *common.ZeroCopySink) error {
 
 //Serialize the blockheader data without program
 func (bd *Header) serializationUnsigned(sink *common.ZeroCopySink) {
-	if bd.Version > CURR_HEADER_VERSION {
-		panic(fmt.Errorf("invalid header %d over max version:%d", bd.Version, CURR_HEADER_VERSION))
+	if bd.Version > MAX_HEADER_VERSION {
+		panic(fmt.Errorf("invalid header %d over max version:%d", bd.Version, MAX_HEADER_VERSION))
 	}
 	sink.WriteUint32(bd.Version)
 	sink.WriteUint64(bd.ChainID)
@@ -109,8 +109,8 @@ func (bd *Header) Serialize(w io.Writer) error {
 }
 
 func (bd *Header) serializeUnsigned(w io.Writer) error {
-	if bd.Version > CURR_HEADER_VERSION {
-		panic(fmt.Errorf("invalid header %d over max version:%d", bd.Version, CURR_HEADER_VERSION))
+	if bd.Version > MAX_HEADER_VERSION {
+		panic(fmt.Errorf("invalid header %d over max version:%d", bd.Version, MAX_HEADER_VERSION))
 	}
 	if err := serialization.WriteUint32(w, bd.Version); err != nil {
 		return err
@@ -204,8 +204,8 @@ func (bd *Header) deserializationUnsigned(source *common.ZeroCopySource) error {
 	if eof {
 		return errors.New("[Header] read version error")
 	}
-	if bd.Version > CURR_HEADER_VERSION {
-		return fmt.Errorf("[Header] header version %d over max version %d", bd.Version, CURR_HEADER_VERSION)
+	if bd.Version > MAX_HEADER_VERSION {
+		return fmt.Errorf("[Header] header version %d over max version %d", bd.Version, MAX_HEADER_VERSION)
 	}
 	bd.ChainID, eof = source.NextUint64()
 	if eof {
@@ -294,8 +294,8 @@ func (bd *Header) deserializeUnsigned(w io.Reader) error {
 	if err != nil {
 		return errors.New("[Header] read version error")
 	}
-	if bd.Version > CURR_HEADER_VERSION {
-		return fmt.Errorf("[Header] header version %d over max version %d", bd.Version, CURR_HEADER_VERSION)
+	if bd.Version > MAX_HEADER_VERSION {
+		return fmt.Errorf("[Header] header version %d over max version %d", bd.Version, MAX_HEADER_VERSION)
 	}
 	bd.ChainID, err = serialization.ReadUint64(w)
 	if err != nil {
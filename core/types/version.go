@@ -20,4 +20,9 @@ package types
 
 const CURR_TX_VERSION = 0
 const CURR_HEADER_VERSION = 0
+
+// BLS_HEADER_VERSION headers are sealed with one bls aggregate signature and a signer
+// bitmap instead of one signature per bookkeeper
+const BLS_HEADER_VERSION = 1
+const MAX_HEADER_VERSION = BLS_HEADER_VERSION
 const MAX_ATTRIBUTES_LEN = 0
@@ -0,0 +1,243 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package stream provides the typed event stream of saved blocks, replayable from a block height
+package stream
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+)
+
+type EventType byte
+
+const (
+	EVENT_BLOCK  EventType = 0 //Block saved, the first event of a block
+	EVENT_NOTIFY EventType = 1 //Execute notify of a transaction in the block
+)
+
+//DEFAULT_RECENT_EVENTS is the count of latest events kept in memory, older ones are replayed from store
+const DEFAULT_RECENT_EVENTS = 4096
+
+//Event of the stream, Seq increases by one per event from genesis block
+type Event struct {
+	Seq    uint64
+	Height uint32
+	Type   EventType
+	Block  *types.Block         //Set for EVENT_BLOCK
+	Notify *event.ExecuteNotify //Set for EVENT_NOTIFY
+}
+
+//NewBlockEvents return events of block, seq is the sequence of the block event
+func NewBlockEvents(seq uint64, block *types.Block, notifies []*event.ExecuteNotify) []*Event {
+	height := block.Header.Height
+	events := make([]*Event, 0, len(notifies)+1)
+	events = append(events, &Event{Seq: seq, Height: height, Type: EVENT_BLOCK, Block: block})
+	for i, notify := range notifies {
+		events = append(events, &Event{Seq: seq + uint64(i) + 1, Height: height, Type: EVENT_NOTIFY, Notify: notify})
+	}
+	return events
+}
+
+//NotIndexedError is returned by Source for blocks saved without stream events, i.e. saved before the
+//stream recorded or pruned. Start is the first height of which events can be loaded, subscriptions skip to it
+type NotIndexedError struct {
+	Height uint32
+	Start  uint32
+}
+
+func (self *NotIndexedError) Error() string {
+	return fmt.Sprintf("stream events of height %d not indexed, indexed from height %d", self.Height, self.Start)
+}
+
+//Source load persisted events of a block, nil events without error if block of height is not saved yet
+type Source interface {
+	GetStreamEvents(height uint32) ([]*Event, error)
+}
+
+//EventBus deliver events published by ledger to subscriptions, subscriptions fallen behind
+//the recent events kept in memory are replayed from source
+type EventBus struct {
+	source     Source
+	maxRecent  int
+	recent     []*Event
+	subscribes map[*Subscription]struct{}
+	lock       sync.RWMutex
+}
+
+//NewEventBus return EventBus replaying from source
+func NewEventBus(source Source, maxRecent int) *EventBus {
+	return &EventBus{
+		source:     source,
+		maxRecent:  maxRecent,
+		subscribes: make(map[*Subscription]struct{}),
+	}
+}
+
+//Publish events of a block after it is saved, never blocked by subscriptions
+func (self *EventBus) Publish(events []*Event) {
+	if len(events) == 0 {
+		return
+	}
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.recent = append(self.recent, events...)
+	//drop whole blocks, so recent events always start from a block event
+	for len(self.recent) > self.maxRecent {
+		height := self.recent[0].Height
+		i := 0
+		for i < len(self.recent) && self.recent[i].Height == height {
+			i++
+		}
+		self.recent = self.recent[i:]
+	}
+	for sub := range self.subscribes {
+		sub.wakeup()
+	}
+}
+
+//Reset drop recent events, called after the ledger rolled back
+func (self *EventBus) Reset() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.recent = nil
+}
+
+//Subscribe return subscription of events from the block at height. At most bufSize events are buffered,
+//the subscription waits for the consumer without blocking the publisher
+func (self *EventBus) Subscribe(height uint32, bufSize int) *Subscription {
+	sub := &Subscription{
+		bus:        self,
+		nextHeight: height,
+		events:     make(chan *Event, bufSize),
+		signal:     make(chan struct{}, 1),
+		quit:       make(chan struct{}),
+	}
+	self.lock.Lock()
+	self.subscribes[sub] = struct{}{}
+	self.lock.Unlock()
+	go sub.run()
+	return sub
+}
+
+//Close unsubscribe all subscriptions
+func (self *EventBus) Close() {
+	self.lock.Lock()
+	subs := self.subscribes
+	self.subscribes = make(map[*Subscription]struct{})
+	self.lock.Unlock()
+	for sub := range subs {
+		sub.stop()
+	}
+}
+
+func (self *EventBus) unsubscribe(sub *Subscription) {
+	self.lock.Lock()
+	delete(self.subscribes, sub)
+	self.lock.Unlock()
+	sub.stop()
+}
+
+//eventsFrom return events of blocks from height, nil if the block is not saved yet
+func (self *EventBus) eventsFrom(height uint32) ([]*Event, error) {
+	self.lock.RLock()
+	if len(self.recent) > 0 && self.recent[0].Height <= height {
+		var events []*Event
+		for i, evt := range self.recent {
+			if evt.Height >= height {
+				events = self.recent[i:len(self.recent):len(self.recent)]
+				break
+			}
+		}
+		self.lock.RUnlock()
+		return events, nil
+	}
+	self.lock.RUnlock()
+	return self.source.GetStreamEvents(height)
+}
+
+//Subscription of events in order of seq
+type Subscription struct {
+	bus        *EventBus
+	nextHeight uint32
+	events     chan *Event
+	signal     chan struct{}
+	quit       chan struct{}
+	once       sync.Once
+	err        error
+}
+
+//Events return the channel of events, closed after unsubscribed or failed to load events
+func (self *Subscription) Events() <-chan *Event {
+	return self.events
+}
+
+//Err return the error failed to load events, valid after the events channel closed
+func (self *Subscription) Err() error {
+	return self.err
+}
+
+//Unsubscribe stop delivering events
+func (self *Subscription) Unsubscribe() {
+	self.bus.unsubscribe(self)
+}
+
+func (self *Subscription) stop() {
+	self.once.Do(func() { close(self.quit) })
+}
+
+func (self *Subscription) wakeup() {
+	select {
+	case self.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (self *Subscription) run() {
+	defer close(self.events)
+	for {
+		events, err := self.bus.eventsFrom(self.nextHeight)
+		if notIndexed, ok := err.(*NotIndexedError); ok && notIndexed.Start > self.nextHeight {
+			self.nextHeight = notIndexed.Start
+			continue
+		}
+		if err != nil {
+			self.err = err
+			return
+		}
+		if len(events) == 0 {
+			select {
+			case <-self.signal:
+				continue
+			case <-self.quit:
+				return
+			}
+		}
+		for _, evt := range events {
+			select {
+			case self.events <- evt:
+			case <-self.quit:
+				return
+			}
+		}
+		self.nextHeight = events[len(events)-1].Height + 1
+	}
+}
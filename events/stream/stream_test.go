@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package stream
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/stretchr/testify/assert"
+)
+
+//testSource keeps blocks of one notify each, so events of height h are seq 2h and 2h+1.
+//Blocks below start are not indexed
+type testSource struct {
+	lock   sync.Mutex
+	height int
+	start  uint32
+	loaded int
+}
+
+func testBlockEvents(height uint32) []*Event {
+	block := &types.Block{Header: &types.Header{Height: height}}
+	return NewBlockEvents(uint64(height)*2, block, []*event.ExecuteNotify{{}})
+}
+
+func (self *testSource) GetStreamEvents(height uint32) ([]*Event, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if int(height) > self.height {
+		return nil, nil
+	}
+	if height < self.start {
+		return nil, &NotIndexedError{Height: height, Start: self.start}
+	}
+	self.loaded++
+	return testBlockEvents(height), nil
+}
+
+func (self *testSource) save(bus *EventBus, height uint32) {
+	self.lock.Lock()
+	self.height = int(height)
+	self.lock.Unlock()
+	bus.Publish(testBlockEvents(height))
+}
+
+func receive(t *testing.T, sub *Subscription, count int) []*Event {
+	events := make([]*Event, 0, count)
+	for len(events) < count {
+		select {
+		case evt, ok := <-sub.Events():
+			if !ok {
+				t.Fatalf("subscription closed: %v", sub.Err())
+			}
+			events = append(events, evt)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout after %d events", len(events))
+		}
+	}
+	return events
+}
+
+func assertSeq(t *testing.T, events []*Event, seq uint64) {
+	for i, evt := range events {
+		assert.Equal(t, seq+uint64(i), evt.Seq)
+		assert.Equal(t, uint32(evt.Seq/2), evt.Height)
+	}
+}
+
+func TestEventBusReplay(t *testing.T) {
+	source := &testSource{height: -1}
+	bus := NewEventBus(source, 4)
+	for h := uint32(0); h < 10; h++ {
+		source.save(bus, h)
+	}
+	//recent events keep the latest 2 blocks, older ones are loaded from source
+	sub := bus.Subscribe(3, 1)
+	assertSeq(t, receive(t, sub, 14), 6)
+	source.lock.Lock()
+	assert.Equal(t, 5, source.loaded)
+	source.lock.Unlock()
+
+	//live events
+	source.save(bus, 10)
+	assertSeq(t, receive(t, sub, 2), 20)
+	sub.Unsubscribe()
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.Nil(t, sub.Err())
+}
+
+func TestEventBusBackPressure(t *testing.T) {
+	source := &testSource{height: -1}
+	bus := NewEventBus(source, 4)
+	sub := bus.Subscribe(0, 1)
+	defer bus.Close()
+	//publisher never waits for the subscription
+	for h := uint32(0); h < 100; h++ {
+		source.save(bus, h)
+	}
+	events := receive(t, sub, 200)
+	assertSeq(t, events, 0)
+}
+
+type errSource struct{}
+
+func (self errSource) GetStreamEvents(height uint32) ([]*Event, error) {
+	return nil, fmt.Errorf("pruned")
+}
+
+func TestEventBusSourceError(t *testing.T) {
+	bus := NewEventBus(errSource{}, 4)
+	sub := bus.Subscribe(0, 1)
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+	assert.NotNil(t, sub.Err())
+}
+
+func TestEventBusNotIndexed(t *testing.T) {
+	source := &testSource{height: -1, start: 5}
+	bus := NewEventBus(source, 4)
+	defer bus.Close()
+	for h := uint32(0); h < 10; h++ {
+		source.save(bus, h)
+	}
+	//replay skips to the first indexed height
+	sub := bus.Subscribe(1, 1)
+	assertSeq(t, receive(t, sub, 10), 10)
+	source.save(bus, 10)
+	assertSeq(t, receive(t, sub, 2), 20)
+}
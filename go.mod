@@ -3,13 +3,16 @@ module github.com/polynetwork/poly
 go 1.14
 
 require (
+	github.com/DataDog/zstd v1.4.1
 	github.com/Zilliqa/gozilliqa-sdk v1.2.1-0.20210927032600-4c733f2cb879
 	github.com/btcsuite/btcd v0.21.0-beta
 	github.com/btcsuite/btcutil v1.0.2
 	github.com/cosmos/cosmos-sdk v0.39.1
+	github.com/dgraph-io/badger/v2 v2.2007.2
 	github.com/ethereum/go-ethereum v1.9.15
 	github.com/gcash/bchd v0.16.5
 	github.com/gcash/bchutil v0.0.0-20200506001747-c2894cd54b33
+	github.com/golang/snappy v0.0.1
 	github.com/gorilla/websocket v1.4.2
 	github.com/gosuri/uiprogress v0.0.1
 	github.com/hashicorp/golang-lru v0.5.4
@@ -20,8 +20,11 @@ package actor
 
 import (
 	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/events"
 	"github.com/polynetwork/poly/events/message"
+	"github.com/polynetwork/poly/events/stream"
 )
 
 type EventActor struct {
@@ -55,3 +58,20 @@ func SubscribeEvent(topic string, handler func(v interface{})) {
 	var sub = events.NewActorSubscriber(pid)
 	sub.Subscribe(topic)
 }
+
+//SubscribeStreamEvents subscribe typed events of blocks from height, handler is called in order of seq
+func SubscribeStreamEvents(height uint32, bufSize int, handler func(evt *stream.Event)) error {
+	sub, err := ledger.DefLedger.SubscribeEvents(height, bufSize)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for evt := range sub.Events() {
+			handler(evt)
+		}
+		if err := sub.Err(); err != nil {
+			log.Errorf("[SubscribeStreamEvents] subscription error:%s", err)
+		}
+	}()
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/polynetwork/poly/common/log"
 	ac "github.com/polynetwork/poly/p2pserver/actor/server"
 	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/link"
 )
 
 var netServerPid *actor.PID
@@ -79,6 +80,24 @@ func GetNeighborAddrs() []common.PeerAddr {
 	return r.Addrs
 }
 
+//GetMsgStats from netSever actor
+func GetMsgStats() (map[string]link.MsgStat, error) {
+	if netServerPid == nil {
+		return map[string]link.MsgStat{}, nil
+	}
+	future := netServerPid.RequestFuture(&ac.GetMsgStatsReq{}, REQ_TIMEOUT*time.Second)
+	result, err := future.Result()
+	if err != nil {
+		log.Errorf(ERR_ACTOR_COMM, err)
+		return nil, err
+	}
+	r, ok := result.(*ac.GetMsgStatsRsp)
+	if !ok {
+		return nil, errors.New("fail")
+	}
+	return r.Stats, nil
+}
+
 //GetConnectionState from netSever actor
 func GetConnectionState() (uint32, error) {
 	if netServerPid == nil {
@@ -21,6 +21,7 @@ package common
 
 import (
 	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/cmd/abi"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/types"
@@ -64,6 +65,7 @@ type PreExecuteResult struct {
 type NotifyEventInfo struct {
 	ContractAddress string
 	States          interface{}
+	Event           *abi.NativeEvent `json:",omitempty"` //States decoded with abi of native contract
 }
 
 type TxAttributeInfo struct {
@@ -155,17 +157,29 @@ func GetExecuteNotify(obj *event.ExecuteNotify) (map[string]bool, ExecuteNotify)
 	evts := []NotifyEventInfo{}
 	var contractAddrs = make(map[string]bool)
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States})
 		contractAddrs[v.ContractAddress.ToHexString()] = true
 	}
 	txhash := obj.TxHash.ToHexString()
 	return contractAddrs, ExecuteNotify{txhash, obj.State, obj.GasConsumed, evts}
 }
 
+//DecodeExecuteNotify decode states of notifies whose event abi is registered
+func DecodeExecuteNotify(notify *ExecuteNotify) {
+	for i := range notify.Notify {
+		evt, err := abi.DefAbiMgr.DecodeNativeEvent(notify.Notify[i].ContractAddress, notify.Notify[i].States)
+		if err != nil {
+			log.Debugf("DecodeNativeEvent contract:%s error:%s", notify.Notify[i].ContractAddress, err)
+			continue
+		}
+		notify.Notify[i].Event = evt
+	}
+}
+
 func ConvertPreExecuteResult(obj *cstate.PreExecResult) PreExecuteResult {
 	evts := []NotifyEventInfo{}
 	for _, v := range obj.Notify {
-		evts = append(evts, NotifyEventInfo{v.ContractAddress.ToHexString(), v.States})
+		evts = append(evts, NotifyEventInfo{ContractAddress: v.ContractAddress.ToHexString(), States: v.States})
 	}
 	return PreExecuteResult{obj.State, obj.Result, evts}
 }
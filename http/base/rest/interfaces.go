@@ -93,6 +93,9 @@ func GetBlockHash(cmd map[string]interface{}) map[string]interface{} {
 
 func getBlock(hash common.Uint256, getTxBytes bool) (interface{}, int64) {
 	block, err := bactor.GetBlockFromStore(hash)
+	if err == scom.ErrPruned {
+		return nil, berr.BLOCK_PRUNED
+	}
 	if err != nil {
 		return nil, berr.UNKNOWN_BLOCK
 	}
@@ -168,6 +171,9 @@ func GetBlockTxsByHeight(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	block, err := bactor.GetBlockFromStore(hash)
+	if err == scom.ErrPruned {
+		return ResponsePack(berr.BLOCK_PRUNED)
+	}
 	if err != nil {
 		return ResponsePack(berr.UNKNOWN_BLOCK)
 	}
@@ -196,6 +202,9 @@ func GetBlockByHeight(cmd map[string]interface{}) map[string]interface{} {
 	}
 	index := uint32(height)
 	block, err := bactor.GetBlockByHeight(index)
+	if err == scom.ErrPruned {
+		return ResponsePack(berr.BLOCK_PRUNED)
+	}
 	if err != nil || block == nil {
 		return ResponsePack(berr.UNKNOWN_BLOCK)
 	}
@@ -220,6 +229,9 @@ func GetTransactionByHash(cmd map[string]interface{}) map[string]interface{} {
 		return ResponsePack(berr.INVALID_PARAMS)
 	}
 	height, tx, err := bactor.GetTxnWithHeightByTxHash(hash)
+	if err == scom.ErrPruned {
+		return ResponsePack(berr.BLOCK_PRUNED)
+	}
 	if tx == nil {
 		return ResponsePack(berr.UNKNOWN_TRANSACTION)
 	}
@@ -305,6 +317,9 @@ func GetSmartCodeEventTxsByHeight(cmd map[string]interface{}) map[string]interfa
 		if scom.ErrNotFound == err {
 			return ResponsePack(berr.SUCCESS)
 		}
+		if scom.ErrPruned == err {
+			return ResponsePack(berr.BLOCK_PRUNED)
+		}
 		return ResponsePack(berr.INTERNAL_ERROR)
 	}
 	eInfos := make([]*bcomn.ExecuteNotify, 0, len(eventInfos))
@@ -337,6 +352,9 @@ func GetSmartCodeEventByTxHash(cmd map[string]interface{}) map[string]interface{
 		if scom.ErrNotFound == err {
 			return ResponsePack(berr.SUCCESS)
 		}
+		if scom.ErrPruned == err {
+			return ResponsePack(berr.BLOCK_PRUNED)
+		}
 		return ResponsePack(berr.INTERNAL_ERROR)
 	}
 	if eventInfo == nil {
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+
+	cfg "github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/common/log"
+	berr "github.com/polynetwork/poly/http/base/error"
+)
+
+//GetReloadableConfig return the config which can be reloaded at runtime
+func GetReloadableConfig(params []interface{}) map[string]interface{} {
+	return responseSuccess(cfg.DefConfig.GetReloadableConfig())
+}
+
+//ReloadConfig apply the fields of params[0] to config, all fields are validated before any is applied
+func ReloadConfig(params []interface{}) map[string]interface{} {
+	if len(params) < 1 {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	fields, ok := params[0].(map[string]interface{})
+	if !ok {
+		return responsePack(berr.INVALID_PARAMS, "")
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	update := &cfg.ReloadableConfig{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(update); err != nil {
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	changes, err := cfg.DefConfig.Reload(update)
+	if err != nil {
+		log.Auditf("config reload rejected: %s", err)
+		return responsePack(berr.INVALID_PARAMS, err.Error())
+	}
+	for _, change := range changes {
+		log.Auditf("config reloaded %s", change)
+	}
+	return responseSuccess(cfg.DefConfig.GetReloadableConfig())
+}
@@ -69,6 +69,9 @@ func GetBlock(params []interface{}) map[string]interface{} {
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
 	block, err := bactor.GetBlockFromStore(hash)
+	if err == scom.ErrPruned {
+		return responsePack(berr.BLOCK_PRUNED, "block pruned")
+	}
 	if err != nil {
 		return responsePack(berr.UNKNOWN_BLOCK, "unknown block")
 	}
@@ -98,6 +101,15 @@ func GetLatestBlockMsgsSnap(params []interface{}) map[string]interface{} {
 	return responseSuccess(result)
 }
 
+// get vbft consensus round status
+func GetConsensusStatus(params []interface{}) map[string]interface{} {
+	result, err := vbft.GetConsensusStatus()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, err.Error())
+	}
+	return responseSuccess(result)
+}
+
 // get cross state root
 func GetCrossStateRoot(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
@@ -213,6 +225,9 @@ func GetRawTransaction(params []interface{}) map[string]interface{} {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
 		h, t, err := bactor.GetTxnWithHeightByTxHash(hash)
+		if err == scom.ErrPruned {
+			return responsePack(berr.BLOCK_PRUNED, fmt.Sprintf("transaction pruned at height:%d", h))
+		}
 		if err != nil {
 			return responsePack(berr.UNKNOWN_TRANSACTION, fmt.Sprintf("unknown transaction:%s", err))
 		}
@@ -337,7 +352,7 @@ func GetNetworkId(params []interface{}) map[string]interface{} {
 	return responseSuccess(config.DefConfig.P2PNode.NetworkId)
 }
 
-//get smartconstract event
+//get smartconstract event, states of native contract notifies are decoded if the second param is 1
 func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if !config.DefConfig.Common.EnableEventLog {
 		return responsePack(berr.INVALID_METHOD, "")
@@ -345,6 +360,14 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 	if len(params) < 1 {
 		return responsePack(berr.INVALID_PARAMS, nil)
 	}
+	decode := false
+	if len(params) >= 2 {
+		flag, ok := params[1].(float64)
+		if !ok {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		decode = flag == 1
+	}
 
 	switch (params[0]).(type) {
 	// block height
@@ -355,11 +378,17 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 			if err == scom.ErrNotFound {
 				return responseSuccess(nil)
 			}
+			if err == scom.ErrPruned {
+				return responsePack(berr.BLOCK_PRUNED, "event pruned")
+			}
 			return responsePack(berr.INTERNAL_ERROR, "")
 		}
 		eInfos := make([]*bcomn.ExecuteNotify, 0, len(eventInfos))
 		for _, eventInfo := range eventInfos {
 			_, notify := bcomn.GetExecuteNotify(eventInfo)
+			if decode {
+				bcomn.DecodeExecuteNotify(&notify)
+			}
 			eInfos = append(eInfos, &notify)
 		}
 		return responseSuccess(eInfos)
@@ -375,9 +404,15 @@ func GetSmartCodeEvent(params []interface{}) map[string]interface{} {
 			if scom.ErrNotFound == err {
 				return responseSuccess(nil)
 			}
+			if scom.ErrPruned == err {
+				return responsePack(berr.BLOCK_PRUNED, "event pruned")
+			}
 			return responsePack(berr.INTERNAL_ERROR, "")
 		}
 		_, notify := bcomn.GetExecuteNotify(eventInfo)
+		if decode {
+			bcomn.DecodeExecuteNotify(&notify)
+		}
 		return responseSuccess(notify)
 	default:
 		return responsePack(berr.INVALID_PARAMS, "")
@@ -486,6 +521,9 @@ func GetBlockTxsByHeight(params []interface{}) map[string]interface{} {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
 		block, err := bactor.GetBlockFromStore(hash)
+		if err == scom.ErrPruned {
+			return responsePack(berr.BLOCK_PRUNED, "block pruned")
+		}
 		if err != nil {
 			return responsePack(berr.UNKNOWN_BLOCK, "")
 		}
@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 
+	cfg "github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	"github.com/polynetwork/poly/http/base/common"
@@ -45,6 +46,14 @@ func GetNeighbor(params []interface{}) map[string]interface{} {
 	return responseSuccess(addr)
 }
 
+func GetP2PStats(params []interface{}) map[string]interface{} {
+	stats, err := bactor.GetMsgStats()
+	if err != nil {
+		return responsePack(berr.INTERNAL_ERROR, false)
+	}
+	return responseSuccess(stats)
+}
+
 func GetNodeState(params []interface{}) map[string]interface{} {
 	state, err := bactor.GetConnectionState()
 	if err != nil {
@@ -114,9 +123,17 @@ func SetDebugInfo(params []interface{}) map[string]interface{} {
 	switch params[0].(type) {
 	case float64:
 		level := params[0].(float64)
-		if err := log.Log.SetDebugLevel(int(level)); err != nil {
+		if level < 0 {
+			return responsePack(berr.INVALID_PARAMS, "")
+		}
+		logLevel := uint(level)
+		changes, err := cfg.DefConfig.Reload(&cfg.ReloadableConfig{LogLevel: &logLevel})
+		if err != nil {
 			return responsePack(berr.INVALID_PARAMS, "")
 		}
+		for _, change := range changes {
+			log.Infof("[audit] config reloaded %s", change)
+		}
 	default:
 		return responsePack(berr.INVALID_PARAMS, "")
 	}
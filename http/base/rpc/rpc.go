@@ -23,27 +23,38 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"os"
 	"strings"
 	"sync"
+	"time"
 
+	cfg "github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	berr "github.com/polynetwork/poly/http/base/error"
 )
 
 func init() {
 	mainMux.m = make(map[string]func([]interface{}) map[string]interface{})
+	mainMux.cors = true
+	adminMux.m = make(map[string]func([]interface{}) map[string]interface{})
 }
 
 //an instance of the multiplexer
 var mainMux ServeMux
 
+//multiplexer of admin methods, only served by local server
+var adminMux ServeMux
+
+var requestLimiter = &limiter{}
+
 //multiplexer that keeps track of every function to be called on specific rpc call
 type ServeMux struct {
 	sync.RWMutex
 	m               map[string]func([]interface{}) map[string]interface{}
 	defaultFunction func(http.ResponseWriter, *http.Request)
+	cors            bool //allow cross origin requests
 }
 
 //a function to register functions to be called for specific rpc calls
@@ -53,6 +64,13 @@ func HandleFunc(pattern string, handler func([]interface{}) map[string]interface
 	mainMux.m[pattern] = handler
 }
 
+//a function to register admin functions, which are only served by local server
+func HandleAdminFunc(pattern string, handler func([]interface{}) map[string]interface{}) {
+	adminMux.Lock()
+	defer adminMux.Unlock()
+	adminMux.m[pattern] = handler
+}
+
 //a function to be called if the request is not a HTTP JSON RPC call
 func SetDefaultFunc(def func(http.ResponseWriter, *http.Request)) {
 	mainMux.defaultFunction = def
@@ -61,19 +79,56 @@ func SetDefaultFunc(def func(http.ResponseWriter, *http.Request)) {
 // this is the function that should be called in order to answer an rpc call
 // should be registered like "http.HandleFunc("/", httpjsonrpc.Handle)"
 func Handle(w http.ResponseWriter, r *http.Request) {
-	mainMux.RLock()
-	defer mainMux.RUnlock()
+	mainMux.handle(w, r)
+}
+
+//LimitedHandle answer rpc call as Handle, requests over config Rpc.MaxRequests per second are rejected
+func LimitedHandle(w http.ResponseWriter, r *http.Request) {
+	if !requestLimiter.allow(cfg.DefConfig.GetRpcMaxRequests()) {
+		data, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"error":   berr.SERVICE_CEILING,
+			"desc":    berr.ErrMap[berr.SERVICE_CEILING],
+			"result":  "too many requests",
+		})
+		w.Header().Set("content-type", "application/json;charset=utf-8")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write(data)
+		return
+	}
+	mainMux.handle(w, r)
+}
+
+//HandleAdmin answer admin rpc call, should only be registered by local server.
+//Every call is logged for audit. Admin rpc has no auth, requests must be of json content type, which
+//browsers do not send to other origins without a preflight, so web pages can not forge admin calls
+func HandleAdmin(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		log.Auditf("admin rpc request from %s rejected, content type %q", r.RemoteAddr, r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+		return
+	}
+	log.Auditf("admin rpc request from %s", r.RemoteAddr)
+	adminMux.handle(w, r)
+}
+
+func (this *ServeMux) handle(w http.ResponseWriter, r *http.Request) {
+	this.RLock()
+	defer this.RUnlock()
 	if r.Method == "OPTIONS" {
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+		if this.cors {
+			w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		return
 	}
 	//JSON RPC commands should be POSTs
 	if r.Method != "POST" {
-		if mainMux.defaultFunction != nil {
+		if this.defaultFunction != nil {
 			log.Info("HTTP JSON RPC Handle - Method!=\"POST\"")
-			mainMux.defaultFunction(w, r)
+			this.defaultFunction(w, r)
 			return
 		} else {
 			log.Warn("HTTP JSON RPC Handle - Method!=\"POST\"")
@@ -83,9 +138,9 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 
 	//check if there is Request Body to read
 	if r.Body == nil {
-		if mainMux.defaultFunction != nil {
+		if this.defaultFunction != nil {
 			log.Info("HTTP JSON RPC Handle - Request body is nil")
-			mainMux.defaultFunction(w, r)
+			this.defaultFunction(w, r)
 			return
 		} else {
 			log.Warn("HTTP JSON RPC Handle - Request body is nil")
@@ -116,7 +171,7 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	//get the corresponding function
-	function, ok := mainMux.m[method]
+	function, ok := this.m[method]
 	if ok {
 		response := function(request["params"].([]interface{}))
 		data, err := json.Marshal(map[string]interface{}{
@@ -130,9 +185,11 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 			log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
 			return
 		}
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+		if this.cors {
+			w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Write(data)
 	} else {
 		//if the function does not exist
@@ -150,9 +207,11 @@ func Handle(w http.ResponseWriter, r *http.Request) {
 			log.Error("HTTP JSON RPC Handle - json.Marshal: ", err)
 			return
 		}
-		w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+		if this.cors {
+			w.Header().Add("Access-Control-Allow-Headers", "Content-Type")
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		w.Header().Set("content-type", "application/json;charset=utf-8")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Write(data)
 	}
 }
@@ -184,3 +243,32 @@ func Call(address string, method string, id interface{}, params []interface{}) (
 
 	return body, nil
 }
+
+//limiter count requests in the window of one second
+type limiter struct {
+	sync.Mutex
+	window int64
+	count  uint
+}
+
+//allow return whether a request is allowed under max requests per second, 0 for unlimited
+func (this *limiter) allow(max uint) bool {
+	return this.allowAt(max, time.Now().Unix())
+}
+
+func (this *limiter) allowAt(max uint, now int64) bool {
+	if max == 0 {
+		return true
+	}
+	this.Lock()
+	defer this.Unlock()
+	if now != this.window {
+		this.window = now
+		this.count = 0
+	}
+	if this.count >= max {
+		return false
+	}
+	this.count++
+	return true
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cfg "github.com/polynetwork/poly/common/config"
+	berr "github.com/polynetwork/poly/http/base/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func callHandle(handle func(http.ResponseWriter, *http.Request), method string, params string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	handle(w, req)
+	resp := make(map[string]interface{})
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w, resp
+}
+
+func TestAdminHandle(t *testing.T) {
+	HandleAdminFunc("reloadconfig", ReloadConfig)
+
+	//admin methods are not served by main mux
+	_, resp := callHandle(Handle, "reloadconfig", `[{"max_tx_in_block":100}]`)
+	assert.Equal(t, float64(berr.INVALID_METHOD), resp["error"])
+
+	w, resp := callHandle(HandleAdmin, "reloadconfig", `[{"max_tx_in_block":100}]`)
+	assert.Equal(t, float64(berr.SUCCESS), resp["error"])
+	assert.Equal(t, uint(100), cfg.DefConfig.Consensus.MaxTxInBlock)
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	_, resp = callHandle(HandleAdmin, "reloadconfig", `[{"unknown_field":1}]`)
+	assert.Equal(t, float64(berr.INVALID_PARAMS), resp["error"])
+	_, resp = callHandle(HandleAdmin, "reloadconfig", `[{"max_conn_out_bound":0}]`)
+	assert.Equal(t, float64(berr.INVALID_PARAMS), resp["error"])
+
+	//requests without json content type, e.g. forged by html forms, are not dispatched
+	cfg.DefConfig.Consensus.MaxTxInBlock = 1
+	body := `{"jsonrpc":"2.0","id":1,"method":"reloadconfig","params":[{"max_tx_in_block":100}]}`
+	for _, contentType := range []string{"", "text/plain", "application/x-www-form-urlencoded"} {
+		w = httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		HandleAdmin(w, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Equal(t, uint(1), cfg.DefConfig.Consensus.MaxTxInBlock)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := &limiter{}
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.allowAt(0, 1))
+	}
+	for i := 0; i < 5; i++ {
+		assert.True(t, l.allowAt(5, 1))
+	}
+	assert.False(t, l.allowAt(5, 1))
+	//next window
+	assert.True(t, l.allowAt(5, 2))
+}
+
+func TestGetConsensusStatus(t *testing.T) {
+	//no vbft server running in this process
+	resp := GetConsensusStatus(nil)
+	assert.Equal(t, berr.INTERNAL_ERROR, resp["error"])
+	assert.Equal(t, "vbft server not running", resp["result"])
+
+	HandleFunc("getconsensusstatus", GetConsensusStatus)
+	_, body := callHandle(Handle, "getconsensusstatus", `[]`)
+	assert.Equal(t, float64(berr.INTERNAL_ERROR), body["error"])
+	assert.Equal(t, berr.ErrMap[berr.INTERNAL_ERROR], body["desc"])
+	assert.Equal(t, "vbft server not running", body["result"])
+
+	//params are ignored
+	_, body = callHandle(Handle, "getconsensusstatus", `["unused"]`)
+	assert.Equal(t, float64(berr.INTERNAL_ERROR), body["error"])
+}
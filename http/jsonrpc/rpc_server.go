@@ -32,37 +32,48 @@ import (
 
 func StartRPCServer() error {
 	log.Debug()
-	http.HandleFunc("/", rpc.Handle)
+	http.HandleFunc("/", rpc.LimitedHandle)
 
 	rpc.HandleFunc("getbestblockhash", rpc.GetBestBlockHash)
-	rpc.HandleFunc("getblock", rpc.GetBlock)
 	rpc.HandleFunc("getblockcount", rpc.GetBlockCount)
 	rpc.HandleFunc("getblockhash", rpc.GetBlockHash)
-	rpc.HandleFunc("getlatestblockmsgssnap", rpc.GetLatestBlockMsgsSnap)
 	rpc.HandleFunc("getcrossstateroot", rpc.GetCrossStateRoot)
 	rpc.HandleFunc("getconnectioncount", rpc.GetConnectionCount)
+	rpc.HandleFunc("getversion", rpc.GetNodeVersion)
+	rpc.HandleFunc("getnetworkid", rpc.GetNetworkId)
+
+	rpc.HandleFunc("getmerkleproof", rpc.GetMerkleProof)
+	rpc.HandleFunc("getcrossstatesproof", rpc.GetCrossStatesProof)
+	rpc.HandleFunc("getheaderbyheight", rpc.GetHeaderByHeight)
+
+	//light node only serves headers and proofs
+	if !cfg.DefConfig.Common.LightNode {
+		handleFullNodeFunc()
+	}
+
+	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
+	if err != nil {
+		return fmt.Errorf("ListenAndServe error:%s", err)
+	}
+	return nil
+}
+
+//handleFullNodeFunc register the rpc methods which need blocks or states
+func handleFullNodeFunc() {
+	rpc.HandleFunc("getblock", rpc.GetBlock)
+	rpc.HandleFunc("getlatestblockmsgssnap", rpc.GetLatestBlockMsgsSnap)
+	rpc.HandleFunc("getconsensusstatus", rpc.GetConsensusStatus)
 	//HandleFunc("getrawmempool", GetRawMemPool)
 
 	rpc.HandleFunc("getrawtransaction", rpc.GetRawTransaction)
 	rpc.HandleFunc("sendrawtransaction", rpc.SendRawTransaction)
 	rpc.HandleFunc("getstorage", rpc.GetStorage)
-	rpc.HandleFunc("getversion", rpc.GetNodeVersion)
-	rpc.HandleFunc("getnetworkid", rpc.GetNetworkId)
 
 	rpc.HandleFunc("getmempooltxcount", rpc.GetMemPoolTxCount)
 	rpc.HandleFunc("getmempooltxstate", rpc.GetMemPoolTxState)
 	rpc.HandleFunc("getsmartcodeevent", rpc.GetSmartCodeEvent)
 	rpc.HandleFunc("getblockheightbytxhash", rpc.GetBlockHeightByTxHash)
 
-	rpc.HandleFunc("getmerkleproof", rpc.GetMerkleProof)
-	rpc.HandleFunc("getcrossstatesproof", rpc.GetCrossStatesProof)
-	rpc.HandleFunc("getheaderbyheight", rpc.GetHeaderByHeight)
 	rpc.HandleFunc("getblocktxsbyheight", rpc.GetBlockTxsByHeight)
 	rpc.HandleFunc("getstatemerkleroot", rpc.GetStateMerkleRoot)
-
-	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpJsonPort)), nil)
-	if err != nil {
-		return fmt.Errorf("ListenAndServe error:%s", err)
-	}
-	return nil
 }
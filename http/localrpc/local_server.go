@@ -32,20 +32,27 @@ import (
 const (
 	LOCAL_HOST string = "127.0.0.1"
 	LOCAL_DIR  string = "/local"
+	ADMIN_DIR  string = "/admin"
 )
 
 func StartLocalServer() error {
 	log.Debug()
-	http.HandleFunc(LOCAL_DIR, rpc.Handle)
+	mux := http.NewServeMux()
+	mux.HandleFunc(LOCAL_DIR, rpc.Handle)
+	//admin methods are only served by local server
+	mux.HandleFunc(ADMIN_DIR, rpc.HandleAdmin)
 
 	rpc.HandleFunc("getneighbor", rpc.GetNeighbor)
 	rpc.HandleFunc("getnodestate", rpc.GetNodeState)
+	rpc.HandleFunc("getp2pstats", rpc.GetP2PStats)
 	rpc.HandleFunc("startconsensus", rpc.StartConsensus)
 	rpc.HandleFunc("stopconsensus", rpc.StopConsensus)
 	rpc.HandleFunc("setdebuginfo", rpc.SetDebugInfo)
 
-	// TODO: only listen to local host
-	err := http.ListenAndServe(":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpLocalPort)), nil)
+	rpc.HandleAdminFunc("getconfig", rpc.GetReloadableConfig)
+	rpc.HandleAdminFunc("reloadconfig", rpc.ReloadConfig)
+
+	err := http.ListenAndServe(LOCAL_HOST+":"+strconv.Itoa(int(cfg.DefConfig.Rpc.HttpLocalPort)), mux)
 	if err != nil {
 		return fmt.Errorf("ListenAndServe error:%s", err)
 	}
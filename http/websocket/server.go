@@ -24,7 +24,7 @@ import (
 	cfg "github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/types"
-	"github.com/polynetwork/poly/events/message"
+	"github.com/polynetwork/poly/events/stream"
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	bcomn "github.com/polynetwork/poly/http/base/common"
 	Err "github.com/polynetwork/poly/http/base/error"
@@ -35,9 +35,13 @@ import (
 
 var ws *websocket.WsServer
 
+const WS_EVENT_BUF_SIZE = 1024
+
 func StartServer() {
-	bactor.SubscribeEvent(message.TOPIC_SAVE_BLOCK_COMPLETE, sendBlock2WSclient)
-	bactor.SubscribeEvent(message.TOPIC_SMART_CODE_EVENT, pushSmartCodeEvent)
+	err := bactor.SubscribeStreamEvents(bactor.GetCurrentBlockHeight()+1, WS_EVENT_BUF_SIZE, pushStreamEvent)
+	if err != nil {
+		log.Errorf("[StartServer] SubscribeStreamEvents error:%s", err)
+	}
 	go func() {
 		ws = websocket.InitWsServer()
 		ws.Start()
@@ -51,6 +55,14 @@ func sendBlock2WSclient(v interface{}) {
 		}()
 	}
 }
+func pushStreamEvent(evt *stream.Event) {
+	switch evt.Type {
+	case stream.EVENT_BLOCK:
+		sendBlock2WSclient(*evt.Block)
+	case stream.EVENT_NOTIFY:
+		pushSmartCodeEvent(types.SmartCodeEvent{TxHash: evt.Notify.TxHash, Action: event.EVENT_NOTIFY, Result: evt.Notify})
+	}
+}
 func Stop() {
 	if ws == nil {
 		return
@@ -42,6 +42,7 @@ import (
 	"github.com/polynetwork/poly/consensus"
 	"github.com/polynetwork/poly/core/genesis"
 	"github.com/polynetwork/poly/core/ledger"
+	"github.com/polynetwork/poly/core/store/ledgerstore"
 	"github.com/polynetwork/poly/events"
 	bactor "github.com/polynetwork/poly/http/base/actor"
 	hserver "github.com/polynetwork/poly/http/base/actor"
@@ -78,13 +79,23 @@ func setupAPP() *cli.App {
 		cmd.MultiSigTxCommand,
 		cmd.SendTxCommand,
 		cmd.ShowTxCommand,
+		cmd.DBCommand,
+		cmd.GenesisCommand,
 	}
 	app.Flags = []cli.Flag{
 		//common setting
 		utils.ConfigFlag,
 		utils.LogLevelFlag,
+		utils.LogFormatFlag,
+		utils.LogModulesFlag,
+		utils.LogMaxSizeFlag,
+		utils.LogMaxAgeFlag,
 		utils.DisableEventLogFlag,
 		utils.DataDirFlag,
+		utils.LightNodeFlag,
+		utils.DBBackendFlag,
+		utils.PruneBlocksFlag,
+		utils.RollbackBlocksFlag,
 		//account setting
 		utils.WalletFileFlag,
 		utils.AccountAddressFlag,
@@ -92,13 +103,16 @@ func setupAPP() *cli.App {
 		//consensus setting
 		utils.EnableConsensusFlag,
 		utils.MaxTxInBlockFlag,
+		utils.BlsKeyFlag,
 		//txpool setting
+		utils.TxpoolCapacityFlag,
 		utils.TxpoolPreExecDisableFlag,
 		utils.DisableSyncVerifyTxFlag,
 		utils.DisableBroadcastNetTxFlag,
 		//p2p setting
 		utils.ReservedPeersOnlyFlag,
 		utils.ReservedPeersFileFlag,
+		utils.NoiseFlag,
 		utils.NetworkIdFlag,
 		utils.NodePortFlag,
 		utils.ConsensusPortFlag,
@@ -107,6 +121,11 @@ func setupAPP() *cli.App {
 		utils.MaxConnInBoundFlag,
 		utils.MaxConnOutBoundFlag,
 		utils.MaxConnInBoundForSingleIPFlag,
+		utils.P2PCompressionFlag,
+		utils.MaxIngressRateFlag,
+		utils.MaxEgressRateFlag,
+		utils.MaxPeerIngressRateFlag,
+		utils.MaxPeerEgressRateFlag,
 		//test mode setting
 		utils.EnableTestModeFlag,
 		utils.TestModeGenBlockTimeFlag,
@@ -115,6 +134,7 @@ func setupAPP() *cli.App {
 		utils.RPCPortFlag,
 		utils.RPCLocalEnableFlag,
 		utils.RPCLocalProtFlag,
+		utils.RPCMaxRequestsFlag,
 		//rest setting
 		utils.RestfulEnableFlag,
 		utils.RestfulPortFlag,
@@ -166,7 +186,7 @@ func startOntology(ctx *cli.Context) {
 		log.Errorf("initTxPool error:%s", err)
 		return
 	}
-	p2pSvr, p2pPid, err := initP2PNode(ctx, txpool)
+	p2pSvr, p2pPid, err := initP2PNode(ctx, txpool, acc)
 	if err != nil {
 		log.Errorf("initP2PNode error:%s", err)
 		return
@@ -198,7 +218,17 @@ func initLog(ctx *cli.Context) {
 	//init log module
 	logLevel := ctx.GlobalInt(utils.GetFlagName(utils.LogLevelFlag))
 	alog.InitLog(log.PATH)
+	log.SetRotation(ctx.GlobalUint(utils.GetFlagName(utils.LogMaxSizeFlag)),
+		ctx.GlobalUint(utils.GetFlagName(utils.LogMaxAgeFlag)))
 	log.InitLog(logLevel, log.PATH, log.Stdout)
+	if err := log.SetFormat(ctx.GlobalString(utils.GetFlagName(utils.LogFormatFlag))); err != nil {
+		fmt.Printf("error: %s\n", err)
+		os.Exit(1)
+	}
+	if err := log.SetModuleLevels(ctx.GlobalString(utils.GetFlagName(utils.LogModulesFlag))); err != nil {
+		fmt.Printf("error: %s\n", err)
+		os.Exit(1)
+	}
 }
 
 func initConfig(ctx *cli.Context) (*config.OntologyConfig, error) {
@@ -243,7 +273,11 @@ func initLedger(ctx *cli.Context) (*ledger.Ledger, error) {
 
 	var err error
 	dbDir := utils.GetStoreDirPath(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName)
-	ledger.DefLedger, err = ledger.NewLedger(dbDir)
+	if config.DefConfig.Common.LightNode {
+		ledger.DefLedger, err = ledger.NewLightLedger(dbDir)
+	} else {
+		ledger.DefLedger, err = ledger.NewLedger(dbDir)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("NewLedger error:%s", err)
 	}
@@ -267,6 +301,9 @@ func initLedger(ctx *cli.Context) (*ledger.Ledger, error) {
 }
 
 func initTxPool(ctx *cli.Context) (*proc.TXPoolServer, error) {
+	if config.DefConfig.Common.LightNode {
+		return nil, nil
+	}
 	disablePreExec := ctx.GlobalBool(utils.GetFlagName(utils.TxpoolPreExecDisableFlag))
 	bactor.DisableSyncVerifyTx = ctx.GlobalBool(utils.GetFlagName(utils.DisableSyncVerifyTxFlag))
 	disableBroadcastNetTx := ctx.GlobalBool(utils.GetFlagName(utils.DisableBroadcastNetTxFlag))
@@ -288,11 +325,11 @@ func initTxPool(ctx *cli.Context) (*proc.TXPoolServer, error) {
 	return txPoolServer, nil
 }
 
-func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer) (*p2pserver.P2PServer, *actor.PID, error) {
+func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer, acc *account.Account) (*p2pserver.P2PServer, *actor.PID, error) {
 	if config.DefConfig.Genesis.ConsensusType == config.CONSENSUS_TYPE_SOLO {
 		return nil, nil, nil
 	}
-	p2p := p2pserver.NewServer()
+	p2p := p2pserver.NewServerWithAccount(acc)
 
 	p2pActor := p2pactor.NewP2PActor(p2p)
 	p2pPID, err := p2pActor.Start()
@@ -304,8 +341,13 @@ func initP2PNode(ctx *cli.Context, txpoolSvr *proc.TXPoolServer) (*p2pserver.P2P
 	if err != nil {
 		return nil, nil, fmt.Errorf("p2p service start error %s", err)
 	}
-	netreqactor.SetTxnPoolPid(txpoolSvr.GetPID(tc.TxActor))
-	txpoolSvr.RegisterActor(tc.NetActor, p2pPID)
+	if txpoolSvr != nil {
+		netreqactor.SetTxnPoolPid(txpoolSvr.GetPID(tc.TxActor))
+		txpoolSvr.RegisterActor(tc.NetActor, p2pPID)
+	}
+	if lightStore, ok := ledger.DefLedger.GetStore().(*ledgerstore.LightStoreImp); ok {
+		lightStore.SetProofFetcher(p2p)
+	}
 	hserver.SetNetServerPID(p2pPID)
 	p2p.WaitForPeersStart()
 	log.Infof("P2P init success")
@@ -385,6 +427,10 @@ func initRestful(ctx *cli.Context) {
 	if !config.DefConfig.Restful.EnableHttpRestful {
 		return
 	}
+	if config.DefConfig.Common.LightNode {
+		log.Warnf("Restful is not supported by light node")
+		return
+	}
 	go restful.StartServer()
 
 	log.Infof("Restful init success")
@@ -394,6 +440,10 @@ func initWs(ctx *cli.Context) {
 	if !config.DefConfig.Ws.EnableHttpWs {
 		return
 	}
+	if config.DefConfig.Common.LightNode {
+		log.Warnf("Ws is not supported by light node")
+		return
+	}
 	websocket.StartServer()
 
 	log.Infof("Ws init success")
@@ -414,11 +464,6 @@ func logCurrBlockHeight() {
 		select {
 		case <-ticker.C:
 			log.Infof("CurrentBlockHeight = %d", ledger.DefLedger.GetCurrentBlockHeight())
-			isNeedNewFile := log.CheckIfNeedNewFile()
-			if isNeedNewFile {
-				log.ClosePrintLog()
-				log.InitLog(int(config.DefConfig.Common.LogLevel), log.PATH, log.Stdout)
-			}
 		}
 	}
 }
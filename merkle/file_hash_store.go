@@ -32,6 +32,7 @@ type HashStore interface {
 	Flush() error
 	Close()
 	GetHash(pos uint32) (common.Uint256, error)
+	Truncate(tree_size uint32) error
 }
 
 type fileHashStore struct {
@@ -127,6 +128,24 @@ func (self *fileHashStore) GetHash(pos uint32) (common.Uint256, error) {
 	return hash, nil
 }
 
+// Truncate drops hashes appended after the tree of tree_size
+func (self *fileHashStore) Truncate(tree_size uint32) error {
+	if self == nil {
+		return nil
+	}
+	err := self.checkConsistence(tree_size)
+	if err != nil {
+		return err
+	}
+	size := getStoredHashNum(tree_size) * int64(common.UINT256_SIZE)
+	err = self.file.Truncate(size)
+	if err != nil {
+		return err
+	}
+	_, err = self.file.Seek(size, io.SeekStart)
+	return err
+}
+
 type memHashStore struct {
 	hashes []common.Uint256
 }
@@ -150,3 +169,12 @@ func (self *memHashStore) Flush() error {
 }
 
 func (self *memHashStore) Close() {}
+
+func (self *memHashStore) Truncate(tree_size uint32) error {
+	num_hashes := getStoredHashNum(tree_size)
+	if int64(len(self.hashes)) < num_hashes {
+		return errors.New("stored hashes are less than expected")
+	}
+	self.hashes = self.hashes[:num_hashes]
+	return nil
+}
@@ -155,6 +155,36 @@ func TestMerkleRoot(t *testing.T) {
 
 }
 
+func TestFileHashStoreTruncate(t *testing.T) {
+	store, err := NewFileHashStore("truncate.db", 0)
+	assert.Nil(t, err)
+	defer func() { os.Remove("truncate.db") }()
+	tree := NewTree(0, nil, store)
+	ref := NewTree(0, nil, NewMemHashStore())
+	for i := 0; i < 100; i++ {
+		tree.Append([]byte{byte(i + 1)})
+		if i < 37 {
+			ref.Append([]byte{byte(i + 1)})
+		}
+	}
+	root, refRoot := tree.Root(), ref.Root()
+
+	//roll back to 37 leaves and append the rest again
+	assert.Nil(t, store.Truncate(37))
+	tree = NewTree(ref.TreeSize(), ref.Hashes(), store)
+	for i := 37; i < 100; i++ {
+		tree.Append([]byte{byte(i + 1)})
+	}
+	assert.Equal(t, root, tree.Root())
+	assert.Equal(t, refRoot, tree.merkleRoot(37))
+	store.Close()
+
+	store, err = NewFileHashStore("truncate.db", 100)
+	assert.Nil(t, err)
+	assert.NotNil(t, store.Truncate(101))
+	store.Close()
+}
+
 func TestGetSubTreeSize(t *testing.T) {
 	sizes := getSubTreeSize(7)
 	fmt.Println("sub tree size", sizes)
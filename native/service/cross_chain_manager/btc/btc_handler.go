@@ -23,7 +23,6 @@ import (
 	"fmt"
 	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 
-	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
@@ -55,6 +54,14 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 	if ok {
 		return fmt.Errorf("MultiSign, address %s already sign", params.Address)
 	}
+	replacement, err := getBtcTxReplacement(service, params.TxHash)
+	if err != nil {
+		return fmt.Errorf("MultiSign, %v", err)
+	}
+	if replacement != nil {
+		return fmt.Errorf("MultiSign, tx %s has been replaced by %s", hex.EncodeToString(params.TxHash),
+			hex.EncodeToString(replacement))
+	}
 
 	redeemScript, err := side_chain_manager.GetBtcRedeemScriptBytes(service, params.RedeemKey, params.ChainID)
 	if err != nil {
@@ -130,8 +137,10 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 			return fmt.Errorf("MultiSign, getUtxos error: %v", err)
 		}
 		txid := mtx.TxHash()
+		hasChange := false
 		for i, v := range mtx.TxOut {
 			if bytes.Equal(witScript, v.PkScript) {
+				hasChange = true
 				newUtxo := &Utxo{
 					Op: &OutPoint{
 						Hash:  txid[:],
@@ -150,6 +159,9 @@ func (this *BTCHandler) MultiSign(service *native.NativeService) error {
 				hex.EncodeToString(params.TxHash), err)
 		}
 		putStxos(service, params.ChainID, params.RedeemKey, stxos)
+		if err = onBtcTxSigned(service, params.ChainID, params.RedeemKey, params.TxHash, hasChange); err != nil {
+			return fmt.Errorf("MultiSign, %v", err)
+		}
 		service.AddNotify(
 			&event.NotifyEventInfo{
 				ContractAddress: utils.CrossChainManagerContractAddress,
@@ -271,15 +283,9 @@ func makeBtcTx(service *native.NativeService, chainID uint64, amounts map[string
 	if err != nil {
 		return fmt.Errorf("makeBtcTx, chooseUtxos error: %v", err)
 	}
-	amts := make([]uint64, len(choosed))
-	txIns := make([]*wire.TxIn, len(choosed))
-	for i, u := range choosed {
-		hash, err := chainhash.NewHash(u.Op.Hash)
-		if err != nil {
-			return fmt.Errorf("makeBtcTx, chainhash.NewHash error: %v", err)
-		}
-		txIns[i] = wire.NewTxIn(wire.NewOutPoint(hash, u.Op.Index), u.ScriptPubkey, nil)
-		amts[i] = u.Value
+	txIns, err := getTxIns(choosed)
+	if err != nil {
+		return fmt.Errorf("makeBtcTx, %v", err)
 	}
 	for i := range outs {
 		outs[i].Value = outs[i].Value - int64(float64(gasFee)/float64(amountSum)*float64(outs[i].Value))
@@ -290,27 +296,13 @@ func makeBtcTx(service *native.NativeService, chainID uint64, amounts map[string
 		return fmt.Errorf("makeBtcTx, get rawtransaction fail: %v", err)
 	}
 
-	var buf bytes.Buffer
-	err = mtx.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding)
-	if err != nil {
-		return fmt.Errorf("makeBtcTx, serialize rawtransaction fail: %v", err)
-	}
-	txHash := mtx.TxHash()
-	service.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
-		txHash[:]), buf.Bytes())
-
 	btcFromInfo := &BtcFromInfo{
 		FromTxHash:  fromTxHash,
 		FromChainID: fromChainID,
 	}
-	if err = putBtcFromInfo(service, txHash[:], btcFromInfo); err != nil {
-		return fmt.Errorf("makeBtcTx, putBtcFromInfo failed: %v", err)
+	if err = putUnsignedBtcTx(service, chainID, rk, mtx, choosed, btcFromInfo, nil); err != nil {
+		return fmt.Errorf("makeBtcTx, %v", err)
 	}
-	service.AddNotify(
-		&event.NotifyEventInfo{
-			ContractAddress: utils.CrossChainManagerContractAddress,
-			States:          []interface{}{"makeBtcTx", hex.EncodeToString(rk), hex.EncodeToString(buf.Bytes()), amts},
-		})
 
 	return nil
 }
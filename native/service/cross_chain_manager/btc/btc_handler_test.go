@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
@@ -45,6 +46,8 @@ import (
 var (
 	acct *account.Account = account.NewAccount("")
 
+	netParam = &chaincfg.TestNet3Params
+
 	rdm               = "552102dec9a415b6384ec0a9331d0cdf02020f0f1e5731c327b86e2b5a92455a289748210365b1066bcfa21987c3e207b92e309b95ca6bee5f1133cf04d6ed4ed265eafdbc21031104e387cd1a103c27fdc8a52d5c68dec25ddfb2f574fbdca405edfd8c5187de21031fdb4b44a9f20883aff505009ebc18702774c105cb04b1eecebcb294d404b1cb210387cda955196cc2b2fc0adbbbac1776f8de77b563c6d2a06a77d96457dc3d0d1f2102dd7767b6a7cc83693343ba721e0f5f4c7b4b8d85eeb7aec20d227625ec0f59d321034ad129efdab75061e8d4def08f5911495af2dae6d3e9a4b6e7aeb5186fa432fc57ae"
 	fromBtcTxid       = "2587a59e8069c563d32de9d4a2b946760d740b6963566dd7b32d8ec549f2d238"
 	fromBtcRawTx      = "010000000147d9b1bc6a52099f746863722282e3febc9ad3ad6b2eac0f2df6d2badf1df28a020000006b483045022100a1e573ba3589217e1b20d6ed53e2dda705deb3d284122c61987266e66aff074802200165734cf4519b560d806d392f10cec2aeb3071cf72c759a5abc9c33cd2f983f012103128a2c4525179e47f38cf3fefca37a61548ca4610255b3fb4ee86de2d3e80c0fffffffff031027000000000000220020216a09cb8ee51da1a91ea8942552d7936c886a10b507299003661816c0e9f18b00000000000000003d6a3b6602000000000000000000000000000000149702640a6b971ca18efc20ad73ca4e8ba390c910145cd3143f91a13fe971043e1e4605c1c23b46bf44620e0700000000001976a91428d2e8cee08857f569e5a1b147c5d5e87339e08188ac00000000"
@@ -88,7 +91,9 @@ var (
 			ChainId:      1,
 			BlocksToWait: 1,
 			Router:       0,
+			CCMCAddress:  make([]byte, 8),
 		}
+		binary.LittleEndian.PutUint64(side.CCMCAddress, uint64(utils.TyTestnet3))
 		sink := common.NewZeroCopySink(nil)
 		_ = side.Serialization(sink)
 
@@ -247,6 +252,7 @@ func TestBTCHandler_MultiSign(t *testing.T) {
 	_ = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
 	ns := getNativeFunc(nil, nil)
 	_ = addUtxos(ns, 1, 0, mtx)
+	setSideChain(ns)
 	setBtcTxParam(ns.GetCacheDB(), utxoKey)
 	registerRC(ns.GetCacheDB())
 
@@ -337,7 +343,7 @@ func TestBTCHandler_MultiSign(t *testing.T) {
 	err = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
 	assert.NoError(t, err)
 	txid = mtx.TxHash()
-	utxos, err = getUtxos(ns, 1, utxoKey)
+	utxos, err := getUtxos(ns, 1, utxoKey)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(utxos.Utxos))
 	assert.Equal(t, uint64(4000), utxos.Utxos[0].Value)
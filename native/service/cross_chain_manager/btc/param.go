@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type ConsolidateUtxosParam struct {
+	ChainID   uint64
+	RedeemKey string
+	MaxInputs uint64
+	// FeeRate in satoshi per byte, zero means the fee rate set by SetBtcTxParam
+	FeeRate uint64
+	Address common.Address
+}
+
+func (this *ConsolidateUtxosParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteString(this.RedeemKey)
+	sink.WriteVarUint(this.MaxInputs)
+	sink.WriteVarUint(this.FeeRate)
+}
+
+func (this *ConsolidateUtxosParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *ConsolidateUtxosParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ConsolidateUtxosParam deserialize chainID error")
+	}
+	redeemKey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("ConsolidateUtxosParam deserialize redeemKey error")
+	}
+	maxInputs, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ConsolidateUtxosParam deserialize maxInputs error")
+	}
+	feeRate, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ConsolidateUtxosParam deserialize feeRate error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ConsolidateUtxosParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.RedeemKey = redeemKey
+	this.MaxInputs = maxInputs
+	this.FeeRate = feeRate
+	this.Address = addr
+	return nil
+}
+
+type BumpTxFeeParam struct {
+	ChainID   uint64
+	RedeemKey string
+	TxHash    []byte
+	// FeeRate in satoshi per byte of the replacement transaction
+	FeeRate uint64
+	Address common.Address
+}
+
+func (this *BumpTxFeeParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteString(this.RedeemKey)
+	sink.WriteVarBytes(this.TxHash)
+	sink.WriteVarUint(this.FeeRate)
+}
+
+func (this *BumpTxFeeParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *BumpTxFeeParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BumpTxFeeParam deserialize chainID error")
+	}
+	redeemKey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("BumpTxFeeParam deserialize redeemKey error")
+	}
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BumpTxFeeParam deserialize txHash error")
+	}
+	feeRate, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BumpTxFeeParam deserialize feeRate error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BumpTxFeeParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.RedeemKey = redeemKey
+	this.TxHash = txHash
+	this.FeeRate = feeRate
+	this.Address = addr
+	return nil
+}
+
+type GetVaultParam struct {
+	ChainID   uint64
+	RedeemKey string
+}
+
+func (this *GetVaultParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteString(this.RedeemKey)
+}
+
+func (this *GetVaultParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("GetVaultParam deserialize chainID error")
+	}
+	redeemKey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("GetVaultParam deserialize redeemKey error")
+	}
+
+	this.ChainID = chainID
+	this.RedeemKey = redeemKey
+	return nil
+}
@@ -463,3 +463,127 @@ func (this *BtcFromInfo) Deserialization(source *common.ZeroCopySource) error {
 	this.FromChainID = fromChainID
 	return nil
 }
+
+// PendingTx is a multisig transaction built by poly that can still be replaced on the
+// bitcoin network: either its signatures are being collected, or it is fully signed and
+// its change output has not been spent by a later transaction yet.
+type PendingTx struct {
+	TxHash   []byte
+	Inputs   *Utxos
+	Fee      uint64
+	Height   uint32
+	Signed   bool
+	Replaces []byte
+}
+
+func (this *PendingTx) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.TxHash)
+	this.Inputs.Serialization(sink)
+	sink.WriteUint64(this.Fee)
+	sink.WriteUint32(this.Height)
+	sink.WriteBool(this.Signed)
+	sink.WriteVarBytes(this.Replaces)
+}
+
+func (this *PendingTx) Deserialization(source *common.ZeroCopySource) error {
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize txHash error")
+	}
+	inputs := new(Utxos)
+	if err := inputs.Deserialization(source); err != nil {
+		return fmt.Errorf("PendingTx deserialize inputs error: %v", err)
+	}
+	fee, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize fee error")
+	}
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize height error")
+	}
+	signed, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize signed error")
+	}
+	replaces, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("PendingTx deserialize replaces error")
+	}
+
+	this.TxHash = txHash
+	this.Inputs = inputs
+	this.Fee = fee
+	this.Height = height
+	this.Signed = signed
+	this.Replaces = replaces
+	return nil
+}
+
+type PendingTxs struct {
+	Txs []*PendingTx
+}
+
+func (this *PendingTxs) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Txs)))
+	for _, v := range this.Txs {
+		v.Serialization(sink)
+	}
+}
+
+func (this *PendingTxs) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("PendingTxs deserialize length error")
+	}
+	txs := make([]*PendingTx, 0, n)
+	for i := uint64(0); i < n; i++ {
+		tx := new(PendingTx)
+		if err := tx.Deserialization(source); err != nil {
+			return fmt.Errorf("PendingTxs deserialize no.%d tx error: %v", i+1, err)
+		}
+		txs = append(txs, tx)
+	}
+	this.Txs = txs
+	return nil
+}
+
+func (this *PendingTxs) find(txHash []byte) (int, *PendingTx) {
+	for i, v := range this.Txs {
+		if bytes.Equal(v.TxHash, txHash) {
+			return i, v
+		}
+	}
+	return -1, nil
+}
+
+func (this *PendingTxs) remove(idx int) {
+	this.Txs = append(this.Txs[:idx], this.Txs[idx+1:]...)
+}
+
+// BtcVault is the view of a multisig vault returned by the GetBtcVault query
+type BtcVault struct {
+	Utxos      *Utxos
+	Reserved   *Utxos
+	PendingTxs *PendingTxs
+}
+
+func (this *BtcVault) Serialization(sink *common.ZeroCopySink) {
+	this.Utxos.Serialization(sink)
+	this.Reserved.Serialization(sink)
+	this.PendingTxs.Serialization(sink)
+}
+
+func (this *BtcVault) Deserialization(source *common.ZeroCopySource) error {
+	this.Utxos, this.Reserved, this.PendingTxs = new(Utxos), new(Utxos), new(PendingTxs)
+	if err := this.Utxos.Deserialization(source); err != nil {
+		return fmt.Errorf("BtcVault deserialize utxos error: %v", err)
+	}
+	if err := this.Reserved.Deserialization(source); err != nil {
+		return fmt.Errorf("BtcVault deserialize reserved utxos error: %v", err)
+	}
+	if err := this.PendingTxs.Deserialization(source); err != nil {
+		return fmt.Errorf("BtcVault deserialize pending txs error: %v", err)
+	}
+	return nil
+}
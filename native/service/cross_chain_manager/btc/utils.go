@@ -49,6 +49,8 @@ const (
 	UTXOS                   = "utxos"
 	STXOS                   = "stxos"
 	MULTI_SIGN_INFO         = "multiSignInfo"
+	PENDING_TXS             = "pendingTxs"
+	BTC_TX_REPLACED         = "btcTxReplaced"
 	MAX_FEE_COST_PERCENTS   = 1.0
 	MAX_SELECTING_TRY_LIMIT = 1000000
 	SELECTING_K             = 4.0
@@ -91,21 +93,13 @@ func verifyFromBtcTx(native *native.NativeService, proof, tx []byte, fromChainID
 		return nil, fmt.Errorf("VerifyFromBtcProof, not crosschain btc tx, since failed to resolve parameter: %v", err)
 	}
 
-	// make sure the header with height is already synced, meaning the tx is already confirmed in btc block chain
-	bestHeader, err := btc.GetBestBlockHeader(native, fromChainID)
+	// make sure the header with height is already synced and final, meaning the tx is confirmed in btc block chain
+	finalizedHeight, err := btc.GetFinalizedHeight(native, fromChainID)
 	if err != nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, get best block header error:%s", err)
+		return nil, fmt.Errorf("VerifyFromBtcProof, get finalized height error:%s", err)
 	}
-	sideChain, err := side_chain_manager.GetSideChain(native, fromChainID)
-	if err != nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, side_chain_manager.GetSideChain error: %v", err)
-	}
-	if sideChain == nil {
-		return nil, fmt.Errorf("VerifyFromBtcProof, side chain is not registered")
-	}
-	bestHeight := bestHeader.Height
-	if bestHeight < height || bestHeight-height < uint32(sideChain.BlocksToWait-1) {
-		return nil, fmt.Errorf("verifyFromBtcTx, transaction is not confirmed, current height: %d, input height: %d", bestHeight, height)
+	if finalizedHeight < height {
+		return nil, fmt.Errorf("verifyFromBtcTx, transaction is not confirmed, finalized height: %d, input height: %d", finalizedHeight, height)
 	}
 
 	// verify btc merkle proof
@@ -356,6 +350,9 @@ func chooseUtxos(native *native.NativeService, chainID uint64, amount int64, out
 	}
 	stxos.Utxos = append(stxos.Utxos, result...)
 	putStxos(native, chainID, utxoKey, stxos)
+	if err = settlePendingTxs(native, chainID, utxoKey, result); err != nil {
+		return nil, 0, 0, fmt.Errorf("chooseUtxos, %v", err)
+	}
 
 	toSort := new(Utxos)
 	toSort.Utxos = result
@@ -0,0 +1,545 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	CONSOLIDATE_BTC_UTXOS = "ConsolidateBtcUtxos"
+	BUMP_BTC_TX_FEE       = "BumpBtcTxFee"
+	GET_BTC_VAULT         = "GetBtcVault"
+
+	// inputs of consolidations and fee bumps signal replaceability, see BIP125. Withdrawals keep
+	// the default sequence so their bytes are the same as before
+	RBF_SEQUENCE_NUM = wire.MaxTxInSequenceNum - 2
+	// satoshi per byte a replacement has to pay on top of the replaced fee
+	RBF_MIN_FEE_RATE_INCREMENT = 1
+	MIN_CONSOLIDATE_INPUTS     = 2
+)
+
+// ConsolidateUtxos merges the smallest utxos of a vault into one output paying back
+// to the vault. It is triggered by the consensus nodes and the transaction goes
+// through the same MultiSign flow as a withdrawal.
+func (this *BTCHandler) ConsolidateUtxos(service *native.NativeService) error {
+	params := new(ConsolidateUtxosParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("ConsolidateUtxos, contract params deserialize error: %v", err)
+	}
+	if params.MaxInputs < MIN_CONSOLIDATE_INPUTS {
+		return fmt.Errorf("ConsolidateUtxos, max inputs should be at least %d", MIN_CONSOLIDATE_INPUTS)
+	}
+	rk, err := hex.DecodeString(params.RedeemKey)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, failed to decode redeem key %s: %v", params.RedeemKey, err)
+	}
+	ok, err := checkVaultSigns(service, CONSOLIDATE_BTC_UTXOS, params.Address, params.serializeContent)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	redeemScript, err := side_chain_manager.GetBtcRedeemScriptBytes(service, params.RedeemKey, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, get btc redeem script with redeem key %v from db error: %v", params.RedeemKey, err)
+	}
+	netParam, err := getNetParam(service, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, %v", err)
+	}
+	_, addrs, m, err := txscript.ExtractPkScriptAddrs(redeemScript, netParam)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, failed to extract pkscript addrs: %v", err)
+	}
+	detail, err := side_chain_manager.GetBtcTxParam(service, rk, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, failed to get btcTxParam: %v", err)
+	}
+	if detail == nil {
+		return fmt.Errorf("ConsolidateUtxos, no btcTxParam is set for redeem key %s", params.RedeemKey)
+	}
+	feeRate := params.FeeRate
+	if feeRate == 0 {
+		feeRate = detail.FeeRate
+	}
+	script, err := getLockScript(redeemScript, netParam)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, %v", err)
+	}
+
+	utxoKey := hex.EncodeToString(rk)
+	utxos, err := getUtxos(service, params.ChainID, utxoKey)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, getUtxos error: %v", err)
+	}
+	if utxos.Len() < MIN_CONSOLIDATE_INPUTS {
+		return fmt.Errorf("ConsolidateUtxos, only %d utxos in vault, nothing to consolidate", utxos.Len())
+	}
+	// spend the dust first
+	sort.Sort(utxos)
+	num := params.MaxInputs
+	if num > uint64(utxos.Len()) {
+		num = uint64(utxos.Len())
+	}
+	choosed := make([]*Utxo, num)
+	copy(choosed, utxos.Utxos[:num])
+
+	out := wire.NewTxOut(0, script)
+	cs := &CoinSelector{
+		txOuts:  []*wire.TxOut{out},
+		feeRate: feeRate,
+		m:       m,
+		n:       len(addrs),
+	}
+	fee := cs.estimateTxFee(choosed)
+	var sum uint64
+	for _, u := range choosed {
+		sum += u.Value
+	}
+	if sum < fee+detail.MinChange {
+		return fmt.Errorf("ConsolidateUtxos, sum %d of %d utxos can not cover fee %d and min-change %d", sum, num, fee,
+			detail.MinChange)
+	}
+	out.Value = int64(sum - fee)
+
+	txIns, err := getTxIns(choosed)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, %v", err)
+	}
+	for _, in := range txIns {
+		in.Sequence = RBF_SEQUENCE_NUM
+	}
+	mtx, err := getUnsignedTx(txIns, nil, out, nil)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, get rawtransaction fail: %v", err)
+	}
+
+	utxos.Utxos = utxos.Utxos[num:]
+	putUtxos(service, params.ChainID, utxoKey, utxos)
+	stxos, err := getStxos(service, params.ChainID, utxoKey)
+	if err != nil {
+		return fmt.Errorf("ConsolidateUtxos, failed to get stxos: %v", err)
+	}
+	stxos.Utxos = append(stxos.Utxos, choosed...)
+	putStxos(service, params.ChainID, utxoKey, stxos)
+	if err = settlePendingTxs(service, params.ChainID, utxoKey, choosed); err != nil {
+		return fmt.Errorf("ConsolidateUtxos, %v", err)
+	}
+
+	// consolidation is not caused by any cross chain tx, so the poly tx is recorded as its source
+	polyTxHash := service.GetTx().Hash()
+	fromInfo := &BtcFromInfo{
+		FromTxHash: polyTxHash.ToArray(),
+	}
+	if err = putUnsignedBtcTx(service, params.ChainID, rk, mtx, choosed, fromInfo, nil); err != nil {
+		return fmt.Errorf("ConsolidateUtxos, %v", err)
+	}
+	txHash := mtx.TxHash()
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"btcUtxosConsolidated", params.ChainID, params.RedeemKey,
+				hex.EncodeToString(txHash[:]), num, sum, fee},
+		})
+	return nil
+}
+
+// BumpTxFee replaces a pending multisig transaction with one paying a higher fee rate.
+// The extra fee is taken from the change output. Poly can not observe confirmations of
+// its own transactions on bitcoin, so the consensus nodes are expected to only bump
+// transactions which are stuck in the mempool.
+func (this *BTCHandler) BumpTxFee(service *native.NativeService) error {
+	params := new(BumpTxFeeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("BumpTxFee, contract params deserialize error: %v", err)
+	}
+	rk, err := hex.DecodeString(params.RedeemKey)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, failed to decode redeem key %s: %v", params.RedeemKey, err)
+	}
+	ok, err := checkVaultSigns(service, BUMP_BTC_TX_FEE, params.Address, params.serializeContent)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	utxoKey := hex.EncodeToString(rk)
+	pendings, err := getPendingTxs(service, params.ChainID, utxoKey)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	idx, pending := pendings.find(params.TxHash)
+	if pending == nil {
+		return fmt.Errorf("BumpTxFee, tx %s is not pending", hex.EncodeToString(params.TxHash))
+	}
+	redeemScript, err := side_chain_manager.GetBtcRedeemScriptBytes(service, params.RedeemKey, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, get btc redeem script with redeem key %v from db error: %v", params.RedeemKey, err)
+	}
+	netParam, err := getNetParam(service, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	_, addrs, m, err := txscript.ExtractPkScriptAddrs(redeemScript, netParam)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, failed to extract pkscript addrs: %v", err)
+	}
+	detail, err := side_chain_manager.GetBtcTxParam(service, rk, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, failed to get btcTxParam: %v", err)
+	}
+	if detail == nil {
+		return fmt.Errorf("BumpTxFee, no btcTxParam is set for redeem key %s", params.RedeemKey)
+	}
+	witScript, err := getLockScript(redeemScript, netParam)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	mtx, err := getUnsignedBtcTx(service, params.TxHash)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	changeIdx := -1
+	for i, v := range mtx.TxOut {
+		if bytes.Equal(witScript, v.PkScript) {
+			changeIdx = i
+		}
+	}
+	if changeIdx < 0 {
+		return fmt.Errorf("BumpTxFee, tx %s has no change output to pay the fee", hex.EncodeToString(params.TxHash))
+	}
+
+	cs := &CoinSelector{
+		txOuts:  mtx.TxOut,
+		feeRate: params.FeeRate,
+		m:       m,
+		n:       len(addrs),
+	}
+	size := uint64(cs.estimateTxSize(pending.Inputs.Utxos))
+	newFee := cs.estimateTxFee(pending.Inputs.Utxos)
+	if newFee < pending.Fee+size*RBF_MIN_FEE_RATE_INCREMENT {
+		return fmt.Errorf("BumpTxFee, fee %d with rate %d is too low to replace fee %d", newFee, params.FeeRate,
+			pending.Fee)
+	}
+	delta := newFee - pending.Fee
+	change := uint64(mtx.TxOut[changeIdx].Value)
+	if change < delta+detail.MinChange {
+		return fmt.Errorf("BumpTxFee, change %d can not cover extra fee %d and min-change %d", change, delta,
+			detail.MinChange)
+	}
+
+	if pending.Signed {
+		// the change was already counted as a utxo, take it back and reserve the inputs again
+		utxos, err := getUtxos(service, params.ChainID, utxoKey)
+		if err != nil {
+			return fmt.Errorf("BumpTxFee, getUtxos error: %v", err)
+		}
+		found := false
+		for i, u := range utxos.Utxos {
+			if bytes.Equal(u.Op.Hash, params.TxHash) && u.Op.Index == uint32(changeIdx) {
+				utxos.Utxos = append(utxos.Utxos[:i], utxos.Utxos[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("BumpTxFee, change of tx %s is already spent", hex.EncodeToString(params.TxHash))
+		}
+		putUtxos(service, params.ChainID, utxoKey, utxos)
+		stxos, err := getStxos(service, params.ChainID, utxoKey)
+		if err != nil {
+			return fmt.Errorf("BumpTxFee, failed to get stxos: %v", err)
+		}
+		stxos.Utxos = append(stxos.Utxos, pending.Inputs.Utxos...)
+		putStxos(service, params.ChainID, utxoKey, stxos)
+	}
+
+	replacement := mtx.Copy()
+	replacement.TxOut[changeIdx].Value -= int64(delta)
+	// a replaced withdrawal did not signal replaceability, so only full-rbf nodes relay its replacement
+	for _, in := range replacement.TxIn {
+		in.Sequence = RBF_SEQUENCE_NUM
+	}
+	fromInfo, err := getBtcFromInfo(service, params.TxHash)
+	if err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	pendings.remove(idx)
+	putPendingTxs(service, params.ChainID, utxoKey, pendings)
+	newHash := replacement.TxHash()
+	putBtcTxReplacement(service, params.TxHash, newHash[:])
+	if err = putUnsignedBtcTx(service, params.ChainID, rk, replacement, pending.Inputs.Utxos, fromInfo,
+		params.TxHash); err != nil {
+		return fmt.Errorf("BumpTxFee, %v", err)
+	}
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"btcTxFeeBumped", params.ChainID, params.RedeemKey,
+				hex.EncodeToString(params.TxHash), hex.EncodeToString(newHash[:]), pending.Fee, newFee},
+		})
+	return nil
+}
+
+// GetVault returns the utxos, the reserved utxos and the pending multisig transactions of a vault
+func (this *BTCHandler) GetVault(service *native.NativeService) ([]byte, error) {
+	params := new(GetVaultParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("GetVault, contract params deserialize error: %v", err)
+	}
+	utxos, err := getUtxos(service, params.ChainID, params.RedeemKey)
+	if err != nil {
+		return nil, fmt.Errorf("GetVault, getUtxos error: %v", err)
+	}
+	stxos, err := getStxos(service, params.ChainID, params.RedeemKey)
+	if err != nil {
+		return nil, fmt.Errorf("GetVault, getStxos error: %v", err)
+	}
+	pendings, err := getPendingTxs(service, params.ChainID, params.RedeemKey)
+	if err != nil {
+		return nil, fmt.Errorf("GetVault, %v", err)
+	}
+	vault := &BtcVault{
+		Utxos:      utxos,
+		Reserved:   stxos,
+		PendingTxs: pendings,
+	}
+	sink := common.NewZeroCopySink(nil)
+	vault.Serialization(sink)
+	return sink.Bytes(), nil
+}
+
+// isVaultEnabled returns whether pending txs and replacements of vaults are kept at current height,
+// withdrawals before the fork height write the same states as before
+func isVaultEnabled(service *native.NativeService) bool {
+	return service.GetHeight() >= config.GetBtcVaultHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+func checkVaultSigns(service *native.NativeService, method string, address common.Address,
+	serializeContent func(sink *common.ZeroCopySink)) (bool, error) {
+	if !isVaultEnabled(service) {
+		return false, fmt.Errorf("btc vault is not enabled until height %d",
+			config.GetBtcVaultHeight(config.DefConfig.P2PNode.NetworkId))
+	}
+	if err := utils.ValidateOwner(service, address); err != nil {
+		return false, fmt.Errorf("checkWitness error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	serializeContent(sink)
+	ok, err := node_manager.CheckConsensusSigns(service, method, sink.Bytes(), address)
+	if err != nil {
+		return false, fmt.Errorf("CheckConsensusSigns error: %v", err)
+	}
+	return ok, nil
+}
+
+func getTxIns(utxos []*Utxo) ([]*wire.TxIn, error) {
+	txIns := make([]*wire.TxIn, len(utxos))
+	for i, u := range utxos {
+		hash, err := chainhash.NewHash(u.Op.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("getTxIns, chainhash.NewHash error: %v", err)
+		}
+		txIns[i] = wire.NewTxIn(wire.NewOutPoint(hash, u.Op.Index), u.ScriptPubkey, nil)
+	}
+	return txIns, nil
+}
+
+// putUnsignedBtcTx stores a transaction waiting for signatures, records it as pending
+// and notifies the signers with the makeBtcTx event.
+func putUnsignedBtcTx(service *native.NativeService, chainID uint64, rk []byte, mtx *wire.MsgTx, inputs []*Utxo,
+	fromInfo *BtcFromInfo, replaces []byte) error {
+	var buf bytes.Buffer
+	err := mtx.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding)
+	if err != nil {
+		return fmt.Errorf("putUnsignedBtcTx, serialize rawtransaction fail: %v", err)
+	}
+	txHash := mtx.TxHash()
+	service.GetCacheDB().Put(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
+		txHash[:]), buf.Bytes())
+	if err = putBtcFromInfo(service, txHash[:], fromInfo); err != nil {
+		return fmt.Errorf("putUnsignedBtcTx, putBtcFromInfo failed: %v", err)
+	}
+
+	amts := make([]uint64, len(inputs))
+	var sumIn, sumOut uint64
+	for i, u := range inputs {
+		amts[i] = u.Value
+		sumIn += u.Value
+	}
+	for _, out := range mtx.TxOut {
+		sumOut += uint64(out.Value)
+	}
+	utxoKey := hex.EncodeToString(rk)
+	if isVaultEnabled(service) {
+		pendings, err := getPendingTxs(service, chainID, utxoKey)
+		if err != nil {
+			return fmt.Errorf("putUnsignedBtcTx, %v", err)
+		}
+		pendings.Txs = append(pendings.Txs, &PendingTx{
+			TxHash:   txHash[:],
+			Inputs:   &Utxos{Utxos: inputs},
+			Fee:      sumIn - sumOut,
+			Height:   service.GetHeight(),
+			Replaces: replaces,
+		})
+		putPendingTxs(service, chainID, utxoKey, pendings)
+	}
+
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"makeBtcTx", utxoKey, hex.EncodeToString(buf.Bytes()), amts},
+		})
+	return nil
+}
+
+func getUnsignedBtcTx(service *native.NativeService, txHash []byte) (*wire.MsgTx, error) {
+	txb, err := service.GetCacheDB().Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_PREFIX),
+		txHash))
+	if err != nil {
+		return nil, fmt.Errorf("getUnsignedBtcTx, failed to get tx %s from cacheDB: %v", hex.EncodeToString(txHash), err)
+	}
+	if txb == nil {
+		return nil, fmt.Errorf("getUnsignedBtcTx, tx %s not found", hex.EncodeToString(txHash))
+	}
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	if err = mtx.BtcDecode(bytes.NewBuffer(txb), wire.ProtocolVersion, wire.LatestEncoding); err != nil {
+		return nil, fmt.Errorf("getUnsignedBtcTx, failed to decode tx: %v", err)
+	}
+	return mtx, nil
+}
+
+// settlePendingTxs drops the pending txs whose outputs are spent by a new tx,
+// replacing them would invalidate the spending tx.
+func settlePendingTxs(service *native.NativeService, chainID uint64, utxoKey string, spent []*Utxo) error {
+	if !isVaultEnabled(service) {
+		return nil
+	}
+	pendings, err := getPendingTxs(service, chainID, utxoKey)
+	if err != nil {
+		return fmt.Errorf("settlePendingTxs, %v", err)
+	}
+	l := len(pendings.Txs)
+	for _, u := range spent {
+		if idx, _ := pendings.find(u.Op.Hash); idx >= 0 {
+			pendings.remove(idx)
+		}
+	}
+	if len(pendings.Txs) != l {
+		putPendingTxs(service, chainID, utxoKey, pendings)
+	}
+	return nil
+}
+
+// onBtcTxSigned keeps a fully signed tx pending only if it has change to bump the fee with
+func onBtcTxSigned(service *native.NativeService, chainID uint64, utxoKey string, txHash []byte, hasChange bool) error {
+	if !isVaultEnabled(service) {
+		return nil
+	}
+	pendings, err := getPendingTxs(service, chainID, utxoKey)
+	if err != nil {
+		return fmt.Errorf("onBtcTxSigned, %v", err)
+	}
+	idx, pending := pendings.find(txHash)
+	if pending == nil {
+		return nil
+	}
+	if hasChange {
+		pending.Signed = true
+	} else {
+		pendings.remove(idx)
+	}
+	putPendingTxs(service, chainID, utxoKey, pendings)
+	return nil
+}
+
+func putPendingTxs(service *native.NativeService, chainID uint64, utxoKey string, pendings *PendingTxs) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PENDING_TXS), utils.GetUint64Bytes(chainID),
+		[]byte(utxoKey))
+	if len(pendings.Txs) == 0 {
+		service.GetCacheDB().Delete(key)
+		return
+	}
+	sink := common.NewZeroCopySink(nil)
+	pendings.Serialization(sink)
+	service.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func getPendingTxs(service *native.NativeService, chainID uint64, utxoKey string) (*PendingTxs, error) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(PENDING_TXS), utils.GetUint64Bytes(chainID),
+		[]byte(utxoKey))
+	store, err := service.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getPendingTxs, get pending txs error: %v", err)
+	}
+	pendings := &PendingTxs{
+		Txs: make([]*PendingTx, 0),
+	}
+	if store != nil {
+		pendingsBytes, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return nil, fmt.Errorf("getPendingTxs, deserialize from raw storage item err:%v", err)
+		}
+		if err = pendings.Deserialization(common.NewZeroCopySource(pendingsBytes)); err != nil {
+			return nil, fmt.Errorf("getPendingTxs, deserialize pending txs err:%v", err)
+		}
+	}
+	return pendings, nil
+}
+
+func putBtcTxReplacement(service *native.NativeService, txHash, replacement []byte) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_REPLACED), txHash)
+	service.GetCacheDB().Put(key, cstates.GenRawStorageItem(replacement))
+}
+
+func getBtcTxReplacement(service *native.NativeService, txHash []byte) ([]byte, error) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BTC_TX_REPLACED), txHash)
+	store, err := service.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getBtcTxReplacement, get replacement error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	replacement, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getBtcTxReplacement, deserialize from raw storage item err:%v", err)
+	}
+	return replacement, nil
+}
@@ -0,0 +1,317 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newVaultNative(args []byte, db *storage.CacheDB) *native.NativeService {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns, _ := native.NewNativeService(db, tx, 0, 10, common.Uint256{0}, 0, args, false)
+	return ns
+}
+
+// setVaultHeight moves the btc vault fork of the configured network to height until the test ends
+func setVaultHeight(t *testing.T, height uint32) {
+	id := config.DefConfig.P2PNode.NetworkId
+	old, present := config.BTC_VAULT_HEIGHT[id]
+	config.BTC_VAULT_HEIGHT[id] = height
+	t.Cleanup(func() {
+		if present {
+			config.BTC_VAULT_HEIGHT[id] = old
+		} else {
+			delete(config.BTC_VAULT_HEIGHT, id)
+		}
+	})
+}
+
+// newVaultDB makes acct the only consensus node and fills the vault of utxoKey with the given values
+func newVaultDB(t *testing.T, values ...uint64) *storage.CacheDB {
+	setVaultHeight(t, 0)
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		states.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			vconfig.PubkeyID(acct.PublicKey): {
+				Address:    acct.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+			},
+		},
+	}
+	sink.Reset()
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		states.GenRawStorageItem(sink.Bytes()))
+
+	netType := make([]byte, 8)
+	binary.LittleEndian.PutUint64(netType, uint64(utils.TyTestnet3))
+	ns := newVaultNative(nil, db)
+	err := side_chain_manager.PutSideChain(ns, &side_chain_manager.SideChain{
+		ChainId:      1,
+		Router:       utils.BTC_ROUTER,
+		Name:         "btc",
+		BlocksToWait: 1,
+		CCMCAddress:  netType,
+	})
+	assert.NoError(t, err)
+	db = setBtcTxParam(registerRC(db), utxoKey)
+
+	redeem, _ := hex.DecodeString(rdm)
+	script, err := getLockScript(redeem, &chaincfg.TestNet3Params)
+	assert.NoError(t, err)
+	utxos := &Utxos{Utxos: make([]*Utxo, len(values))}
+	for i, v := range values {
+		utxos.Utxos[i] = &Utxo{
+			Op:           &OutPoint{Hash: chainhash.DoubleHashB([]byte{byte(i)}), Index: uint32(i)},
+			Value:        v,
+			ScriptPubkey: script,
+		}
+	}
+	putUtxos(ns, 1, utxoKey, utxos)
+	return db
+}
+
+func consolidate(db *storage.CacheDB, maxInputs, feeRate uint64) error {
+	param := &ConsolidateUtxosParam{
+		ChainID:   1,
+		RedeemKey: utxoKey,
+		MaxInputs: maxInputs,
+		FeeRate:   feeRate,
+		Address:   acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return NewBTCHandler().ConsolidateUtxos(newVaultNative(sink.Bytes(), db))
+}
+
+func bumpFee(db *storage.CacheDB, txHash []byte, feeRate uint64) error {
+	param := &BumpTxFeeParam{
+		ChainID:   1,
+		RedeemKey: utxoKey,
+		TxHash:    txHash,
+		FeeRate:   feeRate,
+		Address:   acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return NewBTCHandler().BumpTxFee(newVaultNative(sink.Bytes(), db))
+}
+
+func getVault(t *testing.T, db *storage.CacheDB) *BtcVault {
+	param := &GetVaultParam{
+		ChainID:   1,
+		RedeemKey: utxoKey,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	res, err := NewBTCHandler().GetVault(newVaultNative(sink.Bytes(), db))
+	assert.NoError(t, err)
+	vault := new(BtcVault)
+	assert.NoError(t, vault.Deserialization(common.NewZeroCopySource(res)))
+	return vault
+}
+
+func TestConsolidateUtxos(t *testing.T) {
+	db := newVaultDB(t, 50000, 3000, 40000, 1000, 2000)
+	assert.Error(t, consolidate(db, 1, 0))
+	assert.NoError(t, consolidate(db, 3, 0))
+
+	vault := getVault(t, db)
+	assert.Equal(t, 2, vault.Utxos.Len())
+	assert.Equal(t, 3, vault.Reserved.Len())
+	for _, u := range vault.Utxos.Utxos {
+		assert.True(t, u.Value >= 40000)
+	}
+	assert.Equal(t, 1, len(vault.PendingTxs.Txs))
+	pending := vault.PendingTxs.Txs[0]
+	assert.False(t, pending.Signed)
+	assert.Equal(t, 3, pending.Inputs.Len())
+	assert.Equal(t, uint32(10), pending.Height)
+
+	mtx, err := getUnsignedBtcTx(newVaultNative(nil, db), pending.TxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(mtx.TxIn))
+	assert.Equal(t, 1, len(mtx.TxOut))
+	assert.Equal(t, int64(6000-pending.Fee), mtx.TxOut[0].Value)
+	for _, in := range mtx.TxIn {
+		assert.Equal(t, uint32(RBF_SEQUENCE_NUM), in.Sequence)
+	}
+
+	// utxos left can not cover the fee and min-change any more
+	db = newVaultDB(t, 1000, 1200)
+	assert.Error(t, consolidate(db, 2, 0))
+}
+
+func TestBumpTxFee(t *testing.T) {
+	db := newVaultDB(t, 50000, 30000, 40000)
+	assert.NoError(t, consolidate(db, 3, 2))
+	old := getVault(t, db).PendingTxs.Txs[0]
+
+	assert.Error(t, bumpFee(db, []byte{1}, 10))
+	assert.Error(t, bumpFee(db, old.TxHash, 2))
+	assert.NoError(t, bumpFee(db, old.TxHash, 10))
+
+	vault := getVault(t, db)
+	assert.Equal(t, 1, len(vault.PendingTxs.Txs))
+	bumped := vault.PendingTxs.Txs[0]
+	assert.Equal(t, old.TxHash, bumped.Replaces)
+	assert.True(t, bumped.Fee > old.Fee)
+	assert.Equal(t, 3, vault.Reserved.Len())
+
+	ns := newVaultNative(nil, db)
+	replacement, err := getBtcTxReplacement(ns, old.TxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, bumped.TxHash, replacement)
+	oldTx, _ := getUnsignedBtcTx(ns, old.TxHash)
+	newTx, err := getUnsignedBtcTx(ns, bumped.TxHash)
+	assert.NoError(t, err)
+	assert.Equal(t, oldTx.TxOut[0].Value-int64(bumped.Fee-old.Fee), newTx.TxOut[0].Value)
+	from, err := getBtcFromInfo(ns, bumped.TxHash)
+	assert.NoError(t, err)
+	polyTxHash := ns.GetTx().Hash()
+	assert.Equal(t, polyTxHash.ToArray(), from.FromTxHash)
+
+	// the replaced tx is not pending any more
+	assert.Error(t, bumpFee(db, old.TxHash, 20))
+}
+
+func TestBumpSignedTxFee(t *testing.T) {
+	db := newVaultDB(t, 50000, 30000, 40000)
+	assert.NoError(t, consolidate(db, 3, 2))
+	ns := newVaultNative(nil, db)
+	pending := getVault(t, db).PendingTxs.Txs[0]
+
+	// what MultiSign does once all signatures are collected
+	mtx, _ := getUnsignedBtcTx(ns, pending.TxHash)
+	_, stxos, err := getStxoAmts(ns, 1, mtx.TxIn, utxoKey)
+	assert.NoError(t, err)
+	putStxos(ns, 1, utxoKey, stxos)
+	utxos, _ := getUtxos(ns, 1, utxoKey)
+	utxos.Utxos = append(utxos.Utxos, &Utxo{
+		Op:           &OutPoint{Hash: pending.TxHash, Index: 0},
+		Value:        uint64(mtx.TxOut[0].Value),
+		ScriptPubkey: mtx.TxOut[0].PkScript,
+	})
+	putUtxos(ns, 1, utxoKey, utxos)
+	assert.NoError(t, onBtcTxSigned(ns, 1, utxoKey, pending.TxHash, true))
+	vault := getVault(t, db)
+	assert.True(t, vault.PendingTxs.Txs[0].Signed)
+	assert.Equal(t, 0, vault.Reserved.Len())
+
+	assert.NoError(t, bumpFee(db, pending.TxHash, 10))
+	vault = getVault(t, db)
+	assert.Equal(t, 0, vault.Utxos.Len())
+	assert.Equal(t, 3, vault.Reserved.Len())
+	assert.False(t, vault.PendingTxs.Txs[0].Signed)
+
+	// spending the change of a signed tx makes it irreplaceable
+	bumped := vault.PendingTxs.Txs[0]
+	assert.NoError(t, onBtcTxSigned(ns, 1, utxoKey, bumped.TxHash, true))
+	change := &Utxo{Op: &OutPoint{Hash: bumped.TxHash, Index: 0}}
+	assert.NoError(t, settlePendingTxs(ns, 1, utxoKey, []*Utxo{change}))
+	assert.Equal(t, 0, len(getVault(t, db).PendingTxs.Txs))
+	assert.Error(t, bumpFee(db, bumped.TxHash, 20))
+}
+
+func TestWithdrawalNotReplaceable(t *testing.T) {
+	rawTx, _ := hex.DecodeString(fromBtcRawTx)
+	mtx := wire.NewMsgTx(wire.TxVersion)
+	_ = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
+	ns := getNativeFunc(nil, nil)
+	_ = addUtxos(ns, 1, 0, mtx)
+	setSideChain(ns)
+	setBtcTxParam(ns.GetCacheDB(), utxoKey)
+	registerRC(ns.GetCacheDB())
+
+	rb, _ := hex.DecodeString(rdm)
+	assert.NoError(t, makeBtcTx(ns, 1, map[string]int64{"mjEoyyCPsLzJ23xMX6Mti13zMyN36kzn57": 6000}, []byte{123},
+		2, rb, btcutil.Hash160(rb)))
+	// withdrawals are encoded the same as before consolidation and fee bump were added
+	raw := ns.GetNotify()[0].States.([]interface{})[2].(string)
+	assert.Equal(t, "010000000138d2f249c58e2db3d76d5663690b740d7646b9a2d4e92dd363c569809ea5872500000000220020216a"+
+		"09cb8ee51da1a91ea8942552d7936c886a10b507299003661816c0e9f18bffffffff0232150000000000001976a91428d2e8cee08857f5"+
+		"69e5a1b147c5d5e87339e08188aca00f000000000000220020216a09cb8ee51da1a91ea8942552d7936c886a10b507299003661816c0e9"+
+		"f18b00000000", raw)
+	rawTx, _ = hex.DecodeString(raw)
+	_ = mtx.BtcDecode(bytes.NewBuffer(rawTx), wire.ProtocolVersion, wire.LatestEncoding)
+	for _, in := range mtx.TxIn {
+		assert.Equal(t, uint32(wire.MaxTxInSequenceNum), in.Sequence)
+	}
+}
+
+func TestVaultBeforeForkHeight(t *testing.T) {
+	db := newVaultDB(t, 50000, 30000, 40000)
+	assert.NoError(t, consolidate(db, 2, 0))
+	pending := getVault(t, db).PendingTxs.Txs[0]
+
+	// vault natives run at height 10
+	setVaultHeight(t, 11)
+	assert.Error(t, consolidate(db, 2, 0))
+	assert.Error(t, bumpFee(db, pending.TxHash, 10))
+	vault := getVault(t, db)
+	assert.Equal(t, 1, vault.Utxos.Len())
+	assert.Equal(t, 1, len(vault.PendingTxs.Txs))
+
+	ns := newVaultNative(nil, db)
+	rb, _ := hex.DecodeString(rdm)
+	assert.NoError(t, makeBtcTx(ns, 1, map[string]int64{"mjEoyyCPsLzJ23xMX6Mti13zMyN36kzn57": 6000}, []byte{123},
+		2, rb, btcutil.Hash160(rb)))
+	assert.NoError(t, onBtcTxSigned(ns, 1, utxoKey, pending.TxHash, false))
+	change := &Utxo{Op: &OutPoint{Hash: pending.TxHash, Index: 0}}
+	assert.NoError(t, settlePendingTxs(ns, 1, utxoKey, []*Utxo{change}))
+
+	// the withdrawal is neither recorded as pending nor settles the pending tx
+	vault = getVault(t, db)
+	assert.Equal(t, 0, vault.Utxos.Len())
+	assert.Equal(t, 1, len(vault.PendingTxs.Txs))
+	assert.Equal(t, pending.TxHash, vault.PendingTxs.Txs[0].TxHash)
+	assert.False(t, vault.PendingTxs.Txs[0].Signed)
+}
@@ -0,0 +1,272 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package committee
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	SET_ATTESTER_COMMITTEE       = "SetAttesterCommittee"
+	BLACK_ATTESTER               = "BlackAttester"
+	WHITE_ATTESTER               = "WhiteAttester"
+	REPORT_ATTESTER_EQUIVOCATION = "ReportAttesterEquivocation"
+	GET_ATTESTER_COMMITTEE       = "GetAttesterCommittee"
+)
+
+// CommitteeHandler verifies cross chain transactions of chains whose headers can not
+// be verified on poly, relying on a governance registered committee of attesters
+type CommitteeHandler struct {
+}
+
+func NewCommitteeHandler() *CommitteeHandler {
+	return &CommitteeHandler{}
+}
+
+func (this *CommitteeHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, contract params deserialize error: %v", err)
+	}
+	committee, err := getCommittee(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, %v", err)
+	}
+	if committee == nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, no attester committee for chain %d", params.SourceChainID)
+	}
+
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(params.Extra)); err != nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, deserialize MakeTxParam error:%s", err)
+	}
+	attestations := new(Attestations)
+	if err := attestations.Deserialization(common.NewZeroCopySource(params.Proof)); err != nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, deserialize Attestations error:%s", err)
+	}
+
+	//count valid attestations of current members, each attester counts once
+	signed := make(map[string]bool)
+	for _, v := range attestations.List {
+		k := hex.EncodeToString(v.PubKey)
+		if signed[k] || !committee.IsMember(v.PubKey) {
+			continue
+		}
+		blacked, err := isAttesterBlacked(service, params.SourceChainID, v.PubKey)
+		if err != nil {
+			return nil, fmt.Errorf("committee MakeDepositProposal, %v", err)
+		}
+		if blacked {
+			continue
+		}
+		if err := verifyAttestation(params.SourceChainID, txParam.TxHash, params.Extra, v.PubKey, v.Sig); err != nil {
+			return nil, fmt.Errorf("committee MakeDepositProposal, attestation of %s: %v", k, err)
+		}
+		signed[k] = true
+	}
+	if uint64(len(signed)) < committee.Threshold {
+		return nil, fmt.Errorf("committee MakeDepositProposal, not enough attestations: %d of %d required",
+			len(signed), committee.Threshold)
+	}
+
+	if err := scom.CheckDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, check done transaction error:%s", err)
+	}
+	if err := scom.PutDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("committee MakeDepositProposal, PutDoneTx error:%s", err)
+	}
+	return txParam, nil
+}
+
+// SetCommittee replaces the attester committee of a side chain and starts a new epoch.
+// The threshold must be a majority of the attesters so that two conflicting payloads
+// can not both be approved.
+func (this *CommitteeHandler) SetCommittee(service *native.NativeService) error {
+	params := new(SetAttesterCommitteeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("SetCommittee, contract params deserialize error: %v", err)
+	}
+	if err := checkAttestationChain(service, params.ChainID); err != nil {
+		return fmt.Errorf("SetCommittee, %v", err)
+	}
+	n := uint64(len(params.Attesters))
+	if params.Threshold == 0 || params.Threshold > n || 2*params.Threshold <= n {
+		return fmt.Errorf("SetCommittee, invalid threshold %d for %d attesters", params.Threshold, n)
+	}
+	attesters := make(map[string]bool)
+	for _, v := range params.Attesters {
+		if _, err := keypair.DeserializePublicKey(v); err != nil {
+			return fmt.Errorf("SetCommittee, keypair.DeserializePublicKey error: %v", err)
+		}
+		k := hex.EncodeToString(v)
+		if attesters[k] {
+			return fmt.Errorf("SetCommittee, duplicate attester %s", k)
+		}
+		attesters[k] = true
+	}
+
+	ok, err := checkCommitteeSigns(service, SET_ATTESTER_COMMITTEE, params.Address, params.serializeContent)
+	if err != nil {
+		return fmt.Errorf("SetCommittee, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	committee, err := getCommittee(service, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("SetCommittee, %v", err)
+	}
+	epoch := uint64(0)
+	if committee != nil {
+		epoch = committee.Epoch + 1
+	}
+	putCommittee(service, &AttesterCommittee{
+		ChainID:   params.ChainID,
+		Epoch:     epoch,
+		Threshold: params.Threshold,
+		Attesters: params.Attesters,
+	})
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"setAttesterCommittee", params.ChainID, epoch, params.Threshold, n},
+		})
+	return nil
+}
+
+func (this *CommitteeHandler) BlackAttester(service *native.NativeService) error {
+	params := new(BlackAttesterParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("BlackAttester, contract params deserialize error: %v", err)
+	}
+	if err := checkAttestationChain(service, params.ChainID); err != nil {
+		return fmt.Errorf("BlackAttester, %v", err)
+	}
+	ok, err := checkCommitteeSigns(service, BLACK_ATTESTER, params.Address, params.serializeContent)
+	if err != nil {
+		return fmt.Errorf("BlackAttester, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	putBlackedAttester(service, params.ChainID, params.PubKey)
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"blackAttester", params.ChainID, hex.EncodeToString(params.PubKey)},
+		})
+	return nil
+}
+
+func (this *CommitteeHandler) WhiteAttester(service *native.NativeService) error {
+	params := new(BlackAttesterParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("WhiteAttester, contract params deserialize error: %v", err)
+	}
+	if err := checkAttestationChain(service, params.ChainID); err != nil {
+		return fmt.Errorf("WhiteAttester, %v", err)
+	}
+	ok, err := checkCommitteeSigns(service, WHITE_ATTESTER, params.Address, params.serializeContent)
+	if err != nil {
+		return fmt.Errorf("WhiteAttester, %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	removeBlackedAttester(service, params.ChainID, params.PubKey)
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States:          []interface{}{"whiteAttester", params.ChainID, hex.EncodeToString(params.PubKey)},
+		})
+	return nil
+}
+
+// ReportEquivocation blacks an attester that signed two different payloads for the
+// same transaction. Anyone can report since the two signatures are the evidence.
+func (this *CommitteeHandler) ReportEquivocation(service *native.NativeService) error {
+	params := new(ReportEquivocationParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("ReportEquivocation, contract params deserialize error: %v", err)
+	}
+	if err := utils.ValidateOwner(service, params.Address); err != nil {
+		return fmt.Errorf("ReportEquivocation, checkWitness error: %v", err)
+	}
+	if bytes.Equal(params.Payload1, params.Payload2) {
+		return fmt.Errorf("ReportEquivocation, payloads are the same")
+	}
+	committee, err := getCommittee(service, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("ReportEquivocation, %v", err)
+	}
+	if committee == nil || !committee.IsMember(params.PubKey) {
+		return fmt.Errorf("ReportEquivocation, %s is not an attester of chain %d", hex.EncodeToString(params.PubKey),
+			params.ChainID)
+	}
+	if err := verifyAttestation(params.ChainID, params.TxHash, params.Payload1, params.PubKey, params.Sig1); err != nil {
+		return fmt.Errorf("ReportEquivocation, first attestation: %v", err)
+	}
+	if err := verifyAttestation(params.ChainID, params.TxHash, params.Payload2, params.PubKey, params.Sig2); err != nil {
+		return fmt.Errorf("ReportEquivocation, second attestation: %v", err)
+	}
+	blacked, err := isAttesterBlacked(service, params.ChainID, params.PubKey)
+	if err != nil {
+		return fmt.Errorf("ReportEquivocation, %v", err)
+	}
+	if blacked {
+		return fmt.Errorf("ReportEquivocation, attester already blacked")
+	}
+
+	putBlackedAttester(service, params.ChainID, params.PubKey)
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"attesterEquivocation", params.ChainID, hex.EncodeToString(params.PubKey),
+				hex.EncodeToString(params.TxHash), params.Address.ToBase58()},
+		})
+	return nil
+}
+
+func (this *CommitteeHandler) GetCommittee(service *native.NativeService) ([]byte, error) {
+	params := new(GetAttesterCommitteeParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("GetCommittee, contract params deserialize error: %v", err)
+	}
+	committee, err := getCommittee(service, params.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("GetCommittee, %v", err)
+	}
+	if committee == nil {
+		return nil, fmt.Errorf("GetCommittee, no attester committee for chain %d", params.ChainID)
+	}
+	sink := common.NewZeroCopySink(nil)
+	committee.Serialization(sink)
+	return sink.Bytes(), nil
+}
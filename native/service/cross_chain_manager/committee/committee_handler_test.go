@@ -0,0 +1,315 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package committee
+
+import (
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const chainID = uint64(100)
+
+var (
+	acct      = account.NewAccount("")
+	attesters = []*account.Account{account.NewAccount(""), account.NewAccount(""), account.NewAccount("")}
+)
+
+func newNative(args []byte, db *storage.CacheDB) *native.NativeService {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns, _ := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, args, false)
+	return ns
+}
+
+// newCommitteeDB registers the side chain and a committee of all attesters with threshold 2
+func newCommitteeDB(t *testing.T) *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			vconfig.PubkeyID(acct.PublicKey): {
+				Address:    acct.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+			},
+		},
+	}
+	sink = common.NewZeroCopySink(nil)
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	err := side_chain_manager.PutSideChain(newNative(nil, db), &side_chain_manager.SideChain{
+		ChainId: chainID,
+		Router:  utils.ATTESTATION_ROUTER,
+		Name:    "attested",
+	})
+	assert.NoError(t, err)
+
+	param := &SetAttesterCommitteeParam{
+		ChainID:   chainID,
+		Threshold: 2,
+		Address:   acct.Address,
+	}
+	for _, a := range attesters {
+		param.Attesters = append(param.Attesters, keypair.SerializePublicKey(a.PublicKey))
+	}
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.NoError(t, NewCommitteeHandler().SetCommittee(newNative(sink.Bytes(), db)))
+	return db
+}
+
+func newTxParam(crossChainID byte) []byte {
+	txParam := &scom.MakeTxParam{
+		TxHash:              []byte{crossChainID, 1},
+		CrossChainID:        []byte{crossChainID},
+		FromContractAddress: []byte{1},
+		ToChainID:           2,
+		ToContractAddress:   []byte{2},
+		Method:              "unlock",
+		Args:                []byte{3},
+	}
+	sink := common.NewZeroCopySink(nil)
+	txParam.Serialization(sink)
+	return sink.Bytes()
+}
+
+func attest(t *testing.T, signer *account.Account, txHash, payload []byte) *Attestation {
+	sig, err := signature.Sign(signer, AttestationMessage(chainID, txHash, payload))
+	assert.NoError(t, err)
+	return &Attestation{PubKey: keypair.SerializePublicKey(signer.PublicKey), Sig: sig}
+}
+
+func newEntranceParam(payload []byte, attestations ...*Attestation) []byte {
+	proof := common.NewZeroCopySink(nil)
+	(&Attestations{List: attestations}).Serialization(proof)
+	param := &scom.EntranceParam{
+		SourceChainID:  chainID,
+		Proof:          proof.Bytes(),
+		RelayerAddress: acct.Address[:],
+		Extra:          payload,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return sink.Bytes()
+}
+
+func TestSetCommittee(t *testing.T) {
+	db := newCommitteeDB(t)
+
+	sink := common.NewZeroCopySink(nil)
+	(&GetAttesterCommitteeParam{ChainID: chainID}).Serialization(sink)
+	raw, err := NewCommitteeHandler().GetCommittee(newNative(sink.Bytes(), db))
+	assert.NoError(t, err)
+	committee := new(AttesterCommittee)
+	assert.NoError(t, committee.Deserialization(common.NewZeroCopySource(raw)))
+	assert.Equal(t, uint64(0), committee.Epoch)
+	assert.Equal(t, uint64(2), committee.Threshold)
+	assert.Equal(t, 3, len(committee.Attesters))
+
+	// rotation starts a new epoch
+	param := &SetAttesterCommitteeParam{
+		ChainID:   chainID,
+		Threshold: 1,
+		Attesters: [][]byte{keypair.SerializePublicKey(attesters[0].PublicKey)},
+		Address:   acct.Address,
+	}
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.NoError(t, NewCommitteeHandler().SetCommittee(newNative(sink.Bytes(), db)))
+	committee, err = getCommittee(newNative(nil, db), chainID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), committee.Epoch)
+	assert.Equal(t, 1, len(committee.Attesters))
+
+	// threshold must be a majority
+	param.Threshold = 1
+	param.Attesters = append(param.Attesters, keypair.SerializePublicKey(attesters[1].PublicKey))
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.Error(t, NewCommitteeHandler().SetCommittee(newNative(sink.Bytes(), db)))
+
+	// duplicate attesters
+	param.Threshold = 2
+	param.Attesters[1] = param.Attesters[0]
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.Error(t, NewCommitteeHandler().SetCommittee(newNative(sink.Bytes(), db)))
+}
+
+func TestMakeDepositProposal(t *testing.T) {
+	db := newCommitteeDB(t)
+	payload := newTxParam(1)
+	txHash := []byte{1, 1}
+
+	// below threshold, a repeated attestation counts once
+	a0 := attest(t, attesters[0], txHash, payload)
+	_, err := NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, a0), db))
+	assert.Error(t, err)
+
+	// attestation over another payload
+	bad := attest(t, attesters[1], txHash, newTxParam(2))
+	_, err = NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, bad), db))
+	assert.Error(t, err)
+
+	// non member attestations are ignored
+	outsider := attest(t, acct, txHash, payload)
+	_, err = NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, outsider), db))
+	assert.Error(t, err)
+
+	a1 := attest(t, attesters[1], txHash, payload)
+	txParam, err := NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, a1), db))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, txParam.CrossChainID)
+
+	// replay
+	_, err = NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, a1), db))
+	assert.Error(t, err)
+}
+
+func TestBlackAttester(t *testing.T) {
+	db := newCommitteeDB(t)
+	payload := newTxParam(1)
+	txHash := []byte{1, 1}
+
+	param := &BlackAttesterParam{
+		ChainID: chainID,
+		PubKey:  keypair.SerializePublicKey(attesters[1].PublicKey),
+		Address: acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.NoError(t, NewCommitteeHandler().BlackAttester(newNative(sink.Bytes(), db)))
+
+	// chain not registered with attestation router
+	err := side_chain_manager.PutSideChain(newNative(nil, db), &side_chain_manager.SideChain{
+		ChainId: chainID + 1,
+		Router:  utils.ETH_ROUTER,
+		Name:    "eth",
+	})
+	assert.NoError(t, err)
+	other := *param
+	for _, id := range []uint64{chainID + 1, chainID + 2} {
+		other.ChainID = id
+		otherSink := common.NewZeroCopySink(nil)
+		other.Serialization(otherSink)
+		ns := newNative(otherSink.Bytes(), db)
+		assert.Error(t, NewCommitteeHandler().BlackAttester(ns))
+		assert.Error(t, NewCommitteeHandler().WhiteAttester(ns))
+		blacked, err := isAttesterBlacked(ns, id, param.PubKey)
+		assert.NoError(t, err)
+		assert.False(t, blacked)
+	}
+
+	a0 := attest(t, attesters[0], txHash, payload)
+	a1 := attest(t, attesters[1], txHash, payload)
+	_, err = NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, a1), db))
+	assert.Error(t, err)
+
+	assert.NoError(t, NewCommitteeHandler().WhiteAttester(newNative(sink.Bytes(), db)))
+	_, err = NewCommitteeHandler().MakeDepositProposal(newNative(newEntranceParam(payload, a0, a1), db))
+	assert.NoError(t, err)
+}
+
+func TestReportEquivocation(t *testing.T) {
+	db := newCommitteeDB(t)
+	txHash := []byte{1, 1}
+	payload1, payload2 := newTxParam(1), newTxParam(2)
+	a1 := attest(t, attesters[2], txHash, payload1)
+	a2 := attest(t, attesters[2], txHash, payload2)
+
+	param := &ReportEquivocationParam{
+		ChainID:  chainID,
+		TxHash:   txHash,
+		PubKey:   a1.PubKey,
+		Payload1: payload1,
+		Sig1:     a1.Sig,
+		Payload2: payload1,
+		Sig2:     a1.Sig,
+		Address:  acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.Error(t, NewCommitteeHandler().ReportEquivocation(newNative(sink.Bytes(), db)))
+
+	// signer not in the committee
+	outsider := account.NewAccount("")
+	o1 := attest(t, outsider, txHash, payload1)
+	o2 := attest(t, outsider, txHash, payload2)
+	report := &ReportEquivocationParam{
+		ChainID:  chainID,
+		TxHash:   txHash,
+		PubKey:   o1.PubKey,
+		Payload1: payload1,
+		Sig1:     o1.Sig,
+		Payload2: payload2,
+		Sig2:     o2.Sig,
+		Address:  acct.Address,
+	}
+	sink = common.NewZeroCopySink(nil)
+	report.Serialization(sink)
+	ns := newNative(sink.Bytes(), db)
+	assert.Error(t, NewCommitteeHandler().ReportEquivocation(ns))
+	blacked, err := isAttesterBlacked(ns, chainID, o1.PubKey)
+	assert.NoError(t, err)
+	assert.False(t, blacked)
+
+	// signature not matching the payload
+	param.Payload2 = payload2
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	assert.Error(t, NewCommitteeHandler().ReportEquivocation(newNative(sink.Bytes(), db)))
+
+	param.Sig2 = a2.Sig
+	sink = common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	ns = newNative(sink.Bytes(), db)
+	assert.NoError(t, NewCommitteeHandler().ReportEquivocation(ns))
+	assert.Equal(t, "attesterEquivocation", ns.GetNotify()[0].States.([]interface{})[0])
+	blacked, err = isAttesterBlacked(ns, chainID, a1.PubKey)
+	assert.NoError(t, err)
+	assert.True(t, blacked)
+
+	assert.Error(t, NewCommitteeHandler().ReportEquivocation(newNative(sink.Bytes(), db)))
+}
@@ -0,0 +1,213 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package committee
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type SetAttesterCommitteeParam struct {
+	ChainID   uint64
+	Threshold uint64
+	Attesters [][]byte
+	Address   common.Address
+}
+
+func (this *SetAttesterCommitteeParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarUint(this.Threshold)
+	sink.WriteVarUint(uint64(len(this.Attesters)))
+	for _, v := range this.Attesters {
+		sink.WriteVarBytes(v)
+	}
+}
+
+func (this *SetAttesterCommitteeParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SetAttesterCommitteeParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SetAttesterCommitteeParam deserialize chainID error")
+	}
+	threshold, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SetAttesterCommitteeParam deserialize threshold error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SetAttesterCommitteeParam deserialize attesters length error")
+	}
+	attesters := make([][]byte, 0)
+	for i := uint64(0); i < n; i++ {
+		pubKey, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("SetAttesterCommitteeParam deserialize attester error")
+		}
+		attesters = append(attesters, pubKey)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetAttesterCommitteeParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.Threshold = threshold
+	this.Attesters = attesters
+	this.Address = addr
+	return nil
+}
+
+type BlackAttesterParam struct {
+	ChainID uint64
+	PubKey  []byte
+	Address common.Address
+}
+
+func (this *BlackAttesterParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.PubKey)
+}
+
+func (this *BlackAttesterParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *BlackAttesterParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("BlackAttesterParam deserialize chainID error")
+	}
+	pubKey, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BlackAttesterParam deserialize pubKey error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("BlackAttesterParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.PubKey = pubKey
+	this.Address = addr
+	return nil
+}
+
+// ReportEquivocationParam carries two attestations of the same attester over the same
+// transaction with different payloads
+type ReportEquivocationParam struct {
+	ChainID  uint64
+	TxHash   []byte
+	PubKey   []byte
+	Payload1 []byte
+	Sig1     []byte
+	Payload2 []byte
+	Sig2     []byte
+	Address  common.Address
+}
+
+func (this *ReportEquivocationParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.TxHash)
+	sink.WriteVarBytes(this.PubKey)
+	sink.WriteVarBytes(this.Payload1)
+	sink.WriteVarBytes(this.Sig1)
+	sink.WriteVarBytes(this.Payload2)
+	sink.WriteVarBytes(this.Sig2)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *ReportEquivocationParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize chainID error")
+	}
+	txHash, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize txHash error")
+	}
+	pubKey, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize pubKey error")
+	}
+	payload1, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize payload1 error")
+	}
+	sig1, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize sig1 error")
+	}
+	payload2, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize payload2 error")
+	}
+	sig2, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize sig2 error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("ReportEquivocationParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.TxHash = txHash
+	this.PubKey = pubKey
+	this.Payload1 = payload1
+	this.Sig1 = sig1
+	this.Payload2 = payload2
+	this.Sig2 = sig2
+	this.Address = addr
+	return nil
+}
+
+type GetAttesterCommitteeParam struct {
+	ChainID uint64
+}
+
+func (this *GetAttesterCommitteeParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+}
+
+func (this *GetAttesterCommitteeParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("GetAttesterCommitteeParam deserialize chainID error")
+	}
+
+	this.ChainID = chainID
+	return nil
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package committee
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// AttesterCommittee is the set of external attesters trusted to vouch for the
+// cross chain transactions of a side chain
+type AttesterCommittee struct {
+	ChainID   uint64
+	Epoch     uint64
+	Threshold uint64
+	// serialized public keys of the attesters
+	Attesters [][]byte
+}
+
+func (this *AttesterCommittee) IsMember(pubKey []byte) bool {
+	for _, v := range this.Attesters {
+		if bytes.Equal(v, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *AttesterCommittee) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarUint(this.Epoch)
+	sink.WriteVarUint(this.Threshold)
+	sink.WriteVarUint(uint64(len(this.Attesters)))
+	for _, v := range this.Attesters {
+		sink.WriteVarBytes(v)
+	}
+}
+
+func (this *AttesterCommittee) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AttesterCommittee deserialize chainID error")
+	}
+	epoch, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AttesterCommittee deserialize epoch error")
+	}
+	threshold, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AttesterCommittee deserialize threshold error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("AttesterCommittee deserialize attesters length error")
+	}
+	attesters := make([][]byte, 0)
+	for i := uint64(0); i < n; i++ {
+		pubKey, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("AttesterCommittee deserialize attester error")
+		}
+		attesters = append(attesters, pubKey)
+	}
+
+	this.ChainID = chainID
+	this.Epoch = epoch
+	this.Threshold = threshold
+	this.Attesters = attesters
+	return nil
+}
+
+// Attestation is the signature of an attester over AttestationMessage
+type Attestation struct {
+	PubKey []byte
+	Sig    []byte
+}
+
+func (this *Attestation) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.PubKey)
+	sink.WriteVarBytes(this.Sig)
+}
+
+func (this *Attestation) Deserialization(source *common.ZeroCopySource) error {
+	pubKey, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("Attestation deserialize pubKey error")
+	}
+	sig, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("Attestation deserialize sig error")
+	}
+
+	this.PubKey = pubKey
+	this.Sig = sig
+	return nil
+}
+
+// Attestations is carried in the proof field of EntranceParam
+type Attestations struct {
+	List []*Attestation
+}
+
+func (this *Attestations) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.List)))
+	for _, v := range this.List {
+		v.Serialization(sink)
+	}
+}
+
+func (this *Attestations) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("Attestations deserialize length error")
+	}
+	list := make([]*Attestation, 0)
+	for i := uint64(0); i < n; i++ {
+		attestation := new(Attestation)
+		if err := attestation.Deserialization(source); err != nil {
+			return fmt.Errorf("Attestations deserialize attestation error: %v", err)
+		}
+		list = append(list, attestation)
+	}
+
+	this.List = list
+	return nil
+}
+
+// AttestationMessage returns the bytes signed by attesters for a transaction of chainID
+func AttestationMessage(chainID uint64, txHash, payload []byte) []byte {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(chainID)
+	sink.WriteVarBytes(txHash)
+	sink.WriteVarBytes(payload)
+	return sink.Bytes()
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package committee
+
+import (
+	"fmt"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/signature"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+const (
+	ATTESTER_COMMITTEE = "attesterCommittee"
+	BLACKED_ATTESTER   = "blackedAttester"
+)
+
+// checkAttestationChain return error if the chain is not registered with attestation router
+func checkAttestationChain(service *native.NativeService, chainID uint64) error {
+	sideChain, err := side_chain_manager.GetSideChain(service, chainID)
+	if err != nil {
+		return fmt.Errorf("side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil || sideChain.Router != utils.ATTESTATION_ROUTER {
+		return fmt.Errorf("chain %d is not registered with attestation router", chainID)
+	}
+	return nil
+}
+
+func getCommittee(service *native.NativeService, chainID uint64) (*AttesterCommittee, error) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ATTESTER_COMMITTEE),
+		utils.GetUint64Bytes(chainID))
+	store, err := service.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getCommittee, get committee store error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	committeeBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getCommittee, deserialize from raw storage item err:%v", err)
+	}
+	committee := new(AttesterCommittee)
+	if err := committee.Deserialization(common.NewZeroCopySource(committeeBytes)); err != nil {
+		return nil, fmt.Errorf("getCommittee, deserialize AttesterCommittee err:%v", err)
+	}
+	return committee, nil
+}
+
+func putCommittee(service *native.NativeService, committee *AttesterCommittee) {
+	sink := common.NewZeroCopySink(nil)
+	committee.Serialization(sink)
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(ATTESTER_COMMITTEE),
+		utils.GetUint64Bytes(committee.ChainID))
+	service.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func blackedAttesterKey(chainID uint64, pubKey []byte) []byte {
+	return utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(BLACKED_ATTESTER),
+		utils.GetUint64Bytes(chainID), pubKey)
+}
+
+func isAttesterBlacked(service *native.NativeService, chainID uint64, pubKey []byte) (bool, error) {
+	store, err := service.GetCacheDB().Get(blackedAttesterKey(chainID, pubKey))
+	if err != nil {
+		return false, fmt.Errorf("isAttesterBlacked, get blacked attester store error: %v", err)
+	}
+	return store != nil, nil
+}
+
+func putBlackedAttester(service *native.NativeService, chainID uint64, pubKey []byte) {
+	service.GetCacheDB().Put(blackedAttesterKey(chainID, pubKey), cstates.GenRawStorageItem(pubKey))
+}
+
+func removeBlackedAttester(service *native.NativeService, chainID uint64, pubKey []byte) {
+	service.GetCacheDB().Delete(blackedAttesterKey(chainID, pubKey))
+}
+
+func checkCommitteeSigns(service *native.NativeService, method string, address common.Address,
+	serializeContent func(sink *common.ZeroCopySink)) (bool, error) {
+	if err := utils.ValidateOwner(service, address); err != nil {
+		return false, fmt.Errorf("checkWitness error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	serializeContent(sink)
+	ok, err := node_manager.CheckConsensusSigns(service, method, sink.Bytes(), address)
+	if err != nil {
+		return false, fmt.Errorf("CheckConsensusSigns error: %v", err)
+	}
+	return ok, nil
+}
+
+// verifyAttestation checks that sig is a signature of pubKey over the attestation message
+func verifyAttestation(chainID uint64, txHash, payload, pubKey, sig []byte) error {
+	pk, err := keypair.DeserializePublicKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("keypair.DeserializePublicKey error: %v", err)
+	}
+	if err := signature.Verify(pk, AttestationMessage(chainID, txHash, payload), sig); err != nil {
+		return fmt.Errorf("signature.Verify error: %v", err)
+	}
+	return nil
+}
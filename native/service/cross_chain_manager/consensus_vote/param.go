@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package consensus_vote
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type SetVoteConfigParam struct {
+	ChainID uint64
+	Config  *VoteConfig
+	Address common.Address
+}
+
+func (this *SetVoteConfigParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	this.Config.Serialization(sink)
+}
+
+func (this *SetVoteConfigParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SetVoteConfigParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("SetVoteConfigParam deserialize chainID error")
+	}
+	config := new(VoteConfig)
+	if err := config.Deserialization(source); err != nil {
+		return fmt.Errorf("SetVoteConfigParam deserialize config error: %v", err)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("SetVoteConfigParam deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.ChainID = chainID
+	this.Config = config
+	this.Address = addr
+	return nil
+}
+
+type GetVotesParam struct {
+	ChainID      uint64
+	CrossChainID []byte
+}
+
+func (this *GetVotesParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarBytes(this.CrossChainID)
+}
+
+func (this *GetVotesParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("GetVotesParam deserialize chainID error")
+	}
+	crossChainID, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("GetVotesParam deserialize crossChainID error")
+	}
+
+	this.ChainID = chainID
+	this.CrossChainID = crossChainID
+	return nil
+}
@@ -26,6 +26,9 @@ import (
 type VoteInfo struct {
 	Status   bool
 	VoteInfo map[string]bool
+	// height of the first vote, used for expiry. Zero for vote info stored before the vote
+	// expiry height, which is kept in the legacy format
+	StartHeight uint32
 }
 
 func (this *VoteInfo) Serialization(sink *common.ZeroCopySink) {
@@ -43,6 +46,9 @@ func (this *VoteInfo) Serialization(sink *common.ZeroCopySink) {
 		v := this.VoteInfo[k]
 		sink.WriteBool(v)
 	}
+	if this.StartHeight != 0 {
+		sink.WriteUint32(this.StartHeight)
+	}
 }
 
 func (this *VoteInfo) Deserialization(source *common.ZeroCopySource) error {
@@ -66,7 +72,159 @@ func (this *VoteInfo) Deserialization(source *common.ZeroCopySource) error {
 		}
 		voteInfo[k] = v
 	}
+	//vote info stored before expiry was introduced has no start height
+	startHeight, eof := source.NextUint32()
+	if eof {
+		startHeight = 0
+	}
 	this.Status = status
 	this.VoteInfo = voteInfo
+	this.StartHeight = startHeight
+	return nil
+}
+
+// VoteConfig is the per side chain setting of consensus votes. A message is approved
+// when QuorumNumerator/QuorumDenominator of the consensus peers voted for it, and
+// votes not reaching quorum within ExpiryBlocks are dropped, zero means never.
+type VoteConfig struct {
+	QuorumNumerator   uint64
+	QuorumDenominator uint64
+	ExpiryBlocks      uint64
+}
+
+func (this *VoteConfig) Quorum(sum int) int {
+	return int((uint64(sum)*this.QuorumNumerator + this.QuorumDenominator - 1) / this.QuorumDenominator)
+}
+
+func (this *VoteConfig) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.QuorumNumerator)
+	sink.WriteVarUint(this.QuorumDenominator)
+	sink.WriteVarUint(this.ExpiryBlocks)
+}
+
+func (this *VoteConfig) Deserialization(source *common.ZeroCopySource) error {
+	numerator, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("VoteConfig deserialize quorumNumerator error")
+	}
+	denominator, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("VoteConfig deserialize quorumDenominator error")
+	}
+	expiry, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("VoteConfig deserialize expiryBlocks error")
+	}
+	this.QuorumNumerator = numerator
+	this.QuorumDenominator = denominator
+	this.ExpiryBlocks = expiry
+	return nil
+}
+
+// VoteStatus shows the votes of one message, Missing lists the current consensus
+// peers which have not voted yet
+type VoteStatus struct {
+	ID          []byte
+	Status      bool
+	StartHeight uint32
+	Quorum      uint64
+	Voters      []common.Address
+	Missing     []common.Address
+}
+
+func (this *VoteStatus) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarBytes(this.ID)
+	sink.WriteBool(this.Status)
+	sink.WriteUint32(this.StartHeight)
+	sink.WriteVarUint(this.Quorum)
+	sink.WriteVarUint(uint64(len(this.Voters)))
+	for _, v := range this.Voters {
+		sink.WriteVarBytes(v[:])
+	}
+	sink.WriteVarUint(uint64(len(this.Missing)))
+	for _, v := range this.Missing {
+		sink.WriteVarBytes(v[:])
+	}
+}
+
+func deserializeAddresses(source *common.ZeroCopySource) ([]common.Address, error) {
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("deserialize length error")
+	}
+	addrs := make([]common.Address, 0)
+	for i := uint64(0); i < n; i++ {
+		address, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("deserialize address error")
+		}
+		addr, err := common.AddressParseFromBytes(address)
+		if err != nil {
+			return nil, fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func (this *VoteStatus) Deserialization(source *common.ZeroCopySource) error {
+	id, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("VoteStatus deserialize id error")
+	}
+	status, eof := source.NextBool()
+	if eof {
+		return fmt.Errorf("VoteStatus deserialize status error")
+	}
+	startHeight, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("VoteStatus deserialize startHeight error")
+	}
+	quorum, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("VoteStatus deserialize quorum error")
+	}
+	voters, err := deserializeAddresses(source)
+	if err != nil {
+		return fmt.Errorf("VoteStatus voters %v", err)
+	}
+	missing, err := deserializeAddresses(source)
+	if err != nil {
+		return fmt.Errorf("VoteStatus missing %v", err)
+	}
+	this.ID = id
+	this.Status = status
+	this.StartHeight = startHeight
+	this.Quorum = quorum
+	this.Voters = voters
+	this.Missing = missing
+	return nil
+}
+
+type VoteStatuses struct {
+	List []*VoteStatus
+}
+
+func (this *VoteStatuses) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.List)))
+	for _, v := range this.List {
+		v.Serialization(sink)
+	}
+}
+
+func (this *VoteStatuses) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("VoteStatuses deserialize length error")
+	}
+	list := make([]*VoteStatus, 0)
+	for i := uint64(0); i < n; i++ {
+		status := new(VoteStatus)
+		if err := status.Deserialization(source); err != nil {
+			return fmt.Errorf("VoteStatuses deserialize error: %v", err)
+		}
+		list = append(list, status)
+	}
+	this.List = list
 	return nil
 }
@@ -20,17 +20,23 @@ package consensus_vote
 import (
 	"encoding/hex"
 	"fmt"
+	"sort"
+
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
 const (
-	VOTE_INFO = "voteInfo"
+	VOTE_INFO   = "voteInfo"
+	VOTE_CONFIG = "voteConfig"
+	VOTE_INDEX  = "voteIndex"
 )
 
 func getVoteInfo(native *native.NativeService, id []byte) (*VoteInfo, error) {
@@ -63,88 +69,228 @@ func putVoteInfo(native *native.NativeService, id []byte, voteInfo *VoteInfo) {
 	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(VOTE_INFO), id), cstates.GenRawStorageItem(sink.Bytes()))
 }
 
-func CheckVotes(native *native.NativeService, id []byte, address common.Address) (bool, error) {
-	voteInfo, err := getVoteInfo(native, id)
+func deleteVoteInfo(native *native.NativeService, id []byte) {
+	native.GetCacheDB().Delete(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(VOTE_INFO), id))
+}
+
+// GetVoteConfig returns the vote config of chainID, chains without config need 2/3 of
+// the consensus peers and their votes never expire
+func GetVoteConfig(native *native.NativeService, chainID uint64) (*VoteConfig, error) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(VOTE_CONFIG), utils.GetUint64Bytes(chainID))
+	store, err := native.GetCacheDB().Get(key)
 	if err != nil {
-		return false, fmt.Errorf("CheckVotes, getVoteInfo error: %v", err)
+		return nil, fmt.Errorf("GetVoteConfig, get vote config store error: %v", err)
+	}
+	config := &VoteConfig{
+		QuorumNumerator:   2,
+		QuorumDenominator: 3,
 	}
+	if store != nil {
+		configBytes, err := cstates.GetValueFromRawStorageItem(store)
+		if err != nil {
+			return nil, fmt.Errorf("GetVoteConfig, deserialize from raw storage item err:%v", err)
+		}
+		if err := config.Deserialization(common.NewZeroCopySource(configBytes)); err != nil {
+			return nil, fmt.Errorf("GetVoteConfig, deserialize VoteConfig err:%v", err)
+		}
+	}
+	return config, nil
+}
 
-	//check voteInfo status
-	if voteInfo.Status {
-		return false, nil
+func putVoteConfig(native *native.NativeService, chainID uint64, config *VoteConfig) {
+	sink := common.NewZeroCopySink(nil)
+	config.Serialization(sink)
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(VOTE_CONFIG), utils.GetUint64Bytes(chainID))
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// getVoteIndex returns the ids of the messages voted for a cross chain id, relayers
+// may submit different messages with the same cross chain id
+func getVoteIndex(native *native.NativeService, chainID uint64, crossChainID []byte) ([][]byte, error) {
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(VOTE_INDEX), utils.GetUint64Bytes(chainID), crossChainID)
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getVoteIndex, get vote index store error: %v", err)
+	}
+	ids := make([][]byte, 0)
+	if store == nil {
+		return ids, nil
+	}
+	indexBytes, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getVoteIndex, deserialize from raw storage item err:%v", err)
 	}
+	source := common.NewZeroCopySource(indexBytes)
+	n, eof := source.NextVarUint()
+	if eof {
+		return nil, fmt.Errorf("getVoteIndex, deserialize length error")
+	}
+	for i := uint64(0); i < n; i++ {
+		id, eof := source.NextVarBytes()
+		if eof {
+			return nil, fmt.Errorf("getVoteIndex, deserialize id error")
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func addVoteIndex(native *native.NativeService, chainID uint64, crossChainID []byte, id []byte) error {
+	ids, err := getVoteIndex(native, chainID, crossChainID)
+	if err != nil {
+		return err
+	}
+	for _, v := range ids {
+		if string(v) == string(id) {
+			return nil
+		}
+	}
+	ids = append(ids, id)
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarUint(uint64(len(ids)))
+	for _, v := range ids {
+		sink.WriteVarBytes(v)
+	}
+	key := utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(VOTE_INDEX), utils.GetUint64Bytes(chainID), crossChainID)
+	native.GetCacheDB().Put(key, cstates.GenRawStorageItem(sink.Bytes()))
+	return nil
+}
 
+// isVoteExpiryEnabled returns whether votes expire and are indexed at current height
+func isVoteExpiryEnabled(native *native.NativeService) bool {
+	return native.GetHeight() >= config.GetVoteExpiryHeight(config.DefConfig.P2PNode.NetworkId)
+}
+
+// getConsensusAddresses returns the addresses of current consensus peers sorted by base58
+func getConsensusAddresses(native *native.NativeService) ([]common.Address, error) {
 	//get view
 	view, err := node_manager.GetView(native)
 	if err != nil {
-		return false, fmt.Errorf("CheckVotes, GetView error: %v", err)
+		return nil, fmt.Errorf("GetView error: %v", err)
 	}
 	//get consensus peer
 	peerPoolMap, err := node_manager.GetPeerPoolMap(native, view)
 	if err != nil {
-		return false, fmt.Errorf("CheckVotes, GetPeerPoolMap error: %v", err)
+		return nil, fmt.Errorf("GetPeerPoolMap error: %v", err)
 	}
-
-	//check if signer is consensus peer
-	consensus := false
+	addrs := make([]common.Address, 0)
 	for key, v := range peerPoolMap.PeerPoolMap {
 		if v.Status == node_manager.ConsensusStatus {
 			k, err := hex.DecodeString(key)
 			if err != nil {
-				return false, fmt.Errorf("CheckVotes, hex.DecodeString public key error: %v", err)
+				return nil, fmt.Errorf("hex.DecodeString public key error: %v", err)
 			}
 			publicKey, err := keypair.DeserializePublicKey(k)
 			if err != nil {
-				return false, fmt.Errorf("CheckVotes, keypair.DeserializePublicKey error: %v", err)
+				return nil, fmt.Errorf("keypair.DeserializePublicKey error: %v", err)
 			}
-			addr := types.AddressFromPubKey(publicKey)
+			addrs = append(addrs, types.AddressFromPubKey(publicKey))
+		}
+	}
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return addrs[i].ToBase58() < addrs[j].ToBase58()
+	})
+	return addrs, nil
+}
 
-			if addr == address {
-				consensus = true
-				break
-			}
+func CheckVotes(native *native.NativeService, chainID uint64, crossChainID []byte, id []byte, address common.Address) (bool, error) {
+	voteInfo, err := getVoteInfo(native, id)
+	if err != nil {
+		return false, fmt.Errorf("CheckVotes, getVoteInfo error: %v", err)
+	}
+
+	//check voteInfo status
+	if voteInfo.Status {
+		return false, nil
+	}
+
+	voteConfig, err := GetVoteConfig(native, chainID)
+	if err != nil {
+		return false, fmt.Errorf("CheckVotes, %v", err)
+	}
+	consensusAddrs, err := getConsensusAddresses(native)
+	if err != nil {
+		return false, fmt.Errorf("CheckVotes, %v", err)
+	}
+
+	//check if signer is consensus peer
+	consensus := false
+	for _, addr := range consensusAddrs {
+		if addr == address {
+			consensus = true
+			break
 		}
 	}
 	if !consensus {
 		return false, fmt.Errorf("CheckVotes, signer is not consensus peer")
 	}
 
-	//check signs num
-	num := 0
-	sum := 0
-	flag := false
-	for key, v := range peerPoolMap.PeerPoolMap {
-		if v.Status == node_manager.ConsensusStatus {
-			k, err := hex.DecodeString(key)
-			if err != nil {
-				return false, fmt.Errorf("CheckVotes, hex.DecodeString public key error: %v", err)
-			}
-			publicKey, err := keypair.DeserializePublicKey(k)
-			if err != nil {
-				return false, fmt.Errorf("CheckVotes, keypair.DeserializePublicKey error: %v", err)
-			}
-			addr := types.AddressFromPubKey(publicKey)
-			_, ok := voteInfo.VoteInfo[addr.ToBase58()]
-			if ok {
-				num = num + 1
-			}
-			sum = sum + 1
+	//votes expire and are indexed from the fork height on, votes before keep the legacy format
+	expiry := isVoteExpiryEnabled(native)
+	height := native.GetHeight()
+	started := false
+	if expiry && len(voteInfo.VoteInfo) > 0 && voteInfo.StartHeight == 0 {
+		//start height of legacy votes is unknown, they start expiring from now on
+		voteInfo.StartHeight = height
+		started = true
+	}
 
-			//check if voted
-			_, ok = voteInfo.VoteInfo[address.ToBase58()]
-			if !ok {
-				flag = true
-			}
+	//drop expired votes
+	if expiry && voteConfig.ExpiryBlocks > 0 && len(voteInfo.VoteInfo) > 0 &&
+		uint64(height) > uint64(voteInfo.StartHeight)+voteConfig.ExpiryBlocks {
+		deleteVoteInfo(native, id)
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{"voteExpired", chainID, hex.EncodeToString(crossChainID),
+					hex.EncodeToString(id), len(voteInfo.VoteInfo)},
+			})
+		voteInfo = &VoteInfo{
+			VoteInfo: make(map[string]bool),
 		}
 	}
-	if flag {
+	if expiry && (len(voteInfo.VoteInfo) == 0 || started) {
+		voteInfo.StartHeight = height
+		if err := addVoteIndex(native, chainID, crossChainID, id); err != nil {
+			return false, fmt.Errorf("CheckVotes, %v", err)
+		}
+	}
+
+	//check if voted
+	_, voted := voteInfo.VoteInfo[address.ToBase58()]
+	if !voted {
 		voteInfo.VoteInfo[address.ToBase58()] = true
-		num = num + 1
+	}
+	if !voted || started {
 		putVoteInfo(native, id, voteInfo)
 	}
-	if num >= (2*sum+2)/3 {
+
+	//check signs num
+	num := 0
+	for _, addr := range consensusAddrs {
+		if _, ok := voteInfo.VoteInfo[addr.ToBase58()]; ok {
+			num = num + 1
+		}
+	}
+	sum := len(consensusAddrs)
+	quorum := voteConfig.Quorum(sum)
+	if !voted {
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{"vote", chainID, hex.EncodeToString(crossChainID), hex.EncodeToString(id),
+					address.ToBase58(), num, quorum},
+			})
+	}
+	if num >= quorum {
 		voteInfo.Status = true
 		putVoteInfo(native, id, voteInfo)
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.CrossChainManagerContractAddress,
+				States: []interface{}{"voteFinalized", chainID, hex.EncodeToString(crossChainID),
+					hex.EncodeToString(id), num, sum},
+			})
 		return true, nil
 	} else {
 		return false, nil
@@ -22,11 +22,20 @@ import (
 	"fmt"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 )
 
+const (
+	SET_VOTE_CONFIG = "SetVoteConfig"
+	GET_VOTES       = "GetVotes"
+)
+
 type VoteHandler struct {
 }
 
@@ -61,16 +70,17 @@ func (this *VoteHandler) MakeDepositProposal(service *native.NativeService) (*sc
 	temp := sha256.Sum256(sink.Bytes())
 	id := temp[:]
 
-	ok, err := CheckVotes(service, id, address)
+	data := common.NewZeroCopySource(params.Extra)
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(data); err != nil {
+		return nil, fmt.Errorf("vote MakeDepositProposal, deserialize MakeTxParam error:%s", err)
+	}
+
+	ok, err := CheckVotes(service, params.SourceChainID, txParam.CrossChainID, id, address)
 	if err != nil {
 		return nil, fmt.Errorf("vote MakeDepositProposal, CheckVotes error: %v", err)
 	}
 	if ok {
-		data := common.NewZeroCopySource(params.Extra)
-		txParam := new(scom.MakeTxParam)
-		if err := txParam.Deserialization(data); err != nil {
-			return nil, fmt.Errorf("vote MakeDepositProposal, deserialize MakeTxParam error:%s", err)
-		}
 		if err := scom.CheckDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
 			return nil, fmt.Errorf("vote MakeDepositProposal, check done transaction error:%s", err)
 		}
@@ -81,3 +91,98 @@ func (this *VoteHandler) MakeDepositProposal(service *native.NativeService) (*sc
 	}
 	return nil, nil
 }
+
+func (this *VoteHandler) SetVoteConfig(service *native.NativeService) error {
+	params := new(SetVoteConfigParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return fmt.Errorf("SetVoteConfig, contract params deserialize error: %v", err)
+	}
+	if !isVoteExpiryEnabled(service) {
+		return fmt.Errorf("SetVoteConfig, vote config is not enabled until height %d",
+			config.GetVoteExpiryHeight(config.DefConfig.P2PNode.NetworkId))
+	}
+	sideChain, err := side_chain_manager.GetSideChain(service, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("SetVoteConfig, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil || sideChain.Router != utils.VOTE_ROUTER {
+		return fmt.Errorf("SetVoteConfig, chain %d is not registered with vote router", params.ChainID)
+	}
+	//quorum must be more than half and at most all of the consensus peers
+	config := params.Config
+	if config.QuorumDenominator == 0 || config.QuorumNumerator > config.QuorumDenominator ||
+		2*config.QuorumNumerator <= config.QuorumDenominator {
+		return fmt.Errorf("SetVoteConfig, invalid quorum %d/%d", config.QuorumNumerator, config.QuorumDenominator)
+	}
+
+	//check consensus signs
+	err = utils.ValidateOwner(service, params.Address)
+	if err != nil {
+		return fmt.Errorf("SetVoteConfig, checkWitness error: %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	params.serializeContent(sink)
+	ok, err := node_manager.CheckConsensusSigns(service, SET_VOTE_CONFIG, sink.Bytes(), params.Address)
+	if err != nil {
+		return fmt.Errorf("SetVoteConfig, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return nil
+	}
+
+	putVoteConfig(service, params.ChainID, config)
+	service.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.CrossChainManagerContractAddress,
+			States: []interface{}{"setVoteConfig", params.ChainID, config.QuorumNumerator, config.QuorumDenominator,
+				config.ExpiryBlocks},
+		})
+	return nil
+}
+
+// GetVotes returns the votes of every message submitted with the cross chain id
+func (this *VoteHandler) GetVotes(service *native.NativeService) ([]byte, error) {
+	params := new(GetVotesParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("GetVotes, contract params deserialize error: %v", err)
+	}
+	ids, err := getVoteIndex(service, params.ChainID, params.CrossChainID)
+	if err != nil {
+		return nil, fmt.Errorf("GetVotes, %v", err)
+	}
+	config, err := GetVoteConfig(service, params.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("GetVotes, %v", err)
+	}
+	consensusAddrs, err := getConsensusAddresses(service)
+	if err != nil {
+		return nil, fmt.Errorf("GetVotes, %v", err)
+	}
+
+	statuses := &VoteStatuses{}
+	for _, id := range ids {
+		voteInfo, err := getVoteInfo(service, id)
+		if err != nil {
+			return nil, fmt.Errorf("GetVotes, %v", err)
+		}
+		status := &VoteStatus{
+			ID:          id,
+			Status:      voteInfo.Status,
+			StartHeight: voteInfo.StartHeight,
+			Quorum:      uint64(config.Quorum(len(consensusAddrs))),
+			Voters:      make([]common.Address, 0),
+			Missing:     make([]common.Address, 0),
+		}
+		for _, addr := range consensusAddrs {
+			if _, ok := voteInfo.VoteInfo[addr.ToBase58()]; ok {
+				status.Voters = append(status.Voters, addr)
+			} else {
+				status.Missing = append(status.Missing, addr)
+			}
+		}
+		statuses.List = append(statuses.List, status)
+	}
+	sink := common.NewZeroCopySink(nil)
+	statuses.Serialization(sink)
+	return sink.Bytes(), nil
+}
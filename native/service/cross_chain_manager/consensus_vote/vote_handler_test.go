@@ -27,6 +27,7 @@ import (
 	"testing"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/store/overlaydb"
@@ -541,3 +542,235 @@ func TestAutoTriggerConsensusVote(t *testing.T) {
 		assert.NilError(t, err, "test error")
 	}
 }
+
+func newVoteParam(acct *account.Account) []byte {
+	makeTxParam := &scom.MakeTxParam{
+		TxHash:              []byte{0x01, 0x02},
+		CrossChainID:        []byte{0x01, 0x02},
+		FromContractAddress: []byte{0x01, 0x02},
+		ToChainID:           2,
+		ToContractAddress:   []byte{0x01, 0x02},
+		Method:              "lock",
+		Args:                []byte{0x01, 0x02},
+	}
+	makeTxParamSink := common.NewZeroCopySink(nil)
+	makeTxParam.Serialization(makeTxParamSink)
+	param := &scom.EntranceParam{
+		SourceChainID:  10,
+		Height:         20000,
+		RelayerAddress: acct.Address[:],
+		Extra:          makeTxParamSink.Bytes(),
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return sink.Bytes()
+}
+
+func newNativeAt(args []byte, acct *account.Account, height uint32, db *storage.CacheDB) *native.NativeService {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns, err := native.NewNativeService(db, tx, 0, height, common.Uint256{0}, 0, args, false)
+	if err != nil {
+		panic(fmt.Sprintf("NewNativeService error: %+v", err))
+	}
+	return ns
+}
+
+// setVoteExpiryHeight moves the vote expiry fork of the configured network to height until the test ends
+func setVoteExpiryHeight(t *testing.T, height uint32) {
+	id := config.DefConfig.P2PNode.NetworkId
+	old, present := config.VOTE_EXPIRY_HEIGHT[id]
+	config.VOTE_EXPIRY_HEIGHT[id] = height
+	t.Cleanup(func() {
+		if present {
+			config.VOTE_EXPIRY_HEIGHT[id] = old
+		} else {
+			delete(config.VOTE_EXPIRY_HEIGHT, id)
+		}
+	})
+}
+
+func setVoteConfig(t *testing.T, db *storage.CacheDB, voteConfig *VoteConfig) {
+	setVoteExpiryHeight(t, 0)
+	side := &side_chain_manager.SideChain{
+		Name:    "vote",
+		ChainId: 10,
+		Router:  utils.VOTE_ROUTER,
+	}
+	sink := common.NewZeroCopySink(nil)
+	_ = side.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.SideChainManagerContractAddress, []byte(side_chain_manager.SIDE_CHAIN), utils.GetUint64Bytes(10)), cstates.GenRawStorageItem(sink.Bytes()))
+
+	for _, acct := range acctList1 {
+		param := &SetVoteConfigParam{
+			ChainID: 10,
+			Config:  voteConfig,
+			Address: acct.Address,
+		}
+		sink := common.NewZeroCopySink(nil)
+		param.Serialization(sink)
+		err := NewVoteHandler().SetVoteConfig(newNativeAt(sink.Bytes(), acct, 0, db))
+		assert.NilError(t, err)
+	}
+}
+
+func TestVoteConfigQuorum(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	Init(db)
+	setVoteExpiryHeight(t, 0)
+
+	//invalid quorum
+	param := &SetVoteConfigParam{
+		ChainID: 10,
+		Config:  &VoteConfig{QuorumNumerator: 1, QuorumDenominator: 2},
+		Address: acct1.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	err := NewVoteHandler().SetVoteConfig(newNativeAt(sink.Bytes(), acct1, 0, db))
+	assert.ErrorContains(t, err, "not registered with vote router")
+	setVoteConfig(t, db, &VoteConfig{QuorumNumerator: 1, QuorumDenominator: 1})
+	err = NewVoteHandler().SetVoteConfig(newNativeAt(sink.Bytes(), acct1, 0, db))
+	assert.ErrorContains(t, err, "invalid quorum")
+
+	//all of 7 peers must vote
+	voteHandler := NewVoteHandler()
+	for i := 0; i < 6; i++ {
+		ns := newNativeAt(newVoteParam(acctList1[i]), acctList1[i], 1, db)
+		v, err := voteHandler.MakeDepositProposal(ns)
+		assert.NilError(t, err)
+		assert.Equal(t, (*scom.MakeTxParam)(nil), v)
+		assert.Equal(t, "vote", ns.GetNotify()[0].States.([]interface{})[0])
+		assert.Equal(t, 7, ns.GetNotify()[0].States.([]interface{})[6])
+	}
+	ns := newNativeAt(newVoteParam(acctList1[6]), acctList1[6], 1, db)
+	v, err := voteHandler.MakeDepositProposal(ns)
+	assert.NilError(t, err)
+	assert.Assert(t, v != nil)
+	assert.Equal(t, "voteFinalized", ns.GetNotify()[1].States.([]interface{})[0])
+}
+
+func TestVoteExpiry(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	Init(db)
+	setVoteConfig(t, db, &VoteConfig{QuorumNumerator: 2, QuorumDenominator: 3, ExpiryBlocks: 10})
+
+	voteHandler := NewVoteHandler()
+	for i := 0; i < 4; i++ {
+		_, err := voteHandler.MakeDepositProposal(newNativeAt(newVoteParam(acctList1[i]), acctList1[i], 100, db))
+		assert.NilError(t, err)
+	}
+	//votes at height 100 expire after height 110
+	ns := newNativeAt(newVoteParam(acctList1[4]), acctList1[4], 111, db)
+	v, err := voteHandler.MakeDepositProposal(ns)
+	assert.NilError(t, err)
+	assert.Equal(t, (*scom.MakeTxParam)(nil), v)
+	assert.Equal(t, "voteExpired", ns.GetNotify()[0].States.([]interface{})[0])
+
+	for i := 0; i < 3; i++ {
+		v, err := voteHandler.MakeDepositProposal(newNativeAt(newVoteParam(acctList1[i]), acctList1[i], 120, db))
+		assert.NilError(t, err)
+		assert.Equal(t, (*scom.MakeTxParam)(nil), v)
+	}
+	v, err = voteHandler.MakeDepositProposal(newNativeAt(newVoteParam(acctList1[3]), acctList1[3], 121, db))
+	assert.NilError(t, err)
+	assert.Assert(t, v != nil)
+}
+
+func TestGetVotes(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	Init(db)
+	setVoteExpiryHeight(t, 0)
+
+	voteHandler := NewVoteHandler()
+	for i := 0; i < 2; i++ {
+		_, err := voteHandler.MakeDepositProposal(newNativeAt(newVoteParam(acctList1[i]), acctList1[i], 1, db))
+		assert.NilError(t, err)
+	}
+
+	param := &GetVotesParam{ChainID: 10, CrossChainID: []byte{0x01, 0x02}}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	res, err := voteHandler.GetVotes(newNativeAt(sink.Bytes(), acct1, 1, db))
+	assert.NilError(t, err)
+	statuses := new(VoteStatuses)
+	assert.NilError(t, statuses.Deserialization(common.NewZeroCopySource(res)))
+	assert.Equal(t, 1, len(statuses.List))
+	status := statuses.List[0]
+	assert.Equal(t, false, status.Status)
+	assert.Equal(t, uint64(5), status.Quorum)
+	assert.Equal(t, 2, len(status.Voters))
+	assert.Equal(t, 5, len(status.Missing))
+	for _, addr := range status.Voters {
+		assert.Assert(t, addr == acct1.Address || addr == acct2.Address)
+	}
+}
+
+func TestVoteBeforeForkHeight(t *testing.T) {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	Init(db)
+	setVoteExpiryHeight(t, 200)
+
+	param := &SetVoteConfigParam{
+		ChainID: 10,
+		Config:  &VoteConfig{QuorumNumerator: 2, QuorumDenominator: 3, ExpiryBlocks: 10},
+		Address: acct1.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	err := NewVoteHandler().SetVoteConfig(newNativeAt(sink.Bytes(), acct1, 100, db))
+	assert.ErrorContains(t, err, "not enabled")
+
+	//votes before the fork height are stored in the legacy format without index
+	voteHandler := NewVoteHandler()
+	var id []byte
+	for i := 0; i < 2; i++ {
+		ns := newNativeAt(newVoteParam(acctList1[i]), acctList1[i], 100, db)
+		_, err := voteHandler.MakeDepositProposal(ns)
+		assert.NilError(t, err)
+		id, _ = hex.DecodeString(ns.GetNotify()[0].States.([]interface{})[3].(string))
+	}
+	voters := []string{acct1.Address.ToBase58(), acct2.Address.ToBase58()}
+	if voters[0] < voters[1] {
+		voters[0], voters[1] = voters[1], voters[0]
+	}
+	legacy := common.NewZeroCopySink(nil)
+	legacy.WriteBool(false)
+	legacy.WriteUint64(2)
+	for _, voter := range voters {
+		legacy.WriteString(voter)
+		legacy.WriteBool(true)
+	}
+	item, err := db.Get(utils.ConcatKey(utils.CrossChainManagerContractAddress, []byte(VOTE_INFO), id))
+	assert.NilError(t, err)
+	raw, err := cstates.GetValueFromRawStorageItem(item)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, legacy.Bytes(), raw)
+	ids, err := getVoteIndex(newNativeAt(nil, acct1, 100, db), 10, []byte{0x01, 0x02})
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(ids))
+
+	//legacy votes start expiring at the first vote after the fork height
+	setVoteConfig(t, db, &VoteConfig{QuorumNumerator: 2, QuorumDenominator: 3, ExpiryBlocks: 10})
+	ns := newNativeAt(newVoteParam(acctList1[2]), acctList1[2], 300, db)
+	_, err = voteHandler.MakeDepositProposal(ns)
+	assert.NilError(t, err)
+	assert.Equal(t, "vote", ns.GetNotify()[0].States.([]interface{})[0])
+	assert.Equal(t, 3, ns.GetNotify()[0].States.([]interface{})[5])
+	voteInfo, err := getVoteInfo(ns, id)
+	assert.NilError(t, err)
+	assert.Equal(t, uint32(300), voteInfo.StartHeight)
+	ids, err = getVoteIndex(ns, 10, []byte{0x01, 0x02})
+	assert.NilError(t, err)
+	assert.Equal(t, 1, len(ids))
+
+	ns = newNativeAt(newVoteParam(acctList1[3]), acctList1[3], 311, db)
+	_, err = voteHandler.MakeDepositProposal(ns)
+	assert.NilError(t, err)
+	assert.Equal(t, "voteExpired", ns.GetNotify()[0].States.([]interface{})[0])
+}
@@ -25,14 +25,17 @@ import (
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/zilliqa"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/bsc"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/btc"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/committee"
 	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/cosmos"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/eth"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/heco"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/msc"
+	"github.com/polynetwork/poly/native/service/cross_chain_manager/near"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/neo3"
 	"github.com/polynetwork/poly/native/service/cross_chain_manager/okex"
@@ -57,6 +60,16 @@ const (
 func RegisterCrossChainManagerContract(native *native.NativeService) {
 	native.Register(IMPORT_OUTER_TRANSFER_NAME, ImportExTransfer)
 	native.Register(MULTI_SIGN, MultiSign)
+	native.Register(btc.CONSOLIDATE_BTC_UTXOS, ConsolidateBtcUtxos)
+	native.Register(btc.BUMP_BTC_TX_FEE, BumpBtcTxFee)
+	native.Register(btc.GET_BTC_VAULT, GetBtcVault)
+	native.Register(committee.SET_ATTESTER_COMMITTEE, SetAttesterCommittee)
+	native.Register(committee.BLACK_ATTESTER, BlackAttester)
+	native.Register(committee.WHITE_ATTESTER, WhiteAttester)
+	native.Register(committee.REPORT_ATTESTER_EQUIVOCATION, ReportAttesterEquivocation)
+	native.Register(committee.GET_ATTESTER_COMMITTEE, GetAttesterCommittee)
+	native.Register(consensus_vote.SET_VOTE_CONFIG, SetVoteConfig)
+	native.Register(consensus_vote.GET_VOTES, GetVotes)
 
 	native.Register(BLACK_CHAIN, BlackChain)
 	native.Register(WHITE_CHAIN, WhiteChain)
@@ -96,6 +109,10 @@ func GetChainHandler(router uint64) (scom.ChainHandler, error) {
 		return polygon.NewHandler(), nil
 	case utils.PIXIECHAIN_ROUTER:
 		return pixiechain.NewPixieHandler(), nil
+	case utils.NEAR_ROUTER:
+		return near.NewNearHandler(), nil
+	case utils.ATTESTATION_ROUTER:
+		return committee.NewCommitteeHandler(), nil
 	default:
 		return nil, fmt.Errorf("not a supported router:%d", router)
 	}
@@ -130,8 +147,10 @@ func ImportExTransfer(native *native.NativeService) ([]byte, error) {
 		return utils.BYTE_FALSE, err
 	}
 	//1. verify tx
+	logger := log.Module("native/service/cross_chain_manager").With(log.Fields{"chainID": chainID, "height": params.Height})
 	txParam, err := handler.MakeDepositProposal(native)
 	if err != nil {
+		logger.Debugf("ImportExTransfer, MakeDepositProposal error: %s", err)
 		return utils.BYTE_FALSE, err
 	}
 	if txParam == nil && sideChain.Router == utils.VOTE_ROUTER {
@@ -156,6 +175,7 @@ func ImportExTransfer(native *native.NativeService) ([]byte, error) {
 	if sideChain == nil {
 		return utils.BYTE_FALSE, fmt.Errorf("ImportExTransfer, side chain %d is not registered", targetid)
 	}
+	logger.Debugf("ImportExTransfer, cross chain tx %x to chain %d", txParam.CrossChainID, targetid)
 	if sideChain.Router == utils.BTC_ROUTER {
 		err := btc.NewBTCHandler().MakeTransaction(native, txParam, chainID)
 		if err != nil {
@@ -183,6 +203,74 @@ func MultiSign(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_TRUE, nil
 }
 
+func ConsolidateBtcUtxos(native *native.NativeService) ([]byte, error) {
+	err := btc.NewBTCHandler().ConsolidateUtxos(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func BumpBtcTxFee(native *native.NativeService) ([]byte, error) {
+	err := btc.NewBTCHandler().BumpTxFee(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func GetBtcVault(native *native.NativeService) ([]byte, error) {
+	return btc.NewBTCHandler().GetVault(native)
+}
+
+func SetAttesterCommittee(native *native.NativeService) ([]byte, error) {
+	err := committee.NewCommitteeHandler().SetCommittee(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func BlackAttester(native *native.NativeService) ([]byte, error) {
+	err := committee.NewCommitteeHandler().BlackAttester(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func WhiteAttester(native *native.NativeService) ([]byte, error) {
+	err := committee.NewCommitteeHandler().WhiteAttester(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func ReportAttesterEquivocation(native *native.NativeService) ([]byte, error) {
+	err := committee.NewCommitteeHandler().ReportEquivocation(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func GetAttesterCommittee(native *native.NativeService) ([]byte, error) {
+	return committee.NewCommitteeHandler().GetCommittee(native)
+}
+
+func SetVoteConfig(native *native.NativeService) ([]byte, error) {
+	err := consensus_vote.NewVoteHandler().SetVoteConfig(native)
+	if err != nil {
+		return utils.BYTE_FALSE, err
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+func GetVotes(native *native.NativeService) ([]byte, error) {
+	return consensus_vote.NewVoteHandler().GetVotes(native)
+}
+
 func MakeTransaction(service *native.NativeService, params *scom.MakeTxParam, fromChainID uint64) error {
 	txHash := service.GetTx().Hash()
 	merkleValue := &scom.ToMerkleValue{
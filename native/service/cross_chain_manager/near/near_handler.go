@@ -0,0 +1,113 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	"github.com/polynetwork/poly/native/service/header_sync/near"
+)
+
+type NearHandler struct{}
+
+func NewNearHandler() *NearHandler {
+	return &NearHandler{}
+}
+
+// MakeDepositProposal verifies an execution outcome of the cross chain manager contract on
+// NEAR. params.Proof is a NearProof, whose block proof leads to the block merkle root of the
+// synced block at params.Height. The contract returns the serialized MakeTxParam.
+func (this *NearHandler) MakeDepositProposal(service *native.NativeService) (*scom.MakeTxParam, error) {
+	params := new(scom.EntranceParam)
+	if err := params.Deserialization(common.NewZeroCopySource(service.GetInput())); err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, contract params deserialize error: %v", err)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(service, params.SourceChainID)
+	if err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, side chain %d is not registered", params.SourceChainID)
+	}
+	head, err := near.GetBlockInfo(service, params.SourceChainID, uint64(params.Height))
+	if err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, %v", err)
+	}
+	if head == nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, block %d is not synced", params.Height)
+	}
+	proof := new(NearProof)
+	if err = proof.Deserialization(common.NewZeroCopySource(params.Proof)); err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, %v", err)
+	}
+	outcome, err := verifyOutcomeProof(proof, head)
+	if err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, %v", err)
+	}
+	if outcome.ExecutorID != string(sideChain.CCMCAddress) {
+		return nil, fmt.Errorf("near MakeDepositProposal, executor %s is not the cross chain manager %s",
+			outcome.ExecutorID, string(sideChain.CCMCAddress))
+	}
+	if outcome.Status != STATUS_SUCCESS_VALUE {
+		return nil, fmt.Errorf("near MakeDepositProposal, outcome status %d is not success value", outcome.Status)
+	}
+
+	txParam := new(scom.MakeTxParam)
+	if err := txParam.Deserialization(common.NewZeroCopySource(outcome.StatusValue)); err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, deserialize MakeTxParam error:%s", err)
+	}
+	if err := scom.CheckDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, check done transaction error:%s", err)
+	}
+	if err := scom.PutDoneTx(service, txParam.CrossChainID, params.SourceChainID); err != nil {
+		return nil, fmt.Errorf("near MakeDepositProposal, PutDoneTx error:%s", err)
+	}
+	return txParam, nil
+}
+
+// verifyOutcomeProof goes from the outcome to the outcome root of its block, and from
+// the block hash to the block merkle root of head
+func verifyOutcomeProof(proof *NearProof, head *near.NearBlockInfo) (*ExecutionOutcome, error) {
+	blockHash := proof.BlockHeaderLite.Hash()
+	if blockHash != proof.OutcomeProof.BlockHash {
+		return nil, fmt.Errorf("verifyOutcomeProof, block hash %s of header not equal to %s in outcome proof",
+			hex.EncodeToString(blockHash[:]), hex.EncodeToString(proof.OutcomeProof.BlockHash[:]))
+	}
+	outcome := proof.OutcomeProof.Outcome
+	shardRoot := proof.OutcomeProof.Proof.ComputeRoot(outcome.Hash(proof.OutcomeProof.ID))
+	outcomeRoot := proof.OutcomeRootProof.ComputeRoot(near.HashOf(shardRoot[:]))
+	if outcomeRoot != proof.BlockHeaderLite.InnerLite.OutcomeRoot {
+		return nil, fmt.Errorf("verifyOutcomeProof, outcome root %s not equal to %s in header",
+			hex.EncodeToString(outcomeRoot[:]), hex.EncodeToString(proof.BlockHeaderLite.InnerLite.OutcomeRoot[:]))
+	}
+	// the block merkle root of a block covers all blocks before it
+	if proof.BlockHeaderLite.InnerLite.Height >= head.Height {
+		return nil, fmt.Errorf("verifyOutcomeProof, block %d is not lower than the proving block %d",
+			proof.BlockHeaderLite.InnerLite.Height, head.Height)
+	}
+	if root := proof.BlockProof.ComputeRoot(blockHash); root != head.BlockMerkleRoot {
+		return nil, fmt.Errorf("verifyOutcomeProof, block merkle root %s not equal to %s of block %d",
+			hex.EncodeToString(root[:]), hex.EncodeToString(head.BlockMerkleRoot[:]), head.Height)
+	}
+	return outcome, nil
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"crypto/ed25519"
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/header_sync/near"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	nearChainID = uint64(19)
+	ccmAccount  = "ccm.poly.near"
+)
+
+var acct *account.Account = account.NewAccount("")
+
+func newNative(args []byte, db *storage.CacheDB) *native.NativeService {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns, _ := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, args, false)
+	return ns
+}
+
+// newNearDB registers the NEAR side chain and syncs a genesis block whose block merkle
+// root commits to blockHash
+func newNearDB(t *testing.T, blockHash near.CryptoHash, blockProof near.MerklePath) *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			vconfig.PubkeyID(acct.PublicKey): {
+				Address:    acct.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+			},
+		},
+	}
+	sink.Reset()
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	err := side_chain_manager.PutSideChain(newNative(nil, db), &side_chain_manager.SideChain{
+		ChainId:      nearChainID,
+		Router:       utils.NEAR_ROUTER,
+		Name:         "near",
+		BlocksToWait: 1,
+		CCMCAddress:  []byte(ccmAccount),
+	})
+	assert.NoError(t, err)
+
+	sk := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	genesis := &near.NearGenesis{
+		Block: &near.LightClientBlock{
+			BlockHeaderLite: near.BlockHeaderLite{
+				InnerLite: near.BlockHeaderInnerLite{
+					Height:          10,
+					BlockMerkleRoot: blockProof.ComputeRoot(blockHash),
+				},
+			},
+		},
+		CurrentBps: &near.BlockProducers{
+			Producers: []*near.ValidatorStake{
+				{AccountID: "bp.near", PublicKey: sk.Public().(ed25519.PublicKey), Stake: big.NewInt(1)},
+			},
+		},
+	}
+	sink.Reset()
+	genesis.Serialization(sink)
+	param := &hscom.SyncGenesisHeaderParam{
+		ChainID:       nearChainID,
+		GenesisHeader: sink.Bytes(),
+	}
+	paramSink := common.NewZeroCopySink(nil)
+	param.Serialization(paramSink)
+	assert.NoError(t, near.NewNearHandler().SyncGenesisHeader(newNative(paramSink.Bytes(), db)))
+	return db
+}
+
+// newNearProof builds a proof of an outcome returning txParam in block 5
+func newNearProof(txParam *scom.MakeTxParam) *NearProof {
+	sink := common.NewZeroCopySink(nil)
+	txParam.Serialization(sink)
+	proof := &NearProof{
+		OutcomeProof: &OutcomeProof{
+			Proof: near.MerklePath{{Hash: near.CryptoHash{1}, Direction: near.MERKLE_DIRECTION_RIGHT}},
+			ID:    near.CryptoHash{2},
+			Outcome: &ExecutionOutcome{
+				Logs:        []string{"cross chain"},
+				ReceiptIDs:  []near.CryptoHash{{3}},
+				GasBurnt:    100,
+				TokensBurnt: big.NewInt(1000),
+				ExecutorID:  ccmAccount,
+				Status:      STATUS_SUCCESS_VALUE,
+				StatusValue: sink.Bytes(),
+			},
+		},
+		OutcomeRootProof: near.MerklePath{{Hash: near.CryptoHash{4}, Direction: near.MERKLE_DIRECTION_LEFT}},
+		BlockHeaderLite: &near.BlockHeaderLite{
+			PrevBlockHash: near.CryptoHash{5},
+			InnerLite: near.BlockHeaderInnerLite{
+				Height: 5,
+			},
+		},
+		BlockProof: near.MerklePath{{Hash: near.CryptoHash{6}, Direction: near.MERKLE_DIRECTION_RIGHT}},
+	}
+	outcome := proof.OutcomeProof
+	shardRoot := outcome.Proof.ComputeRoot(outcome.Outcome.Hash(outcome.ID))
+	proof.BlockHeaderLite.InnerLite.OutcomeRoot = proof.OutcomeRootProof.ComputeRoot(near.HashOf(shardRoot[:]))
+	outcome.BlockHash = proof.BlockHeaderLite.Hash()
+	return proof
+}
+
+func makeDeposit(db *storage.CacheDB, proof *NearProof, height uint32) (*scom.MakeTxParam, error) {
+	sink := common.NewZeroCopySink(nil)
+	proof.Serialization(sink)
+	param := &scom.EntranceParam{
+		SourceChainID:  nearChainID,
+		Height:         height,
+		Proof:          sink.Bytes(),
+		RelayerAddress: acct.Address[:],
+	}
+	paramSink := common.NewZeroCopySink(nil)
+	param.Serialization(paramSink)
+	return NewNearHandler().MakeDepositProposal(newNative(paramSink.Bytes(), db))
+}
+
+func TestOutcomeSerialization(t *testing.T) {
+	proof := newNearProof(&scom.MakeTxParam{Method: "unlock"})
+	sink := common.NewZeroCopySink(nil)
+	proof.Serialization(sink)
+	decoded := new(NearProof)
+	assert.NoError(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, proof.OutcomeProof.Outcome.Hash(proof.OutcomeProof.ID),
+		decoded.OutcomeProof.Outcome.Hash(decoded.OutcomeProof.ID))
+	assert.Equal(t, proof.BlockHeaderLite.Hash(), decoded.BlockHeaderLite.Hash())
+}
+
+func TestMakeDepositProposal(t *testing.T) {
+	txParam := &scom.MakeTxParam{
+		TxHash:              []byte{1, 2, 3},
+		CrossChainID:        []byte{4, 5, 6},
+		FromContractAddress: []byte(ccmAccount),
+		ToChainID:           2,
+		ToContractAddress:   []byte{7, 8},
+		Method:              "unlock",
+		Args:                []byte{9},
+	}
+	proof := newNearProof(txParam)
+	db := newNearDB(t, proof.BlockHeaderLite.Hash(), proof.BlockProof)
+
+	// side chain not registered
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	_, err := makeDeposit(storage.NewCacheDB(overlaydb.NewOverlayDB(store)), proof, 10)
+	assert.Error(t, err)
+
+	// block 11 is not synced
+	_, err = makeDeposit(db, proof, 11)
+	assert.Error(t, err)
+
+	// outcome not committed in the outcome root
+	tampered := newNearProof(txParam)
+	tampered.OutcomeProof.Outcome.GasBurnt++
+	_, err = makeDeposit(db, tampered, 10)
+	assert.Error(t, err)
+
+	// block not committed in the block merkle root
+	tampered = newNearProof(txParam)
+	tampered.BlockProof[0].Direction = near.MERKLE_DIRECTION_LEFT
+	_, err = makeDeposit(db, tampered, 10)
+	assert.Error(t, err)
+
+	// outcome not from the cross chain manager
+	tampered = newNearProof(txParam)
+	tampered.OutcomeProof.Outcome.ExecutorID = "evil.near"
+	outcome := tampered.OutcomeProof
+	shardRoot := outcome.Proof.ComputeRoot(outcome.Outcome.Hash(outcome.ID))
+	tampered.BlockHeaderLite.InnerLite.OutcomeRoot = tampered.OutcomeRootProof.ComputeRoot(near.HashOf(shardRoot[:]))
+	outcome.BlockHash = tampered.BlockHeaderLite.Hash()
+	evilDB := newNearDB(t, outcome.BlockHash, tampered.BlockProof)
+	_, err = makeDeposit(evilDB, tampered, 10)
+	assert.Error(t, err)
+
+	res, err := makeDeposit(db, proof, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, txParam, res)
+
+	// the same cross chain tx can not be processed twice
+	_, err = makeDeposit(db, proof, 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/native/service/header_sync/near"
+)
+
+const (
+	STATUS_UNKNOWN            = byte(0)
+	STATUS_FAILURE            = byte(1)
+	STATUS_SUCCESS_VALUE      = byte(2)
+	STATUS_SUCCESS_RECEIPT_ID = byte(3)
+)
+
+// ExecutionOutcome keeps the fields of a NEAR execution outcome that are committed in
+// the outcome root. The relayer encodes a failed status without its error detail.
+type ExecutionOutcome struct {
+	Logs        []string
+	ReceiptIDs  []near.CryptoHash
+	GasBurnt    uint64
+	TokensBurnt *big.Int
+	ExecutorID  string
+	Status      byte
+	// borsh encoded return value for STATUS_SUCCESS_VALUE, receipt id for STATUS_SUCCESS_RECEIPT_ID
+	StatusValue []byte
+}
+
+func (this *ExecutionOutcome) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(uint32(len(this.Logs)))
+	for _, v := range this.Logs {
+		near.WriteBorshString(sink, v)
+	}
+	this.serializePartial(sink)
+}
+
+// serializePartial writes the PartialExecutionOutcome nearcore hashes into the outcome root
+func (this *ExecutionOutcome) serializePartial(sink *common.ZeroCopySink) {
+	sink.WriteUint32(uint32(len(this.ReceiptIDs)))
+	for _, v := range this.ReceiptIDs {
+		sink.WriteBytes(v[:])
+	}
+	sink.WriteUint64(this.GasBurnt)
+	near.WriteU128(sink, this.TokensBurnt)
+	near.WriteBorshString(sink, this.ExecutorID)
+	sink.WriteByte(this.Status)
+	switch this.Status {
+	case STATUS_SUCCESS_VALUE:
+		sink.WriteUint32(uint32(len(this.StatusValue)))
+		sink.WriteBytes(this.StatusValue)
+	case STATUS_SUCCESS_RECEIPT_ID:
+		sink.WriteBytes(this.StatusValue)
+	}
+}
+
+func (this *ExecutionOutcome) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("ExecutionOutcome deserialize logs length error")
+	}
+	logs := make([]string, n)
+	for i := range logs {
+		logs[i], eof = near.NextBorshString(source)
+		if eof {
+			return fmt.Errorf("ExecutionOutcome deserialize no.%d log error", i+1)
+		}
+	}
+	n, eof = source.NextUint32()
+	if eof {
+		return fmt.Errorf("ExecutionOutcome deserialize receipt_ids length error")
+	}
+	receiptIDs := make([]near.CryptoHash, n)
+	for i := range receiptIDs {
+		if err := receiptIDs[i].Deserialization(source); err != nil {
+			return fmt.Errorf("ExecutionOutcome deserialize no.%d receipt id error: %v", i+1, err)
+		}
+	}
+	gasBurnt, eof := source.NextUint64()
+	if eof {
+		return fmt.Errorf("ExecutionOutcome deserialize gas_burnt error")
+	}
+	tokensBurnt, eof := near.NextU128(source)
+	if eof {
+		return fmt.Errorf("ExecutionOutcome deserialize tokens_burnt error")
+	}
+	executorID, eof := near.NextBorshString(source)
+	if eof {
+		return fmt.Errorf("ExecutionOutcome deserialize executor_id error")
+	}
+	status, eof := source.NextByte()
+	if eof {
+		return fmt.Errorf("ExecutionOutcome deserialize status error")
+	}
+	var value []byte
+	switch status {
+	case STATUS_UNKNOWN, STATUS_FAILURE:
+	case STATUS_SUCCESS_VALUE:
+		l, eof := source.NextUint32()
+		if eof {
+			return fmt.Errorf("ExecutionOutcome deserialize status value length error")
+		}
+		value, eof = source.NextBytes(uint64(l))
+		if eof {
+			return fmt.Errorf("ExecutionOutcome deserialize status value error")
+		}
+	case STATUS_SUCCESS_RECEIPT_ID:
+		value, eof = source.NextBytes(uint64(len(near.CryptoHash{})))
+		if eof {
+			return fmt.Errorf("ExecutionOutcome deserialize status receipt id error")
+		}
+	default:
+		return fmt.Errorf("ExecutionOutcome, unknown status %d", status)
+	}
+
+	this.Logs = logs
+	this.ReceiptIDs = receiptIDs
+	this.GasBurnt = gasBurnt
+	this.TokensBurnt = tokensBurnt
+	this.ExecutorID = executorID
+	this.Status = status
+	this.StatusValue = value
+	return nil
+}
+
+// Hash is the leaf of the outcome in the merkle tree of its shard
+func (this *ExecutionOutcome) Hash(id near.CryptoHash) near.CryptoHash {
+	sink := common.NewZeroCopySink(nil)
+	this.serializePartial(sink)
+	hashes := []near.CryptoHash{id, near.HashOf(sink.Bytes())}
+	for _, v := range this.Logs {
+		hashes = append(hashes, near.HashOf([]byte(v)))
+	}
+	sink.Reset()
+	sink.WriteUint32(uint32(len(hashes)))
+	for _, v := range hashes {
+		sink.WriteBytes(v[:])
+	}
+	return near.HashOf(sink.Bytes())
+}
+
+type OutcomeProof struct {
+	Proof     near.MerklePath
+	BlockHash near.CryptoHash
+	ID        near.CryptoHash
+	Outcome   *ExecutionOutcome
+}
+
+func (this *OutcomeProof) Serialization(sink *common.ZeroCopySink) {
+	this.Proof.Serialization(sink)
+	sink.WriteBytes(this.BlockHash[:])
+	sink.WriteBytes(this.ID[:])
+	this.Outcome.Serialization(sink)
+}
+
+func (this *OutcomeProof) Deserialization(source *common.ZeroCopySource) error {
+	if err := this.Proof.Deserialization(source); err != nil {
+		return fmt.Errorf("OutcomeProof deserialize proof error: %v", err)
+	}
+	if err := this.BlockHash.Deserialization(source); err != nil {
+		return fmt.Errorf("OutcomeProof deserialize block_hash error: %v", err)
+	}
+	if err := this.ID.Deserialization(source); err != nil {
+		return fmt.Errorf("OutcomeProof deserialize id error: %v", err)
+	}
+	this.Outcome = new(ExecutionOutcome)
+	if err := this.Outcome.Deserialization(source); err != nil {
+		return fmt.Errorf("OutcomeProof deserialize outcome error: %v", err)
+	}
+	return nil
+}
+
+// NearProof mirrors the response of the light_client_proof RPC
+type NearProof struct {
+	OutcomeProof     *OutcomeProof
+	OutcomeRootProof near.MerklePath
+	BlockHeaderLite  *near.BlockHeaderLite
+	BlockProof       near.MerklePath
+}
+
+func (this *NearProof) Serialization(sink *common.ZeroCopySink) {
+	this.OutcomeProof.Serialization(sink)
+	this.OutcomeRootProof.Serialization(sink)
+	this.BlockHeaderLite.Serialization(sink)
+	this.BlockProof.Serialization(sink)
+}
+
+func (this *NearProof) Deserialization(source *common.ZeroCopySource) error {
+	this.OutcomeProof, this.BlockHeaderLite = new(OutcomeProof), new(near.BlockHeaderLite)
+	if err := this.OutcomeProof.Deserialization(source); err != nil {
+		return fmt.Errorf("NearProof deserialize outcome_proof error: %v", err)
+	}
+	if err := this.OutcomeRootProof.Deserialization(source); err != nil {
+		return fmt.Errorf("NearProof deserialize outcome_root_proof error: %v", err)
+	}
+	if err := this.BlockHeaderLite.Deserialization(source); err != nil {
+		return fmt.Errorf("NearProof deserialize block_header_lite error: %v", err)
+	}
+	if err := this.BlockProof.Deserialization(source); err != nil {
+		return fmt.Errorf("NearProof deserialize block_proof error: %v", err)
+	}
+	return nil
+}
@@ -20,6 +20,7 @@ package neo3_state_manager
 import (
 	"fmt"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
@@ -33,6 +34,7 @@ const (
 	APPROVE_REGISTER_STATE_VALIDATOR = "approveRegisterStateValidator"
 	REMOVE_STATE_VALIDATOR           = "removeStateValidator"
 	APPROVE_REMOVE_STATE_VALIDATOR   = "approveRemoveStateValidator"
+	INIT_GENESIS                     = "initGenesis"
 
 	//key prefix
 	STATE_VALIDATOR           = "stateValidator"
@@ -49,6 +51,25 @@ func RegisterStateValidatorManagerContract(native *native.NativeService) {
 	native.Register(APPROVE_REGISTER_STATE_VALIDATOR, ApproveRegisterStateValidator)
 	native.Register(REMOVE_STATE_VALIDATOR, RemoveStateValidator)
 	native.Register(APPROVE_REMOVE_STATE_VALIDATOR, ApproveRemoveStateValidator)
+	native.Register(INIT_GENESIS, InitGenesis)
+}
+
+//InitGenesis register state validators of genesis config, only allowed in genesis block
+func InitGenesis(native *native.NativeService) ([]byte, error) {
+	if native.GetHeight() != 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, only allowed in genesis block")
+	}
+	params := new(config.GenesisGovernanceConfig)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, contract params deserialize error: %v", err)
+	}
+	if len(params.StateValidators) == 0 {
+		return utils.BYTE_TRUE, nil
+	}
+	if err := putStateValidators(native, params.StateValidators); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, putStateValidators error: %v", err)
+	}
+	return utils.BYTE_TRUE, nil
 }
 
 func GetCurrentStateValidator(native *native.NativeService) ([]byte, error) {
@@ -23,6 +23,7 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/signature/bls"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
@@ -45,6 +46,8 @@ const (
 	QUIT_NODE            = "quitNode"
 	UPDATE_CONFIG        = "updateConfig"
 	COMMIT_DPOS          = "commitDpos"
+	REGISTER_BLS_KEY     = "registerBlsKey"
+	REPORT_EQUIVOCATION  = "reportEquivocation"
 
 	//key prefix
 	GOVERNANCE_VIEW = "governanceView"
@@ -55,6 +58,7 @@ const (
 	PEER_INDEX      = "peerIndex"
 	BLACK_LIST      = "blackList"
 	CONSENSUS_SIGNS = "consensusSigns"
+	BLS_KEY         = "blsKey"
 
 	//const
 	MIN_PEER_NUM = 4
@@ -71,6 +75,8 @@ func RegisterNodeManagerContract(native *native.NativeService) {
 	native.Register(WHITE_NODE, WhiteNode)
 	native.Register(UPDATE_CONFIG, UpdateConfig)
 	native.Register(COMMIT_DPOS, CommitDpos)
+	native.Register(REGISTER_BLS_KEY, RegisterBlsKey)
+	native.Register(REPORT_EQUIVOCATION, ReportEquivocation)
 }
 
 //Init node_manager contract
@@ -565,6 +571,136 @@ func QuitNode(native *native.NativeService) ([]byte, error) {
 	return utils.BYTE_TRUE, nil
 }
 
+//Register the bls key of a peer, it is used to seal blocks from the next consensus epoch
+func RegisterBlsKey(native *native.NativeService) ([]byte, error) {
+	params := new(RegisterBlsKeyParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, checkWitness error: %v", err)
+	}
+
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, get peerPoolMap error: %v", err)
+	}
+
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, peerPubkey is not in peerPoolMap")
+	}
+	if peerPoolItem.Status != ConsensusStatus && peerPoolItem.Status != CandidateStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, peerPubkey is not CandidateStatus or ConsensusStatus")
+	}
+	if params.Address != peerPoolItem.Address {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, peerPubkey is not registered by this address")
+	}
+
+	//proof of possession prevents rogue key attack on aggregate signatures
+	if err := bls.VerifyPossession(params.BlsPubkey, params.Proof); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, verify proof of possession error: %v", err)
+	}
+
+	if err := putBlsKey(native, params.PeerPubkey, params.BlsPubkey); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("registerBlsKey, put bls key error: %v", err)
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"registerBlsKey", params.PeerPubkey, hex.EncodeToString(params.BlsPubkey)},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+//Report a peer signing conflicting headers in one round, the peer is put into black list
+//and removed from consensus at next commitDpos
+func ReportEquivocation(native *native.NativeService) ([]byte, error) {
+	params := new(ReportEquivocationParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, contract params deserialize error: %v", err)
+	}
+	contract := utils.NodeManagerContractAddress
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, checkWitness error: %v", err)
+	}
+
+	//get current view
+	view, err := GetView(native)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, get view error: %v", err)
+	}
+	//get peerPoolMap
+	peerPoolMap, err := GetPeerPoolMap(native, view)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, get peerPoolMap error: %v", err)
+	}
+
+	peerPoolItem, ok := peerPoolMap.PeerPoolMap[params.PeerPubkey]
+	if !ok {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, peerPubkey is not in peerPoolMap")
+	}
+	if peerPoolItem.Status != CandidateStatus && peerPoolItem.Status != ConsensusStatus {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, peerPubkey is not CandidateStatus or ConsensusStatus")
+	}
+
+	height, err := verifyEquivocation(peerPoolItem.Index, params.PeerPubkey, params.Kind, params.Headers, params.Sigs)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, verify evidence error: %v", err)
+	}
+	if height > native.GetHeight() {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, evidence height %d is higher than current height", height)
+	}
+
+	//check peers num
+	num := 0
+	for _, peerPoolItem := range peerPoolMap.PeerPoolMap {
+		if peerPoolItem.Status == CandidateStatus || peerPoolItem.Status == ConsensusStatus {
+			num = num + 1
+		}
+	}
+	if num <= MIN_PEER_NUM {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, num of peers is less than 4")
+	}
+
+	peerPubkeyPrefix, err := hex.DecodeString(params.PeerPubkey)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("reportEquivocation, peerPubkey format error: %v", err)
+	}
+	blackListItem := &BlackListItem{
+		PeerPubkey: peerPoolItem.PeerPubkey,
+		Address:    peerPoolItem.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	blackListItem.Serialization(sink)
+	//put peer into black list
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLACK_LIST), peerPubkeyPrefix), cstates.GenRawStorageItem(sink.Bytes()))
+
+	//change peerPool status, blacked peer is removed at next commitDpos
+	peerPoolItem.Status = BlackStatus
+	peerPoolMap.PeerPoolMap[params.PeerPubkey] = peerPoolItem
+	putPeerPoolMap(native, peerPoolMap, view)
+
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.NodeManagerContractAddress,
+			States:          []interface{}{"reportEquivocation", params.PeerPubkey, height},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
 //Go to next consensus epoch
 func CommitDpos(native *native.NativeService) ([]byte, error) {
 	// get config
@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node_manager
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
+	"github.com/polynetwork/poly/core/signature/bls"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterBlsKey(t *testing.T) {
+	acct := account.NewAccount("")
+	peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(acct.PublicKey))
+
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	sink := common.NewZeroCopySink(nil)
+	view := &GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &PeerPoolMap{
+		PeerPoolMap: map[string]*PeerPoolItem{
+			peerPubkey: {
+				Address:    acct.Address,
+				Status:     ConsensusStatus,
+				PeerPubkey: peerPubkey,
+			},
+		},
+	}
+	sink = common.NewZeroCopySink(nil)
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	register := func(param *RegisterBlsKeyParam) (*native.NativeService, error) {
+		sink := common.NewZeroCopySink(nil)
+		param.Serialization(sink)
+		tx := &types.Transaction{
+			SignedAddr: []common.Address{acct.Address},
+		}
+		ns, _ := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, sink.Bytes(), false)
+		_, err := RegisterBlsKey(ns)
+		return ns, err
+	}
+
+	sk, _ := bls.GenerateKey(rand.Reader)
+	other, _ := bls.GenerateKey(rand.Reader)
+	param := &RegisterBlsKeyParam{
+		PeerPubkey: peerPubkey,
+		BlsPubkey:  sk.PublicKey(),
+		Proof:      other.ProvePossession(),
+		Address:    acct.Address,
+	}
+	_, err := register(param)
+	assert.Error(t, err)
+
+	param.Proof = sk.ProvePossession()
+	param.Address = common.ADDRESS_EMPTY
+	_, err = register(param)
+	assert.Error(t, err)
+
+	param.Address = acct.Address
+	ns, err := register(param)
+	assert.NoError(t, err)
+	key, err := GetBlsKey(ns, peerPubkey)
+	assert.NoError(t, err)
+	assert.Equal(t, sk.PublicKey(), key)
+}
+
+func TestReportEquivocation(t *testing.T) {
+	accts := make([]*account.Account, 0)
+	peerPoolMap := &PeerPoolMap{
+		PeerPoolMap: make(map[string]*PeerPoolItem),
+	}
+	for i := 0; i < 5; i++ {
+		acct := account.NewAccount("")
+		peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(acct.PublicKey))
+		peerPoolMap.PeerPoolMap[peerPubkey] = &PeerPoolItem{
+			Index:      uint32(i),
+			Address:    acct.Address,
+			Status:     ConsensusStatus,
+			PeerPubkey: peerPubkey,
+		}
+		accts = append(accts, acct)
+	}
+	peerPubkey := hex.EncodeToString(keypair.SerializePublicKey(accts[0].PublicKey))
+	reporter := accts[1]
+
+	var db *storage.CacheDB
+	resetDB := func() {
+		store, _ := leveldbstore.NewMemLevelDBStore()
+		db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+		sink := common.NewZeroCopySink(nil)
+		view := &GovernanceView{
+			TxHash: common.UINT256_EMPTY,
+		}
+		view.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(GOVERNANCE_VIEW)), cstates.GenRawStorageItem(sink.Bytes()))
+		sink = common.NewZeroCopySink(nil)
+		peerPoolMap.Serialization(sink)
+		db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(PEER_POOL), utils.GetUint32Bytes(0)),
+			cstates.GenRawStorageItem(sink.Bytes()))
+	}
+	resetDB()
+
+	signHeader := func(acct *account.Account, proposer, height uint32, nonce uint64, empty bool) ([]byte, []byte) {
+		payload, err := json.Marshal(&vconfig.VbftBlockInfo{Proposer: proposer})
+		assert.NoError(t, err)
+		header := &types.Header{
+			Height:           height,
+			ConsensusData:    nonce,
+			ConsensusPayload: payload,
+		}
+		if !empty {
+			header.TransactionsRoot = common.Uint256{1}
+		}
+		hash := header.Hash()
+		sig, err := signature.Sign(acct, hash[:])
+		assert.NoError(t, err)
+		sink := common.NewZeroCopySink(nil)
+		header.Serialization(sink)
+		return sink.Bytes(), sig
+	}
+	report := func(param *ReportEquivocationParam) (*native.NativeService, error) {
+		sink := common.NewZeroCopySink(nil)
+		param.Serialization(sink)
+		tx := &types.Transaction{
+			SignedAddr: []common.Address{reporter.Address},
+		}
+		ns, _ := native.NewNativeService(db, tx, 0, 10, common.Uint256{0}, 0, sink.Bytes(), false)
+		_, err := ReportEquivocation(ns)
+		return ns, err
+	}
+	evidence := func(kind SigKind, headers ...[2]interface{}) *ReportEquivocationParam {
+		param := &ReportEquivocationParam{
+			PeerPubkey: peerPubkey,
+			Kind:       kind,
+			Address:    reporter.Address,
+		}
+		for _, h := range headers {
+			param.Headers = append(param.Headers, h[0].([]byte))
+			param.Sigs = append(param.Sigs, h[1].([]byte))
+		}
+		return param
+	}
+	signed := func(acct *account.Account, proposer, height uint32, nonce uint64, empty bool) [2]interface{} {
+		header, sig := signHeader(acct, proposer, height, nonce, empty)
+		return [2]interface{}{header, sig}
+	}
+
+	// peer 0 proposes block and empty block
+	block := signed(accts[0], 0, 5, 0, false)
+	_, err := report(evidence(ProposalSig, block, signed(accts[0], 0, 5, 1, true)))
+	assert.Error(t, err)
+	// peer 0 proposes and endorses the block of peer 2
+	endorsed := signed(accts[0], 2, 5, 2, false)
+	for _, kind := range []SigKind{ProposalSig, EndorseSig, CommitSig} {
+		_, err = report(evidence(kind, block, endorsed))
+		assert.Error(t, err)
+	}
+	// headers of different heights
+	_, err = report(evidence(ProposalSig, block, signed(accts[0], 0, 6, 3, false)))
+	assert.Error(t, err)
+	// header not signed by peer 0
+	_, err = report(evidence(ProposalSig, block, signed(accts[2], 0, 5, 4, false)))
+	assert.Error(t, err)
+	// same header twice
+	_, err = report(evidence(ProposalSig, block, block))
+	assert.Error(t, err)
+	// endorses the block of peer 2 and empty block of peer 3
+	_, err = report(evidence(EndorseSig, signed(accts[0], 2, 5, 5, false), signed(accts[0], 3, 5, 6, true)))
+	assert.Error(t, err)
+
+	// blocks of peer 2 and peer 3 signed as proposals, or by unknown kind
+	endorsed2, endorsed3 := signed(accts[0], 2, 5, 7, false), signed(accts[0], 3, 5, 8, false)
+	_, err = report(evidence(ProposalSig, endorsed2, endorsed3))
+	assert.Error(t, err)
+	_, err = report(evidence(CommitSig+1, endorsed2, endorsed3))
+	assert.Error(t, err)
+
+	// commits blocks of peer 2 and peer 3
+	ns, err := report(evidence(CommitSig, endorsed2, endorsed3))
+	assert.NoError(t, err)
+	blacked, err := GetPeerPoolMap(ns, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, BlackStatus, blacked.PeerPoolMap[peerPubkey].Status)
+
+	// endorses blocks of peer 2 and peer 3
+	resetDB()
+	ns, err = report(evidence(EndorseSig, endorsed2, endorsed3))
+	assert.NoError(t, err)
+	blacked, err = GetPeerPoolMap(ns, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, BlackStatus, blacked.PeerPoolMap[peerPubkey].Status)
+
+	// double proposal
+	resetDB()
+	proposed := signed(accts[0], 0, 5, 9, false)
+	_, err = report(evidence(EndorseSig, block, proposed))
+	assert.Error(t, err)
+	param := evidence(ProposalSig, block, proposed)
+	ns, err = report(param)
+	assert.NoError(t, err)
+	states := ns.GetNotify()[0].States.([]interface{})
+	assert.Equal(t, "reportEquivocation", states[0])
+	assert.Equal(t, uint32(5), states[2])
+
+	peerPoolMap, err = GetPeerPoolMap(ns, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, BlackStatus, peerPoolMap.PeerPoolMap[peerPubkey].Status)
+	peerPubkeyPrefix, _ := hex.DecodeString(peerPubkey)
+	blackList, err := ns.GetCacheDB().Get(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(BLACK_LIST), peerPubkeyPrefix))
+	assert.NoError(t, err)
+	assert.NotNil(t, blackList)
+
+	// blacked peer can not be reported twice
+	_, err = report(param)
+	assert.Error(t, err)
+}
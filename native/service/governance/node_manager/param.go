@@ -137,3 +137,127 @@ func (this *UpdateConfigParam) Deserialization(source *common.ZeroCopySource) er
 	this.Configuration = configuration
 	return nil
 }
+
+type RegisterBlsKeyParam struct {
+	PeerPubkey string
+	BlsPubkey  []byte
+	Proof      []byte
+	Address    common.Address
+}
+
+func (this *RegisterBlsKeyParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteVarBytes(this.BlsPubkey)
+	sink.WriteVarBytes(this.Proof)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *RegisterBlsKeyParam) Deserialization(source *common.ZeroCopySource) error {
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+	}
+	blsPubkey, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize blsPubkey error")
+	}
+	proof, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize proof error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.PeerPubkey = peerPubkey
+	this.BlsPubkey = blsPubkey
+	this.Proof = proof
+	this.Address = addr
+	return nil
+}
+
+// SigKind is the kind of consensus msg a header signature of equivocation evidence is taken from
+type SigKind uint8
+
+const (
+	ProposalSig SigKind = iota // signature of proposer on its own proposal
+	EndorseSig                 // signature of endorser
+	CommitSig                  // signature of committer
+)
+
+type ReportEquivocationParam struct {
+	PeerPubkey string
+	Kind       SigKind // both sigs are of this kind
+	Headers    [][]byte
+	Sigs       [][]byte
+	Address    common.Address
+}
+
+func (this *ReportEquivocationParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteString(this.PeerPubkey)
+	sink.WriteUint8(uint8(this.Kind))
+	sink.WriteVarUint(uint64(len(this.Headers)))
+	for _, header := range this.Headers {
+		sink.WriteVarBytes(header)
+	}
+	sink.WriteVarUint(uint64(len(this.Sigs)))
+	for _, sig := range this.Sigs {
+		sink.WriteVarBytes(sig)
+	}
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *ReportEquivocationParam) Deserialization(source *common.ZeroCopySource) error {
+	peerPubkey, eof := source.NextString()
+	if eof {
+		return fmt.Errorf("source.NextString, deserialize peerPubkey error")
+	}
+	kind, eof := source.NextUint8()
+	if eof {
+		return fmt.Errorf("source.NextUint8, deserialize kind error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize headers length error")
+	}
+	headers := make([][]byte, 0)
+	for i := uint64(0); i < n; i++ {
+		header, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize header error")
+		}
+		headers = append(headers, header)
+	}
+	n, eof = source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize sigs length error")
+	}
+	sigs := make([][]byte, 0)
+	for i := uint64(0); i < n; i++ {
+		sig, eof := source.NextVarBytes()
+		if eof {
+			return fmt.Errorf("source.NextVarBytes, deserialize sig error")
+		}
+		sigs = append(sigs, sig)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+
+	this.PeerPubkey = peerPubkey
+	this.Kind = SigKind(kind)
+	this.Headers = headers
+	this.Sigs = sigs
+	this.Address = addr
+	return nil
+}
@@ -21,12 +21,15 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	"github.com/polynetwork/poly/core/signature"
 	cstates "github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
@@ -69,6 +72,36 @@ func putPeerApply(native *native.NativeService, peer *RegisterPeerParam) error {
 	return nil
 }
 
+func GetBlsKey(native *native.NativeService, peerPubkey string) ([]byte, error) {
+	contract := utils.NodeManagerContractAddress
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlsKey, peerPubkey format error: %v", err)
+	}
+	keyBytes, err := native.GetCacheDB().Get(utils.ConcatKey(contract, []byte(BLS_KEY), peerPubkeyPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("GetBlsKey, get bls key error: %v", err)
+	}
+	if keyBytes == nil {
+		return nil, nil
+	}
+	key, err := cstates.GetValueFromRawStorageItem(keyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlsKey, deserialize from raw storage item err:%v", err)
+	}
+	return key, nil
+}
+
+func putBlsKey(native *native.NativeService, peerPubkey string, key []byte) error {
+	contract := utils.NodeManagerContractAddress
+	peerPubkeyPrefix, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return fmt.Errorf("putBlsKey, peerPubkey format error: %v", err)
+	}
+	native.GetCacheDB().Put(utils.ConcatKey(contract, []byte(BLS_KEY), peerPubkeyPrefix), cstates.GenRawStorageItem(key))
+	return nil
+}
+
 func GetPeerPoolMap(native *native.NativeService, view uint32) (*PeerPoolMap, error) {
 	contract := utils.NodeManagerContractAddress
 	viewBytes := utils.GetUint32Bytes(view)
@@ -354,3 +387,64 @@ func GetCurConOperator(native *native.NativeService) (common.Address, error) {
 	}
 	return operator, nil
 }
+
+// verifyEquivocation checks the two headers are distinct non-empty blocks signed by the peer in the same
+// round, i.e. at the same height and chain config view, by the same kind of consensus msg. An honest peer
+// proposes one block, endorses one block and commits one block in a round, and the blocks it endorses and
+// commits may differ, so signatures of different kinds never conflict. The signature of a peer on its own
+// proposal can not be told from an endorsement or commitment, so proposal evidence must consist of headers
+// proposed by the peer and endorse or commit evidence of headers proposed by others. Empty blocks, and blocks
+// with no user transaction, are signed alongside the proposed block and never count as a conflict.
+func verifyEquivocation(peerIndex uint32, peerPubkey string, kind SigKind, headers, sigs [][]byte) (uint32, error) {
+	if kind > CommitSig {
+		return 0, fmt.Errorf("verifyEquivocation, unknown sig kind %d", kind)
+	}
+	if len(headers) != 2 || len(sigs) != 2 {
+		return 0, fmt.Errorf("verifyEquivocation, 2 headers and sigs required, got %d headers with %d sigs", len(headers), len(sigs))
+	}
+	k, err := hex.DecodeString(peerPubkey)
+	if err != nil {
+		return 0, fmt.Errorf("verifyEquivocation, peerPubkey format error: %v", err)
+	}
+	pk, err := keypair.DeserializePublicKey(k)
+	if err != nil {
+		return 0, fmt.Errorf("verifyEquivocation, keypair.DeserializePublicKey error: %v", err)
+	}
+
+	signed := make([]*types.Header, 0, len(headers))
+	hashes := make([]common.Uint256, 0, len(headers))
+	infos := make([]*vconfig.VbftBlockInfo, 0, len(headers))
+	for i, raw := range headers {
+		header, err := types.HeaderFromRawBytes(raw)
+		if err != nil {
+			return 0, fmt.Errorf("verifyEquivocation, deserialize header error: %v", err)
+		}
+		hash := header.Hash()
+		info := new(vconfig.VbftBlockInfo)
+		if err := json.Unmarshal(header.ConsensusPayload, info); err != nil {
+			return 0, fmt.Errorf("verifyEquivocation, unmarshal consensus payload of header %s error: %v", hash.ToHexString(), err)
+		}
+		if info.NewChainConfig != nil || header.TransactionsRoot == common.UINT256_EMPTY {
+			return 0, fmt.Errorf("verifyEquivocation, header %s is empty block", hash.ToHexString())
+		}
+		if err := signature.Verify(pk, hash[:], sigs[i]); err != nil {
+			return 0, fmt.Errorf("verifyEquivocation, verify signature of header %s error: %v", hash.ToHexString(), err)
+		}
+		signed = append(signed, header)
+		hashes = append(hashes, hash)
+		infos = append(infos, info)
+	}
+	if hashes[0] == hashes[1] {
+		return 0, fmt.Errorf("verifyEquivocation, duplicated header %s", hashes[0].ToHexString())
+	}
+	if signed[0].Height != signed[1].Height || infos[0].LastConfigBlockNum != infos[1].LastConfigBlockNum {
+		return 0, fmt.Errorf("verifyEquivocation, headers not proposed in the same round")
+	}
+	for i, info := range infos {
+		if (info.Proposer == peerIndex) != (kind == ProposalSig) {
+			return 0, fmt.Errorf("verifyEquivocation, header %s proposed by %d is not signed by kind %d of the peer",
+				hashes[i].ToHexString(), info.Proposer, kind)
+		}
+	}
+	return signed[0].Height, nil
+}
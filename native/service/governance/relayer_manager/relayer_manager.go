@@ -22,6 +22,7 @@ import (
 	"github.com/polynetwork/poly/native/event"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/utils"
@@ -33,6 +34,7 @@ const (
 	APPROVE_REGISTER_RELAYER = "approveRegisterRelayer"
 	REMOVE_RELAYER           = "RemoveRelayer"
 	APPROVE_REMOVE_RELAYER   = "approveRemoveRelayer"
+	INIT_GENESIS             = "initGenesis"
 
 	//key prefix
 	RELAYER        = "relayer"
@@ -48,6 +50,28 @@ func RegisterRelayerManagerContract(native *native.NativeService) {
 	native.Register(APPROVE_REGISTER_RELAYER, ApproveRegisterRelayer)
 	native.Register(REMOVE_RELAYER, RemoveRelayer)
 	native.Register(APPROVE_REMOVE_RELAYER, ApproveRemoveRelayer)
+	native.Register(INIT_GENESIS, InitGenesis)
+}
+
+//InitGenesis register relayers of genesis config, only allowed in genesis block
+func InitGenesis(native *native.NativeService) ([]byte, error) {
+	if native.GetHeight() != 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, only allowed in genesis block")
+	}
+	params := new(config.GenesisGovernanceConfig)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, contract params deserialize error: %v", err)
+	}
+	for _, v := range params.Relayers {
+		relayer, err := common.AddressFromBase58(v)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, relayer %s error: %v", v, err)
+		}
+		if err = putRelayer(native, relayer); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, putRelayer error: %v", err)
+		}
+	}
+	return utils.BYTE_TRUE, nil
 }
 
 func RegisterRelayer(native *native.NativeService) ([]byte, error) {
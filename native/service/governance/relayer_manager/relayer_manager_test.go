@@ -20,6 +20,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
 	cstates "github.com/polynetwork/poly/core/states"
@@ -197,3 +198,28 @@ func TestRemoveRelayer(t *testing.T) {
 		}
 	}
 }
+
+func TestInitGenesis(t *testing.T) {
+	relayers := []common.Address{{1, 2, 4, 6}, {1, 4, 5, 7}}
+	gov := &config.GenesisGovernanceConfig{}
+	for _, relayer := range relayers {
+		gov.Relayers = append(gov.Relayers, relayer.ToBase58())
+	}
+	sink := common.NewZeroCopySink(nil)
+	gov.Serialization(sink)
+
+	ns, _ := native.NewNativeService(nil, new(types.Transaction), 0, 200, common.Uint256{0}, 0, sink.Bytes(), false)
+	res, err := InitGenesis(ns)
+	assert.Error(t, err)
+	assert.Equal(t, utils.BYTE_FALSE, res)
+
+	ns = NewNative(sink.Bytes(), new(types.Transaction), nil)
+	res, err = InitGenesis(ns)
+	assert.Nil(t, err)
+	assert.Equal(t, utils.BYTE_TRUE, res)
+	for _, relayer := range relayers {
+		value, err := ns.GetCacheDB().Get(utils.ConcatKey(utils.RelayerManagerContractAddress, []byte(RELAYER), relayer[:]))
+		assert.Nil(t, err)
+		assert.NotNil(t, value)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/native"
 	"github.com/polynetwork/poly/native/event"
 	"github.com/polynetwork/poly/native/service/governance/node_manager"
@@ -40,6 +41,7 @@ const (
 	APPROVE_QUIT_SIDE_CHAIN     = "approveQuitSideChain"
 	REGISTER_REDEEM             = "registerRedeem"
 	SET_BTC_TX_PARAM            = "setBtcTxParam"
+	INIT_GENESIS                = "initGenesis"
 
 	//key prefix
 	SIDE_CHAIN_APPLY          = "sideChainApply"
@@ -63,6 +65,97 @@ func RegisterSideChainManagerContract(native *native.NativeService) {
 
 	native.Register(REGISTER_REDEEM, RegisterRedeem)
 	native.Register(SET_BTC_TX_PARAM, SetBtcTxParam)
+	native.Register(INIT_GENESIS, InitGenesis)
+}
+
+//InitGenesis register side chains and btc redeems of genesis config, only allowed in genesis block
+func InitGenesis(native *native.NativeService) ([]byte, error) {
+	if native.GetHeight() != 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, only allowed in genesis block")
+	}
+	params := new(config.GenesisGovernanceConfig)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, contract params deserialize error: %v", err)
+	}
+
+	for _, chain := range params.SideChains {
+		sideChain, err := GetSideChain(native, chain.ChainId)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, getSideChain error: %v", err)
+		}
+		if sideChain != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, chainid %d already registered", chain.ChainId)
+		}
+		sideChain = &SideChain{
+			ChainId:      chain.ChainId,
+			Router:       chain.Router,
+			Name:         chain.Name,
+			BlocksToWait: chain.BlocksToWait,
+		}
+		if chain.Address != "" {
+			sideChain.Address, err = common.AddressFromBase58(chain.Address)
+			if err != nil {
+				return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, chainid %d address error: %v", chain.ChainId, err)
+			}
+		}
+		if sideChain.CCMCAddress, err = hex.DecodeString(chain.CCMCAddress); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, chainid %d ccmc address error: %v", chain.ChainId, err)
+		}
+		if sideChain.ExtraInfo, err = hex.DecodeString(chain.ExtraInfo); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, chainid %d extra info error: %v", chain.ChainId, err)
+		}
+		if err = PutSideChain(native, sideChain); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, putSideChain error: %v", err)
+		}
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.SideChainManagerContractAddress,
+				States:          []interface{}{"ApproveRegisterSideChain", chain.ChainId},
+			})
+	}
+
+	for _, redeem := range params.BtcRedeems {
+		redeemScript, err := hex.DecodeString(redeem.Redeem)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, redeem of chain %d error: %v", redeem.RedeemChainID, err)
+		}
+		contractAddress, err := hex.DecodeString(redeem.ContractAddress)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, contract address of chain %d error: %v", redeem.ContractChainID, err)
+		}
+		ty, _, _, err := txscript.ExtractPkScriptAddrs(redeemScript, netParam)
+		if err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, failed to extract addrs: %v", err)
+		}
+		if ty != txscript.MultiSigTy {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, wrong type of redeem: %s", ty.String())
+		}
+		rk := btcutil.Hash160(redeemScript)
+		if err = putContractBind(native, redeem.RedeemChainID, redeem.ContractChainID, rk, contractAddress, 0); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, putContractBind error: %v", err)
+		}
+		if err = putBtcRedeemScript(native, hex.EncodeToString(rk), redeemScript, redeem.RedeemChainID); err != nil {
+			return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, failed to save redeemscript %s, error: %v", redeem.Redeem, err)
+		}
+		if redeem.FeeRate > 0 {
+			if redeem.MinChange < 2000 {
+				return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, min-change can't less than 2000")
+			}
+			detail := &BtcTxParamDetial{
+				FeeRate:   redeem.FeeRate,
+				MinChange: redeem.MinChange,
+			}
+			if err = putBtcTxParam(native, rk, redeem.RedeemChainID, detail); err != nil {
+				return utils.BYTE_FALSE, fmt.Errorf("InitGenesis, failed to put btcTxParam: %v", err)
+			}
+		}
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.SideChainManagerContractAddress,
+				States:          []interface{}{"RegisterRedeem", hex.EncodeToString(rk), hex.EncodeToString(contractAddress)},
+			})
+	}
+	return utils.BYTE_TRUE, nil
 }
 
 func RegisterSideChain(native *native.NativeService) ([]byte, error) {
@@ -22,11 +22,15 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/genesis"
+	"github.com/polynetwork/poly/core/states"
 	"github.com/polynetwork/poly/core/store/leveldbstore"
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
 	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
@@ -67,6 +71,33 @@ func NewNative(args []byte, tx *types.Transaction, db *storage.CacheDB) *native.
 	return ns
 }
 
+//newGovernanceDB make acct the only consensus node, so its approvals reach the quorum
+func newGovernanceDB() *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		states.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			vconfig.PubkeyID(acct.PublicKey): {
+				Address:    acct.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+			},
+		},
+	}
+	sink.Reset()
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		states.GenRawStorageItem(sink.Bytes()))
+	return db
+}
+
 func TestRegisterSideChainManager(t *testing.T) {
 	param := new(RegisterSideChainParam)
 	param.Address = acct.Address
@@ -81,7 +112,7 @@ func TestRegisterSideChainManager(t *testing.T) {
 	tx := &types.Transaction{
 		SignedAddr: []common.Address{acct.Address},
 	}
-	nativeService = NewNative(sink.Bytes(), tx, nil)
+	nativeService = NewNative(sink.Bytes(), tx, newGovernanceDB())
 	res, err := RegisterSideChain(nativeService)
 	assert.Equal(t, res, []byte{1})
 	assert.Nil(t, err)
@@ -98,6 +129,7 @@ func TestRegisterSideChainManager(t *testing.T) {
 func TestApproveRegisterSideChain(t *testing.T) {
 	param := new(ChainidParam)
 	param.Chainid = 8
+	param.Address = acct.Address
 
 	sink := common.NewZeroCopySink(nil)
 	param.Serialization(sink)
@@ -114,7 +146,7 @@ func TestApproveRegisterSideChain(t *testing.T) {
 
 func TestUpdateSideChain(t *testing.T) {
 	param := new(RegisterSideChainParam)
-	param.Address = common.Address{1, 2, 3}
+	param.Address = acct.Address
 	param.BlocksToWait = 10
 	param.ChainId = 8
 	param.Name = "own"
@@ -137,6 +169,7 @@ func TestUpdateSideChain(t *testing.T) {
 func TestApproveUpdateSideChain(t *testing.T) {
 	param := new(ChainidParam)
 	param.Chainid = 8
+	param.Address = acct.Address
 	sink := common.NewZeroCopySink(nil)
 	param.Serialization(sink)
 
@@ -242,3 +275,69 @@ func TestSetBtcTxParam(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, utils.BYTE_FALSE, ok)
 }
+
+func TestInitGenesis(t *testing.T) {
+	redeem := "552102dec9a415b6384ec0a9331d0cdf02020f0f1e5731c327b86e2b5a92455a289748210365b1066bcfa21987c3e207b92e309b95ca6bee5f1133cf04d6ed4ed265eafdbc21031104e387cd1a103c27fdc8a52d5c68dec25ddfb2f574fbdca405edfd8c5187de21031fdb4b44a9f20883aff505009ebc18702774c105cb04b1eecebcb294d404b1cb210387cda955196cc2b2fc0adbbbac1776f8de77b563c6d2a06a77d96457dc3d0d1f2102dd7767b6a7cc83693343ba721e0f5f4c7b4b8d85eeb7aec20d227625ec0f59d321034ad129efdab75061e8d4def08f5911495af2dae6d3e9a4b6e7aeb5186fa432fc57ae"
+	gov := &config.GenesisGovernanceConfig{
+		SideChains: []*config.GenesisSideChain{
+			{
+				Address:      acct.Address.ToBase58(),
+				ChainId:      8,
+				Router:       3,
+				Name:         "mychain",
+				BlocksToWait: 4,
+				CCMCAddress:  "9a20bed97360d28ae93c21750e9492ea8f85989f",
+				ExtraInfo:    "0102",
+			},
+		},
+		BtcRedeems: []*config.GenesisBtcRedeem{
+			{
+				RedeemChainID:   1,
+				ContractChainID: 2,
+				Redeem:          redeem,
+				ContractAddress: "9A20BED97360D28AE93C21750E9492EA8F85989F",
+				FeeRate:         2,
+				MinChange:       2000,
+			},
+		},
+	}
+	sink := common.NewZeroCopySink(nil)
+	gov.Serialization(sink)
+
+	ok, err := InitGenesis(getNativeFunc(sink.Bytes()))
+	assert.Error(t, err)
+	assert.Equal(t, utils.BYTE_FALSE, ok)
+
+	ns := NewNative(sink.Bytes(), new(types.Transaction), nil)
+	ok, err = InitGenesis(ns)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.BYTE_TRUE, ok)
+
+	sideChain, err := GetSideChain(ns, 8)
+	assert.NoError(t, err)
+	assert.Equal(t, acct.Address, sideChain.Address)
+	assert.Equal(t, uint64(3), sideChain.Router)
+	assert.Equal(t, "mychain", sideChain.Name)
+	assert.Equal(t, []byte{1, 2}, sideChain.ExtraInfo)
+
+	rk, _ := hex.DecodeString("c330431496364497d7257839737b5e4596f5ac06")
+	contract, err := GetContractBind(ns, 1, 2, rk)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), contract.Ver)
+	assert.Equal(t, "9a20bed97360d28ae93c21750e9492ea8f85989f", hex.EncodeToString(contract.Contract))
+	script, err := GetBtcRedeemScriptBytes(ns, hex.EncodeToString(rk), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, redeem, hex.EncodeToString(script))
+	param, err := GetBtcTxParam(ns, rk, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), param.FeeRate)
+	//contract address is notified in hex as RegisterRedeem does
+	notify := ns.GetNotify()[len(ns.GetNotify())-1]
+	assert.Equal(t, []interface{}{"RegisterRedeem", hex.EncodeToString(rk), "9a20bed97360d28ae93c21750e9492ea8f85989f"},
+		notify.States)
+
+	//side chain registered
+	ok, err = InitGenesis(ns)
+	assert.Error(t, err)
+	assert.Equal(t, utils.BYTE_FALSE, ok)
+}
@@ -29,6 +29,8 @@ func TestSideChain_Serialization(t *testing.T) {
 	paramSerialize.Router = 7
 	paramSerialize.ChainId = 8
 	paramSerialize.BlocksToWait = 10
+	paramSerialize.CCMCAddress = []byte{}
+	paramSerialize.ExtraInfo = []byte{}
 	sink := common.NewZeroCopySink(nil)
 	err := paramSerialize.Serialization(sink)
 	assert.Nil(t, err)
@@ -236,7 +236,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("bsc Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
-			log.Warnf("bsc Handler SyncBlockHeader, header has exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/bsc").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("bsc Handler SyncBlockHeader, header has exist. Header: %s", string(v))
 			continue
 		}
 
@@ -245,7 +246,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("bsc Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
-			log.Warnf("bsc Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/bsc").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("bsc Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
 			continue
 		}
 
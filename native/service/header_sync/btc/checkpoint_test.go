@@ -0,0 +1,301 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const cpChainID = uint64(8)
+
+func newCheckpointNative(args []byte, db *storage.CacheDB) *native.NativeService {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns, _ := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, args, false)
+	return ns
+}
+
+// newCheckpointDB makes acct the only consensus node and registers a regtest side chain with its genesis header synced
+func newCheckpointDB(t *testing.T, blocksToWait uint64) *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			vconfig.PubkeyID(acct.PublicKey): {
+				Address:    acct.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+			},
+		},
+	}
+	sink.Reset()
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+
+	netType := make([]byte, 8)
+	binary.LittleEndian.PutUint64(netType, uint64(utils.TyRegtest))
+	err := side_chain_manager.PutSideChain(newCheckpointNative(nil, db), &side_chain_manager.SideChain{
+		ChainId:      cpChainID,
+		Router:       utils.BTC_ROUTER,
+		Name:         "btc",
+		BlocksToWait: blocksToWait,
+		CCMCAddress:  netType,
+	})
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	_ = chaincfg.RegressionNetParams.GenesisBlock.Header.BtcEncode(&buf, wire.ProtocolVersion, wire.LatestEncoding)
+	param := &scom.SyncGenesisHeaderParam{
+		ChainID:       cpChainID,
+		GenesisHeader: append(buf.Bytes(), 0, 0, 0, 0),
+	}
+	sink.Reset()
+	param.Serialization(sink)
+	err = NewBTCHandler().SyncGenesisHeader(newCheckpointNative(sink.Bytes(), db))
+	assert.NoError(t, err)
+	return db
+}
+
+// mineHeaders builds n regtest headers on top of prev, branch makes sibling headers differ
+func mineHeaders(prev chainhash.Hash, n int, branch byte) []wire.BlockHeader {
+	hdrs := make([]wire.BlockHeader, 0, n)
+	ts := chaincfg.RegressionNetParams.GenesisBlock.Header.Timestamp
+	for i := 0; i < n; i++ {
+		hdr := wire.BlockHeader{
+			Version:    1,
+			PrevBlock:  prev,
+			MerkleRoot: chainhash.Hash{branch, byte(i)},
+			Timestamp:  ts.Add(time.Duration(i+1) * 10 * time.Minute),
+			Bits:       chaincfg.RegressionNetParams.PowLimitBits,
+		}
+		for !checkProofOfWork(hdr, &chaincfg.RegressionNetParams) {
+			hdr.Nonce++
+		}
+		hdrs = append(hdrs, hdr)
+		prev = hdr.BlockHash()
+	}
+	return hdrs
+}
+
+func syncHeaders(db *storage.CacheDB, hdrs ...wire.BlockHeader) error {
+	param := &scom.SyncBlockHeaderParam{
+		ChainID: cpChainID,
+		Address: acct.Address,
+	}
+	for _, hdr := range hdrs {
+		var buf bytes.Buffer
+		_ = hdr.Serialize(&buf)
+		param.Headers = append(param.Headers, buf.Bytes())
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return NewBTCHandler().SyncBlockHeader(newCheckpointNative(sink.Bytes(), db))
+}
+
+func setCheckpoints(db *storage.CacheDB, cps ...*Checkpoint) error {
+	param := &SetCheckpointsParam{
+		ChainID:     cpChainID,
+		Checkpoints: cps,
+		Address:     acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	_, err := SetCheckpoints(newCheckpointNative(sink.Bytes(), db))
+	return err
+}
+
+func setMaxReorgDepth(db *storage.CacheDB, depth uint32) error {
+	param := &SetMaxReorgDepthParam{
+		ChainID:       cpChainID,
+		MaxReorgDepth: depth,
+		Address:       acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	_, err := SetMaxReorgDepth(newCheckpointNative(sink.Bytes(), db))
+	return err
+}
+
+func TestSetCheckpointsParam(t *testing.T) {
+	param := SetCheckpointsParam{
+		ChainID: cpChainID,
+		Checkpoints: []*Checkpoint{
+			{Height: 1, Hash: chainhash.Hash{1}},
+			{Height: 100, Hash: chainhash.Hash{2}},
+		},
+		Address: acct.Address,
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+
+	var p SetCheckpointsParam
+	err := p.Deserialization(common.NewZeroCopySource(sink.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, param, p)
+}
+
+func TestCheckpoints_Put(t *testing.T) {
+	cps := new(Checkpoints)
+	cps.Put(&Checkpoint{Height: 10, Hash: chainhash.Hash{1}})
+	cps.Put(&Checkpoint{Height: 5, Hash: chainhash.Hash{2}})
+	cps.Put(&Checkpoint{Height: 20, Hash: chainhash.Hash{3}})
+	cps.Put(&Checkpoint{Height: 10, Hash: chainhash.Hash{4}})
+
+	assert.Equal(t, 3, len(cps.Checkpoints))
+	for i, h := range []uint32{5, 10, 20} {
+		assert.Equal(t, h, cps.Checkpoints[i].Height)
+	}
+	assert.Equal(t, chainhash.Hash{4}, cps.Checkpoints[1].Hash)
+}
+
+func TestCommitHeader_Checkpoint(t *testing.T) {
+	db := newCheckpointDB(t, 1)
+	genesisHash := chaincfg.RegressionNetParams.GenesisBlock.Header.BlockHash()
+	mainHdrs := mineHeaders(genesisHash, 6, 0)
+
+	// checkpoint ahead of the synced chain
+	err := setCheckpoints(db, &Checkpoint{Height: 4, Hash: mainHdrs[3].BlockHash()})
+	assert.NoError(t, err)
+	assert.NoError(t, syncHeaders(db, mainHdrs[:3]...))
+
+	// a header conflicting with the checkpoint is rejected
+	conflict := mineHeaders(mainHdrs[2].BlockHash(), 1, 1)
+	assert.Error(t, syncHeaders(db, conflict...))
+	assert.NoError(t, syncHeaders(db, mainHdrs[3:]...))
+
+	// forks branching off below the passed checkpoint are rejected even with more work
+	fork := mineHeaders(mainHdrs[1].BlockHash(), 6, 2)
+	assert.Error(t, syncHeaders(db, fork...))
+	ns := newCheckpointNative(nil, db)
+	best, err := GetBestBlockHeader(ns, cpChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, mainHdrs[5].BlockHash(), best.Header.BlockHash())
+
+	// forks above the checkpoint are still fine
+	fork = mineHeaders(mainHdrs[3].BlockHash(), 3, 3)
+	assert.NoError(t, syncHeaders(db, fork...))
+	best, err = GetBestBlockHeader(ns, cpChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, fork[2].BlockHash(), best.Header.BlockHash())
+
+	// checkpoints can not rewrite the synced chain
+	assert.Error(t, setCheckpoints(db, &Checkpoint{Height: 2, Hash: chainhash.Hash{1}}))
+}
+
+func TestCommitHeader_MaxReorgDepth(t *testing.T) {
+	db := newCheckpointDB(t, 1)
+	genesisHash := chaincfg.RegressionNetParams.GenesisBlock.Header.BlockHash()
+	mainHdrs := mineHeaders(genesisHash, 6, 0)
+	assert.NoError(t, syncHeaders(db, mainHdrs...))
+	assert.NoError(t, setMaxReorgDepth(db, 2))
+
+	// fork header at height 4 would replace 3 blocks
+	fork := mineHeaders(mainHdrs[2].BlockHash(), 4, 1)
+	assert.Error(t, syncHeaders(db, fork[0]))
+
+	// fork header at height 5 is kept as a side branch
+	fork = mineHeaders(mainHdrs[3].BlockHash(), 4, 2)
+	assert.NoError(t, syncHeaders(db, fork[0]))
+
+	// the best chain moves on, the branch falls behind the final height
+	next := mineHeaders(mainHdrs[5].BlockHash(), 1, 0)
+	assert.NoError(t, syncHeaders(db, next...))
+	assert.Error(t, syncHeaders(db, fork[1:]...))
+
+	ns := newCheckpointNative(nil, db)
+	best, err := GetBestBlockHeader(ns, cpChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, next[0].BlockHash(), best.Header.BlockHash())
+
+	// a reorg within the depth is accepted
+	fork = mineHeaders(mainHdrs[5].BlockHash(), 3, 3)
+	assert.NoError(t, syncHeaders(db, fork...))
+	best, err = GetBestBlockHeader(ns, cpChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, fork[2].BlockHash(), best.Header.BlockHash())
+}
+
+func TestGetFinalizedHeight(t *testing.T) {
+	genesisHash := chaincfg.RegressionNetParams.GenesisBlock.Header.BlockHash()
+
+	db := newCheckpointDB(t, 5)
+	assert.NoError(t, syncHeaders(db, mineHeaders(genesisHash, 3, 0)...))
+	_, err := GetFinalizedHeight(newCheckpointNative(nil, db), cpChainID)
+	assert.Error(t, err)
+
+	db = newCheckpointDB(t, 5)
+	ns := newCheckpointNative(nil, db)
+	assert.NoError(t, syncHeaders(db, mineHeaders(genesisHash, 10, 0)...))
+	height, err := GetFinalizedHeight(ns, cpChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(6), height)
+
+	// the deeper of max reorg depth and BlocksToWait wins
+	assert.NoError(t, setMaxReorgDepth(db, 7))
+	height, err = GetFinalizedHeight(ns, cpChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), height)
+
+	// query through the header sync contract
+	native.Contracts[utils.HeaderSyncContractAddress] = func(ns *native.NativeService) {
+		ns.Register(GET_FINALIZED_HEIGHT, GetBtcFinalizedHeight)
+	}
+	defer delete(native.Contracts, utils.HeaderSyncContractAddress)
+	sink := common.NewZeroCopySink(nil)
+	(&GetFinalizedHeightParam{ChainID: cpChainID}).Serialization(sink)
+	res, err := newCheckpointNative(nil, db).NativeCall(utils.HeaderSyncContractAddress, GET_FINALIZED_HEIGHT, sink.Bytes())
+	assert.NoError(t, err)
+	height, eof := common.NewZeroCopySource(res.([]byte)).NextUint32()
+	assert.False(t, eof)
+	assert.Equal(t, uint32(3), height)
+
+	sink.Reset()
+	(&GetFinalizedHeightParam{ChainID: cpChainID + 1}).Serialization(sink)
+	_, err = newCheckpointNative(nil, db).NativeCall(utils.HeaderSyncContractAddress, GET_FINALIZED_HEIGHT, sink.Bytes())
+	assert.Error(t, err)
+}
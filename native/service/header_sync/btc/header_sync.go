@@ -32,6 +32,20 @@ import (
 	"bytes"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/native/event"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+)
+
+const (
+	//function name
+	SET_CHECKPOINTS      = "setBtcCheckpoints"
+	SET_MAX_REORG_DEPTH  = "setBtcMaxReorgDepth"
+	GET_FINALIZED_HEIGHT = "getBtcFinalizedHeight"
+
+	//key prefix
+	CHECKPOINTS     = "btcCheckpoints"
+	MAX_REORG_DEPTH = "btcMaxReorgDepth"
 )
 
 type BTCHandler struct {
@@ -146,6 +160,19 @@ func commitHeader(native *native.NativeService, chainID uint64, header wire.Bloc
 				headerHash, err)
 		}
 	}
+	newHeight := parentHeader.Height + 1
+	if err = checkCheckpoints(native, chainID, headerHash, newHeight, bestHeader.Height); err != nil {
+		return false, nil, 0, fmt.Errorf("commit header error: %v", err)
+	}
+	maxReorgDepth, err := GetMaxReorgDepth(native, chainID)
+	if err != nil {
+		return false, nil, 0, err
+	}
+	// every header at or below best height is a fork, reject it if it branches off a final header
+	if maxReorgDepth > 0 && newHeight+maxReorgDepth <= bestHeader.Height {
+		return false, nil, 0, fmt.Errorf("commit header error: header %s at height %d forks below final height %d",
+			headerHash, newHeight, bestHeader.Height-maxReorgDepth)
+	}
 	valid, err := CheckHeader(native, chainID, header, parentHeader)
 	if err != nil {
 		return false, nil, 0, err
@@ -176,12 +203,15 @@ func commitHeader(native *native.NativeService, chainID uint64, header wire.Bloc
 			if err != nil {
 				return newTip, commonAncestor, 0, fmt.Errorf("Error calculating common ancestor: %s", err.Error())
 			}
-			log.Warnf("REORG! At block %d, Wiped out %d blocks", int(bestHeader.Height),
-				int(bestHeader.Height-commonAncestor.Height))
+			if maxReorgDepth > 0 && bestHeader.Height-commonAncestor.Height > maxReorgDepth {
+				return false, nil, 0, fmt.Errorf("commit header error: reorg of %d blocks exceeds max reorg depth %d",
+					bestHeader.Height-commonAncestor.Height, maxReorgDepth)
+			}
+			log.Module("native/service/header_sync/btc").With(log.Fields{"chainID": chainID, "height": bestHeader.Height}).
+				Warnf("REORG! Wiped out %d blocks", int(bestHeader.Height-commonAncestor.Height))
 		}
 	}
 
-	newHeight := parentHeader.Height + 1
 	nb := StoredHeader{
 		Header:    header,
 		Height:    newHeight,
@@ -212,3 +242,126 @@ func commitHeader(native *native.NativeService, chainID uint64, header wire.Bloc
 
 	return newTip, commonAncestor, newHeight, nil
 }
+
+// SetCheckpoints adds checkpoints for a btc chain once enough consensus nodes approve them
+func SetCheckpoints(native *native.NativeService) ([]byte, error) {
+	params := new(SetCheckpointsParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, contract params deserialize error: %v", err)
+	}
+	if len(params.Checkpoints) == 0 {
+		return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, no checkpoint is given")
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, checkWitness error: %v", err)
+	}
+	if err = checkBtcSideChain(native, params.ChainID); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, %v", err)
+	}
+
+	checkpoints, err := GetCheckpoints(native, params.ChainID)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, %v", err)
+	}
+	for _, cp := range params.Checkpoints {
+		// a checkpoint can not rewrite the synced best chain
+		hash, err := GetBlockHashByHeight(native, params.ChainID, cp.Height)
+		if err == nil && !hash.IsEqual(&cp.Hash) {
+			return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, checkpoint %s at height %d conflicts with synced header %s",
+				cp.Hash.String(), cp.Height, hash.String())
+		}
+		checkpoints.Put(cp)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.serializeContent(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_CHECKPOINTS, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetCheckpoints, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putCheckpoints(native, params.ChainID, checkpoints)
+	for _, cp := range params.Checkpoints {
+		native.AddNotify(
+			&event.NotifyEventInfo{
+				ContractAddress: utils.HeaderSyncContractAddress,
+				States:          []interface{}{SET_CHECKPOINTS, params.ChainID, cp.Height, cp.Hash.String()},
+			})
+	}
+	return utils.BYTE_TRUE, nil
+}
+
+// SetMaxReorgDepth sets how many blocks a reorg of a btc chain may wipe out once enough consensus nodes approve it
+func SetMaxReorgDepth(native *native.NativeService) ([]byte, error) {
+	params := new(SetMaxReorgDepthParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxReorgDepth, contract params deserialize error: %v", err)
+	}
+
+	//check witness
+	err := utils.ValidateOwner(native, params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxReorgDepth, checkWitness error: %v", err)
+	}
+	if err = checkBtcSideChain(native, params.ChainID); err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxReorgDepth, %v", err)
+	}
+
+	//check consensus signs
+	sink := common.NewZeroCopySink(nil)
+	params.serializeContent(sink)
+	ok, err := node_manager.CheckConsensusSigns(native, SET_MAX_REORG_DEPTH, sink.Bytes(), params.Address)
+	if err != nil {
+		return utils.BYTE_FALSE, fmt.Errorf("SetMaxReorgDepth, CheckConsensusSigns error: %v", err)
+	}
+	if !ok {
+		return utils.BYTE_TRUE, nil
+	}
+
+	putMaxReorgDepth(native, params.ChainID, params.MaxReorgDepth)
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.HeaderSyncContractAddress,
+			States:          []interface{}{SET_MAX_REORG_DEPTH, params.ChainID, params.MaxReorgDepth},
+		})
+	return utils.BYTE_TRUE, nil
+}
+
+// GetBtcFinalizedHeight returns the finalized height of a btc chain as uint32, see GetFinalizedHeight
+func GetBtcFinalizedHeight(native *native.NativeService) ([]byte, error) {
+	params := new(GetFinalizedHeightParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return nil, fmt.Errorf("GetBtcFinalizedHeight, contract params deserialize error: %v", err)
+	}
+	if err := checkBtcSideChain(native, params.ChainID); err != nil {
+		return nil, fmt.Errorf("GetBtcFinalizedHeight, %v", err)
+	}
+	height, err := GetFinalizedHeight(native, params.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("GetBtcFinalizedHeight, %v", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint32(height)
+	return sink.Bytes(), nil
+}
+
+func checkBtcSideChain(native *native.NativeService, chainID uint64) error {
+	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil {
+		return fmt.Errorf("side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return fmt.Errorf("side chain %d is not registered", chainID)
+	}
+	if sideChain.Router != utils.BTC_ROUTER {
+		return fmt.Errorf("side chain %d is not a btc chain", chainID)
+	}
+	return nil
+}
@@ -30,7 +30,9 @@ import (
 	"github.com/polynetwork/poly/core/store/overlaydb"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
 	"github.com/polynetwork/poly/native/storage"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -39,15 +41,45 @@ import (
 var (
 	acct *account.Account = account.NewAccount("")
 
+	// copy of testnet3 params, tests change its difficulty flags
+	netParam = func() *chaincfg.Params {
+		p := chaincfg.TestNet3Params
+		return &p
+	}()
+
 	getNativeFunc = func(args []byte, db *storage.CacheDB) *native.NativeService {
 		if db == nil {
 			store, _ := leveldbstore.NewMemLevelDBStore()
 			db = storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+			putTestSideChain(db)
 		}
 		ns, _ := native.NewNativeService(db, new(types.Transaction), 0, 0, common.Uint256{0}, 0, args, false)
 		return ns
 	}
 
+	// register chain 0 with the net type of netParam, which CheckHeader reads
+	putTestSideChain = func(db *storage.CacheDB) {
+		netType := utils.TyTestnet3
+		switch netParam.Name {
+		case chaincfg.RegressionNetParams.Name:
+			netType = utils.TyRegtest
+		case chaincfg.SimNetParams.Name:
+			netType = utils.TySimnet
+		case chaincfg.MainNetParams.Name:
+			netType = utils.TyMainnet
+		}
+		ccmc := make([]byte, 8)
+		binary.LittleEndian.PutUint64(ccmc, uint64(netType))
+		ns, _ := native.NewNativeService(db, new(types.Transaction), 0, 0, common.Uint256{0}, 0, nil, false)
+		_ = side_chain_manager.PutSideChain(ns, &side_chain_manager.SideChain{
+			Name:         "btc",
+			ChainId:      0,
+			Router:       utils.BTC_ROUTER,
+			BlocksToWait: 1,
+			CCMCAddress:  ccmc,
+		})
+	}
+
 	getHeaders = func() []*wire.BlockHeader {
 		res := make([]*wire.BlockHeader, 0)
 		for _, v := range chain {
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package btc
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+)
+
+type SetCheckpointsParam struct {
+	ChainID     uint64
+	Checkpoints []*Checkpoint
+	Address     common.Address
+}
+
+func (this *SetCheckpointsParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+// serializeContent writes everything except the address, consensus nodes sign
+// the same content with their own addresses
+func (this *SetCheckpointsParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteVarUint(uint64(len(this.Checkpoints)))
+	for _, v := range this.Checkpoints {
+		v.Serialization(sink)
+	}
+}
+
+func (this *SetCheckpointsParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize chainID error")
+	}
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize checkpoints length error")
+	}
+	checkpoints := make([]*Checkpoint, 0, n)
+	for i := uint64(0); i < n; i++ {
+		cp := new(Checkpoint)
+		if err := cp.Deserialization(source); err != nil {
+			return fmt.Errorf("deserialize checkpoint error: %v", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.ChainID = chainID
+	this.Checkpoints = checkpoints
+	this.Address = addr
+	return nil
+}
+
+type SetMaxReorgDepthParam struct {
+	ChainID       uint64
+	MaxReorgDepth uint32
+	Address       common.Address
+}
+
+func (this *SetMaxReorgDepthParam) Serialization(sink *common.ZeroCopySink) {
+	this.serializeContent(sink)
+	sink.WriteVarBytes(this.Address[:])
+}
+
+func (this *SetMaxReorgDepthParam) serializeContent(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+	sink.WriteUint32(this.MaxReorgDepth)
+}
+
+func (this *SetMaxReorgDepthParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize chainID error")
+	}
+	depth, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("source.NextUint32, deserialize max reorg depth error")
+	}
+	address, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("source.NextVarBytes, deserialize address error")
+	}
+	addr, err := common.AddressParseFromBytes(address)
+	if err != nil {
+		return fmt.Errorf("common.AddressParseFromBytes, deserialize address error: %s", err)
+	}
+	this.ChainID = chainID
+	this.MaxReorgDepth = depth
+	this.Address = addr
+	return nil
+}
+
+type GetFinalizedHeightParam struct {
+	ChainID uint64
+}
+
+func (this *GetFinalizedHeightParam) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(this.ChainID)
+}
+
+func (this *GetFinalizedHeightParam) Deserialization(source *common.ZeroCopySource) error {
+	chainID, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("source.NextVarUint, deserialize chainID error")
+	}
+	this.ChainID = chainID
+	return nil
+}
@@ -20,10 +20,12 @@ package btc
 import (
 	"bytes"
 	"fmt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/log"
 	"math/big"
+	"sort"
 )
 
 type StoredHeader struct {
@@ -76,3 +78,72 @@ func (this *StoredHeader) Deserialization(source *common.ZeroCopySource) error {
 	this.totalWork = totalWork
 	return nil
 }
+
+// Checkpoint pins the block hash of a btc chain at a height. Headers conflicting
+// with a checkpoint and forks branching off below it are rejected.
+type Checkpoint struct {
+	Height uint32
+	Hash   chainhash.Hash
+}
+
+func (this *Checkpoint) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(this.Height)
+	sink.WriteBytes(this.Hash[:])
+}
+
+func (this *Checkpoint) Deserialization(source *common.ZeroCopySource) error {
+	height, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("Checkpoint deserialize height error")
+	}
+	hash, eof := source.NextBytes(chainhash.HashSize)
+	if eof {
+		return fmt.Errorf("Checkpoint deserialize hash error")
+	}
+	this.Height = height
+	copy(this.Hash[:], hash)
+	return nil
+}
+
+// Checkpoints are kept sorted by height in ascending order
+type Checkpoints struct {
+	Checkpoints []*Checkpoint
+}
+
+func (this *Checkpoints) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteVarUint(uint64(len(this.Checkpoints)))
+	for _, v := range this.Checkpoints {
+		v.Serialization(sink)
+	}
+}
+
+func (this *Checkpoints) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextVarUint()
+	if eof {
+		return fmt.Errorf("Checkpoints deserialize length error")
+	}
+	checkpoints := make([]*Checkpoint, 0, n)
+	for i := uint64(0); i < n; i++ {
+		cp := new(Checkpoint)
+		if err := cp.Deserialization(source); err != nil {
+			return fmt.Errorf("Checkpoints deserialize checkpoint error: %v", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	this.Checkpoints = checkpoints
+	return nil
+}
+
+// Put adds cp, replacing any checkpoint at the same height
+func (this *Checkpoints) Put(cp *Checkpoint) {
+	i := sort.Search(len(this.Checkpoints), func(i int) bool {
+		return this.Checkpoints[i].Height >= cp.Height
+	})
+	if i < len(this.Checkpoints) && this.Checkpoints[i].Height == cp.Height {
+		this.Checkpoints[i] = cp
+		return
+	}
+	this.Checkpoints = append(this.Checkpoints, nil)
+	copy(this.Checkpoints[i+1:], this.Checkpoints[i:])
+	this.Checkpoints[i] = cp
+}
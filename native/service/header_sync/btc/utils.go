@@ -337,6 +337,103 @@ func ReIndexHeaderHeight(native *native.NativeService, chainID uint64, bestHeade
 	return nil
 }
 
+func putCheckpoints(native *native.NativeService, chainID uint64, checkpoints *Checkpoints) {
+	sink := common.NewZeroCopySink(nil)
+	checkpoints.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(CHECKPOINTS), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+func GetCheckpoints(native *native.NativeService, chainID uint64) (*Checkpoints, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(CHECKPOINTS), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return nil, fmt.Errorf("GetCheckpoints, get checkpoints store error: %v", err)
+	}
+	checkpoints := &Checkpoints{
+		Checkpoints: make([]*Checkpoint, 0),
+	}
+	if store == nil {
+		return checkpoints, nil
+	}
+	checkpointsBs, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetCheckpoints, deserialize from raw storage item err: %v", err)
+	}
+	if err := checkpoints.Deserialization(common.NewZeroCopySource(checkpointsBs)); err != nil {
+		return nil, fmt.Errorf("GetCheckpoints, deserialize checkpoints error: %v", err)
+	}
+	return checkpoints, nil
+}
+
+func putMaxReorgDepth(native *native.NativeService, chainID uint64, depth uint32) {
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(MAX_REORG_DEPTH), utils.GetUint64Bytes(chainID)),
+		cstates.GenRawStorageItem(utils.GetUint32Bytes(depth)))
+}
+
+// GetMaxReorgDepth returns the max number of blocks a reorg may wipe out, 0 means no limit
+func GetMaxReorgDepth(native *native.NativeService, chainID uint64) (uint32, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(MAX_REORG_DEPTH), utils.GetUint64Bytes(chainID)))
+	if err != nil {
+		return 0, fmt.Errorf("GetMaxReorgDepth, get max reorg depth store error: %v", err)
+	}
+	if store == nil {
+		return 0, nil
+	}
+	depthBs, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return 0, fmt.Errorf("GetMaxReorgDepth, deserialize from raw storage item err: %v", err)
+	}
+	return utils.GetBytesUint32(depthBs), nil
+}
+
+// checkCheckpoints rejects a header not yet stored at height if it conflicts with a checkpoint,
+// or if it forks off the best chain below a checkpoint the best chain already passed
+func checkCheckpoints(native *native.NativeService, chainID uint64, hash chainhash.Hash, height, bestHeight uint32) error {
+	checkpoints, err := GetCheckpoints(native, chainID)
+	if err != nil {
+		return err
+	}
+	for _, cp := range checkpoints.Checkpoints {
+		if cp.Height == height && !cp.Hash.IsEqual(&hash) {
+			return fmt.Errorf("header %s at height %d conflicts with checkpoint %s", hash.String(), height,
+				cp.Hash.String())
+		}
+		if cp.Height >= height && cp.Height <= bestHeight {
+			return fmt.Errorf("header %s at height %d forks below checkpoint at height %d", hash.String(), height,
+				cp.Height)
+		}
+	}
+	return nil
+}
+
+// GetFinalizedHeight returns the highest height whose header on the best chain is final. A header is final
+// once it is buried deeper than both the max reorg depth and the BlocksToWait of the side chain.
+func GetFinalizedHeight(native *native.NativeService, chainID uint64) (uint32, error) {
+	bestHeader, err := GetBestBlockHeader(native, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("GetFinalizedHeight, %v", err)
+	}
+	sideChain, err := side_chain_manager.GetSideChain(native, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("GetFinalizedHeight, side_chain_manager.GetSideChain error: %v", err)
+	}
+	if sideChain == nil {
+		return 0, fmt.Errorf("GetFinalizedHeight, side chain %d is not registered", chainID)
+	}
+	depth, err := GetMaxReorgDepth(native, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("GetFinalizedHeight, %v", err)
+	}
+	if sideChain.BlocksToWait > 0 && sideChain.BlocksToWait-1 > uint64(depth) {
+		depth = uint32(sideChain.BlocksToWait - 1)
+	}
+	if bestHeader.Height < depth {
+		return 0, fmt.Errorf("GetFinalizedHeight, no header is final yet, best height: %d, depth: %d",
+			bestHeader.Height, depth)
+	}
+	return bestHeader.Height - depth, nil
+}
+
 // Verifies the header hashes into something lower than specified by the 4-byte bits field.
 func checkProofOfWork(header wire.BlockHeader, p *chaincfg.Params) bool {
 	target := blockchain.CompactToBig(header.Bits)
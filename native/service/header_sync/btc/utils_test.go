@@ -91,7 +91,7 @@ func TestCalcRequiredWork(t *testing.T) {
 	// Test during difficulty adjust period
 	newHdr := wire.BlockHeader{}
 	newHdr.PrevBlock = bestHeader.Header.BlockHash()
-	work, err := calcRequiredWork(nativeService, 0, newHdr, 2016, bestHeader)
+	work, err := calcRequiredWork(nativeService, 0, newHdr, 2016, bestHeader, netParam)
 	if err != nil {
 		t.Error(err)
 	}
@@ -114,7 +114,7 @@ func TestCalcRequiredWork(t *testing.T) {
 	netParam.ReduceMinDifficulty = false
 	newHdr1 := wire.BlockHeader{}
 	newHdr1.PrevBlock = newHdr.BlockHash()
-	work1, err := calcRequiredWork(nativeService, 0, newHdr1, 2017, &sh)
+	work1, err := calcRequiredWork(nativeService, 0, newHdr1, 2017, &sh, netParam)
 	if err != nil {
 		t.Error(err)
 	}
@@ -137,7 +137,7 @@ func TestCalcRequiredWork(t *testing.T) {
 	netParam.ReduceMinDifficulty = true
 	newHdr2 := wire.BlockHeader{}
 	newHdr2.PrevBlock = newHdr1.BlockHash()
-	work2, err := calcRequiredWork(nativeService, 0, newHdr2, 2018, &sh)
+	work2, err := calcRequiredWork(nativeService, 0, newHdr2, 2018, &sh, netParam)
 	if err != nil {
 		t.Error(err)
 	}
@@ -160,7 +160,7 @@ func TestCalcRequiredWork(t *testing.T) {
 	newHdr3 := wire.BlockHeader{}
 	newHdr3.PrevBlock = newHdr2.BlockHash()
 	newHdr3.Timestamp = newHdr2.Timestamp.Add(time.Minute * 21)
-	work3, err := calcRequiredWork(nativeService, 0, newHdr3, 2019, &sh)
+	work3, err := calcRequiredWork(nativeService, 0, newHdr3, 2019, &sh, netParam)
 	if err != nil {
 		t.Error(err)
 	}
@@ -183,7 +183,7 @@ func TestCalcRequiredWork(t *testing.T) {
 	netParam.ReduceMinDifficulty = true
 	newHdr4 := wire.BlockHeader{}
 	newHdr4.PrevBlock = newHdr3.BlockHash()
-	work4, err := calcRequiredWork(nativeService, 0, newHdr4, 2020, &sh)
+	work4, err := calcRequiredWork(nativeService, 0, newHdr4, 2020, &sh, netParam)
 	if err != nil {
 		t.Error(err)
 	}
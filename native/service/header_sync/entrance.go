@@ -30,6 +30,7 @@ import (
 
 	"github.com/polynetwork/poly/native/service/header_sync/heco"
 	"github.com/polynetwork/poly/native/service/header_sync/msc"
+	"github.com/polynetwork/poly/native/service/header_sync/near"
 	"github.com/polynetwork/poly/native/service/header_sync/okex"
 	"github.com/polynetwork/poly/native/service/header_sync/polygon"
 
@@ -57,6 +58,10 @@ func RegisterHeaderSyncContract(native *native.NativeService) {
 	native.Register(SYNC_GENESIS_HEADER, SyncGenesisHeader)
 	native.Register(SYNC_BLOCK_HEADER, SyncBlockHeader)
 	native.Register(SYNC_CROSS_CHAIN_MSG, SyncCrossChainMsg)
+
+	native.Register(btc.SET_CHECKPOINTS, btc.SetCheckpoints)
+	native.Register(btc.SET_MAX_REORG_DEPTH, btc.SetMaxReorgDepth)
+	native.Register(btc.GET_FINALIZED_HEIGHT, btc.GetBtcFinalizedHeight)
 }
 
 func GetChainHandler(router uint64) (hscommon.HeaderSyncHandler, error) {
@@ -95,6 +100,8 @@ func GetChainHandler(router uint64) (hscommon.HeaderSyncHandler, error) {
 		return polygon.NewBorHandler(), nil
 	case utils.PIXIECHAIN_ROUTER:
 		return pixiechain.NewPixieHandler(), nil
+	case utils.NEAR_ROUTER:
+		return near.NewNearHandler(), nil
 	default:
 		return nil, fmt.Errorf("not a supported router:%d", router)
 	}
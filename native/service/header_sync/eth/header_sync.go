@@ -115,7 +115,8 @@ func (this *ETHHandler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("SyncBlockHeader, check header exist err: %v", err)
 		}
 		if exist == true {
-			log.Warnf("SyncBlockHeader, header has exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/eth").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("SyncBlockHeader, header has exist. Header: %s", string(v))
 			continue
 		}
 		// get pre header
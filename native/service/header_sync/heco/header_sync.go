@@ -245,7 +245,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("heco Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
-			log.Warnf("heco Handler SyncBlockHeader, header has exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/heco").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("heco Handler SyncBlockHeader, header has exist. Header: %s", string(v))
 			continue
 		}
 
@@ -254,7 +255,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("heco Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
-			log.Warnf("heco Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/heco").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("heco Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
 			continue
 		}
 
@@ -231,7 +231,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("msc Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
-			log.Warnf("msc Handler SyncBlockHeader, header has exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/msc").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("msc Handler SyncBlockHeader, header has exist. Header: %s", string(v))
 			continue
 		}
 
@@ -240,7 +241,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("msc Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
-			log.Warnf("msc Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/msc").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("msc Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
 			continue
 		}
 
@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+type NearHandler struct{}
+
+func NewNearHandler() *NearHandler {
+	return &NearHandler{}
+}
+
+func (this *NearHandler) SyncGenesisHeader(native *native.NativeService) error {
+	param := new(hscommon.SyncGenesisHeaderParam)
+	if err := param.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, contract params deserialize error: %v", err)
+	}
+	// Get current epoch operator
+	operatorAddress, err := node_manager.GetCurConOperator(native)
+	if err != nil {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, get current consensus operator address error: %v", err)
+	}
+	//check witness
+	err = utils.ValidateOwner(native, operatorAddress)
+	if err != nil {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, checkWitness error: %v", err)
+	}
+	// check if has genesis header
+	head, err := GetHead(native, param.ChainID)
+	if err != nil {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, %v", err)
+	}
+	if head != nil {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, genesis header had been initialized")
+	}
+	genesis := new(NearGenesis)
+	if err = genesis.Deserialization(common.NewZeroCopySource(param.GenesisHeader)); err != nil {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, %v", err)
+	}
+	if len(genesis.CurrentBps.Producers) == 0 {
+		return fmt.Errorf("NearHandler SyncGenesisHeader, no block producers of current epoch")
+	}
+
+	info := blockInfoOf(genesis.Block)
+	putBlockProducers(native, param.ChainID, info.EpochID, genesis.CurrentBps)
+	notifyBlockProducers(native, param.ChainID, info, info.EpochID)
+	if genesis.Block.NextBps != nil {
+		if h := genesis.Block.NextBps.Hash(); h != genesis.Block.InnerLite.NextBpHash {
+			return fmt.Errorf("NearHandler SyncGenesisHeader, hash of next_bps not equal to next_bp_hash")
+		}
+		putBlockProducers(native, param.ChainID, info.NextEpochID, genesis.Block.NextBps)
+		notifyBlockProducers(native, param.ChainID, info, info.NextEpochID)
+	}
+	putBlockInfo(native, param.ChainID, info)
+	putHead(native, param.ChainID, info)
+	return nil
+}
+
+func (this *NearHandler) SyncBlockHeader(native *native.NativeService) error {
+	params := new(hscommon.SyncBlockHeaderParam)
+	if err := params.Deserialization(common.NewZeroCopySource(native.GetInput())); err != nil {
+		return fmt.Errorf("NearHandler SyncBlockHeader, contract params deserialize error: %v", err)
+	}
+	head, err := GetHead(native, params.ChainID)
+	if err != nil {
+		return fmt.Errorf("NearHandler SyncBlockHeader, %v", err)
+	}
+	if head == nil {
+		return fmt.Errorf("NearHandler SyncBlockHeader, genesis header is not synced")
+	}
+	cnt := 0
+	for _, v := range params.Headers {
+		block := new(LightClientBlock)
+		if err := block.Deserialization(common.NewZeroCopySource(v)); err != nil {
+			return fmt.Errorf("NearHandler SyncBlockHeader, failed to deserialize block: %v", err)
+		}
+		if block.InnerLite.Height <= head.Height {
+			log.Module("native/service/header_sync/near").With(log.Fields{"chainID": params.ChainID, "height": block.InnerLite.Height}).
+				Debugf("NearHandler SyncBlockHeader, height is lower or equal than head height %d", head.Height)
+			continue
+		}
+		bps, err := GetBlockProducers(native, params.ChainID, block.InnerLite.EpochID)
+		if err != nil {
+			return fmt.Errorf("NearHandler SyncBlockHeader, %v", err)
+		}
+		if bps == nil {
+			return fmt.Errorf("NearHandler SyncBlockHeader, block producers of block %d are unknown",
+				block.InnerLite.Height)
+		}
+		if err = VerifyLightClientBlock(head, block, bps); err != nil {
+			return fmt.Errorf("NearHandler SyncBlockHeader, failed to verify block %d: %v", block.InnerLite.Height, err)
+		}
+		head = blockInfoOf(block)
+		if block.NextBps != nil {
+			putBlockProducers(native, params.ChainID, head.NextEpochID, block.NextBps)
+			notifyBlockProducers(native, params.ChainID, head, head.NextEpochID)
+		}
+		putBlockInfo(native, params.ChainID, head)
+		cnt++
+	}
+	if cnt == 0 {
+		return fmt.Errorf("no header you commited is useful")
+	}
+	putHead(native, params.ChainID, head)
+	return nil
+}
+
+func (this *NearHandler) SyncCrossChainMsg(native *native.NativeService) error {
+	return nil
+}
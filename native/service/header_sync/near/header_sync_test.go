@@ -0,0 +1,268 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/overlaydb"
+	"github.com/polynetwork/poly/core/types"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/service/governance/node_manager"
+	scom "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"github.com/polynetwork/poly/native/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const nearChainID = uint64(19)
+
+var acct *account.Account = account.NewAccount("")
+
+type testProducer struct {
+	stake *ValidatorStake
+	sk    ed25519.PrivateKey
+}
+
+func newProducers(n int, seed byte) ([]*testProducer, *BlockProducers) {
+	tps := make([]*testProducer, n)
+	bps := &BlockProducers{}
+	for i := range tps {
+		sd := make([]byte, ed25519.SeedSize)
+		sd[0], sd[1] = seed, byte(i)
+		sk := ed25519.NewKeyFromSeed(sd)
+		tps[i] = &testProducer{
+			stake: &ValidatorStake{
+				AccountID: fmt.Sprintf("bp%d-%d.near", seed, i),
+				PublicKey: sk.Public().(ed25519.PublicKey),
+				Stake:     big.NewInt(100),
+			},
+			sk: sk,
+		}
+		bps.Producers = append(bps.Producers, tps[i].stake)
+	}
+	return tps, bps
+}
+
+func newBlock(prev *LightClientBlock, epoch, nextEpoch CryptoHash, nextBps *BlockProducers) *LightClientBlock {
+	block := &LightClientBlock{
+		BlockHeaderLite: BlockHeaderLite{
+			InnerLite: BlockHeaderInnerLite{
+				Height:      1,
+				EpochID:     epoch,
+				NextEpochID: nextEpoch,
+			},
+		},
+		NextBlockInnerHash: CryptoHash{0xff},
+		NextBps:            nextBps,
+	}
+	if prev != nil {
+		block.PrevBlockHash = prev.Hash()
+		block.InnerLite.Height = prev.InnerLite.Height + 1
+		block.InnerLite.BlockMerkleRoot = HashOf(prev.InnerLite.BlockMerkleRoot[:], block.PrevBlockHash[:])
+	}
+	if nextBps != nil {
+		block.InnerLite.NextBpHash = nextBps.Hash()
+	}
+	return block
+}
+
+// approve signs block with the producers whose index is in signers
+func approve(block *LightClientBlock, tps []*testProducer, signers ...int) *LightClientBlock {
+	msg := block.ApprovalMessage()
+	block.ApprovalsAfterNext = make([][]byte, len(tps))
+	for _, i := range signers {
+		block.ApprovalsAfterNext[i] = ed25519.Sign(tps[i].sk, msg)
+	}
+	return block
+}
+
+// newDB makes acct the only consensus node and so the operator
+func newDB() *storage.CacheDB {
+	store, _ := leveldbstore.NewMemLevelDBStore()
+	db := storage.NewCacheDB(overlaydb.NewOverlayDB(store))
+
+	sink := common.NewZeroCopySink(nil)
+	view := &node_manager.GovernanceView{
+		TxHash: common.UINT256_EMPTY,
+	}
+	view.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.GOVERNANCE_VIEW)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	peerPoolMap := &node_manager.PeerPoolMap{
+		PeerPoolMap: map[string]*node_manager.PeerPoolItem{
+			vconfig.PubkeyID(acct.PublicKey): {
+				Address:    acct.Address,
+				Status:     node_manager.ConsensusStatus,
+				PeerPubkey: vconfig.PubkeyID(acct.PublicKey),
+			},
+		},
+	}
+	sink.Reset()
+	peerPoolMap.Serialization(sink)
+	db.Put(utils.ConcatKey(utils.NodeManagerContractAddress, []byte(node_manager.PEER_POOL), utils.GetUint32Bytes(0)),
+		cstates.GenRawStorageItem(sink.Bytes()))
+	return db
+}
+
+func newNative(args []byte, db *storage.CacheDB) *native.NativeService {
+	tx := &types.Transaction{
+		SignedAddr: []common.Address{acct.Address},
+	}
+	ns, _ := native.NewNativeService(db, tx, 0, 0, common.Uint256{0}, 0, args, false)
+	return ns
+}
+
+func syncGenesis(db *storage.CacheDB, block *LightClientBlock, bps *BlockProducers) error {
+	sink := common.NewZeroCopySink(nil)
+	(&NearGenesis{Block: block, CurrentBps: bps}).Serialization(sink)
+	param := &scom.SyncGenesisHeaderParam{
+		ChainID:       nearChainID,
+		GenesisHeader: sink.Bytes(),
+	}
+	paramSink := common.NewZeroCopySink(nil)
+	param.Serialization(paramSink)
+	return NewNearHandler().SyncGenesisHeader(newNative(paramSink.Bytes(), db))
+}
+
+func syncBlocks(db *storage.CacheDB, blocks ...*LightClientBlock) error {
+	param := &scom.SyncBlockHeaderParam{
+		ChainID: nearChainID,
+		Address: acct.Address,
+	}
+	for _, b := range blocks {
+		sink := common.NewZeroCopySink(nil)
+		b.Serialization(sink)
+		param.Headers = append(param.Headers, sink.Bytes())
+	}
+	sink := common.NewZeroCopySink(nil)
+	param.Serialization(sink)
+	return NewNearHandler().SyncBlockHeader(newNative(sink.Bytes(), db))
+}
+
+func TestLightClientBlockSerialization(t *testing.T) {
+	tps, bps := newProducers(3, 1)
+	block := approve(newBlock(nil, CryptoHash{1}, CryptoHash{2}, bps), tps, 0, 2)
+	sink := common.NewZeroCopySink(nil)
+	block.Serialization(sink)
+
+	decoded := new(LightClientBlock)
+	assert.NoError(t, decoded.Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Equal(t, block.Hash(), decoded.Hash())
+	assert.Equal(t, block.NextBps.Hash(), decoded.NextBps.Hash())
+	assert.Nil(t, decoded.ApprovalsAfterNext[1])
+	assert.Equal(t, block.ApprovalsAfterNext[2], decoded.ApprovalsAfterNext[2])
+
+	// u128 is little endian
+	sink.Reset()
+	stake, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	WriteU128(sink, stake)
+	assert.Equal(t, 16, len(sink.Bytes()))
+	decodedStake, eof := NextU128(common.NewZeroCopySource(sink.Bytes()))
+	assert.False(t, eof)
+	assert.Equal(t, 0, stake.Cmp(decodedStake))
+}
+
+func TestDeserializationHugeLength(t *testing.T) {
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteUint32(0xffffffff)
+	assert.Error(t, new(BlockProducers).Deserialization(common.NewZeroCopySource(sink.Bytes())))
+	assert.Error(t, new(MerklePath).Deserialization(common.NewZeroCopySource(sink.Bytes())))
+
+	tps, bps := newProducers(3, 1)
+	block := approve(newBlock(nil, CryptoHash{1}, CryptoHash{2}, bps), tps, 0, 2)
+	block.ApprovalsAfterNext = nil
+	sink.Reset()
+	block.Serialization(sink)
+	raw := sink.Bytes()
+	//approvals length is the last 4 bytes
+	copy(raw[len(raw)-4:], []byte{0xff, 0xff, 0xff, 0xff})
+	err := new(LightClientBlock).Deserialization(common.NewZeroCopySource(raw))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds remaining bytes")
+}
+
+func TestSyncGenesisHeader(t *testing.T) {
+	db := newDB()
+	tps, bps := newProducers(3, 1)
+	_, nextBps := newProducers(3, 2)
+	genesisBlock := approve(newBlock(nil, CryptoHash{1}, CryptoHash{2}, nextBps), tps)
+
+	assert.NoError(t, syncGenesis(db, genesisBlock, bps))
+	assert.Error(t, syncGenesis(db, genesisBlock, bps))
+
+	ns := newNative(nil, db)
+	head, err := GetHead(ns, nearChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, genesisBlock.Hash(), head.Hash)
+	stored, err := GetBlockProducers(ns, nearChainID, CryptoHash{2})
+	assert.NoError(t, err)
+	assert.Equal(t, nextBps.Hash(), stored.Hash())
+}
+
+func TestSyncBlockHeader(t *testing.T) {
+	db := newDB()
+	epoch1, epoch2, epoch3 := CryptoHash{1}, CryptoHash{2}, CryptoHash{3}
+	tps, bps := newProducers(3, 1)
+	nextTps, nextBps := newProducers(4, 2)
+	genesisBlock := newBlock(nil, epoch1, epoch2, nil)
+	assert.NoError(t, syncGenesis(db, genesisBlock, bps))
+
+	// 2 of 3 is not more than 2/3 of the stake
+	b1 := approve(newBlock(genesisBlock, epoch1, epoch2, nil), tps, 0, 1)
+	assert.Error(t, syncBlocks(db, b1))
+	// approval signed by another key
+	b1 = approve(newBlock(genesisBlock, epoch1, epoch2, nil), tps, 0, 1, 2)
+	b1.ApprovalsAfterNext[2] = ed25519.Sign(nextTps[0].sk, b1.ApprovalMessage())
+	assert.Error(t, syncBlocks(db, b1))
+	// next_bps not matching next_bp_hash
+	b1 = newBlock(genesisBlock, epoch1, epoch2, nextBps)
+	b1.InnerLite.NextBpHash = CryptoHash{}
+	assert.Error(t, syncBlocks(db, approve(b1, tps, 0, 1, 2)))
+
+	b1 = approve(newBlock(genesisBlock, epoch1, epoch2, nextBps), tps, 0, 1, 2)
+	// the first block of next epoch must carry next_bps
+	b2 := approve(newBlock(b1, epoch2, epoch3, nil), nextTps, 0, 1, 2, 3)
+	assert.Error(t, syncBlocks(db, b1, b2))
+
+	_, bps3 := newProducers(2, 3)
+	b2 = approve(newBlock(b1, epoch2, epoch3, bps3), nextTps, 1, 2, 3)
+	assert.NoError(t, syncBlocks(db, b1, b2))
+	// blocks not higher than head are useless
+	assert.Error(t, syncBlocks(db, b1))
+
+	ns := newNative(nil, db)
+	head, err := GetHead(ns, nearChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, b2.InnerLite.Height, head.Height)
+	assert.Equal(t, b2.Hash(), head.Hash)
+	info, err := GetBlockInfo(ns, nearChainID, b1.InnerLite.Height)
+	assert.NoError(t, err)
+	assert.Equal(t, b1.InnerLite.BlockMerkleRoot, info.BlockMerkleRoot)
+	stored, err := GetBlockProducers(ns, nearChainID, epoch3)
+	assert.NoError(t, err)
+	assert.Equal(t, bps3.Hash(), stored.Hash())
+}
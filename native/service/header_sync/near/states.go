@@ -0,0 +1,467 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+)
+
+// The types below are encoded in the borsh layout used by nearcore, so the relayer
+// can submit what the NEAR light client RPC returns without any conversion.
+
+const (
+	KEY_TYPE_ED25519 = byte(0)
+
+	VALIDATOR_STAKE_V1 = byte(0)
+
+	MERKLE_DIRECTION_LEFT  = byte(0)
+	MERKLE_DIRECTION_RIGHT = byte(1)
+
+	U128_SIZE = 16
+
+	//min encoded sizes of list items, lengths read from relayer input are checked against
+	//remaining bytes with them before allocating
+	MIN_VALIDATOR_STAKE_SIZE = 1 + 4 + 1 + ed25519.PublicKeySize + U128_SIZE
+	MIN_APPROVAL_SIZE        = 1
+	MERKLE_PATH_ITEM_SIZE    = sha256.Size + 1
+)
+
+type CryptoHash [sha256.Size]byte
+
+func (this *CryptoHash) Deserialization(source *common.ZeroCopySource) error {
+	raw, eof := source.NextBytes(sha256.Size)
+	if eof {
+		return fmt.Errorf("CryptoHash deserialize error")
+	}
+	copy(this[:], raw)
+	return nil
+}
+
+func HashOf(data ...[]byte) CryptoHash {
+	hasher := sha256.New()
+	for _, v := range data {
+		hasher.Write(v)
+	}
+	var h CryptoHash
+	copy(h[:], hasher.Sum(nil))
+	return h
+}
+
+func WriteU128(sink *common.ZeroCopySink, v *big.Int) {
+	raw := make([]byte, U128_SIZE)
+	be := v.Bytes()
+	for i := 0; i < len(be) && i < U128_SIZE; i++ {
+		raw[i] = be[len(be)-1-i]
+	}
+	sink.WriteBytes(raw)
+}
+
+func NextU128(source *common.ZeroCopySource) (*big.Int, bool) {
+	raw, eof := source.NextBytes(U128_SIZE)
+	if eof {
+		return nil, eof
+	}
+	be := make([]byte, U128_SIZE)
+	for i := range raw {
+		be[U128_SIZE-1-i] = raw[i]
+	}
+	return new(big.Int).SetBytes(be), false
+}
+
+func WriteBorshString(sink *common.ZeroCopySink, s string) {
+	sink.WriteUint32(uint32(len(s)))
+	sink.WriteBytes([]byte(s))
+}
+
+func NextBorshString(source *common.ZeroCopySource) (string, bool) {
+	l, eof := source.NextUint32()
+	if eof {
+		return "", eof
+	}
+	raw, eof := source.NextBytes(uint64(l))
+	return string(raw), eof
+}
+
+type BlockHeaderInnerLite struct {
+	Height          uint64
+	EpochID         CryptoHash
+	NextEpochID     CryptoHash
+	PrevStateRoot   CryptoHash
+	OutcomeRoot     CryptoHash
+	Timestamp       uint64
+	NextBpHash      CryptoHash
+	BlockMerkleRoot CryptoHash
+}
+
+func (this *BlockHeaderInnerLite) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Height)
+	sink.WriteBytes(this.EpochID[:])
+	sink.WriteBytes(this.NextEpochID[:])
+	sink.WriteBytes(this.PrevStateRoot[:])
+	sink.WriteBytes(this.OutcomeRoot[:])
+	sink.WriteUint64(this.Timestamp)
+	sink.WriteBytes(this.NextBpHash[:])
+	sink.WriteBytes(this.BlockMerkleRoot[:])
+}
+
+func (this *BlockHeaderInnerLite) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("BlockHeaderInnerLite deserialize height error")
+	}
+	for _, h := range []*CryptoHash{&this.EpochID, &this.NextEpochID, &this.PrevStateRoot, &this.OutcomeRoot} {
+		if err := h.Deserialization(source); err != nil {
+			return fmt.Errorf("BlockHeaderInnerLite deserialize hash error: %v", err)
+		}
+	}
+	this.Timestamp, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("BlockHeaderInnerLite deserialize timestamp error")
+	}
+	if err := this.NextBpHash.Deserialization(source); err != nil {
+		return fmt.Errorf("BlockHeaderInnerLite deserialize next_bp_hash error: %v", err)
+	}
+	if err := this.BlockMerkleRoot.Deserialization(source); err != nil {
+		return fmt.Errorf("BlockHeaderInnerLite deserialize block_merkle_root error: %v", err)
+	}
+	return nil
+}
+
+// BlockHeaderLite is enough to compute the hash of a NEAR block
+type BlockHeaderLite struct {
+	PrevBlockHash CryptoHash
+	InnerRestHash CryptoHash
+	InnerLite     BlockHeaderInnerLite
+}
+
+func (this *BlockHeaderLite) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteBytes(this.PrevBlockHash[:])
+	sink.WriteBytes(this.InnerRestHash[:])
+	this.InnerLite.Serialization(sink)
+}
+
+func (this *BlockHeaderLite) Deserialization(source *common.ZeroCopySource) error {
+	if err := this.PrevBlockHash.Deserialization(source); err != nil {
+		return fmt.Errorf("BlockHeaderLite deserialize prev_block_hash error: %v", err)
+	}
+	if err := this.InnerRestHash.Deserialization(source); err != nil {
+		return fmt.Errorf("BlockHeaderLite deserialize inner_rest_hash error: %v", err)
+	}
+	if err := this.InnerLite.Deserialization(source); err != nil {
+		return fmt.Errorf("BlockHeaderLite deserialize inner_lite error: %v", err)
+	}
+	return nil
+}
+
+func (this *BlockHeaderLite) Hash() CryptoHash {
+	sink := common.NewZeroCopySink(nil)
+	this.InnerLite.Serialization(sink)
+	innerLiteHash := HashOf(sink.Bytes())
+	innerHash := HashOf(innerLiteHash[:], this.InnerRestHash[:])
+	return HashOf(innerHash[:], this.PrevBlockHash[:])
+}
+
+type ValidatorStake struct {
+	AccountID string
+	PublicKey ed25519.PublicKey
+	Stake     *big.Int
+}
+
+func (this *ValidatorStake) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteByte(VALIDATOR_STAKE_V1)
+	WriteBorshString(sink, this.AccountID)
+	sink.WriteByte(KEY_TYPE_ED25519)
+	sink.WriteBytes(this.PublicKey)
+	WriteU128(sink, this.Stake)
+}
+
+func (this *ValidatorStake) Deserialization(source *common.ZeroCopySource) error {
+	version, eof := source.NextByte()
+	if eof || version != VALIDATOR_STAKE_V1 {
+		return fmt.Errorf("ValidatorStake deserialize version error")
+	}
+	this.AccountID, eof = NextBorshString(source)
+	if eof {
+		return fmt.Errorf("ValidatorStake deserialize account_id error")
+	}
+	keyType, eof := source.NextByte()
+	if eof {
+		return fmt.Errorf("ValidatorStake deserialize key type error")
+	}
+	if keyType != KEY_TYPE_ED25519 {
+		return fmt.Errorf("ValidatorStake, key type %d of %s is not supported", keyType, this.AccountID)
+	}
+	pk, eof := source.NextBytes(ed25519.PublicKeySize)
+	if eof {
+		return fmt.Errorf("ValidatorStake deserialize public_key error")
+	}
+	this.PublicKey = ed25519.PublicKey(pk)
+	this.Stake, eof = NextU128(source)
+	if eof {
+		return fmt.Errorf("ValidatorStake deserialize stake error")
+	}
+	return nil
+}
+
+type BlockProducers struct {
+	Producers []*ValidatorStake
+}
+
+func (this *BlockProducers) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(uint32(len(this.Producers)))
+	for _, v := range this.Producers {
+		v.Serialization(sink)
+	}
+}
+
+func (this *BlockProducers) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("BlockProducers deserialize length error")
+	}
+	if uint64(n)*MIN_VALIDATOR_STAKE_SIZE > source.Len() {
+		return fmt.Errorf("BlockProducers deserialize length %d exceeds remaining bytes", n)
+	}
+	producers := make([]*ValidatorStake, 0, n)
+	for i := uint32(0); i < n; i++ {
+		bp := new(ValidatorStake)
+		if err := bp.Deserialization(source); err != nil {
+			return fmt.Errorf("BlockProducers deserialize no.%d producer error: %v", i+1, err)
+		}
+		producers = append(producers, bp)
+	}
+	this.Producers = producers
+	return nil
+}
+
+func (this *BlockProducers) Hash() CryptoHash {
+	sink := common.NewZeroCopySink(nil)
+	this.Serialization(sink)
+	return HashOf(sink.Bytes())
+}
+
+type LightClientBlock struct {
+	BlockHeaderLite
+	NextBlockInnerHash CryptoHash
+	// nil if the block is not the last final block of an epoch
+	NextBps *BlockProducers
+	// nil for block producers not approving
+	ApprovalsAfterNext [][]byte
+}
+
+func (this *LightClientBlock) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteBytes(this.PrevBlockHash[:])
+	sink.WriteBytes(this.NextBlockInnerHash[:])
+	this.InnerLite.Serialization(sink)
+	sink.WriteBytes(this.InnerRestHash[:])
+	if this.NextBps == nil {
+		sink.WriteByte(0)
+	} else {
+		sink.WriteByte(1)
+		this.NextBps.Serialization(sink)
+	}
+	sink.WriteUint32(uint32(len(this.ApprovalsAfterNext)))
+	for _, v := range this.ApprovalsAfterNext {
+		if v == nil {
+			sink.WriteByte(0)
+			continue
+		}
+		sink.WriteByte(1)
+		sink.WriteByte(KEY_TYPE_ED25519)
+		sink.WriteBytes(v)
+	}
+}
+
+func (this *LightClientBlock) Deserialization(source *common.ZeroCopySource) error {
+	if err := this.PrevBlockHash.Deserialization(source); err != nil {
+		return fmt.Errorf("LightClientBlock deserialize prev_block_hash error: %v", err)
+	}
+	if err := this.NextBlockInnerHash.Deserialization(source); err != nil {
+		return fmt.Errorf("LightClientBlock deserialize next_block_inner_hash error: %v", err)
+	}
+	if err := this.InnerLite.Deserialization(source); err != nil {
+		return fmt.Errorf("LightClientBlock deserialize inner_lite error: %v", err)
+	}
+	if err := this.InnerRestHash.Deserialization(source); err != nil {
+		return fmt.Errorf("LightClientBlock deserialize inner_rest_hash error: %v", err)
+	}
+	hasBps, eof := source.NextByte()
+	if eof {
+		return fmt.Errorf("LightClientBlock deserialize next_bps option error")
+	}
+	if hasBps == 1 {
+		this.NextBps = new(BlockProducers)
+		if err := this.NextBps.Deserialization(source); err != nil {
+			return fmt.Errorf("LightClientBlock deserialize next_bps error: %v", err)
+		}
+	}
+	n, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("LightClientBlock deserialize approvals length error")
+	}
+	if uint64(n)*MIN_APPROVAL_SIZE > source.Len() {
+		return fmt.Errorf("LightClientBlock deserialize approvals length %d exceeds remaining bytes", n)
+	}
+	approvals := make([][]byte, n)
+	for i := range approvals {
+		has, eof := source.NextByte()
+		if eof {
+			return fmt.Errorf("LightClientBlock deserialize no.%d approval option error", i+1)
+		}
+		if has == 0 {
+			continue
+		}
+		keyType, eof := source.NextByte()
+		if eof {
+			return fmt.Errorf("LightClientBlock deserialize no.%d approval key type error", i+1)
+		}
+		if keyType != KEY_TYPE_ED25519 {
+			return fmt.Errorf("LightClientBlock, key type %d of no.%d approval is not supported", keyType, i+1)
+		}
+		approvals[i], eof = source.NextBytes(ed25519.SignatureSize)
+		if eof {
+			return fmt.Errorf("LightClientBlock deserialize no.%d approval error", i+1)
+		}
+	}
+	this.ApprovalsAfterNext = approvals
+	return nil
+}
+
+// ApprovalMessage is what the block producers sign to endorse the block after this one
+func (this *LightClientBlock) ApprovalMessage() []byte {
+	curr := this.Hash()
+	next := HashOf(this.NextBlockInnerHash[:], curr[:])
+	sink := common.NewZeroCopySink(nil)
+	// ApprovalInner::Endorsement
+	sink.WriteByte(0)
+	sink.WriteBytes(next[:])
+	sink.WriteUint64(this.InnerLite.Height + 2)
+	return sink.Bytes()
+}
+
+// NearBlockInfo is what poly keeps for every synced block. Poly don't save the whole
+// header, proofs are verified against the block merkle root.
+type NearBlockInfo struct {
+	Height          uint64
+	Hash            CryptoHash
+	EpochID         CryptoHash
+	NextEpochID     CryptoHash
+	BlockMerkleRoot CryptoHash
+}
+
+func (this *NearBlockInfo) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Height)
+	sink.WriteBytes(this.Hash[:])
+	sink.WriteBytes(this.EpochID[:])
+	sink.WriteBytes(this.NextEpochID[:])
+	sink.WriteBytes(this.BlockMerkleRoot[:])
+}
+
+func (this *NearBlockInfo) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Height, eof = source.NextUint64()
+	if eof {
+		return fmt.Errorf("NearBlockInfo deserialize height error")
+	}
+	for _, h := range []*CryptoHash{&this.Hash, &this.EpochID, &this.NextEpochID, &this.BlockMerkleRoot} {
+		if err := h.Deserialization(source); err != nil {
+			return fmt.Errorf("NearBlockInfo deserialize hash error: %v", err)
+		}
+	}
+	return nil
+}
+
+type MerklePathItem struct {
+	Hash      CryptoHash
+	Direction byte
+}
+
+type MerklePath []*MerklePathItem
+
+func (this MerklePath) Serialization(sink *common.ZeroCopySink) {
+	sink.WriteUint32(uint32(len(this)))
+	for _, v := range this {
+		sink.WriteBytes(v.Hash[:])
+		sink.WriteByte(v.Direction)
+	}
+}
+
+func (this *MerklePath) Deserialization(source *common.ZeroCopySource) error {
+	n, eof := source.NextUint32()
+	if eof {
+		return fmt.Errorf("MerklePath deserialize length error")
+	}
+	if uint64(n)*MERKLE_PATH_ITEM_SIZE > source.Len() {
+		return fmt.Errorf("MerklePath deserialize length %d exceeds remaining bytes", n)
+	}
+	path := make(MerklePath, n)
+	for i := range path {
+		item := new(MerklePathItem)
+		if err := item.Hash.Deserialization(source); err != nil {
+			return fmt.Errorf("MerklePath deserialize no.%d hash error: %v", i+1, err)
+		}
+		item.Direction, eof = source.NextByte()
+		if eof || item.Direction > MERKLE_DIRECTION_RIGHT {
+			return fmt.Errorf("MerklePath deserialize no.%d direction error", i+1)
+		}
+		path[i] = item
+	}
+	*this = path
+	return nil
+}
+
+// ComputeRoot folds the path from leaf up to the merkle root
+func (this MerklePath) ComputeRoot(leaf CryptoHash) CryptoHash {
+	h := leaf
+	for _, v := range this {
+		if v.Direction == MERKLE_DIRECTION_LEFT {
+			h = HashOf(v.Hash[:], h[:])
+		} else {
+			h = HashOf(h[:], v.Hash[:])
+		}
+	}
+	return h
+}
+
+// NearGenesis is the block poly starts from along with the producers of its epoch
+type NearGenesis struct {
+	Block      *LightClientBlock
+	CurrentBps *BlockProducers
+}
+
+func (this *NearGenesis) Serialization(sink *common.ZeroCopySink) {
+	this.Block.Serialization(sink)
+	this.CurrentBps.Serialization(sink)
+}
+
+func (this *NearGenesis) Deserialization(source *common.ZeroCopySource) error {
+	this.Block, this.CurrentBps = new(LightClientBlock), new(BlockProducers)
+	if err := this.Block.Deserialization(source); err != nil {
+		return fmt.Errorf("NearGenesis deserialize block error: %v", err)
+	}
+	if err := this.CurrentBps.Deserialization(source); err != nil {
+		return fmt.Errorf("NearGenesis deserialize current block producers error: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package near
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	cstates "github.com/polynetwork/poly/core/states"
+	"github.com/polynetwork/poly/native"
+	"github.com/polynetwork/poly/native/event"
+	hscommon "github.com/polynetwork/poly/native/service/header_sync/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+// VerifyLightClientBlock checks the block against the current head as described by the
+// NEAR light client spec, bps are the block producers of the epoch of the block.
+func VerifyLightClientBlock(head *NearBlockInfo, block *LightClientBlock, bps *BlockProducers) error {
+	if block.InnerLite.Height <= head.Height {
+		return fmt.Errorf("VerifyLightClientBlock, height %d is not higher than head %d", block.InnerLite.Height,
+			head.Height)
+	}
+	if block.InnerLite.EpochID != head.EpochID && block.InnerLite.EpochID != head.NextEpochID {
+		return fmt.Errorf("VerifyLightClientBlock, epoch %s of block is neither current nor next epoch of head",
+			hex.EncodeToString(block.InnerLite.EpochID[:]))
+	}
+	if block.InnerLite.EpochID == head.NextEpochID && block.NextBps == nil {
+		return fmt.Errorf("VerifyLightClientBlock, the first block of next epoch must carry next_bps")
+	}
+	if len(block.ApprovalsAfterNext) > len(bps.Producers) {
+		return fmt.Errorf("VerifyLightClientBlock, %d approvals is more than %d block producers",
+			len(block.ApprovalsAfterNext), len(bps.Producers))
+	}
+
+	msg := block.ApprovalMessage()
+	totalStake, approvedStake := new(big.Int), new(big.Int)
+	for i, bp := range bps.Producers {
+		totalStake.Add(totalStake, bp.Stake)
+		if i >= len(block.ApprovalsAfterNext) || block.ApprovalsAfterNext[i] == nil {
+			continue
+		}
+		if !ed25519.Verify(bp.PublicKey, msg, block.ApprovalsAfterNext[i]) {
+			return fmt.Errorf("VerifyLightClientBlock, invalid approval from %s", bp.AccountID)
+		}
+		approvedStake.Add(approvedStake, bp.Stake)
+	}
+	threshold := new(big.Int).Div(new(big.Int).Mul(totalStake, big.NewInt(2)), big.NewInt(3))
+	if approvedStake.Cmp(threshold) <= 0 {
+		return fmt.Errorf("VerifyLightClientBlock, approved stake %s is not more than 2/3 of total stake %s",
+			approvedStake.String(), totalStake.String())
+	}
+
+	if block.NextBps != nil {
+		if h := block.NextBps.Hash(); h != block.InnerLite.NextBpHash {
+			return fmt.Errorf("VerifyLightClientBlock, hash %s of next_bps not equal to next_bp_hash %s",
+				hex.EncodeToString(h[:]), hex.EncodeToString(block.InnerLite.NextBpHash[:]))
+		}
+	}
+	return nil
+}
+
+func blockInfoOf(block *LightClientBlock) *NearBlockInfo {
+	return &NearBlockInfo{
+		Height:          block.InnerLite.Height,
+		Hash:            block.Hash(),
+		EpochID:         block.InnerLite.EpochID,
+		NextEpochID:     block.InnerLite.NextEpochID,
+		BlockMerkleRoot: block.InnerLite.BlockMerkleRoot,
+	}
+}
+
+func notifyBlockProducers(native *native.NativeService, chainID uint64, info *NearBlockInfo, epochID CryptoHash) {
+	if !config.DefConfig.Common.EnableEventLog {
+		return
+	}
+	native.AddNotify(
+		&event.NotifyEventInfo{
+			ContractAddress: utils.HeaderSyncContractAddress,
+			States: []interface{}{chainID, hex.EncodeToString(info.Hash[:]), info.Height,
+				hex.EncodeToString(epochID[:]), native.GetHeight()},
+		})
+}
+
+func putHead(native *native.NativeService, chainID uint64, info *NearBlockInfo) {
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.CURRENT_HEADER_HEIGHT),
+		utils.GetUint64Bytes(chainID)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetHead returns the latest synced block, nil if genesis is not synced yet
+func GetHead(native *native.NativeService, chainID uint64) (*NearBlockInfo, error) {
+	return getBlockInfo(native, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.CURRENT_HEADER_HEIGHT),
+		utils.GetUint64Bytes(chainID)))
+}
+
+func putBlockInfo(native *native.NativeService, chainID uint64, info *NearBlockInfo) {
+	sink := common.NewZeroCopySink(nil)
+	info.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.BLOCK_HEADER),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(info.Height)), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetBlockInfo returns the synced block at height, nil if no block at height is synced
+func GetBlockInfo(native *native.NativeService, chainID, height uint64) (*NearBlockInfo, error) {
+	return getBlockInfo(native, utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.BLOCK_HEADER),
+		utils.GetUint64Bytes(chainID), utils.GetUint64Bytes(height)))
+}
+
+func getBlockInfo(native *native.NativeService, key []byte) (*NearBlockInfo, error) {
+	store, err := native.GetCacheDB().Get(key)
+	if err != nil {
+		return nil, fmt.Errorf("getBlockInfo, get block info error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("getBlockInfo, deserialize from raw storage item err: %v", err)
+	}
+	info := new(NearBlockInfo)
+	if err = info.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("getBlockInfo, deserialize NearBlockInfo err: %v", err)
+	}
+	return info, nil
+}
+
+func putBlockProducers(native *native.NativeService, chainID uint64, epochID CryptoHash, bps *BlockProducers) {
+	sink := common.NewZeroCopySink(nil)
+	bps.Serialization(sink)
+	native.GetCacheDB().Put(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH),
+		utils.GetUint64Bytes(chainID), epochID[:]), cstates.GenRawStorageItem(sink.Bytes()))
+}
+
+// GetBlockProducers returns the block producers of epoch, nil if unknown
+func GetBlockProducers(native *native.NativeService, chainID uint64, epochID CryptoHash) (*BlockProducers, error) {
+	store, err := native.GetCacheDB().Get(utils.ConcatKey(utils.HeaderSyncContractAddress, []byte(hscommon.EPOCH_SWITCH),
+		utils.GetUint64Bytes(chainID), epochID[:]))
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockProducers, get block producers error: %v", err)
+	}
+	if store == nil {
+		return nil, nil
+	}
+	raw, err := cstates.GetValueFromRawStorageItem(store)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockProducers, deserialize from raw storage item err: %v", err)
+	}
+	bps := new(BlockProducers)
+	if err = bps.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("GetBlockProducers, deserialize BlockProducers err: %v", err)
+	}
+	return bps, nil
+}
@@ -119,8 +119,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			continue
 		}
 		if info.Height >= myHeader.Header.Height {
-			log.Debugf("SyncBlockHeader, height %d is lower or equal than epoch switching height %d",
-				myHeader.Header.Height, info.Height)
+			log.Module("native/service/header_sync/okex").With(log.Fields{"chainID": params.ChainID, "height": myHeader.Header.Height}).
+				Debugf("SyncBlockHeader, height is lower or equal than epoch switching height %d", info.Height)
 			continue
 		}
 		if err = VerifyCosmosHeader(&myHeader, info); err != nil {
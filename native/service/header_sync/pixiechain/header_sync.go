@@ -215,7 +215,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("pixie Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
-			log.Warnf("pixie Handler SyncBlockHeader, header has exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/pixiechain").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("pixie Handler SyncBlockHeader, header has exist. Header: %s", string(v))
 			continue
 		}
 
@@ -224,7 +225,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("pixie Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
-			log.Warnf("pixie Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/pixiechain").With(log.Fields{"chainID": headerParams.ChainID, "height": header.Number}).
+				Warnf("pixie Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
 			continue
 		}
 
@@ -238,7 +238,8 @@ func (h *BorHandler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("bor Handler SyncBlockHeader, isHeaderExist headerHash err: %v", err)
 		}
 		if exist {
-			log.Warnf("bor Handler SyncBlockHeader, header has exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/polygon").With(log.Fields{"chainID": headerParams.ChainID, "height": headerWOP.Header.Number}).
+				Warnf("bor Handler SyncBlockHeader, header has exist. Header: %s", string(v))
 			continue
 		}
 
@@ -247,7 +248,8 @@ func (h *BorHandler) SyncBlockHeader(native *native.NativeService) error {
 			return fmt.Errorf("bor Handler SyncBlockHeader, isHeaderExist ParentHash err: %v", err)
 		}
 		if !parentExist {
-			log.Warnf("bor Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
+			log.Module("native/service/header_sync/polygon").With(log.Fields{"chainID": headerParams.ChainID, "height": headerWOP.Header.Number}).
+				Warnf("bor Handler SyncBlockHeader, parent header not exist. Header: %s", string(v))
 			continue
 		}
 
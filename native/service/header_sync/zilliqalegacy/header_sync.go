@@ -123,7 +123,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 				return fmt.Errorf("SyncDsBlockHeader, check header exist err: %v", err)
 			}
 			if exist == true {
-				log.Warnf("SyncDsBlockHeader, header has exist. Header: %s", string(v))
+				log.Module("native/service/header_sync/zilliqalegacy").With(log.Fields{"chainID": headerParams.ChainID, "height": dsBlock.BlockHeader.BlockNum}).
+					Warnf("SyncDsBlockHeader, header has exist. Header: %s", string(v))
 				continue
 			}
 
@@ -164,7 +165,8 @@ func (h *Handler) SyncBlockHeader(native *native.NativeService) error {
 				return fmt.Errorf("SyncTxBlockHeader, check header exist err: %v", err)
 			}
 			if exist == true {
-				log.Warnf("SyncTxBlockHeader, header has exist. Header: %s", string(v))
+				log.Module("native/service/header_sync/zilliqalegacy").With(log.Fields{"chainID": headerParams.ChainID, "height": txBlock.BlockHeader.BlockNum}).
+					Warnf("SyncTxBlockHeader, header has exist. Header: %s", string(v))
 				continue
 			}
 
@@ -57,4 +57,6 @@ var (
 	POLYGON_BOR_ROUTER      = uint64(16)
 	ZILLIQA_ROUTER          = uint64(17)
 	PIXIECHAIN_ROUTER       = uint64(18)
+	NEAR_ROUTER             = uint64(19)
+	ATTESTATION_ROUTER      = uint64(20)
 )
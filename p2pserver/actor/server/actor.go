@@ -80,6 +80,8 @@ func (this *P2PActor) Receive(ctx actor.Context) {
 		this.handleGetTimeReq(ctx, msg)
 	case *GetNeighborAddrsReq:
 		this.handleGetNeighborAddrsReq(ctx, msg)
+	case *GetMsgStatsReq:
+		this.handleGetMsgStatsReq(ctx, msg)
 	case *GetRelayStateReq:
 		this.handleGetRelayStateReq(ctx, msg)
 	case *GetNodeTypeReq:
@@ -92,6 +94,8 @@ func (this *P2PActor) Receive(ctx actor.Context) {
 		this.server.OnDelNode(msg.ID)
 	case *common.AppendHeaders:
 		this.server.OnHeaderReceive(msg.FromID, msg.Headers)
+	case *common.AppendProof:
+		this.server.OnProofReceive(msg.FromID, msg.ReqID, msg.Proof)
 	case *common.AppendBlock:
 		this.server.OnBlockReceive(msg.FromID, msg.BlockSize, msg.Block, msg.MerkleRoot)
 	default:
@@ -213,6 +217,17 @@ func (this *P2PActor) handleGetNeighborAddrsReq(ctx actor.Context, req *GetNeigh
 	}
 }
 
+//byte counters per message type handler
+func (this *P2PActor) handleGetMsgStatsReq(ctx actor.Context, req *GetMsgStatsReq) {
+	stats := this.server.GetMsgStats()
+	if ctx.Sender() != nil {
+		resp := &GetMsgStatsRsp{
+			Stats: stats,
+		}
+		ctx.Sender().Request(resp, ctx.Self())
+	}
+}
+
 //peer`s relay state handler
 func (this *P2PActor) handleGetRelayStateReq(ctx actor.Context, req *GetRelayStateReq) {
 	ret := this.server.GetNetWork().GetRelay()
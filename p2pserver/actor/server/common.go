@@ -20,6 +20,7 @@ package server
 
 import (
 	types "github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/link"
 	ptypes "github.com/polynetwork/poly/p2pserver/message/types"
 )
 
@@ -128,6 +129,15 @@ type GetNeighborAddrsRsp struct {
 	Addrs []types.PeerAddr
 }
 
+//get byte counters per message type request
+type GetMsgStatsReq struct {
+}
+
+//response of byte counters per message type
+type GetMsgStatsRsp struct {
+	Stats map[string]link.MsgStat
+}
+
 type TransmitConsensusMsgReq struct {
 	Target uint64
 	Msg    ptypes.Message
@@ -36,7 +36,8 @@ import (
 const (
 	SYNC_MAX_HEADER_FORWARD_SIZE = 5000       //keep CurrentHeaderHeight - CurrentBlockHeight <= SYNC_MAX_HEADER_FORWARD_SIZE
 	SYNC_MAX_FLIGHT_HEADER_SIZE  = 1          //Number of headers on flight
-	SYNC_MAX_FLIGHT_BLOCK_SIZE   = 50         //Number of blocks on flight
+	SYNC_NODE_WINDOW_INIT        = 8          //Init number of blocks on flight per node
+	SYNC_NODE_WINDOW_MAX         = 64         //Max number of blocks on flight per node, window grows when node responds in time
 	SYNC_MAX_BLOCK_CACHE_SIZE    = 500        //Cache size of block wait to commit to ledger
 	SYNC_HEADER_REQUEST_TIMEOUT  = 2          //s, Request header timeout time. If header haven't receive after SYNC_HEADER_REQUEST_TIMEOUT second, retry
 	SYNC_BLOCK_REQUEST_TIMEOUT   = 2          //s, Request block timeout time. If block haven't received after SYNC_BLOCK_REQUEST_TIMEOUT second, retry
@@ -56,6 +57,7 @@ type NodeWeight struct {
 	timeoutCnt   int       //Node response timeout count
 	errorRespCnt int       //Node response error data count
 	reqTime      []int64   //Record request time, using for calc the avg req time interval, unit millisecond
+	window       int       //Max blocks on flight of node, increases on response and halves on timeout
 }
 
 //NewNodeWeight new a nodeweight
@@ -75,12 +77,29 @@ func NewNodeWeight(id uint64) *NodeWeight {
 		timeoutCnt:   0,
 		errorRespCnt: 0,
 		reqTime:      r,
+		window:       SYNC_NODE_WINDOW_INIT,
 	}
 }
 
-//AddTimeoutCnt incre timeout count
+//AddTimeoutCnt incre timeout count, and halve the window
 func (this *NodeWeight) AddTimeoutCnt() {
 	this.timeoutCnt++
+	this.window = this.window / 2
+	if this.window < 1 {
+		this.window = 1
+	}
+}
+
+//IncreaseWindow grow the window after node responds a block in time
+func (this *NodeWeight) IncreaseWindow() {
+	if this.window < SYNC_NODE_WINDOW_MAX {
+		this.window++
+	}
+}
+
+//GetWindow return the max number of blocks on flight of node
+func (this *NodeWeight) GetWindow() int {
+	return this.window
 }
 
 //AddErrorRespCnt incre receive error header/block count
@@ -387,7 +406,7 @@ func (this *BlockSyncMgr) syncBlock() {
 	}
 	defer this.releaseSyncBlockLock()
 
-	availCount := SYNC_MAX_FLIGHT_BLOCK_SIZE - this.getFlightBlockCount()
+	availCount := this.getTotalWindow() - this.getFlightBlockCount()
 	if availCount <= 0 {
 		return
 	}
@@ -433,7 +452,7 @@ func (this *BlockSyncMgr) syncBlock() {
 			reqTimes = SYNC_NEXT_BLOCK_TIMES
 		}
 		for t := 0; t < reqTimes; t++ {
-			reqNode := this.getNextNode(nextBlockHeight)
+			reqNode := this.getNextBlockNode(nextBlockHeight)
 			if reqNode == nil {
 				return
 			}
@@ -471,11 +490,7 @@ func (this *BlockSyncMgr) OnHeaderReceive(fromID uint64, headers []*types.Header
 	err := this.ledger.AddHeaders(headers)
 	this.delFlightHeader(height)
 	if err != nil {
-		this.addErrorRespCnt(fromID)
-		n := this.getNodeWeight(fromID)
-		if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
-			this.delNode(fromID)
-		}
+		this.addErrorResp(fromID)
 		log.Warnf("[p2p]OnHeaderReceive AddHeaders error:%s", err)
 		return
 	}
@@ -488,15 +503,26 @@ func (this *BlockSyncMgr) OnBlockReceive(fromID uint64, blockSize uint32, block
 	height := block.Header.Height
 	blockHash := block.Hash()
 	log.Trace("[p2p]OnBlockReceive Height:%d", height)
+	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
+	if height <= curHeaderHeight {
+		//header of the height is verified by bookkeepers, block must match it
+		headerHash := this.ledger.GetBlockHash(height)
+		if headerHash != common.UINT256_EMPTY && headerHash != blockHash {
+			log.Warnf("[p2p]OnBlockReceive Height:%d block 0x%x mismatch header 0x%x from:%d",
+				height, blockHash, headerHash, fromID)
+			this.server.banPeer(fromID, "block mismatch verified header")
+			return
+		}
+	}
 	flightInfo := this.getFlightBlock(blockHash, fromID)
 	if flightInfo != nil {
 		t := (time.Now().UnixNano() - flightInfo.GetStartTime().UnixNano()) / int64(time.Millisecond)
 		s := float32(blockSize) / float32(t) * 1000.0 / 1024.0
 		this.addNewSpeed(fromID, s)
+		this.increaseWindow(fromID)
 	}
 
 	this.delFlightBlock(blockHash)
-	curHeaderHeight := this.ledger.GetCurrentHeaderHeight()
 	nextHeader := curHeaderHeight + 1
 	if height > nextHeader {
 		return
@@ -638,13 +664,9 @@ func (this *BlockSyncMgr) saveBlock() {
 		err := this.ledger.AddBlock(nextBlock, merkleRoot)
 		this.delBlockCache(nextBlockHeight)
 		if err != nil {
-			this.addErrorRespCnt(fromID)
-			n := this.getNodeWeight(fromID)
-			if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
-				this.delNode(fromID)
-			}
+			this.addErrorResp(fromID)
 			log.Warnf("[p2p]saveBlock Height:%d AddBlock error:%s", nextBlockHeight, err)
-			reqNode := this.getNextNode(nextBlockHeight)
+			reqNode := this.getNextBlockNode(nextBlockHeight)
 			if reqNode == nil {
 				return
 			}
@@ -767,6 +789,33 @@ func (this *BlockSyncMgr) getFlightBlockCount() int {
 	return cnt
 }
 
+//getFlightBlockCountByNode return number of blocks on flight of each node
+func (this *BlockSyncMgr) getFlightBlockCountByNode() map[uint64]int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	cnt := make(map[uint64]int)
+	for _, infos := range this.flightBlocks {
+		for _, info := range infos {
+			cnt[info.GetNodeId()]++
+		}
+	}
+	return cnt
+}
+
+//getTotalWindow return the sum of node windows, bounded by block cache size
+func (this *BlockSyncMgr) getTotalWindow() int {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	total := 0
+	for _, w := range this.nodeWeights {
+		total += w.GetWindow()
+	}
+	if total > SYNC_MAX_BLOCK_CACHE_SIZE {
+		total = SYNC_MAX_BLOCK_CACHE_SIZE
+	}
+	return total
+}
+
 func (this *BlockSyncMgr) isBlockOnFlight(blockHash common.Uint256) bool {
 	flightInfos := this.getFlightBlocks(blockHash)
 	if len(flightInfos) != 0 {
@@ -776,11 +825,25 @@ func (this *BlockSyncMgr) isBlockOnFlight(blockHash common.Uint256) bool {
 }
 
 func (this *BlockSyncMgr) getNextNode(nextBlockHeight uint32) *peer.Peer {
+	return this.getNextNodeWithFilter(nextBlockHeight, func(*NodeWeight) bool { return true })
+}
+
+//getNextBlockNode return the node of highest weight which window is not full
+func (this *BlockSyncMgr) getNextBlockNode(nextBlockHeight uint32) *peer.Peer {
+	flights := this.getFlightBlockCountByNode()
+	return this.getNextNodeWithFilter(nextBlockHeight, func(w *NodeWeight) bool {
+		return flights[w.id] < w.GetWindow()
+	})
+}
+
+func (this *BlockSyncMgr) getNextNodeWithFilter(nextBlockHeight uint32, filter func(*NodeWeight) bool) *peer.Peer {
 	weights := this.getAllNodeWeights()
 	sort.Sort(sort.Reverse(weights))
 	nodelist := make([]uint64, 0)
 	for _, n := range weights {
-		nodelist = append(nodelist, n.id)
+		if filter(n) {
+			nodelist = append(nodelist, n.id)
+		}
 	}
 	nextNodeIndex := 0
 	triedNode := make(map[uint64]bool, 0)
@@ -873,6 +936,24 @@ func (this *BlockSyncMgr) addErrorRespCnt(nodeId uint64) {
 	}
 }
 
+//addErrorResp incre a node's error resp count, and delete the node when reaches the max times.
+//Headers and blocks may fail to be added for reasons other than peer fault, so the node is not banned
+func (this *BlockSyncMgr) addErrorResp(nodeId uint64) {
+	this.addErrorRespCnt(nodeId)
+	n := this.getNodeWeight(nodeId)
+	if n != nil && n.GetErrorRespCnt() >= SYNC_MAX_ERROR_RESP_TIMES {
+		this.delNode(nodeId)
+	}
+}
+
+//increaseWindow grow a node's window
+func (this *BlockSyncMgr) increaseWindow(nodeId uint64) {
+	n := this.getNodeWeight(nodeId)
+	if n != nil {
+		n.IncreaseWindow()
+	}
+}
+
 //appendReqTime append a node's request time
 func (this *BlockSyncMgr) appendReqTime(nodeId uint64) {
 	n := this.getNodeWeight(nodeId)
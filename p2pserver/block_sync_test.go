@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	p2pComm "github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/peer"
+)
+
+//newSyncTestServer return a p2p server with established peers, data dir is a temp dir
+func newSyncTestServer(t *testing.T, ids ...uint64) *P2PServer {
+	dir, err := ioutil.TempDir("", "blocksync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, config.DefConfig.P2PNode.NetworkName), 0700); err != nil {
+		t.Fatal(err)
+	}
+	dataDir := config.DefConfig.Common.DataDir
+	config.DefConfig.Common.DataDir = dir
+	t.Cleanup(func() {
+		config.DefConfig.Common.DataDir = dataDir
+		os.RemoveAll(dir)
+	})
+
+	server := NewServer()
+	for _, id := range ids {
+		p := peer.NewPeer()
+		p.UpdateInfo(time.Now(), 0, 0, 20338, 20339, id, 0, 1000, "")
+		p.SetSyncState(p2pComm.ESTABLISH)
+		p.SyncLink.SetAddr("127.0.0.1:20338")
+		server.network.AddNbrNode(p)
+		server.blockSync.OnAddNode(id)
+	}
+	return server
+}
+
+func TestNodeWeightWindow(t *testing.T) {
+	w := NewNodeWeight(1)
+	if w.GetWindow() != SYNC_NODE_WINDOW_INIT {
+		t.Fatal("TestNodeWeightWindow init window error", w.GetWindow())
+	}
+	w.IncreaseWindow()
+	if w.GetWindow() != SYNC_NODE_WINDOW_INIT+1 {
+		t.Fatal("TestNodeWeightWindow increase window error", w.GetWindow())
+	}
+	for i := 0; i < 10; i++ {
+		w.AddTimeoutCnt()
+	}
+	if w.GetWindow() != 1 {
+		t.Fatal("TestNodeWeightWindow window should not drop below 1", w.GetWindow())
+	}
+	for i := 0; i < 2*SYNC_NODE_WINDOW_MAX; i++ {
+		w.IncreaseWindow()
+	}
+	if w.GetWindow() != SYNC_NODE_WINDOW_MAX {
+		t.Fatal("TestNodeWeightWindow window should not exceed max", w.GetWindow())
+	}
+}
+
+func TestGetNextBlockNode(t *testing.T) {
+	server := newSyncTestServer(t, 1, 2)
+	bs := server.blockSync
+	if bs.getTotalWindow() != 2*SYNC_NODE_WINDOW_INIT {
+		t.Fatal("TestGetNextBlockNode total window error", bs.getTotalWindow())
+	}
+
+	for i := 0; i < SYNC_NODE_WINDOW_INIT; i++ {
+		bs.addFlightBlock(1, uint32(i+1), common.Uint256{byte(i + 1)})
+	}
+	for i := 0; i < 10; i++ {
+		n := bs.getNextBlockNode(100)
+		if n == nil || n.GetID() != 2 {
+			t.Fatal("TestGetNextBlockNode node with full window selected")
+		}
+	}
+	for i := 0; i < SYNC_NODE_WINDOW_INIT; i++ {
+		bs.addFlightBlock(2, uint32(i+100), common.Uint256{byte(i + 100)})
+	}
+	if bs.getNextBlockNode(100) != nil {
+		t.Fatal("TestGetNextBlockNode all windows are full")
+	}
+	if bs.getNextNode(100) == nil {
+		t.Fatal("TestGetNextBlockNode header sync should ignore windows")
+	}
+}
+
+func TestErrorResp(t *testing.T) {
+	server := newSyncTestServer(t, 1, 2)
+	bs := server.blockSync
+	for i := 0; i < SYNC_MAX_ERROR_RESP_TIMES; i++ {
+		bs.addErrorResp(1)
+	}
+	if bs.getNodeWeight(1) != nil {
+		t.Fatal("TestErrorResp node still used for sync")
+	}
+	if server.network.GetBanList().IsBanned(1) {
+		t.Fatal("TestErrorResp node banned for failing to add blocks")
+	}
+}
+
+func TestBanPeer(t *testing.T) {
+	server := newSyncTestServer(t, 1, 2)
+	bs := server.blockSync
+	server.banPeer(1, "block mismatch verified header")
+	if bs.getNodeWeight(1) != nil {
+		t.Fatal("TestBanPeer banned node still used for sync")
+	}
+	bans := server.network.GetBanList()
+	if !bans.IsBanned(1) || !bans.IsIPBanned("127.0.0.1") {
+		t.Fatal("TestBanPeer node not banned")
+	}
+	path := filepath.Join(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName, p2pComm.BAN_FILE_NAME)
+	if peer.NewBanList(path).IsBanned(1) == false {
+		t.Fatal("TestBanPeer ban not persisted in data dir")
+	}
+	if bans.IsBanned(2) {
+		t.Fatal("TestBanPeer good node banned")
+	}
+}
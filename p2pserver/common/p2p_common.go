@@ -31,6 +31,7 @@ import (
 const (
 	VERIFY_NODE  = 1 //peer involved in consensus
 	SERVICE_NODE = 2 //peer only sync with consensus peer
+	LIGHT_NODE   = 3 //peer only keep verified headers
 )
 
 //link and concurrent const
@@ -58,8 +59,9 @@ const (
 
 //msg type const
 const (
-	MAX_ADDR_NODE_CNT = 64 //the maximum peer address from msg
-	MAX_INV_BLK_CNT   = 64 //the maximum blk hash cnt of inv msg
+	MAX_ADDR_NODE_CNT   = 64 //the maximum peer address from msg
+	MAX_NODE_RECORD_CNT = 64 //the maximum node record from msg
+	MAX_INV_BLK_CNT     = 64 //the maximum blk hash cnt of inv msg
 )
 
 //info update const
@@ -88,6 +90,14 @@ const (
 //cap flag
 const (
 	HTTP_INFO_FLAG = 0 //peer`s http info bit in cap field
+	COMPRESS_FLAG  = 1 //peer`s supported compression mask in cap field
+)
+
+//compression const
+const (
+	COMPRESS_SNAPPY  = 0x01 //snappy compression, also the algorithm id in msg hdr
+	COMPRESS_ZSTD    = 0x02 //zstd compression, only offered by nodes built with cgo
+	COMPRESS_MIN_LEN = 256  //payload shorter than it is sent uncompressed
 )
 
 //actor const
@@ -102,6 +112,28 @@ const (
 	RECENT_LIMIT     = 10 //recent contact list limit
 )
 
+//ban const
+const (
+	BAN_FILE_NAME = "peers.banned"
+	BAN_DURATION  = 24 * 3600 //ban time of peer serving invalid data in sec
+)
+
+//node record const
+const (
+	PEER_DB_FILE_NAME   = "peers.db"
+	PEER_DB_LIMIT       = 1024          //max node records kept in peer db
+	NODE_RECORD_CYCLE   = 60            //node record exchange cycle in secs
+	NODE_RECORD_TIMEOUT = 7 * 24 * 3600 //node record not seen in time are dropped
+)
+
+//proof fetch const
+const (
+	MERKLE_PROOF       = 1 //block merkle proof of GetMerkleProof
+	CROSS_STATES_PROOF = 2 //cross states proof of GetCrossStatesProof
+	PROOF_TIMEOUT      = 5 //proof request timeout in secs
+	PROOF_MAX_TRY      = 3 //max full peers asked for one proof
+)
+
 //PeerAddr represent peer`s net information
 type PeerAddr struct {
 	Time          int64    //latest timestamp
@@ -114,22 +146,26 @@ type PeerAddr struct {
 
 //const channel msg id and type
 const (
-	VERSION_TYPE     = "version"    //peer`s information
-	VERACK_TYPE      = "verack"     //ack msg after version recv
-	GetADDR_TYPE     = "getaddr"    //req nbr address from peer
-	ADDR_TYPE        = "addr"       //nbr address
-	PING_TYPE        = "ping"       //ping  sync height
-	PONG_TYPE        = "pong"       //pong  recv nbr height
-	GET_HEADERS_TYPE = "getheaders" //req blk hdr
-	HEADERS_TYPE     = "headers"    //blk hdr
-	INV_TYPE         = "inv"        //inv payload
-	GET_DATA_TYPE    = "getdata"    //req data from peer
-	BLOCK_TYPE       = "block"      //blk payload
-	TX_TYPE          = "tx"         //transaction
-	CONSENSUS_TYPE   = "consensus"  //consensus payload
-	GET_BLOCKS_TYPE  = "getblocks"  //req blks from peer
-	NOT_FOUND_TYPE   = "notfound"   //peer can`t find blk according to the hash
-	DISCONNECT_TYPE  = "disconnect" //peer disconnect info raise by link
+	VERSION_TYPE          = "version"    //peer`s information
+	VERACK_TYPE           = "verack"     //ack msg after version recv
+	GetADDR_TYPE          = "getaddr"    //req nbr address from peer
+	ADDR_TYPE             = "addr"       //nbr address
+	PING_TYPE             = "ping"       //ping  sync height
+	PONG_TYPE             = "pong"       //pong  recv nbr height
+	GET_HEADERS_TYPE      = "getheaders" //req blk hdr
+	HEADERS_TYPE          = "headers"    //blk hdr
+	INV_TYPE              = "inv"        //inv payload
+	GET_DATA_TYPE         = "getdata"    //req data from peer
+	BLOCK_TYPE            = "block"      //blk payload
+	TX_TYPE               = "tx"         //transaction
+	CONSENSUS_TYPE        = "consensus"  //consensus payload
+	GET_BLOCKS_TYPE       = "getblocks"  //req blks from peer
+	NOT_FOUND_TYPE        = "notfound"   //peer can`t find blk according to the hash
+	DISCONNECT_TYPE       = "disconnect" //peer disconnect info raise by link
+	GET_NODE_RECORDS_TYPE = "getrecords" //req signed node records
+	NODE_RECORDS_TYPE     = "records"    //signed node records
+	GET_PROOF_TYPE        = "getproof"   //req merkle or cross states proof
+	PROOF_TYPE            = "proof"      //merkle or cross states proof
 )
 
 type AppendPeerID struct {
@@ -145,6 +181,12 @@ type AppendHeaders struct {
 	Headers []*types.Header // Headers to be added to the ledger
 }
 
+type AppendProof struct {
+	FromID uint64 // The peer id
+	ReqID  string // ReqID of the proof request answered
+	Proof  []byte // Proof, empty if peer cannot build it
+}
+
 type AppendBlock struct {
 	FromID     uint64       // The peer id
 	BlockSize  uint32       // Block size
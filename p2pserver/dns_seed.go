@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	msgtypes "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+const (
+	DNS_RECORD_PREFIX = "poly:" //prefix of node record in dns txt entry
+	DNS_SEED_TIMEOUT  = 10      //dns seed lookup timeout in secs
+)
+
+//DNSResolver resolve the txt entries of dns seed names, net.DefaultResolver is used by default
+type DNSResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+//DefaultDNSResolver is the resolver used by new servers, tests may replace it
+var DefaultDNSResolver DNSResolver = net.DefaultResolver
+
+//EncodeDNSRecord encode the node record to the txt entry published in dns seed list
+func EncodeDNSRecord(record *msgtypes.NodeRecord) string {
+	sink := comm.NewZeroCopySink(nil)
+	record.Serialization(sink)
+	return DNS_RECORD_PREFIX + base64.RawURLEncoding.EncodeToString(sink.Bytes())
+}
+
+//DecodeDNSRecord decode and verify the node record in txt entry
+func DecodeDNSRecord(entry string) (*msgtypes.NodeRecord, error) {
+	if !strings.HasPrefix(entry, DNS_RECORD_PREFIX) {
+		return nil, errors.New("not a node record")
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(entry, DNS_RECORD_PREFIX))
+	if err != nil {
+		return nil, err
+	}
+	record := &msgtypes.NodeRecord{}
+	if err := record.Deserialization(comm.NewZeroCopySource(buf)); err != nil {
+		return nil, err
+	}
+	if err := record.Verify(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+//ResolveDNSSeeds return the valid node records published under the dns names
+func ResolveDNSSeeds(resolver DNSResolver, names []string) []*msgtypes.NodeRecord {
+	records := make([]*msgtypes.NodeRecord, 0)
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(context.Background(), DNS_SEED_TIMEOUT*time.Second)
+		entries, err := resolver.LookupTXT(ctx, name)
+		cancel()
+		if err != nil {
+			log.Warnf("[p2p]resolve dns seed %s err: %s", name, err)
+			continue
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry, DNS_RECORD_PREFIX) {
+				continue
+			}
+			record, err := DecodeDNSRecord(entry)
+			if err != nil {
+				log.Warnf("[p2p]invalid node record in dns seed %s: %s", name, err)
+				continue
+			}
+			records = append(records, record)
+		}
+	}
+	return records
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common/config"
+	msgtypes "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+//stubResolver serve txt entries from memory
+type stubResolver map[string][]string
+
+func (this stubResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	entries, ok := this[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return entries, nil
+}
+
+func TestResolveDNSSeeds(t *testing.T) {
+	record, _ := msgtypes.NewNodeRecord(account.NewAccount(""), 1, "10.0.0.1", 20338, 20339)
+	forged, _ := msgtypes.NewNodeRecord(account.NewAccount(""), 1, "10.0.0.2", 20338, 20339)
+	forged.Seq = 2
+
+	resolver := stubResolver{
+		"seed1.poly.network": {"v=spf1 -all", EncodeDNSRecord(record), EncodeDNSRecord(forged), "poly:invalid"},
+	}
+	records := ResolveDNSSeeds(resolver, []string{"seed1.poly.network", "seed2.poly.network"})
+	if len(records) != 1 || records[0].Host != "10.0.0.1" || records[0].SyncPort != 20338 {
+		t.Fatal("TestResolveDNSSeeds wrong records", records)
+	}
+}
+
+func TestTryDNSSeeds(t *testing.T) {
+	server := newSyncTestServer(t)
+	record, _ := msgtypes.NewNodeRecord(account.NewAccount(""), 1, "10.0.0.1", 20338, 20339)
+	server.resolver = stubResolver{"seed.poly.network": {EncodeDNSRecord(record)}}
+
+	seeds := config.DefConfig.Genesis.DNSSeedList
+	config.DefConfig.Genesis.DNSSeedList = []string{"seed.poly.network"}
+	defer func() { config.DefConfig.Genesis.DNSSeedList = seeds }()
+
+	server.tryDNSSeeds()
+	addrs := server.network.GetPeerDB().GetSyncAddrs()
+	if len(addrs) != 1 || addrs[0] != "10.0.0.1:20338" {
+		t.Fatal("TestTryDNSSeeds dns seed not loaded", addrs)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"sync"
+	"time"
+)
+
+//RateLimiter limit the bytes per second with token bucket, burst of one second
+type RateLimiter struct {
+	sync.Mutex
+	rate   float64 //bytes per second, 0 for unlimited
+	tokens float64
+	last   time.Time
+}
+
+//NewRateLimiter return a limiter of rate bytes per second, 0 for unlimited
+func NewRateLimiter(rate uint) *RateLimiter {
+	return &RateLimiter{
+		rate:   float64(rate),
+		tokens: float64(rate),
+		last:   time.Now(),
+	}
+}
+
+//SetRate change the rate of limiter
+func (this *RateLimiter) SetRate(rate uint) {
+	this.Lock()
+	defer this.Unlock()
+	this.rate = float64(rate)
+	if this.tokens > this.rate {
+		this.tokens = this.rate
+	}
+}
+
+//Wait take n bytes from bucket, block until the bucket is refilled if overdrawn
+func (this *RateLimiter) Wait(n int) {
+	if delay := this.take(n); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+//Take take n bytes from bucket without waiting, later callers wait for the debt
+func (this *RateLimiter) Take(n int) {
+	this.take(n)
+}
+
+//take consume n tokens and return how long the caller should wait
+func (this *RateLimiter) take(n int) time.Duration {
+	if this == nil {
+		return 0
+	}
+	this.Lock()
+	defer this.Unlock()
+	if this.rate <= 0 {
+		return 0
+	}
+	now := time.Now()
+	this.tokens += now.Sub(this.last).Seconds() * this.rate
+	if this.tokens > this.rate {
+		this.tokens = this.rate
+	}
+	this.last = now
+	this.tokens -= float64(n)
+	if this.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-this.tokens / this.rate * float64(time.Second))
+}
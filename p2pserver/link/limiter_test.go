@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(1000)
+	start := time.Now()
+	limiter.Wait(1000)
+	limiter.Wait(500)
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("TestRateLimiter overdrawn bucket not waited, elapsed %s", elapsed)
+	}
+
+	limiter.SetRate(0)
+	start = time.Now()
+	limiter.Wait(1 << 20)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("TestRateLimiter unlimited limiter waited %s", elapsed)
+	}
+
+	var unset *RateLimiter
+	unset.Wait(1 << 20)
+}
@@ -23,9 +23,11 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync/atomic"
 	"time"
 
 	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/types"
@@ -40,15 +42,30 @@ type Link struct {
 	time      time.Time              // The latest time the node activity
 	recvChan  chan *types.MsgPayload //msgpayload channel
 	reqRecord map[string]int64       //Map RequestId to Timestamp, using for rejecting duplicate request in specific time
+	compress  uint32                 //compression algorithm negotiated with the peer
+	rxLimiter *RateLimiter           //ingress bandwidth limit of the link
+	txLimiter *RateLimiter           //egress bandwidth limit of the link
 }
 
 func NewLink() *Link {
 	link := &Link{
 		reqRecord: make(map[string]int64, 0),
+		rxLimiter: NewRateLimiter(config.DefConfig.P2PNode.MaxPeerIngressRate),
+		txLimiter: NewRateLimiter(config.DefConfig.P2PNode.MaxPeerEgressRate),
 	}
 	return link
 }
 
+//SetCompression set the compression algorithm used to send to peer
+func (this *Link) SetCompression(algo uint8) {
+	atomic.StoreUint32(&this.compress, uint32(algo))
+}
+
+//GetCompression return the compression algorithm used to send to peer
+func (this *Link) GetCompression() uint8 {
+	return uint8(atomic.LoadUint32(&this.compress))
+}
+
 //SetID set peer id to link
 func (this *Link) SetID(id uint64) {
 	this.id = id
@@ -127,6 +144,10 @@ func (this *Link) Rx() {
 		t := time.Now()
 		this.UpdateRXTime(t)
 
+		size := common.MSG_HDR_LEN + int(payloadSize)
+		addMsgStat(msg.CmdType(), size, true)
+		this.limit(msg.CmdType(), size, ingressLimiter, this.rxLimiter)
+
 		if !this.needSendMsg(msg) {
 			log.Debugf("skip handle msgType:%s from:%d", msg.CmdType(), this.id)
 			continue
@@ -183,9 +204,14 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 		return errors.New("[p2p]tx link invalid")
 	}
 
+	rawPacket = types.CompressMessage(rawPacket, this.GetCompression())
 	nByteCnt := len(rawPacket)
 	log.Tracef("[p2p]TX buf length: %d\n", nByteCnt)
 
+	cmd := types.GetRawMessageCmd(rawPacket)
+	addMsgStat(cmd, nByteCnt, false)
+	this.limit(cmd, nByteCnt, egressLimiter, this.txLimiter)
+
 	nCount := nByteCnt / common.PER_SEND_LEN
 	if nCount == 0 {
 		nCount = 1
@@ -201,6 +227,17 @@ func (this *Link) SendRaw(rawPacket []byte) error {
 	return nil
 }
 
+//limit wait for the bandwidth, consensus message is never delayed but still consumes the bandwidth
+func (this *Link) limit(cmd string, n int, limiters ...*RateLimiter) {
+	for _, limiter := range limiters {
+		if cmd == common.CONSENSUS_TYPE {
+			limiter.Take(n)
+		} else {
+			limiter.Wait(n)
+		}
+	}
+}
+
 //needSendMsg check whether the msg is needed to push to channel
 func (this *Link) needSendMsg(msg types.Message) bool {
 	if msg.CmdType() != common.GET_DATA_TYPE {
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"sync"
+)
+
+//MsgStat count the messages and bytes on wire of a message type
+type MsgStat struct {
+	RxMsgs  uint64
+	RxBytes uint64
+	TxMsgs  uint64
+	TxBytes uint64
+}
+
+var msgStats = struct {
+	sync.RWMutex
+	stats map[string]*MsgStat
+}{stats: make(map[string]*MsgStat)}
+
+//global bandwidth limit shared by all links
+var (
+	ingressLimiter = NewRateLimiter(0)
+	egressLimiter  = NewRateLimiter(0)
+)
+
+//SetGlobalRateLimit set the bytes per second limit of all links, 0 for unlimited
+func SetGlobalRateLimit(ingress, egress uint) {
+	ingressLimiter.SetRate(ingress)
+	egressLimiter.SetRate(egress)
+}
+
+//GetMsgStats return the byte counters per message type
+func GetMsgStats() map[string]MsgStat {
+	msgStats.RLock()
+	defer msgStats.RUnlock()
+	stats := make(map[string]MsgStat, len(msgStats.stats))
+	for cmd, stat := range msgStats.stats {
+		stats[cmd] = *stat
+	}
+	return stats
+}
+
+func addMsgStat(cmd string, n int, rx bool) {
+	msgStats.Lock()
+	defer msgStats.Unlock()
+	stat, ok := msgStats.stats[cmd]
+	if !ok {
+		stat = &MsgStat{}
+		msgStats.stats[cmd] = stat
+	}
+	if rx {
+		stat.RxMsgs++
+		stat.RxBytes += uint64(n)
+	} else {
+		stat.TxMsgs++
+		stat.TxBytes += uint64(n)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package link
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/p2pserver/common"
+	mt "github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+func TestCompressedSendStats(t *testing.T) {
+	cliConn, serverConn := net.Pipe()
+	cli, server := NewLink(), NewLink()
+	cli.SetConn(cliConn)
+	server.SetConn(serverConn)
+	recv := make(chan *mt.MsgPayload, 2)
+	server.SetChan(recv)
+	go server.Rx()
+	defer cli.CloseConn()
+
+	cli.SetCompression(common.COMPRESS_SNAPPY)
+	acc := account.NewAccount("")
+	msg := &mt.Consensus{Cons: mt.ConsensusPayload{
+		Data:      bytes.Repeat([]byte("btc header "), 1024),
+		Owner:     acc.PublicKey,
+		Signature: []byte{1},
+	}}
+	before := GetMsgStats()[common.CONSENSUS_TYPE]
+	if err := cli.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case payload := <-recv:
+		cons, ok := payload.Payload.(*mt.Consensus)
+		if !ok || !bytes.Equal(cons.Cons.Data, msg.Cons.Data) {
+			t.Fatal("TestCompressedSendStats wrong message received")
+		}
+		if int(payload.PayloadSize) >= len(msg.Cons.Data) {
+			t.Error("TestCompressedSendStats message not compressed", payload.PayloadSize)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TestCompressedSendStats message not received")
+	}
+
+	after := GetMsgStats()[common.CONSENSUS_TYPE]
+	if after.TxMsgs != before.TxMsgs+1 || after.RxMsgs != before.RxMsgs+1 {
+		t.Error("TestCompressedSendStats message not counted", before, after)
+	}
+	if after.TxBytes-before.TxBytes != after.RxBytes-before.RxBytes {
+		t.Error("TestCompressedSendStats tx and rx bytes mismatch", before, after)
+	}
+}
@@ -46,6 +46,44 @@ func NewAddrReq() mt.Message {
 	return &msg
 }
 
+//Node records package
+func NewNodeRecords(records []*mt.NodeRecord) mt.Message {
+	log.Trace()
+	var msg mt.NodeRecords
+	msg.Records = records
+
+	return &msg
+}
+
+//Node records request package
+func NewNodeRecordsReq() mt.Message {
+	log.Trace()
+	var msg mt.NodeRecordsReq
+	return &msg
+}
+
+//Proof request package
+func NewProofReq(proofType uint8, height, rootHeight uint32, key []byte) mt.Message {
+	log.Trace()
+	var msg mt.ProofReq
+	msg.Type = proofType
+	msg.Height = height
+	msg.RootHeight = rootHeight
+	msg.Key = key
+
+	return &msg
+}
+
+//Proof package
+func NewProof(req *mt.ProofReq, proof []byte) mt.Message {
+	log.Trace()
+	var msg mt.Proof
+	msg.Req = *req
+	msg.Proof = proof
+
+	return &msg
+}
+
 ///block package
 func NewBlock(bk *ct.Block, merkleRoot common.Uint256) mt.Message {
 	log.Trace()
@@ -175,6 +213,7 @@ func NewVersion(n p2pnet.P2P, isCons bool, height uint32) mt.Message {
 	} else {
 		version.P.Cap[msgCommon.HTTP_INFO_FLAG] = 0x00
 	}
+	version.P.Cap[msgCommon.COMPRESS_FLAG] = mt.SupportedCompression()
 	return &version
 }
 
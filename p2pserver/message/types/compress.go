@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/snappy"
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/p2pserver/common"
+)
+
+//compressible message types, the others are small and sent as is
+var compressibleCmds = map[string]bool{
+	common.BLOCK_TYPE:     true,
+	common.TX_TYPE:        true,
+	common.CONSENSUS_TYPE: true,
+}
+
+//SupportedCompression return the compression mask advertised in version cap,
+//zstd nodes offer snappy too for peers without zstd
+func SupportedCompression() uint8 {
+	switch config.DefConfig.P2PNode.Compression {
+	case config.P2P_COMPRESSION_SNAPPY:
+		return common.COMPRESS_SNAPPY
+	case config.P2P_COMPRESSION_ZSTD:
+		if zstdSupported {
+			return common.COMPRESS_ZSTD | common.COMPRESS_SNAPPY
+		}
+		return common.COMPRESS_SNAPPY
+	}
+	return 0
+}
+
+//NegotiateCompression return the algorithm used to send to the peer advertised the mask, zstd is preferred
+func NegotiateCompression(mask uint8) uint8 {
+	shared := SupportedCompression() & mask
+	if shared&common.COMPRESS_ZSTD != 0 {
+		return common.COMPRESS_ZSTD
+	}
+	if shared&common.COMPRESS_SNAPPY != 0 {
+		return common.COMPRESS_SNAPPY
+	}
+	return 0
+}
+
+//GetRawMessageCmd return the cmd type of serialized message
+func GetRawMessageCmd(raw []byte) string {
+	if len(raw) < common.MSG_HDR_LEN {
+		return ""
+	}
+	//the last cmd byte carries the compression algorithm
+	cmd := raw[common.CMD_OFFSET : common.CMD_OFFSET+common.MSG_CMD_LEN-1]
+	return string(bytes.TrimRight(cmd, "\x00"))
+}
+
+//CompressMessage compress the payload of serialized message with algo,
+//the message is returned as is if it is not compressible or does not shrink
+func CompressMessage(raw []byte, algo uint8) []byte {
+	if algo == 0 || len(raw) < common.MSG_HDR_LEN+common.COMPRESS_MIN_LEN {
+		return raw
+	}
+	cmd := GetRawMessageCmd(raw)
+	if !compressibleCmds[cmd] || raw[common.CMD_OFFSET+common.MSG_CMD_LEN-1] != 0 {
+		return raw
+	}
+	payload, err := compressPayload(algo, raw[common.MSG_HDR_LEN:])
+	if err != nil || len(payload) >= len(raw)-common.MSG_HDR_LEN {
+		return raw
+	}
+
+	hdr := newMessageHeader(cmd, uint32(len(payload)), common.Checksum(payload))
+	hdr.CMD[common.MSG_CMD_LEN-1] = algo
+	sink := comm.NewZeroCopySink(make([]byte, 0, common.MSG_HDR_LEN+len(payload)))
+	writeMessageHeaderInto(sink, hdr)
+	sink.WriteBytes(payload)
+	return sink.Bytes()
+}
+
+func compressPayload(algo uint8, buf []byte) ([]byte, error) {
+	switch algo {
+	case common.COMPRESS_SNAPPY:
+		return snappy.Encode(nil, buf), nil
+	case common.COMPRESS_ZSTD:
+		return zstdEncode(buf)
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm %d", algo)
+}
+
+func decompressPayload(algo uint8, buf []byte) ([]byte, error) {
+	switch algo {
+	case common.COMPRESS_SNAPPY:
+	case common.COMPRESS_ZSTD:
+		return zstdDecode(buf)
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %d", algo)
+	}
+	size, err := snappy.DecodedLen(buf)
+	if err != nil {
+		return nil, err
+	}
+	if size > common.MAX_PAYLOAD_LEN {
+		return nil, fmt.Errorf("msg decompressed length:%d exceed max payload size: %d",
+			size, common.MAX_PAYLOAD_LEN)
+	}
+	return snappy.Decode(nil, buf)
+}
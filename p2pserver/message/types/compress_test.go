@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConsensus(dataLen int) *Consensus {
+	acc := account.NewAccount("")
+	return &Consensus{Cons: ConsensusPayload{
+		Version:   1,
+		Height:    100,
+		Data:      bytes.Repeat([]byte("btc header "), dataLen/11),
+		Owner:     acc.PublicKey,
+		Signature: []byte{1, 2, 3},
+		PeerId:    1,
+	}}
+}
+
+func TestCompressMessage(t *testing.T) {
+	msg := newTestConsensus(4096)
+	sink := comm.NewZeroCopySink(nil)
+	assert.Nil(t, WriteMessage(sink, msg))
+	raw := sink.Bytes()
+
+	compressed := CompressMessage(raw, common.COMPRESS_SNAPPY)
+	assert.True(t, len(compressed) < len(raw))
+	assert.Equal(t, common.CONSENSUS_TYPE, GetRawMessageCmd(compressed))
+	assert.Equal(t, compressed, CompressMessage(compressed, common.COMPRESS_SNAPPY))
+
+	demsg, size, err := ReadMessage(bytes.NewBuffer(compressed))
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(len(compressed)-common.MSG_HDR_LEN), size)
+	assert.Equal(t, msg.Cons.Data, demsg.(*Consensus).Cons.Data)
+
+	assert.Equal(t, raw, CompressMessage(raw, 0))
+	small := comm.NewZeroCopySink(nil)
+	assert.Nil(t, WriteMessage(small, newTestConsensus(0)))
+	assert.Equal(t, small.Bytes(), CompressMessage(small.Bytes(), common.COMPRESS_SNAPPY))
+	ping := comm.NewZeroCopySink(nil)
+	assert.Nil(t, WriteMessage(ping, &AddrReq{}))
+	assert.Equal(t, ping.Bytes(), CompressMessage(ping.Bytes(), common.COMPRESS_SNAPPY))
+}
+
+func TestCompressMessageZstd(t *testing.T) {
+	if !zstdSupported {
+		t.Skip("zstd requires cgo")
+	}
+	msg := newTestConsensus(4096)
+	sink := comm.NewZeroCopySink(nil)
+	assert.Nil(t, WriteMessage(sink, msg))
+	raw := sink.Bytes()
+
+	compressed := CompressMessage(raw, common.COMPRESS_ZSTD)
+	assert.True(t, len(compressed) < len(raw))
+	assert.Equal(t, uint8(common.COMPRESS_ZSTD), compressed[common.CMD_OFFSET+common.MSG_CMD_LEN-1])
+	assert.Equal(t, compressed, CompressMessage(compressed, common.COMPRESS_SNAPPY))
+
+	demsg, _, err := ReadMessage(bytes.NewBuffer(compressed))
+	assert.Nil(t, err)
+	assert.Equal(t, msg.Cons.Data, demsg.(*Consensus).Cons.Data)
+
+	//payload decompressed over max payload size is rejected
+	bomb, err := zstdEncode(make([]byte, common.MAX_PAYLOAD_LEN+1))
+	assert.Nil(t, err)
+	_, err = decompressPayload(common.COMPRESS_ZSTD, bomb)
+	assert.NotNil(t, err)
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	compression := config.DefConfig.P2PNode.Compression
+	defer func() { config.DefConfig.P2PNode.Compression = compression }()
+
+	config.DefConfig.P2PNode.Compression = config.P2P_COMPRESSION_SNAPPY
+	assert.Equal(t, uint8(common.COMPRESS_SNAPPY), NegotiateCompression(common.COMPRESS_SNAPPY))
+	assert.Equal(t, uint8(0), NegotiateCompression(0))
+
+	config.DefConfig.P2PNode.Compression = config.P2P_COMPRESSION_NONE
+	assert.Equal(t, uint8(0), NegotiateCompression(common.COMPRESS_SNAPPY))
+
+	config.DefConfig.P2PNode.Compression = config.P2P_COMPRESSION_ZSTD
+	assert.Equal(t, uint8(common.COMPRESS_SNAPPY), NegotiateCompression(common.COMPRESS_SNAPPY))
+	if zstdSupported {
+		assert.Equal(t, uint8(common.COMPRESS_ZSTD|common.COMPRESS_SNAPPY), SupportedCompression())
+		assert.Equal(t, uint8(common.COMPRESS_ZSTD), NegotiateCompression(common.COMPRESS_ZSTD|common.COMPRESS_SNAPPY))
+	} else {
+		assert.Equal(t, uint8(common.COMPRESS_SNAPPY), SupportedCompression())
+		assert.Equal(t, uint8(common.COMPRESS_SNAPPY), NegotiateCompression(common.COMPRESS_ZSTD|common.COMPRESS_SNAPPY))
+	}
+
+	config.DefConfig.P2PNode.Compression = config.P2P_COMPRESSION_SNAPPY
+	assert.Equal(t, uint8(common.COMPRESS_SNAPPY), NegotiateCompression(common.COMPRESS_ZSTD|common.COMPRESS_SNAPPY))
+}
@@ -0,0 +1,52 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/DataDog/zstd"
+	"github.com/polynetwork/poly/p2pserver/common"
+)
+
+//zstdSupported is whether zstd compression is built in, which requires cgo
+const zstdSupported = true
+
+func zstdEncode(data []byte) ([]byte, error) {
+	return zstd.CompressLevel(nil, data, zstd.BestSpeed)
+}
+
+//zstdDecode decompress data, the frame size is not trusted and output over max payload size is rejected
+func zstdDecode(data []byte) ([]byte, error) {
+	reader := zstd.NewReader(bytes.NewReader(data))
+	defer reader.Close()
+	buf, err := ioutil.ReadAll(io.LimitReader(reader, common.MAX_PAYLOAD_LEN+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > common.MAX_PAYLOAD_LEN {
+		return nil, fmt.Errorf("msg decompressed length exceed max payload size: %d", common.MAX_PAYLOAD_LEN)
+	}
+	return buf, nil
+}
@@ -134,6 +134,14 @@ func ReadMessage(reader io.Reader) (Message, uint32, error) {
 		return nil, 0, fmt.Errorf("message checksum mismatch: %x != %x ", hdr.Checksum, checksum)
 	}
 
+	if algo := hdr.CMD[common.MSG_CMD_LEN-1]; algo != 0 {
+		buf, err = decompressPayload(algo, buf)
+		if err != nil {
+			return nil, 0, err
+		}
+		hdr.CMD[common.MSG_CMD_LEN-1] = 0
+	}
+
 	cmdType := string(bytes.TrimRight(hdr.CMD[:], string(0)))
 	msg, err := MakeEmptyMessage(cmdType)
 	if err != nil {
@@ -184,6 +192,14 @@ func MakeEmptyMessage(cmdType string) (Message, error) {
 		return &Disconnected{}, nil
 	case common.GET_BLOCKS_TYPE:
 		return &BlocksReq{}, nil
+	case common.GET_NODE_RECORDS_TYPE:
+		return &NodeRecordsReq{}, nil
+	case common.NODE_RECORDS_TYPE:
+		return &NodeRecords{}, nil
+	case common.GET_PROOF_TYPE:
+		return &ProofReq{}, nil
+	case common.PROOF_TYPE:
+		return &Proof{}, nil
 	default:
 		return nil, errors.New("unsupported cmd type:" + cmdType)
 	}
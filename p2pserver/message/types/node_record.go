@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/signature"
+	comm "github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/net/noise"
+)
+
+//NodeRecord is the self signed network information of a node, newer record has bigger seq
+type NodeRecord struct {
+	Seq      uint64
+	Host     string //public ip or domain of node, empty if not known yet
+	SyncPort uint16
+	ConsPort uint16
+	PubKey   keypair.PublicKey
+	Sig      []byte
+}
+
+//NewNodeRecord create a record signed by signer
+func NewNodeRecord(signer signature.Signer, seq uint64, host string, syncPort, consPort uint16) (*NodeRecord, error) {
+	record := &NodeRecord{
+		Seq:      seq,
+		Host:     host,
+		SyncPort: syncPort,
+		ConsPort: consPort,
+		PubKey:   signer.PubKey(),
+	}
+	sig, err := signature.Sign(signer, record.signData())
+	if err != nil {
+		return nil, err
+	}
+	record.Sig = sig
+	return record, nil
+}
+
+func (this *NodeRecord) signData() []byte {
+	sink := common.NewZeroCopySink(nil)
+	this.serializeUnsigned(sink)
+	return sink.Bytes()
+}
+
+func (this *NodeRecord) serializeUnsigned(sink *common.ZeroCopySink) {
+	sink.WriteUint64(this.Seq)
+	sink.WriteString(this.Host)
+	sink.WriteUint16(this.SyncPort)
+	sink.WriteUint16(this.ConsPort)
+	sink.WriteVarBytes(keypair.SerializePublicKey(this.PubKey))
+}
+
+//Verify check the record is signed by its key
+func (this *NodeRecord) Verify() error {
+	if this.PubKey == nil {
+		return errors.New("node record without key")
+	}
+	return signature.Verify(this.PubKey, this.signData(), this.Sig)
+}
+
+//ID return the peer id derived from the record key
+func (this *NodeRecord) ID() uint64 {
+	return noise.PeerID(this.PubKey)
+}
+
+func (this *NodeRecord) Serialization(sink *common.ZeroCopySink) {
+	this.serializeUnsigned(sink)
+	sink.WriteVarBytes(this.Sig)
+}
+
+func (this *NodeRecord) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	if this.Seq, eof = source.NextUint64(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Host, eof = source.NextString(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.SyncPort, eof = source.NextUint16(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.ConsPort, eof = source.NextUint16(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	rawKey, eof := source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if this.Sig, eof = source.NextVarBytes(); eof {
+		return io.ErrUnexpectedEOF
+	}
+	pubKey, err := keypair.DeserializePublicKey(rawKey)
+	if err != nil {
+		return err
+	}
+	this.PubKey = pubKey
+	return nil
+}
+
+type NodeRecordsReq struct{}
+
+//Serialize message payload
+func (this NodeRecordsReq) Serialization(sink *common.ZeroCopySink) error {
+	return nil
+}
+
+func (this *NodeRecordsReq) CmdType() string {
+	return comm.GET_NODE_RECORDS_TYPE
+}
+
+//Deserialize message payload
+func (this *NodeRecordsReq) Deserialization(source *common.ZeroCopySource) error {
+	return nil
+}
+
+type NodeRecords struct {
+	Records []*NodeRecord
+}
+
+//Serialize message payload
+func (this NodeRecords) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint64(uint64(len(this.Records)))
+	for _, record := range this.Records {
+		record.Serialization(sink)
+	}
+	return nil
+}
+
+func (this *NodeRecords) CmdType() string {
+	return comm.NODE_RECORDS_TYPE
+}
+
+//Deserialize message payload
+func (this *NodeRecords) Deserialization(source *common.ZeroCopySource) error {
+	count, eof := source.NextUint64()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	if count > comm.MAX_NODE_RECORD_CNT {
+		return errors.New("too many node records")
+	}
+	for i := 0; i < int(count); i++ {
+		record := &NodeRecord{}
+		if err := record.Deserialization(source); err != nil {
+			return err
+		}
+		this.Records = append(this.Records, record)
+	}
+	return nil
+}
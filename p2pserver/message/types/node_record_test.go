@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeRecordsSerializationDeserialization(t *testing.T) {
+	acc := account.NewAccount("")
+	record, err := NewNodeRecord(acc, 1, "192.168.0.1", 20338, 20339)
+	assert.Nil(t, err)
+	assert.Nil(t, record.Verify())
+
+	var msg NodeRecords
+	msg.Records = append(msg.Records, record)
+	MessageTest(t, &msg)
+	MessageTest(t, &NodeRecordsReq{})
+}
+
+func TestNodeRecordVerify(t *testing.T) {
+	acc := account.NewAccount("")
+	record, err := NewNodeRecord(acc, 1, "192.168.0.1", 20338, 20339)
+	assert.Nil(t, err)
+
+	record.Host = "192.168.0.2"
+	assert.NotNil(t, record.Verify())
+
+	other := account.NewAccount("")
+	record, err = NewNodeRecord(acc, 2, "192.168.0.1", 20338, 20339)
+	assert.Nil(t, err)
+	record.PubKey = other.PublicKey
+	assert.NotNil(t, record.Verify())
+}
+
+func TestNodeRecordTruncated(t *testing.T) {
+	acc := account.NewAccount("")
+	record, err := NewNodeRecord(acc, 1, "192.168.0.1", 20338, 20339)
+	assert.Nil(t, err)
+	sink := common.NewZeroCopySink(nil)
+	record.Serialization(sink)
+	raw := sink.Bytes()
+	for i := 0; i < len(raw); i++ {
+		assert.NotNil(t, new(NodeRecord).Deserialization(common.NewZeroCopySource(raw[:i])), "truncated at %d", i)
+	}
+	decoded := new(NodeRecord)
+	assert.Nil(t, decoded.Deserialization(common.NewZeroCopySource(raw)))
+	assert.Nil(t, decoded.Verify())
+}
@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/polynetwork/poly/common"
+	comm "github.com/polynetwork/poly/p2pserver/common"
+)
+
+//ProofReq ask a full peer for a merkle or cross states proof
+type ProofReq struct {
+	Type       uint8  //comm.MERKLE_PROOF or comm.CROSS_STATES_PROOF
+	Height     uint32 //proof height of merkle proof, or height of cross states
+	RootHeight uint32 //root height of merkle proof
+	Key        []byte //storage key of cross states proof
+}
+
+//Serialize message payload
+func (this ProofReq) Serialization(sink *common.ZeroCopySink) error {
+	sink.WriteUint8(this.Type)
+	sink.WriteUint32(this.Height)
+	sink.WriteUint32(this.RootHeight)
+	sink.WriteVarBytes(this.Key)
+	return nil
+}
+
+func (this *ProofReq) CmdType() string {
+	return comm.GET_PROOF_TYPE
+}
+
+//Deserialize message payload
+func (this *ProofReq) Deserialization(source *common.ZeroCopySource) error {
+	var eof bool
+	this.Type, eof = source.NextUint8()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Height, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.RootHeight, eof = source.NextUint32()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	this.Key, eof = source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+//ReqID identify the request, which is echoed back by the proof
+func (this *ProofReq) ReqID() string {
+	return fmt.Sprintf("%d:%d:%d:%x", this.Type, this.Height, this.RootHeight, this.Key)
+}
+
+//Proof answer the ProofReq
+type Proof struct {
+	Req   ProofReq
+	Proof []byte //empty if peer cannot build the proof
+}
+
+//Serialize message payload
+func (this Proof) Serialization(sink *common.ZeroCopySink) error {
+	this.Req.Serialization(sink)
+	sink.WriteVarBytes(this.Proof)
+	return nil
+}
+
+func (this *Proof) CmdType() string {
+	return comm.PROOF_TYPE
+}
+
+//Deserialize message payload
+func (this *Proof) Deserialization(source *common.ZeroCopySource) error {
+	err := this.Req.Deserialization(source)
+	if err != nil {
+		return err
+	}
+	var eof bool
+	this.Proof, eof = source.NextVarBytes()
+	if eof {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
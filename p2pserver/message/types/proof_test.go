@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package types
+
+import (
+	"testing"
+
+	comm "github.com/polynetwork/poly/p2pserver/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofSerializationDeserialization(t *testing.T) {
+	req := &ProofReq{
+		Type:   comm.CROSS_STATES_PROOF,
+		Height: 100,
+		Key:    []byte("key"),
+	}
+	MessageTest(t, req)
+	MessageTest(t, &Proof{Req: *req, Proof: []byte("proof")})
+	MessageTest(t, &Proof{Req: ProofReq{Type: comm.MERKLE_PROOF, Height: 1, RootHeight: 2, Key: []byte{}}, Proof: []byte{}})
+}
+
+func TestProofReqID(t *testing.T) {
+	req := ProofReq{Type: comm.MERKLE_PROOF, Height: 1, RootHeight: 2}
+	other := req
+	other.RootHeight = 3
+	assert.NotEqual(t, req.ReqID(), other.ReqID())
+	proof := Proof{Req: req}
+	assert.Equal(t, req.ReqID(), proof.Req.ReqID())
+}
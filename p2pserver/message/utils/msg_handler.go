@@ -27,6 +27,7 @@ import (
 	"time"
 
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/ontio/ontology-crypto/keypair"
 	evtActor "github.com/ontio/ontology-eventbus/actor"
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
@@ -37,6 +38,7 @@ import (
 	msgCommon "github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
 	msgTypes "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/polynetwork/poly/p2pserver/net/noise"
 	"github.com/polynetwork/poly/p2pserver/net/protocol"
 )
 
@@ -55,8 +57,9 @@ func AddrReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 	var addrStr []msgCommon.PeerAddr
 	addrStr = p2p.GetNeighborAddrs()
 	//check mask peers
-	mskPeers := config.DefConfig.P2PNode.ReservedCfg.MaskPeers
-	if config.DefConfig.P2PNode.ReservedPeersOnly && len(mskPeers) > 0 {
+	rsvOnly, rsvCfg := config.DefConfig.GetReservedCfg()
+	mskPeers := rsvCfg.MaskPeers
+	if rsvOnly && len(mskPeers) > 0 {
 		for i := 0; i < len(addrStr); i++ {
 			var ip net.IP
 			ip = addrStr[i].IpAddr[:]
@@ -222,9 +225,26 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 	}
 	nodeAddr := addrIp + ":" +
 		strconv.Itoa(int(version.P.SyncPort))
-	if config.DefConfig.P2PNode.ReservedPeersOnly && len(config.DefConfig.P2PNode.ReservedCfg.ReservedPeers) > 0 {
+	link := remotePeer.SyncLink
+	if version.P.IsConsensus {
+		link = remotePeer.ConsLink
+	}
+	if p2p.GetBanList().IsBanned(version.P.Nonce) {
+		log.Debugf("[p2p]peer %d of %s is banned, close", version.P.Nonce, data.Addr)
+		link.CloseConn()
+		p2p.RemoveFromConnectingList(data.Addr)
+		return
+	}
+	if !checkNoiseID(link.GetConn(), version.P.Nonce) {
+		log.Warnf("[p2p]peer id %d not derived from the key of %s, close", version.P.Nonce, data.Addr)
+		link.CloseConn()
+		p2p.RemoveFromConnectingList(data.Addr)
+		return
+	}
+	rsvOnly, rsvCfg := config.DefConfig.GetReservedCfg()
+	if rsvOnly && len(rsvCfg.ReservedPeers) > 0 && !noise.ReservedKeysPinned() {
 		found := false
-		for _, addr := range config.DefConfig.P2PNode.ReservedCfg.ReservedPeers {
+		for _, addr := range rsvCfg.ReservedPeers {
 			if strings.HasPrefix(data.Addr, addr) {
 				log.Debug("[p2p]peer in reserved list", data.Addr)
 				found = true
@@ -283,6 +303,7 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 			version.P.Services, version.P.SyncPort,
 			version.P.ConsPort, version.P.Nonce,
 			version.P.Relay, version.P.StartHeight, version.P.SoftVersion)
+		remotePeer.ConsLink.SetCompression(msgTypes.NegotiateCompression(version.P.Cap[msgCommon.COMPRESS_FLAG]))
 
 		var msg msgTypes.Message
 		if s == msgCommon.INIT {
@@ -364,6 +385,7 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 			version.P.ConsPort, version.P.Nonce,
 			version.P.Relay, version.P.StartHeight, version.P.SoftVersion)
 		remotePeer.SyncLink.SetID(version.P.Nonce)
+		remotePeer.SyncLink.SetCompression(msgTypes.NegotiateCompression(version.P.Cap[msgCommon.COMPRESS_FLAG]))
 		p2p.AddNbrNode(remotePeer)
 
 		if pid != nil {
@@ -389,6 +411,15 @@ func VersionHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, ar
 	}
 }
 
+//checkNoiseID checks the peer id is derived from the key authenticated by noise handshake
+func checkNoiseID(conn net.Conn, id uint64) bool {
+	if !config.DefConfig.P2PNode.IsNoise {
+		return true
+	}
+	secured, ok := conn.(*noise.Conn)
+	return ok && secured.RemoteID() == id
+}
+
 // VerAckHandle handles the version ack from peer
 func VerAckHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	log.Trace("[p2p]receive verAck message from ", data.Addr, data.Id)
@@ -489,6 +520,103 @@ func AddrHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args
 	}
 }
 
+// NodeRecordsReqHandle handles the node records request from peer
+func NodeRecordsReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive node records request message", data.Addr, data.Id)
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debug("[p2p]remotePeer invalid in NodeRecordsReqHandle")
+		return
+	}
+
+	records := make([]*msgTypes.NodeRecord, 0, msgCommon.MAX_NODE_RECORD_CNT)
+	if own := p2p.GetNodeRecord(); own != nil {
+		records = append(records, own)
+	}
+	records = append(records, p2p.GetPeerDB().GetRecords(msgCommon.MAX_NODE_RECORD_CNT-len(records))...)
+	msg := msgpack.NewNodeRecords(records)
+	err := p2p.Send(remotePeer, msg, false)
+	if err != nil {
+		log.Warn(err)
+	}
+}
+
+// NodeRecordsHandle handles the signed node records from peer
+func NodeRecordsHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]handle node records message", data.Addr, data.Id)
+
+	var msg = data.Payload.(*msgTypes.NodeRecords)
+	own := p2p.GetNodeRecord()
+	for _, record := range msg.Records {
+		if own != nil && keypair.ComparePublicKey(own.PubKey, record.PubKey) {
+			continue
+		}
+		//the observed address of the sender is trusted only when noise proves it owns the record
+		host := ""
+		if config.DefConfig.P2PNode.IsNoise && record.ID() == data.Id {
+			host, _ = msgCommon.ParseIPAddr(data.Addr)
+		}
+		if _, err := p2p.GetPeerDB().Add(record, host); err != nil {
+			log.Warnf("[p2p]drop node record from %s: %s", data.Addr, err)
+		}
+	}
+}
+
+// ProofReqHandle handles the proof request from peer, answer an empty proof if cannot build it
+func ProofReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive proof request message", data.Addr, data.Id)
+
+	var req = data.Payload.(*msgTypes.ProofReq)
+	remotePeer := p2p.GetPeer(data.Id)
+	if remotePeer == nil {
+		log.Debug("[p2p]remotePeer invalid in ProofReqHandle")
+		return
+	}
+	var proof []byte
+	var err error
+	//light node fetches proofs itself, so it has none to serve
+	if config.DefConfig.Common.LightNode {
+		err = fmt.Errorf("light node")
+	} else {
+		switch req.Type {
+		case msgCommon.MERKLE_PROOF:
+			proof, err = ledger.DefLedger.GetMerkleProof(req.Height, req.RootHeight)
+		case msgCommon.CROSS_STATES_PROOF:
+			proof, err = ledger.DefLedger.GetCrossStatesProof(req.Height, req.Key)
+		default:
+			err = fmt.Errorf("unknown proof type %d", req.Type)
+		}
+	}
+	if err != nil {
+		log.Debugf("[p2p]can't get proof %s: %s", req.ReqID(), err)
+		proof = nil
+	}
+	err = p2p.Send(remotePeer, msgpack.NewProof(req, proof), false)
+	if err != nil {
+		log.Warn(err)
+	}
+}
+
+// ProofHandle handles the proof answered by peer
+func ProofHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]receive proof message", data.Addr, data.Id)
+
+	if pid != nil {
+		var proof = data.Payload.(*msgTypes.Proof)
+		input := &msgCommon.AppendProof{
+			FromID: data.Id,
+			ReqID:  proof.Req.ReqID(),
+			Proof:  proof.Proof,
+		}
+		pid.Tell(input)
+	}
+}
+
+// LightDropHandle drops the block, transaction and consensus messages light node has no use for
+func LightDropHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
+	log.Trace("[p2p]light node drop message", data.Payload.CmdType(), data.Addr, data.Id)
+}
+
 // DataReqHandle handles the data req(block/Transaction) from peer
 func DataReqHandle(data *msgTypes.MsgPayload, p2p p2p.P2P, pid *evtActor.PID, args ...interface{}) {
 	log.Trace("[p2p]receive data req message", data.Addr, data.Id)
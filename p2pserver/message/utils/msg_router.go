@@ -20,6 +20,7 @@ package utils
 
 import (
 	"github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	msgCommon "github.com/polynetwork/poly/p2pserver/common"
 	"github.com/polynetwork/poly/p2pserver/message/types"
@@ -73,6 +74,18 @@ func (this *MessageRouter) init(p2p p2p.P2P) {
 	this.RegisterMsgHandler(msgCommon.NOT_FOUND_TYPE, NotFoundHandle)
 	this.RegisterMsgHandler(msgCommon.TX_TYPE, TransactionHandle)
 	this.RegisterMsgHandler(msgCommon.DISCONNECT_TYPE, DisconnectHandle)
+	this.RegisterMsgHandler(msgCommon.GET_NODE_RECORDS_TYPE, NodeRecordsReqHandle)
+	this.RegisterMsgHandler(msgCommon.NODE_RECORDS_TYPE, NodeRecordsHandle)
+	this.RegisterMsgHandler(msgCommon.GET_PROOF_TYPE, ProofReqHandle)
+	this.RegisterMsgHandler(msgCommon.PROOF_TYPE, ProofHandle)
+
+	//light node neither executes blocks nor pools transactions
+	if config.DefConfig.Common.LightNode {
+		this.RegisterMsgHandler(msgCommon.INV_TYPE, LightDropHandle)
+		this.RegisterMsgHandler(msgCommon.BLOCK_TYPE, LightDropHandle)
+		this.RegisterMsgHandler(msgCommon.CONSENSUS_TYPE, LightDropHandle)
+		this.RegisterMsgHandler(msgCommon.TX_TYPE, LightDropHandle)
+	}
 }
 
 // RegisterMsgHandler registers msg handler with the msg type
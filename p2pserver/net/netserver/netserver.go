@@ -19,28 +19,40 @@
 package netserver
 
 import (
+	"encoding/binary"
 	"errors"
 	"math/rand"
 	"net"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/polynetwork/poly/account"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/link"
 	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
 	"github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/polynetwork/poly/p2pserver/net/noise"
 	"github.com/polynetwork/poly/p2pserver/net/protocol"
 	"github.com/polynetwork/poly/p2pserver/peer"
 )
 
 //NewNetServer return the net object in p2p
 func NewNetServer() p2p.P2P {
+	return NewNetServerWithAccount(nil)
+}
+
+//NewNetServerWithAccount return the net object in p2p, links are authenticated
+//with the account key when noise is enabled
+func NewNetServerWithAccount(acc *account.Account) p2p.P2P {
 	n := &NetServer{
 		SyncChan: make(chan *types.MsgPayload, common.CHAN_CAPABILITY),
 		ConsChan: make(chan *types.MsgPayload, common.CHAN_CAPABILITY),
+		account:  acc,
 	}
 
 	n.PeerAddrMap.PeerSyncAddress = make(map[string]*peer.Peer)
@@ -63,7 +75,12 @@ type NetServer struct {
 	connectLock   sync.Mutex
 	inConnRecord  InConnectionRecord
 	outConnRecord OutConnectionRecord
-	OwnAddress    string //network`s own address(ip : sync port),which get from version check
+	OwnAddress    string           //network`s own address(ip : sync port),which get from version check
+	account       *account.Account //node key to authenticate links with noise
+	banList       *peer.BanList
+	peerDB        *peer.PeerDB
+	record        *types.NodeRecord //self signed node record
+	recordLock    sync.RWMutex
 }
 
 //InConnectionRecord include all addr connected
@@ -95,7 +112,9 @@ type PeerAddrMap struct {
 func (this *NetServer) init() error {
 	this.base.SetVersion(common.PROTOCOL_VERSION)
 
-	if config.DefConfig.Consensus.EnableConsensus {
+	if config.DefConfig.Common.LightNode {
+		this.base.SetServices(uint64(common.LIGHT_NODE))
+	} else if config.DefConfig.Consensus.EnableConsensus {
 		this.base.SetServices(uint64(common.VERIFY_NODE))
 	} else {
 		this.base.SetServices(uint64(common.SERVICE_NODE))
@@ -119,16 +138,31 @@ func (this *NetServer) init() error {
 		this.base.SetConsPort(0)
 	}
 
-	this.base.SetRelay(true)
+	//light node does not take blocks and transactions broadcast
+	this.base.SetRelay(!config.DefConfig.Common.LightNode)
+	link.SetGlobalRateLimit(config.DefConfig.P2PNode.MaxIngressRate, config.DefConfig.P2PNode.MaxEgressRate)
 
 	rand.Seed(time.Now().UnixNano())
 	id := rand.Uint64()
+	if this.account == nil {
+		//node without account authenticates and signs its record with a temporary key
+		this.account = account.NewAccount("")
+	}
+	if config.DefConfig.P2PNode.IsNoise {
+		id = noise.PeerID(this.account.PublicKey)
+	}
 
 	this.base.SetID(id)
 
 	log.Infof("[p2p]init peer ID to %d", this.base.GetID())
 	this.Np = &peer.NbrPeers{}
 	this.Np.Init()
+	this.banList = peer.NewBanList(dataFilePath(common.BAN_FILE_NAME))
+	this.peerDB = peer.NewPeerDB(dataFilePath(common.PEER_DB_FILE_NAME))
+	if err := this.updateNodeRecord(""); err != nil {
+		log.Errorf("[p2p]sign node record fail: %s", err)
+		return err
+	}
 
 	return nil
 }
@@ -199,6 +233,44 @@ func (this *NetServer) GetNp() *peer.NbrPeers {
 	return this.Np
 }
 
+//dataFilePath return the path of p2p data file, e.g. ban list and peer db, in the data dir of the network
+func dataFilePath(name string) string {
+	return filepath.Join(config.DefConfig.Common.DataDir, config.DefConfig.P2PNode.NetworkName, name)
+}
+
+//GetBanList return the banned peers
+func (this *NetServer) GetBanList() *peer.BanList {
+	return this.banList
+}
+
+//GetPeerDB return the node records learned
+func (this *NetServer) GetPeerDB() *peer.PeerDB {
+	return this.peerDB
+}
+
+//GetNodeRecord return the self signed node record
+func (this *NetServer) GetNodeRecord() *types.NodeRecord {
+	this.recordLock.RLock()
+	defer this.recordLock.RUnlock()
+	return this.record
+}
+
+//updateNodeRecord resign the node record with host and a newer seq
+func (this *NetServer) updateNodeRecord(host string) error {
+	this.recordLock.Lock()
+	defer this.recordLock.Unlock()
+	seq := uint64(time.Now().UnixNano())
+	if this.record != nil && seq <= this.record.Seq {
+		seq = this.record.Seq + 1
+	}
+	record, err := types.NewNodeRecord(this.account, seq, host, this.base.GetSyncPort(), this.base.GetConsPort())
+	if err != nil {
+		return err
+	}
+	this.record = record
+	return nil
+}
+
 //GetNeighborAddrs return all the nbr peer`s addr
 func (this *NetServer) GetNeighborAddrs() []common.PeerAddr {
 	return this.Np.GetNeighborAddrs()
@@ -275,12 +347,16 @@ func (this *NetServer) Connect(addr string, isConsensus bool) error {
 	if !this.AddrValid(addr) {
 		return nil
 	}
+	if ip, err := common.ParseIPAddr(addr); err == nil && this.banList.IsIPBanned(ip) {
+		log.Debugf("[p2p]address %s is banned", addr)
+		return nil
+	}
 
 	this.connectLock.Lock()
 	connCount := uint(this.GetOutConnRecordLen())
-	if connCount >= config.DefConfig.P2PNode.MaxConnOutBound {
-		log.Warnf("[p2p]Connect: out connections(%d) reach the max limit(%d)", connCount,
-			config.DefConfig.P2PNode.MaxConnOutBound)
+	_, maxOutBound, _ := config.DefConfig.GetMaxConnLimits()
+	if connCount >= maxOutBound {
+		log.Warnf("[p2p]Connect: out connections(%d) reach the max limit(%d)", connCount, maxOutBound)
 		this.connectLock.Unlock()
 		return errors.New("[p2p]connect: out connections reach the max limit")
 	}
@@ -314,6 +390,14 @@ func (this *NetServer) Connect(addr string, isConsensus bool) error {
 			return err
 		}
 	}
+	if config.DefConfig.P2PNode.IsNoise {
+		conn, err = this.noiseHandshake(conn, true)
+		if err != nil {
+			this.RemoveFromConnectingList(addr)
+			log.Warnf("[p2p]connect %s failed:%s", addr, err.Error())
+			return err
+		}
+	}
 
 	addr = conn.RemoteAddr().String()
 	log.Debugf("[p2p]peer %s connect with %s with %s",
@@ -452,10 +536,11 @@ func (this *NetServer) startSyncAccept(listener net.Listener) {
 			continue
 		}
 
+		maxInBound, _, maxInBoundForSingleIP := config.DefConfig.GetMaxConnLimits()
 		syncAddrCount := uint(this.GetInConnRecordLen())
-		if syncAddrCount >= config.DefConfig.P2PNode.MaxConnInBound {
+		if syncAddrCount >= maxInBound {
 			log.Warnf("[p2p]SyncAccept: total connections(%d) reach the max limit(%d), conn closed",
-				syncAddrCount, config.DefConfig.P2PNode.MaxConnInBound)
+				syncAddrCount, maxInBound)
 			conn.Close()
 			continue
 		}
@@ -466,27 +551,50 @@ func (this *NetServer) startSyncAccept(listener net.Listener) {
 			conn.Close()
 			continue
 		}
+		if this.banList.IsIPBanned(remoteIp) {
+			log.Debugf("[p2p]remote %s is banned, close it", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
 		connNum := this.GetIpCountInInConnRecord(remoteIp)
-		if connNum >= config.DefConfig.P2PNode.MaxConnInBoundForSingleIP {
+		if connNum >= maxInBoundForSingleIP {
 			log.Warnf("[p2p]SyncAccept: connections(%d) with ip(%s) has reach the max limit(%d), "+
-				"conn closed", connNum, remoteIp, config.DefConfig.P2PNode.MaxConnInBoundForSingleIP)
+				"conn closed", connNum, remoteIp, maxInBoundForSingleIP)
 			conn.Close()
 			continue
 		}
 
-		remotePeer := peer.NewPeer()
 		addr := conn.RemoteAddr().String()
 		this.AddInConnRecord(addr)
 
-		this.AddPeerSyncAddress(addr, remotePeer)
-
-		remotePeer.SyncLink.SetAddr(addr)
-		remotePeer.SyncLink.SetConn(conn)
-		remotePeer.AttachSyncChan(this.SyncChan)
-		go remotePeer.SyncLink.Rx()
+		if config.DefConfig.P2PNode.IsNoise {
+			//handshake out of accept loop, a slow peer should not block others
+			go func(conn net.Conn) {
+				secured, err := this.noiseHandshake(conn, false)
+				if err != nil {
+					log.Warnf("[p2p]accept sync %s failed:%s", addr, err.Error())
+					this.RemoveFromInConnRecord(addr)
+					return
+				}
+				this.addSyncPeer(secured, addr)
+			}(conn)
+			continue
+		}
+		this.addSyncPeer(conn, addr)
 	}
 }
 
+//addSyncPeer adds the inbound sync connection as a new peer
+func (this *NetServer) addSyncPeer(conn net.Conn, addr string) {
+	remotePeer := peer.NewPeer()
+	this.AddPeerSyncAddress(addr, remotePeer)
+
+	remotePeer.SyncLink.SetAddr(addr)
+	remotePeer.SyncLink.SetConn(conn)
+	remotePeer.AttachSyncChan(this.SyncChan)
+	go remotePeer.SyncLink.Rx()
+}
+
 //startConsAccept accepts the consensus connnection from the inbound peer
 func (this *NetServer) startConsAccept(listener net.Listener) {
 	for {
@@ -509,20 +617,60 @@ func (this *NetServer) startConsAccept(listener net.Listener) {
 			conn.Close()
 			continue
 		}
-		if !this.IsIPInInConnRecord(remoteIp) {
+		if !this.IsIPInInConnRecord(remoteIp) || this.banList.IsIPBanned(remoteIp) {
 			conn.Close()
 			continue
 		}
 
-		remotePeer := peer.NewPeer()
 		addr := conn.RemoteAddr().String()
-		this.AddPeerConsAddress(addr, remotePeer)
+		if config.DefConfig.P2PNode.IsNoise {
+			go func(conn net.Conn) {
+				secured, err := this.noiseHandshake(conn, false)
+				if err != nil {
+					log.Warnf("[p2p]accept cons %s failed:%s", addr, err.Error())
+					return
+				}
+				this.addConsPeer(secured, addr)
+			}(conn)
+			continue
+		}
+		this.addConsPeer(conn, addr)
+	}
+}
 
-		remotePeer.ConsLink.SetAddr(addr)
-		remotePeer.ConsLink.SetConn(conn)
-		remotePeer.AttachConsChan(this.ConsChan)
-		go remotePeer.ConsLink.Rx()
+//addConsPeer adds the inbound consensus connection as a new peer
+func (this *NetServer) addConsPeer(conn net.Conn, addr string) {
+	remotePeer := peer.NewPeer()
+	this.AddPeerConsAddress(addr, remotePeer)
+
+	remotePeer.ConsLink.SetAddr(addr)
+	remotePeer.ConsLink.SetConn(conn)
+	remotePeer.AttachConsChan(this.ConsChan)
+	go remotePeer.ConsLink.Rx()
+}
+
+//noiseHandshake secures the connection with noise handshake, the connection is
+//closed if the handshake fails or the peer key is not reserved
+func (this *NetServer) noiseHandshake(conn net.Conn, initiator bool) (net.Conn, error) {
+	prologue := make([]byte, 4)
+	binary.LittleEndian.PutUint32(prologue, config.DefConfig.P2PNode.NetworkMagic)
+
+	var secured *noise.Conn
+	var err error
+	if initiator {
+		secured, err = noise.Client(conn, this.account, prologue)
+	} else {
+		secured, err = noise.Server(conn, this.account, prologue)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if noise.ReservedKeysPinned() && !noise.IsReservedKey(secured.RemoteKey()) {
+		conn.Close()
+		return nil, errors.New("[p2p]peer key not in reserved list")
 	}
+	return secured, nil
 }
 
 //record the peer which is going to be dialed and sent version message but not in establish state
@@ -776,8 +924,13 @@ func (this *NetServer) GetOutConnRecordLen() int {
 
 //AddrValid whether the addr could be connect or accept
 func (this *NetServer) AddrValid(addr string) bool {
-	if config.DefConfig.P2PNode.ReservedPeersOnly && len(config.DefConfig.P2PNode.ReservedCfg.ReservedPeers) > 0 {
-		for _, ip := range config.DefConfig.P2PNode.ReservedCfg.ReservedPeers {
+	if noise.ReservedKeysPinned() {
+		//peers are checked by key after handshake
+		return true
+	}
+	rsvOnly, rsvCfg := config.DefConfig.GetReservedCfg()
+	if rsvOnly && len(rsvCfg.ReservedPeers) > 0 {
+		for _, ip := range rsvCfg.ReservedPeers {
 			if strings.HasPrefix(addr, ip) {
 				log.Info("[p2p]found reserved peer :", addr)
 				return true
@@ -801,6 +954,11 @@ func (this *NetServer) SetOwnAddress(addr string) {
 	if addr != this.OwnAddress {
 		log.Infof("[p2p]set own address %s", addr)
 		this.OwnAddress = addr
+		if host, err := common.ParseIPAddr(addr); err == nil {
+			if err := this.updateNodeRecord(host); err != nil {
+				log.Warnf("[p2p]sign node record fail: %s", err)
+			}
+		}
 	}
 
 }
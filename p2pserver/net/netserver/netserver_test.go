@@ -19,12 +19,18 @@
 package netserver
 
 import (
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/net/noise"
 	"github.com/polynetwork/poly/p2pserver/peer"
 )
 
@@ -126,3 +132,73 @@ func TestNetServerNbrPeer(t *testing.T) {
 	}
 
 }
+
+func startNoiseServer(t *testing.T, reservedKeys []string) (*NetServer, *account.Account) {
+	p2pCfg := *config.DefConfig.P2PNode
+	t.Cleanup(func() { config.DefConfig.P2PNode = &p2pCfg })
+	config.DefConfig.P2PNode.IsNoise = true
+	config.DefConfig.P2PNode.NodePort = 20438
+	config.DefConfig.P2PNode.NodeConsensusPort = 20439
+	config.DefConfig.P2PNode.ReservedPeersOnly = len(reservedKeys) > 0
+	config.DefConfig.P2PNode.ReservedCfg = &config.P2PRsvConfig{ReservedKeys: reservedKeys}
+
+	acc := account.NewAccount("")
+	server := NewNetServerWithAccount(acc).(*NetServer)
+	server.Start()
+	t.Cleanup(server.Halt)
+	return server, acc
+}
+
+func dialNoise(t *testing.T) (*account.Account, *noise.Conn) {
+	acc := account.NewAccount("")
+	conn, err := nonTLSDial("127.0.0.1:20438")
+	if err != nil {
+		t.Fatalf("dial: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	prologue := make([]byte, 4)
+	binary.LittleEndian.PutUint32(prologue, config.DefConfig.P2PNode.NetworkMagic)
+	secured, err := noise.Client(conn, acc, prologue)
+	if err != nil {
+		t.Fatalf("handshake: %s", err)
+	}
+	return acc, secured
+}
+
+func TestNoiseAccept(t *testing.T) {
+	server, acc := startNoiseServer(t, nil)
+	if server.GetID() != noise.PeerID(acc.PublicKey) {
+		t.Fatal("peer id not derived from key")
+	}
+	clientAcc, conn := dialNoise(t)
+	if conn.RemoteID() != server.GetID() {
+		t.Fatal("server id not authenticated")
+	}
+
+	var p *peer.Peer
+	for i := 0; i < 100 && p == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+		p = server.GetPeerFromAddr(conn.LocalAddr().String())
+	}
+	if p == nil {
+		t.Fatal("peer not accepted")
+	}
+	secured, ok := p.SyncLink.GetConn().(*noise.Conn)
+	if !ok || secured.RemoteID() != noise.PeerID(clientAcc.PublicKey) {
+		t.Fatal("client id not authenticated")
+	}
+}
+
+func TestNoiseReservedKeys(t *testing.T) {
+	reserved := hex.EncodeToString(keypair.SerializePublicKey(account.NewAccount("").PublicKey))
+	server, _ := startNoiseServer(t, []string{reserved})
+	_, conn := dialNoise(t)
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("peer with unreserved key not rejected")
+	}
+	if server.GetPeerFromAddr(conn.LocalAddr().String()) != nil {
+		t.Fatal("peer with unreserved key accepted")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package noise
+
+import (
+	"net"
+	"sync"
+
+	"github.com/ontio/ontology-crypto/keypair"
+)
+
+// Conn is a connection secured by noise handshake, messages are encrypted and
+// framed with a 2 bytes length prefix
+type Conn struct {
+	net.Conn
+	remoteKey keypair.PublicKey
+
+	readLock sync.Mutex
+	recv     *cipherState
+	pending  []byte // decrypted bytes not read yet
+
+	writeLock sync.Mutex
+	send      *cipherState
+}
+
+func newConn(conn net.Conn, send, recv *cipherState, remoteKey keypair.PublicKey) *Conn {
+	return &Conn{
+		Conn:      conn,
+		remoteKey: remoteKey,
+		recv:      recv,
+		send:      send,
+	}
+}
+
+// RemoteKey returns the node key authenticated by handshake
+func (this *Conn) RemoteKey() keypair.PublicKey {
+	return this.remoteKey
+}
+
+// RemoteID returns the peer id derived from the remote node key
+func (this *Conn) RemoteID() uint64 {
+	return PeerID(this.remoteKey)
+}
+
+func (this *Conn) Read(b []byte) (int, error) {
+	this.readLock.Lock()
+	defer this.readLock.Unlock()
+
+	for len(this.pending) == 0 {
+		msg, err := readFrame(this.Conn)
+		if err != nil {
+			return 0, err
+		}
+		this.pending, err = this.recv.decrypt(nil, msg)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n := copy(b, this.pending)
+	this.pending = this.pending[n:]
+	return n, nil
+}
+
+func (this *Conn) Write(b []byte) (int, error) {
+	this.writeLock.Lock()
+	defer this.writeLock.Unlock()
+
+	written := 0
+	for written < len(b) {
+		end := written + MAX_PLAIN_LEN
+		if end > len(b) {
+			end = len(b)
+		}
+		msg, err := this.send.encrypt(nil, b[written:end])
+		if err != nil {
+			return written, err
+		}
+		if err := writeFrame(this.Conn, msg); err != nil {
+			return written, err
+		}
+		written = end
+	}
+	return written, nil
+}
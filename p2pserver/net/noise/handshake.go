@@ -0,0 +1,493 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package noise implements an authenticated and encrypted transport for p2p links with
+// the Noise_XX_25519_ChaChaPoly_SHA256 handshake. Both sides prove their identity by
+// signing their static noise key with their node key, the peer id of a node is derived
+// from its node key.
+package noise
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/core/signature"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+const (
+	PROTOCOL_NAME     = "Noise_XX_25519_ChaChaPoly_SHA256"
+	STATIC_KEY_PREFIX = "poly-noise-static-key:" // prefix of the static key signed by node key
+	HANDSHAKE_TIMEOUT = 10 * time.Second
+
+	DH_LEN        = 32
+	HASH_LEN      = 32
+	TAG_LEN       = 16
+	MAX_MSG_LEN   = 65535 // maximum length of a noise message
+	MAX_PLAIN_LEN = MAX_MSG_LEN - TAG_LEN
+	LEN_PREFIX    = 2 // bytes of the length prefix of framed noise messages
+)
+
+var (
+	ErrNonceOverflow = errors.New("noise nonce overflow")
+	ErrMsgTooLong    = errors.New("noise message too long")
+)
+
+// PeerID returns the p2p peer id of node key
+func PeerID(pubKey keypair.PublicKey) uint64 {
+	h := sha256.Sum256(keypair.SerializePublicKey(pubKey))
+	return binary.LittleEndian.Uint64(h[:8])
+}
+
+type cipherState struct {
+	key    [32]byte
+	hasKey bool
+	nonce  uint64
+}
+
+func (this *cipherState) initializeKey(key []byte) {
+	copy(this.key[:], key)
+	this.hasKey = true
+	this.nonce = 0
+}
+
+func (this *cipherState) nonceBytes() []byte {
+	var nonce [chacha20poly1305.NonceSize]byte
+	binary.LittleEndian.PutUint64(nonce[4:], this.nonce)
+	return nonce[:]
+}
+
+func (this *cipherState) encrypt(ad, plaintext []byte) ([]byte, error) {
+	if !this.hasKey {
+		return append([]byte{}, plaintext...), nil
+	}
+	if this.nonce == ^uint64(0) {
+		return nil, ErrNonceOverflow
+	}
+	aead, err := chacha20poly1305.New(this.key[:])
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := aead.Seal(nil, this.nonceBytes(), plaintext, ad)
+	this.nonce++
+	return ciphertext, nil
+}
+
+func (this *cipherState) decrypt(ad, ciphertext []byte) ([]byte, error) {
+	if !this.hasKey {
+		return append([]byte{}, ciphertext...), nil
+	}
+	if this.nonce == ^uint64(0) {
+		return nil, ErrNonceOverflow
+	}
+	aead, err := chacha20poly1305.New(this.key[:])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, this.nonceBytes(), ciphertext, ad)
+	if err != nil {
+		return nil, err
+	}
+	this.nonce++
+	return plaintext, nil
+}
+
+type symmetricState struct {
+	cs cipherState
+	ck [HASH_LEN]byte
+	h  [HASH_LEN]byte
+}
+
+func newSymmetricState(prologue []byte) *symmetricState {
+	this := &symmetricState{}
+	if len(PROTOCOL_NAME) <= HASH_LEN {
+		copy(this.h[:], PROTOCOL_NAME)
+	} else {
+		this.h = sha256.Sum256([]byte(PROTOCOL_NAME))
+	}
+	this.ck = this.h
+	this.mixHash(prologue)
+	return this
+}
+
+func (this *symmetricState) mixKey(ikm []byte) {
+	ck, key := hkdf(this.ck[:], ikm)
+	this.ck = ck
+	this.cs.initializeKey(key[:])
+}
+
+func (this *symmetricState) mixHash(data []byte) {
+	h := sha256.New()
+	h.Write(this.h[:])
+	h.Write(data)
+	copy(this.h[:], h.Sum(nil))
+}
+
+func (this *symmetricState) encryptAndHash(plaintext []byte) ([]byte, error) {
+	ciphertext, err := this.cs.encrypt(this.h[:], plaintext)
+	if err != nil {
+		return nil, err
+	}
+	this.mixHash(ciphertext)
+	return ciphertext, nil
+}
+
+func (this *symmetricState) decryptAndHash(ciphertext []byte) ([]byte, error) {
+	plaintext, err := this.cs.decrypt(this.h[:], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	this.mixHash(ciphertext)
+	return plaintext, nil
+}
+
+func (this *symmetricState) split() (*cipherState, *cipherState) {
+	k1, k2 := hkdf(this.ck[:], nil)
+	c1, c2 := &cipherState{}, &cipherState{}
+	c1.initializeKey(k1[:])
+	c2.initializeKey(k2[:])
+	return c1, c2
+}
+
+// hkdf derives two outputs from chaining key and input key material as defined by noise
+func hkdf(ck, ikm []byte) (out1, out2 [HASH_LEN]byte) {
+	mac := hmac.New(sha256.New, ck)
+	mac.Write(ikm)
+	tempKey := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, tempKey)
+	mac.Write([]byte{1})
+	copy(out1[:], mac.Sum(nil))
+
+	mac = hmac.New(sha256.New, tempKey)
+	mac.Write(out1[:])
+	mac.Write([]byte{2})
+	copy(out2[:], mac.Sum(nil))
+	return
+}
+
+type dhKey struct {
+	priv [DH_LEN]byte
+	pub  [DH_LEN]byte
+}
+
+func newDHKey() (*dhKey, error) {
+	key := &dhKey{}
+	if _, err := io.ReadFull(rand.Reader, key.priv[:]); err != nil {
+		return nil, err
+	}
+	pub, err := curve25519.X25519(key.priv[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	copy(key.pub[:], pub)
+	return key, nil
+}
+
+func (this *dhKey) dh(pub []byte) ([]byte, error) {
+	return curve25519.X25519(this.priv[:], pub)
+}
+
+// handshakeState runs the XX pattern: -> e; <- e, ee, s, es; -> s, se
+type handshakeState struct {
+	ss        *symmetricState
+	signer    signature.Signer
+	initiator bool
+	s         *dhKey
+	e         *dhKey
+	rs        []byte
+	re        []byte
+	remoteKey keypair.PublicKey
+}
+
+func newHandshakeState(signer signature.Signer, initiator bool, prologue []byte) (*handshakeState, error) {
+	s, err := newDHKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate static key: %s", err)
+	}
+	e, err := newDHKey()
+	if err != nil {
+		return nil, fmt.Errorf("generate ephemeral key: %s", err)
+	}
+	return &handshakeState{
+		ss:        newSymmetricState(prologue),
+		signer:    signer,
+		initiator: initiator,
+		s:         s,
+		e:         e,
+	}, nil
+}
+
+func (this *handshakeState) mixDH(key *dhKey, pub []byte) error {
+	secret, err := key.dh(pub)
+	if err != nil {
+		return err
+	}
+	this.ss.mixKey(secret)
+	return nil
+}
+
+// identityPayload returns the node key and its signature of the static key
+func (this *handshakeState) identityPayload() ([]byte, error) {
+	sig, err := signature.Sign(this.signer, append([]byte(STATIC_KEY_PREFIX), this.s.pub[:]...))
+	if err != nil {
+		return nil, fmt.Errorf("sign static key: %s", err)
+	}
+	sink := common.NewZeroCopySink(nil)
+	sink.WriteVarBytes(keypair.SerializePublicKey(this.signer.PubKey()))
+	sink.WriteVarBytes(sig)
+	return sink.Bytes(), nil
+}
+
+// verifyIdentity checks the remote node key signed the remote static key
+func (this *handshakeState) verifyIdentity(payload []byte) error {
+	source := common.NewZeroCopySource(payload)
+	rawKey, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("read node key: %s", io.ErrUnexpectedEOF)
+	}
+	sig, eof := source.NextVarBytes()
+	if eof {
+		return fmt.Errorf("read static key signature: %s", io.ErrUnexpectedEOF)
+	}
+	pubKey, err := keypair.DeserializePublicKey(rawKey)
+	if err != nil {
+		return fmt.Errorf("deserialize node key: %s", err)
+	}
+	if err := signature.Verify(pubKey, append([]byte(STATIC_KEY_PREFIX), this.rs...), sig); err != nil {
+		return fmt.Errorf("verify static key: %s", err)
+	}
+	this.remoteKey = pubKey
+	return nil
+}
+
+// writeMessageE writes -> e
+func (this *handshakeState) writeMessageE() ([]byte, error) {
+	this.ss.mixHash(this.e.pub[:])
+	payload, err := this.ss.encryptAndHash(nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, this.e.pub[:]...), payload...), nil
+}
+
+func (this *handshakeState) readMessageE(msg []byte) error {
+	if len(msg) < DH_LEN {
+		return fmt.Errorf("short message e: %d", len(msg))
+	}
+	this.re = append([]byte{}, msg[:DH_LEN]...)
+	this.ss.mixHash(this.re)
+	_, err := this.ss.decryptAndHash(msg[DH_LEN:])
+	return err
+}
+
+// writeMessageEEsEs writes <- e, ee, s, es
+func (this *handshakeState) writeMessageEEsEs() ([]byte, error) {
+	msg := append([]byte{}, this.e.pub[:]...)
+	this.ss.mixHash(this.e.pub[:])
+	if err := this.mixDH(this.e, this.re); err != nil {
+		return nil, err
+	}
+	s, err := this.ss.encryptAndHash(this.s.pub[:])
+	if err != nil {
+		return nil, err
+	}
+	msg = append(msg, s...)
+	if err := this.mixDH(this.s, this.re); err != nil {
+		return nil, err
+	}
+	payload, err := this.identityPayload()
+	if err != nil {
+		return nil, err
+	}
+	payload, err = this.ss.encryptAndHash(payload)
+	if err != nil {
+		return nil, err
+	}
+	return append(msg, payload...), nil
+}
+
+func (this *handshakeState) readMessageEEsEs(msg []byte) error {
+	if len(msg) < DH_LEN+DH_LEN+TAG_LEN {
+		return fmt.Errorf("short message e, ee, s, es: %d", len(msg))
+	}
+	this.re = append([]byte{}, msg[:DH_LEN]...)
+	this.ss.mixHash(this.re)
+	if err := this.mixDH(this.e, this.re); err != nil {
+		return err
+	}
+	rs, err := this.ss.decryptAndHash(msg[DH_LEN : DH_LEN+DH_LEN+TAG_LEN])
+	if err != nil {
+		return fmt.Errorf("decrypt static key: %s", err)
+	}
+	this.rs = rs
+	if err := this.mixDH(this.e, this.rs); err != nil {
+		return err
+	}
+	payload, err := this.ss.decryptAndHash(msg[DH_LEN+DH_LEN+TAG_LEN:])
+	if err != nil {
+		return fmt.Errorf("decrypt payload: %s", err)
+	}
+	return this.verifyIdentity(payload)
+}
+
+// writeMessageSSe writes -> s, se
+func (this *handshakeState) writeMessageSSe() ([]byte, error) {
+	msg, err := this.ss.encryptAndHash(this.s.pub[:])
+	if err != nil {
+		return nil, err
+	}
+	if err := this.mixDH(this.s, this.re); err != nil {
+		return nil, err
+	}
+	payload, err := this.identityPayload()
+	if err != nil {
+		return nil, err
+	}
+	payload, err = this.ss.encryptAndHash(payload)
+	if err != nil {
+		return nil, err
+	}
+	return append(msg, payload...), nil
+}
+
+func (this *handshakeState) readMessageSSe(msg []byte) error {
+	if len(msg) < DH_LEN+TAG_LEN {
+		return fmt.Errorf("short message s, se: %d", len(msg))
+	}
+	rs, err := this.ss.decryptAndHash(msg[:DH_LEN+TAG_LEN])
+	if err != nil {
+		return fmt.Errorf("decrypt static key: %s", err)
+	}
+	this.rs = rs
+	if err := this.mixDH(this.e, this.rs); err != nil {
+		return err
+	}
+	payload, err := this.ss.decryptAndHash(msg[DH_LEN+TAG_LEN:])
+	if err != nil {
+		return fmt.Errorf("decrypt payload: %s", err)
+	}
+	return this.verifyIdentity(payload)
+}
+
+// Client runs the handshake as initiator over conn, and returns the secured connection.
+// Both sides must use the same prologue, e.g. the network magic.
+func Client(conn net.Conn, signer signature.Signer, prologue []byte) (*Conn, error) {
+	return handshake(conn, signer, prologue, true)
+}
+
+// Server runs the handshake as responder over conn, and returns the secured connection
+func Server(conn net.Conn, signer signature.Signer, prologue []byte) (*Conn, error) {
+	return handshake(conn, signer, prologue, false)
+}
+
+func handshake(conn net.Conn, signer signature.Signer, prologue []byte, initiator bool) (*Conn, error) {
+	hs, err := newHandshakeState(signer, initiator, prologue)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+	defer conn.SetDeadline(time.Time{})
+
+	if initiator {
+		err = hs.runInitiator(conn)
+	} else {
+		err = hs.runResponder(conn)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("noise handshake with %s: %s", conn.RemoteAddr(), err)
+	}
+	c1, c2 := hs.ss.split()
+	if initiator {
+		return newConn(conn, c1, c2, hs.remoteKey), nil
+	}
+	return newConn(conn, c2, c1, hs.remoteKey), nil
+}
+
+func (this *handshakeState) runInitiator(conn net.Conn) error {
+	msg, err := this.writeMessageE()
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(conn, msg); err != nil {
+		return err
+	}
+	if msg, err = readFrame(conn); err != nil {
+		return err
+	}
+	if err := this.readMessageEEsEs(msg); err != nil {
+		return err
+	}
+	if msg, err = this.writeMessageSSe(); err != nil {
+		return err
+	}
+	return writeFrame(conn, msg)
+}
+
+func (this *handshakeState) runResponder(conn net.Conn) error {
+	msg, err := readFrame(conn)
+	if err != nil {
+		return err
+	}
+	if err := this.readMessageE(msg); err != nil {
+		return err
+	}
+	if msg, err = this.writeMessageEEsEs(); err != nil {
+		return err
+	}
+	if err := writeFrame(conn, msg); err != nil {
+		return err
+	}
+	if msg, err = readFrame(conn); err != nil {
+		return err
+	}
+	return this.readMessageSSe(msg)
+}
+
+func writeFrame(w io.Writer, msg []byte) error {
+	if len(msg) > MAX_MSG_LEN {
+		return ErrMsgTooLong
+	}
+	frame := make([]byte, LEN_PREFIX+len(msg))
+	binary.BigEndian.PutUint16(frame, uint16(len(msg)))
+	copy(frame[LEN_PREFIX:], msg)
+	_, err := w.Write(frame)
+	return err
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	var prefix [LEN_PREFIX]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		return nil, err
+	}
+	msg := make([]byte, binary.BigEndian.Uint16(prefix[:]))
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
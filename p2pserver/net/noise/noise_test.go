@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package noise
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/stretchr/testify/assert"
+)
+
+type handshakeResult struct {
+	conn *Conn
+	err  error
+}
+
+func pipeHandshake(clientPrologue, serverPrologue []byte) (client, server handshakeResult, clientAcc, serverAcc *account.Account) {
+	clientAcc, serverAcc = account.NewAccount(""), account.NewAccount("")
+	c, s := net.Pipe()
+	done := make(chan handshakeResult)
+	go func() {
+		conn, err := Server(s, serverAcc, serverPrologue)
+		if err != nil {
+			s.Close()
+		}
+		done <- handshakeResult{conn, err}
+	}()
+	conn, err := Client(c, clientAcc, clientPrologue)
+	if err != nil {
+		c.Close()
+	}
+	client = handshakeResult{conn, err}
+	server = <-done
+	return
+}
+
+func TestHandshake(t *testing.T) {
+	client, server, clientAcc, serverAcc := pipeHandshake([]byte("magic"), []byte("magic"))
+	assert.Nil(t, client.err)
+	assert.Nil(t, server.err)
+	assert.Equal(t, PeerID(serverAcc.PublicKey), client.conn.RemoteID())
+	assert.Equal(t, PeerID(clientAcc.PublicKey), server.conn.RemoteID())
+
+	data := bytes.Repeat([]byte("poly"), MAX_PLAIN_LEN)
+	go func() {
+		client.conn.Write(data)
+	}()
+	buf := make([]byte, len(data))
+	_, err := io.ReadFull(server.conn, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, data, buf)
+
+	go func() {
+		server.conn.Write([]byte("pong"))
+	}()
+	buf = make([]byte, 4)
+	_, err = io.ReadFull(client.conn, buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("pong"), buf)
+}
+
+func TestHandshakePrologueMismatch(t *testing.T) {
+	client, server, _, _ := pipeHandshake([]byte("mainnet"), []byte("testnet"))
+	assert.NotNil(t, client.err)
+	assert.NotNil(t, server.err)
+}
+
+func TestTamperedMessage(t *testing.T) {
+	client, server, _, _ := pipeHandshake(nil, nil)
+	assert.Nil(t, client.err)
+	assert.Nil(t, server.err)
+
+	msg, err := client.conn.send.encrypt(nil, []byte("consensus"))
+	assert.Nil(t, err)
+	msg[0] ^= 1
+	go func() {
+		writeFrame(client.conn.Conn, msg)
+	}()
+	_, err = server.conn.Read(make([]byte, 16))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package noise
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	"github.com/polynetwork/poly/common/config"
+)
+
+// ReservedKeysPinned returns whether reserved peers are pinned by node key instead of address
+func ReservedKeysPinned() bool {
+	rsvOnly, rsvCfg := config.DefConfig.GetReservedCfg()
+	return config.DefConfig.P2PNode.IsNoise && rsvOnly && len(rsvCfg.ReservedKeys) > 0
+}
+
+// IsReservedKey returns whether the node key is one of reserved keys
+func IsReservedKey(pubKey keypair.PublicKey) bool {
+	key := hex.EncodeToString(keypair.SerializePublicKey(pubKey))
+	_, rsvCfg := config.DefConfig.GetReservedCfg()
+	for _, k := range rsvCfg.ReservedKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -43,6 +43,9 @@ type P2P interface {
 	GetNeighborAddrs() []common.PeerAddr
 	GetConnectionCnt() uint32
 	GetNp() *peer.NbrPeers
+	GetBanList() *peer.BanList
+	GetPeerDB() *peer.PeerDB
+	GetNodeRecord() *types.NodeRecord
 	GetPeer(uint64) *peer.Peer
 	SetHeight(uint64)
 	IsPeerEstablished(p *peer.Peer) bool
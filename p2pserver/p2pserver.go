@@ -32,12 +32,14 @@ import (
 	"time"
 
 	evtActor "github.com/ontio/ontology-eventbus/actor"
+	"github.com/polynetwork/poly/account"
 	comm "github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	"github.com/polynetwork/poly/core/ledger"
 	"github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/link"
 	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
 	msgtypes "github.com/polynetwork/poly/p2pserver/message/types"
 	"github.com/polynetwork/poly/p2pserver/message/utils"
@@ -58,6 +60,9 @@ type P2PServer struct {
 	quitSyncRecent chan bool
 	quitOnline     chan bool
 	quitHeartBeat  chan bool
+	quitRecord     chan bool
+	resolver       DNSResolver
+	proofs         *proofWaits
 }
 
 //ReconnectAddrs contain addr need to reconnect
@@ -68,7 +73,13 @@ type ReconnectAddrs struct {
 
 //NewServer return a new p2pserver according to the pubkey
 func NewServer() *P2PServer {
-	n := netserver.NewNetServer()
+	return NewServerWithAccount(nil)
+}
+
+//NewServerWithAccount return a new p2pserver whose links are authenticated with
+//the account key when noise is enabled
+func NewServerWithAccount(acc *account.Account) *P2PServer {
+	n := netserver.NewNetServerWithAccount(acc)
 
 	p := &P2PServer{
 		network: n,
@@ -81,6 +92,9 @@ func NewServer() *P2PServer {
 	p.quitSyncRecent = make(chan bool)
 	p.quitOnline = make(chan bool)
 	p.quitHeartBeat = make(chan bool)
+	p.quitRecord = make(chan bool, 1)
+	p.resolver = DefaultDNSResolver
+	p.proofs = newProofWaits()
 	return p
 }
 
@@ -106,6 +120,7 @@ func (this *P2PServer) Start() error {
 	go this.syncUpRecentPeers()
 	go this.keepOnlineService()
 	go this.heartBeatService()
+	go this.nodeRecordService()
 	go this.blockSync.Start()
 	return nil
 }
@@ -116,6 +131,7 @@ func (this *P2PServer) Stop() {
 	this.quitSyncRecent <- true
 	this.quitOnline <- true
 	this.quitHeartBeat <- true
+	this.quitRecord <- true
 	this.msgRouter.Stop()
 	this.blockSync.Close()
 }
@@ -140,6 +156,11 @@ func (this *P2PServer) GetNeighborAddrs() []common.PeerAddr {
 	return this.network.GetNeighborAddrs()
 }
 
+//GetMsgStats return the bytes sent and received per message type
+func (this *P2PServer) GetMsgStats() map[string]link.MsgStat {
+	return link.GetMsgStats()
+}
+
 //Xmit called by other module to broadcast msg
 func (this *P2PServer) Xmit(message interface{}) error {
 	log.Debug()
@@ -188,6 +209,10 @@ func (this *P2PServer) GetID() uint64 {
 
 // OnAddNode adds the peer id to the block sync mgr
 func (this *P2PServer) OnAddNode(id uint64) {
+	//light peers keep headers only, never sync from them
+	if p := this.getNode(id); p != nil && p.GetServices() == common.LIGHT_NODE {
+		return
+	}
 	this.blockSync.OnAddNode(id)
 }
 
@@ -371,6 +396,21 @@ func (this *P2PServer) getNode(id uint64) *peer.Peer {
 	return this.network.GetPeer(id)
 }
 
+//banPeer ban the peer serving invalid data and disconnect it
+func (this *P2PServer) banPeer(id uint64, reason string) {
+	var ip string
+	p := this.network.GetPeer(id)
+	if p != nil {
+		ip, _ = common.ParseIPAddr(p.GetAddr())
+	}
+	this.network.GetBanList().Ban(id, ip, common.BAN_DURATION*time.Second, reason)
+	this.blockSync.delNode(id)
+	if p != nil {
+		p.CloseSync()
+		p.CloseCons()
+	}
+}
+
 //retryInactivePeer try to connect peer in INACTIVITY state
 func (this *P2PServer) retryInactivePeer() {
 	np := this.network.GetNp()
@@ -399,9 +439,9 @@ func (this *P2PServer) retryInactivePeer() {
 	np.Unlock()
 
 	connCount := uint(this.network.GetOutConnRecordLen())
-	if connCount >= config.DefConfig.P2PNode.MaxConnOutBound {
-		log.Warnf("[p2p]Connect: out connections(%d) reach the max limit(%d)", connCount,
-			config.DefConfig.P2PNode.MaxConnOutBound)
+	_, maxOutBound, _ := config.DefConfig.GetMaxConnLimits()
+	if connCount >= maxOutBound {
+		log.Warnf("[p2p]Connect: out connections(%d) reach the max limit(%d)", connCount, maxOutBound)
 		return
 	}
 
@@ -488,6 +528,66 @@ func (this *P2PServer) reqNbrList(p *peer.Peer) {
 	go this.Send(p, msg, false)
 }
 
+//nodeRecordService load node records from dns seeds, then exchange records
+//with nbr peers and connect the nodes learned periodically
+func (this *P2PServer) nodeRecordService() {
+	this.tryDNSSeeds()
+	this.connectRecords()
+	t := time.NewTicker(time.Second * common.NODE_RECORD_CYCLE)
+	for {
+		select {
+		case <-t.C:
+			this.reqNodeRecords()
+			this.connectRecords()
+		case <-this.quitRecord:
+			t.Stop()
+			return
+		}
+	}
+}
+
+//tryDNSSeeds add the node records published in dns seed list to peer db
+func (this *P2PServer) tryDNSSeeds() {
+	names := config.DefConfig.Genesis.DNSSeedList
+	if len(names) == 0 {
+		return
+	}
+	records := ResolveDNSSeeds(this.resolver, names)
+	log.Infof("[p2p]load %d node records from dns seeds", len(records))
+	for _, record := range records {
+		if _, err := this.network.GetPeerDB().Add(record, ""); err != nil {
+			log.Warnf("[p2p]add dns seed record err: %s", err)
+		}
+	}
+}
+
+//reqNodeRecords ask the nbr peers for the node records they known
+func (this *P2PServer) reqNodeRecords() {
+	msg := msgpack.NewNodeRecordsReq()
+	for _, p := range this.network.GetNeighbors() {
+		if p.GetSyncState() == common.ESTABLISH {
+			go this.Send(p, msg, false)
+		}
+	}
+}
+
+//connectRecords connect the nodes in peer db while out connections are not full
+func (this *P2PServer) connectRecords() {
+	_, maxOutBound, _ := config.DefConfig.GetMaxConnLimits()
+	slots := int(maxOutBound) - this.network.GetOutConnRecordLen()
+	for _, addr := range this.network.GetPeerDB().GetSyncAddrs() {
+		if slots <= 0 {
+			break
+		}
+		if this.network.IsOwnAddress(addr) || this.network.GetPeerFromAddr(addr) != nil ||
+			this.network.IsAddrFromConnecting(addr) {
+			continue
+		}
+		slots--
+		go this.network.Connect(addr, false)
+	}
+}
+
 //heartBeat send ping to nbr peers and check the timeout
 func (this *P2PServer) heartBeatService() {
 	var periodTime uint
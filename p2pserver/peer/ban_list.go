@@ -0,0 +1,150 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+)
+
+//BanEntry record a banned peer
+type BanEntry struct {
+	ID     uint64 `json:"id"`
+	IP     string `json:"ip"`
+	Until  int64  `json:"until"` //unix time when the ban expires
+	Reason string `json:"reason"`
+}
+
+//BanList: peers banned for serving invalid data, persisted so the bans survive restart
+type BanList struct {
+	sync.RWMutex
+	path    string
+	entries []*BanEntry
+}
+
+//NewBanList load the ban list from file, empty path keeps the list in memory only
+func NewBanList(path string) *BanList {
+	this := &BanList{path: path}
+	if path == "" || !comm.FileExisted(path) {
+		return this
+	}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Warnf("[p2p]read %s fail:%s", path, err)
+		return this
+	}
+	if err := json.Unmarshal(buf, &this.entries); err != nil {
+		log.Warnf("[p2p]parse %s fail:%s", path, err)
+		return this
+	}
+	this.expire()
+	return this
+}
+
+//Ban ban the peer id and ip for duration
+func (this *BanList) Ban(id uint64, ip string, duration time.Duration, reason string) {
+	this.Lock()
+	defer this.Unlock()
+	this.expire()
+	until := time.Now().Add(duration).Unix()
+	for _, e := range this.entries {
+		if e.ID == id && e.IP == ip {
+			e.Until = until
+			e.Reason = reason
+			this.save()
+			return
+		}
+	}
+	this.entries = append(this.entries, &BanEntry{ID: id, IP: ip, Until: until, Reason: reason})
+	log.Warnf("[p2p]ban peer %d ip %s until %s: %s", id, ip, time.Unix(until, 0), reason)
+	this.save()
+}
+
+//IsBanned return whether the peer id is banned
+func (this *BanList) IsBanned(id uint64) bool {
+	this.RLock()
+	defer this.RUnlock()
+	now := time.Now().Unix()
+	for _, e := range this.entries {
+		if e.ID == id && e.Until > now {
+			return true
+		}
+	}
+	return false
+}
+
+//IsIPBanned return whether the ip is banned
+func (this *BanList) IsIPBanned(ip string) bool {
+	this.RLock()
+	defer this.RUnlock()
+	now := time.Now().Unix()
+	for _, e := range this.entries {
+		if e.IP != "" && e.IP == ip && e.Until > now {
+			return true
+		}
+	}
+	return false
+}
+
+//GetEntries return the bans not expired
+func (this *BanList) GetEntries() []BanEntry {
+	this.RLock()
+	defer this.RUnlock()
+	now := time.Now().Unix()
+	entries := make([]BanEntry, 0, len(this.entries))
+	for _, e := range this.entries {
+		if e.Until > now {
+			entries = append(entries, *e)
+		}
+	}
+	return entries
+}
+
+//expire remove expired bans, caller should hold the lock
+func (this *BanList) expire() {
+	now := time.Now().Unix()
+	entries := this.entries[:0]
+	for _, e := range this.entries {
+		if e.Until > now {
+			entries = append(entries, e)
+		}
+	}
+	this.entries = entries
+}
+
+//save write the bans to file, caller should hold the lock
+func (this *BanList) save() {
+	if this.path == "" {
+		return
+	}
+	buf, err := json.Marshal(this.entries)
+	if err != nil {
+		log.Warn("[p2p]package ban list fail: ", err)
+		return
+	}
+	if err := ioutil.WriteFile(this.path, buf, os.ModePerm); err != nil {
+		log.Warn("[p2p]write ban list fail: ", err)
+	}
+}
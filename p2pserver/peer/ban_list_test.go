@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBanList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "banlist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "peers.banned")
+
+	bans := NewBanList(path)
+	bans.Ban(1, "10.0.0.1", time.Hour, "invalid block")
+	bans.Ban(2, "10.0.0.2", -time.Second, "expired")
+	if !bans.IsBanned(1) || !bans.IsIPBanned("10.0.0.1") {
+		t.Error("TestBanList peer not banned")
+	}
+	if bans.IsBanned(2) || bans.IsIPBanned("10.0.0.2") {
+		t.Error("TestBanList expired ban still effective")
+	}
+
+	reloaded := NewBanList(path)
+	if !reloaded.IsBanned(1) || !reloaded.IsIPBanned("10.0.0.1") {
+		t.Error("TestBanList ban not persisted")
+	}
+	if len(reloaded.GetEntries()) != 1 {
+		t.Error("TestBanList expired ban persisted", reloaded.GetEntries())
+	}
+}
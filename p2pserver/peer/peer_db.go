@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ontio/ontology-crypto/keypair"
+	comm "github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+//PeerDBEntry record a node record and where the node was seen
+type PeerDBEntry struct {
+	Record   string `json:"record"` //hex of the signed node record
+	Host     string `json:"host"`   //observed host, used when record carries no host
+	LastSeen int64  `json:"lastSeen"`
+
+	record *types.NodeRecord
+}
+
+//PeerDB: signed node records learned from peers and dns seeds, persisted so discovery survives restart
+type PeerDB struct {
+	sync.RWMutex
+	path    string
+	entries map[string]*PeerDBEntry //key is hex of node public key
+}
+
+//NewPeerDB load the peer db from file, empty path keeps the db in memory only
+func NewPeerDB(path string) *PeerDB {
+	this := &PeerDB{path: path, entries: make(map[string]*PeerDBEntry)}
+	if path == "" || !comm.FileExisted(path) {
+		return this
+	}
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Warnf("[p2p]read %s fail:%s", path, err)
+		return this
+	}
+	var entries []*PeerDBEntry
+	if err := json.Unmarshal(buf, &entries); err != nil {
+		log.Warnf("[p2p]parse %s fail:%s", path, err)
+		return this
+	}
+	expired := time.Now().Unix() - common.NODE_RECORD_TIMEOUT
+	for _, e := range entries {
+		if e.LastSeen < expired {
+			continue
+		}
+		record, err := decodeNodeRecord(e.Record)
+		if err != nil {
+			log.Warnf("[p2p]drop invalid node record in %s:%s", path, err)
+			continue
+		}
+		e.record = record
+		this.entries[recordKey(record)] = e
+	}
+	return this
+}
+
+//Add verify and store the record, host is where the record owner was seen and may be empty.
+//return whether the db learned something new
+func (this *PeerDB) Add(record *types.NodeRecord, host string) (bool, error) {
+	if err := record.Verify(); err != nil {
+		return false, fmt.Errorf("invalid node record signature: %s", err)
+	}
+	this.Lock()
+	defer this.Unlock()
+	key := recordKey(record)
+	now := time.Now().Unix()
+	if e, ok := this.entries[key]; ok {
+		if e.record.Seq >= record.Seq {
+			if host != "" {
+				e.Host = host
+				e.LastSeen = now
+				this.save()
+			}
+			return false, nil
+		}
+		if host == "" {
+			host = e.Host
+		}
+	}
+	sink := comm.NewZeroCopySink(nil)
+	record.Serialization(sink)
+	this.entries[key] = &PeerDBEntry{
+		Record:   hex.EncodeToString(sink.Bytes()),
+		Host:     host,
+		LastSeen: now,
+		record:   record,
+	}
+	this.evict()
+	this.save()
+	return true, nil
+}
+
+//Get return the record of the node key
+func (this *PeerDB) Get(pubKey keypair.PublicKey) *types.NodeRecord {
+	this.RLock()
+	defer this.RUnlock()
+	if e, ok := this.entries[hex.EncodeToString(keypair.SerializePublicKey(pubKey))]; ok {
+		return e.record
+	}
+	return nil
+}
+
+//Len return the count of records
+func (this *PeerDB) Len() int {
+	this.RLock()
+	defer this.RUnlock()
+	return len(this.entries)
+}
+
+//GetRecords return at most max records, most recently seen first
+func (this *PeerDB) GetRecords(max int) []*types.NodeRecord {
+	entries := this.sortedEntries()
+	records := make([]*types.NodeRecord, 0, len(entries))
+	for _, e := range entries {
+		if len(records) >= max {
+			break
+		}
+		records = append(records, e.record)
+	}
+	return records
+}
+
+//GetSyncAddrs return the sync address of reachable records, most recently seen first
+func (this *PeerDB) GetSyncAddrs() []string {
+	entries := this.sortedEntries()
+	addrs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		host := e.record.Host
+		if host == "" {
+			host = e.Host
+		}
+		if host == "" || e.record.SyncPort == 0 {
+			continue
+		}
+		addrs = append(addrs, net.JoinHostPort(host, strconv.Itoa(int(e.record.SyncPort))))
+	}
+	return addrs
+}
+
+func (this *PeerDB) sortedEntries() []*PeerDBEntry {
+	this.RLock()
+	entries := make([]*PeerDBEntry, 0, len(this.entries))
+	for _, e := range this.entries {
+		entries = append(entries, e)
+	}
+	this.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastSeen > entries[j].LastSeen
+	})
+	return entries
+}
+
+//evict drop the least recently seen records over limit, caller should hold the lock
+func (this *PeerDB) evict() {
+	for len(this.entries) > common.PEER_DB_LIMIT {
+		var oldest string
+		for key, e := range this.entries {
+			if oldest == "" || e.LastSeen < this.entries[oldest].LastSeen {
+				oldest = key
+			}
+		}
+		delete(this.entries, oldest)
+	}
+}
+
+//save write the records to file, caller should hold the lock
+func (this *PeerDB) save() {
+	if this.path == "" {
+		return
+	}
+	entries := make([]*PeerDBEntry, 0, len(this.entries))
+	for _, e := range this.entries {
+		entries = append(entries, e)
+	}
+	buf, err := json.Marshal(entries)
+	if err != nil {
+		log.Warn("[p2p]package peer db fail: ", err)
+		return
+	}
+	if err := ioutil.WriteFile(this.path, buf, os.ModePerm); err != nil {
+		log.Warn("[p2p]write peer db fail: ", err)
+	}
+}
+
+func recordKey(record *types.NodeRecord) string {
+	return hex.EncodeToString(keypair.SerializePublicKey(record.PubKey))
+}
+
+func decodeNodeRecord(str string) (*types.NodeRecord, error) {
+	buf, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+	record := &types.NodeRecord{}
+	if err := record.Deserialization(comm.NewZeroCopySource(buf)); err != nil {
+		return nil, err
+	}
+	if err := record.Verify(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package peer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/polynetwork/poly/account"
+	"github.com/polynetwork/poly/p2pserver/message/types"
+)
+
+func TestPeerDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "peerdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "peers.db")
+
+	acc := account.NewAccount("")
+	record, _ := types.NewNodeRecord(acc, 2, "10.0.0.1", 20338, 20339)
+	stale, _ := types.NewNodeRecord(acc, 1, "10.0.0.9", 20338, 20339)
+	hostless, _ := types.NewNodeRecord(account.NewAccount(""), 1, "", 20348, 20349)
+
+	db := NewPeerDB(path)
+	if added, err := db.Add(record, ""); !added || err != nil {
+		t.Fatal("TestPeerDB add record fail", err)
+	}
+	if added, _ := db.Add(stale, ""); added {
+		t.Error("TestPeerDB older record replaced newer one")
+	}
+	db.Add(hostless, "10.0.0.1")
+	//host of known record seen again is persisted
+	db.Add(hostless, "10.0.0.2")
+	forged := *record
+	forged.Seq = 3
+	if _, err := db.Add(&forged, ""); err == nil {
+		t.Error("TestPeerDB forged record accepted")
+	}
+
+	reloaded := NewPeerDB(path)
+	if reloaded.Len() != 2 {
+		t.Fatal("TestPeerDB records not persisted", reloaded.Len())
+	}
+	if r := reloaded.Get(acc.PublicKey); r == nil || r.Seq != 2 {
+		t.Error("TestPeerDB wrong record reloaded", r)
+	}
+	addrs := map[string]bool{}
+	for _, addr := range reloaded.GetSyncAddrs() {
+		addrs[addr] = true
+	}
+	if len(addrs) != 2 || !addrs["10.0.0.1:20338"] || !addrs["10.0.0.2:20348"] {
+		t.Error("TestPeerDB wrong sync addrs", addrs)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/polynetwork/poly/common/log"
+	"github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/message/msg_pack"
+	msgtypes "github.com/polynetwork/poly/p2pserver/message/types"
+	"github.com/polynetwork/poly/p2pserver/peer"
+)
+
+type proofWaitKey struct {
+	reqID  string
+	peerID uint64
+}
+
+//proofWaits dispatch the proofs answered by peers to the waiting requests
+type proofWaits struct {
+	sync.Mutex
+	waits map[proofWaitKey][]chan []byte
+}
+
+func newProofWaits() *proofWaits {
+	return &proofWaits{waits: make(map[proofWaitKey][]chan []byte)}
+}
+
+func (this *proofWaits) add(key proofWaitKey) chan []byte {
+	this.Lock()
+	defer this.Unlock()
+	ch := make(chan []byte, 1)
+	this.waits[key] = append(this.waits[key], ch)
+	return ch
+}
+
+func (this *proofWaits) remove(key proofWaitKey, ch chan []byte) {
+	this.Lock()
+	defer this.Unlock()
+	chs := this.waits[key]
+	for i, c := range chs {
+		if c == ch {
+			chs = append(chs[:i], chs[i+1:]...)
+			break
+		}
+	}
+	if len(chs) == 0 {
+		delete(this.waits, key)
+	} else {
+		this.waits[key] = chs
+	}
+}
+
+//deliver the proof to requests waiting for it, return false if no one waits
+func (this *proofWaits) deliver(key proofWaitKey, proof []byte) bool {
+	this.Lock()
+	defer this.Unlock()
+	chs, ok := this.waits[key]
+	if !ok {
+		return false
+	}
+	delete(this.waits, key)
+	for _, ch := range chs {
+		select {
+		case ch <- proof:
+		default:
+		}
+	}
+	return true
+}
+
+//OnProofReceive dispatch the proof answered by peer
+func (this *P2PServer) OnProofReceive(fromID uint64, reqID string, proof []byte) {
+	if !this.proofs.deliver(proofWaitKey{reqID: reqID, peerID: fromID}, proof) {
+		log.Debugf("[p2p]drop unrequested proof %s from peer %d", reqID, fromID)
+	}
+}
+
+//FetchMerkleProof fetch the block merkle proof from full peers, implement ledgerstore.ProofFetcher
+func (this *P2PServer) FetchMerkleProof(proofHeight, rootHeight uint32, verify func(proof []byte) error) ([]byte, error) {
+	req := msgpack.NewProofReq(common.MERKLE_PROOF, proofHeight, rootHeight, nil).(*msgtypes.ProofReq)
+	return this.fetchProof(req, verify)
+}
+
+//FetchCrossStatesProof fetch the cross states proof from full peers, implement ledgerstore.ProofFetcher
+func (this *P2PServer) FetchCrossStatesProof(height uint32, key []byte, verify func(proof []byte) error) ([]byte, error) {
+	req := msgpack.NewProofReq(common.CROSS_STATES_PROOF, height, 0, key).(*msgtypes.ProofReq)
+	return this.fetchProof(req, verify)
+}
+
+//fetchProof ask full peers in random order until one answers a proof passing verify,
+//peers answering invalid proofs are banned
+func (this *P2PServer) fetchProof(req *msgtypes.ProofReq, verify func(proof []byte) error) ([]byte, error) {
+	peers := this.proofPeers()
+	if len(peers) == 0 {
+		return nil, errors.New("[p2p]no full peer to fetch proof")
+	}
+	if len(peers) > common.PROOF_MAX_TRY {
+		peers = peers[:common.PROOF_MAX_TRY]
+	}
+	for _, p := range peers {
+		proof, err := this.requestProof(p, req)
+		if err != nil {
+			log.Debugf("[p2p]request proof %s from peer %d: %s", req.ReqID(), p.GetID(), err)
+			continue
+		}
+		if len(proof) == 0 {
+			log.Debugf("[p2p]peer %d has no proof %s", p.GetID(), req.ReqID())
+			continue
+		}
+		if err := verify(proof); err != nil {
+			log.Warnf("[p2p]invalid proof %s from peer %d: %s", req.ReqID(), p.GetID(), err)
+			this.banPeer(p.GetID(), fmt.Sprintf("invalid proof: %s", err))
+			continue
+		}
+		return proof, nil
+	}
+	return nil, fmt.Errorf("[p2p]failed to fetch proof %s from %d peers", req.ReqID(), len(peers))
+}
+
+//proofPeers return the established full peers in random order
+func (this *P2PServer) proofPeers() []*peer.Peer {
+	var peers []*peer.Peer
+	for _, p := range this.network.GetNeighbors() {
+		if p.GetServices() == common.LIGHT_NODE {
+			continue
+		}
+		peers = append(peers, p)
+	}
+	rand.Shuffle(len(peers), func(i, j int) {
+		peers[i], peers[j] = peers[j], peers[i]
+	})
+	return peers
+}
+
+//requestProof send the request to peer and wait for the answer
+func (this *P2PServer) requestProof(p *peer.Peer, req *msgtypes.ProofReq) ([]byte, error) {
+	key := proofWaitKey{reqID: req.ReqID(), peerID: p.GetID()}
+	ch := this.proofs.add(key)
+	defer this.proofs.remove(key, ch)
+	err := this.Send(p, req, false)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case proof := <-ch:
+		return proof, nil
+	case <-time.After(common.PROOF_TIMEOUT * time.Second):
+		return nil, errors.New("[p2p]proof request timeout")
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2021 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The poly network is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The poly network is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the poly network.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2pserver
+
+import (
+	"testing"
+	"time"
+
+	p2pComm "github.com/polynetwork/poly/p2pserver/common"
+	"github.com/polynetwork/poly/p2pserver/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProofWaitsDeliver(t *testing.T) {
+	waits := newProofWaits()
+	key := proofWaitKey{reqID: "1:1:2:", peerID: 1}
+	ch1 := waits.add(key)
+	ch2 := waits.add(key)
+
+	assert.False(t, waits.deliver(proofWaitKey{reqID: key.reqID, peerID: 2}, []byte("proof")))
+	assert.True(t, waits.deliver(key, []byte("proof")))
+	assert.Equal(t, []byte("proof"), <-ch1)
+	assert.Equal(t, []byte("proof"), <-ch2)
+	assert.False(t, waits.deliver(key, []byte("proof")))
+}
+
+func TestProofWaitsRemove(t *testing.T) {
+	waits := newProofWaits()
+	key := proofWaitKey{reqID: "2:1:0:6b6579", peerID: 1}
+	ch1 := waits.add(key)
+	ch2 := waits.add(key)
+	waits.remove(key, ch1)
+	assert.True(t, waits.deliver(key, nil))
+	select {
+	case <-ch1:
+		t.Fatal("removed wait received proof")
+	default:
+	}
+	assert.Nil(t, <-ch2)
+	waits.remove(key, ch2)
+	assert.Equal(t, 0, len(waits.waits))
+}
+
+func TestFetchProofWithoutPeers(t *testing.T) {
+	server := newSyncTestServer(t)
+	_, err := server.FetchCrossStatesProof(1, []byte("key"), func(proof []byte) error { return nil })
+	assert.NotNil(t, err)
+}
+
+func TestProofPeersSkipLightNode(t *testing.T) {
+	server := newSyncTestServer(t, 1)
+	light := peer.NewPeer()
+	light.UpdateInfo(time.Now(), 0, p2pComm.LIGHT_NODE, 20338, 20339, 2, 0, 1000, "")
+	light.SetSyncState(p2pComm.ESTABLISH)
+	light.SyncLink.SetAddr("127.0.0.2:20338")
+	server.network.AddNbrNode(light)
+	server.OnAddNode(2)
+
+	peers := server.proofPeers()
+	assert.Equal(t, 1, len(peers))
+	assert.Equal(t, uint64(1), peers[0].GetID())
+	assert.Nil(t, server.blockSync.getNodeWeight(2))
+}
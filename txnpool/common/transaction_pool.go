@@ -131,7 +131,7 @@ func (tp *TXPool) GetTxPool(byCount bool, height uint32) ([]*TXEntry,
 		orderByFee = append(orderByFee, txEntry)
 	}
 
-	count := int(config.DefConfig.Consensus.MaxTxInBlock)
+	count := int(config.DefConfig.GetMaxTxInBlock())
 	if count <= 0 {
 		byCount = false
 	}
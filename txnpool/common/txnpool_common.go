@@ -25,7 +25,7 @@ import (
 )
 
 const (
-	MAX_CAPACITY     = 100140                           // The tx pool's capacity that holds the verified txs
+	MAX_CAPACITY     = 100140                           // The default tx pool's capacity that holds the verified txs, see config TxPool.Capacity
 	MAX_PENDING_TXN  = 4096 * 10                        // The max length of pending txs
 	MAX_WORKER_NUM   = 2                                // The max concurrent workers
 	MAX_RCV_TXN_LEN  = MAX_WORKER_NUM * MAX_PENDING_TXN // The max length of the queue that server can hold
@@ -25,6 +25,7 @@ import (
 	"github.com/ontio/ontology-eventbus/actor"
 
 	"github.com/polynetwork/poly/common"
+	"github.com/polynetwork/poly/common/config"
 	"github.com/polynetwork/poly/common/log"
 	tx "github.com/polynetwork/poly/core/types"
 	"github.com/polynetwork/poly/errors"
@@ -95,7 +96,7 @@ func (ta *TxActor) handleTransaction(sender tc.SenderType, self *actor.PID,
 			replyTxResult(txResultCh, txn.Hash(), errors.ErrDuplicateInput,
 				fmt.Sprintf("transaction %x is already in the tx pool", txn.Hash()))
 		}
-	} else if ta.server.getTransactionCount() >= tc.MAX_CAPACITY {
+	} else if uint(ta.server.getTransactionCount()) >= config.DefConfig.GetTxPoolCapacity() {
 		log.Debugf("handleTransaction: transaction pool is full for tx %x",
 			txn.Hash())
 
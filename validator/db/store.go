@@ -26,7 +26,7 @@ import (
 	"github.com/polynetwork/poly/common"
 	"github.com/polynetwork/poly/common/serialization"
 	storcomm "github.com/polynetwork/poly/core/store/common"
-	leveldb "github.com/polynetwork/poly/core/store/leveldbstore"
+	"github.com/polynetwork/poly/core/store/kvstore"
 	"github.com/polynetwork/poly/core/types"
 	pool "github.com/valyala/bytebufferpool"
 )
@@ -43,7 +43,7 @@ type Store struct {
 }
 
 func NewStore(path string) (*Store, error) {
-	ldb, err := leveldb.NewLevelDBStore(path)
+	ldb, err := kvstore.Open(path)
 	if err != nil {
 		return nil, err
 	}